@@ -0,0 +1,65 @@
+// api/openapi/openapi.go
+
+// Package openapi embeds the OpenAPI document of the REST API and the page
+// that renders it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+//go:embed docs.html
+var docsHTML []byte
+
+// The validator only checks the formats it is told about; email is the one
+// the document relies on for request validation.
+func init() {
+	openapi3.DefineStringFormatCallback("email", func(value string) error {
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return errors.New("not a valid email address")
+		}
+		return nil
+	})
+}
+
+// YAML returns the document as written in openapi.yaml.
+func YAML() []byte { return specYAML }
+
+// DocsHTML returns the page served at /docs, which renders /openapi.json.
+func DocsHTML() []byte { return docsHTML }
+
+var jsonOnce = sync.OnceValues(func() ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(specYAML, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi.yaml: %w", err)
+	}
+	return json.Marshal(doc)
+})
+
+// JSON returns the document converted to JSON, as served at /openapi.json.
+func JSON() ([]byte, error) { return jsonOnce() }
+
+// Load parses and validates the document.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(specYAML)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi.yaml: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi.yaml: %w", err)
+	}
+	return doc, nil
+}
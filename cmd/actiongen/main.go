@@ -0,0 +1,41 @@
+// cmd/actiongen/main.go
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"my-application/internal/api/handler"
+	"my-application/internal/hasura"
+)
+
+// actiongen writes the Hasura Actions metadata (actions.graphql and
+// actions.yaml) from the actions registered in handler.NewActionsHandler,
+// so the metadata cannot drift from the Go definitions.
+func main() {
+	dir := flag.String("dir", "hasura/metadata", "Hasura metadata directory")
+	flag.Parse()
+
+	defs := handler.NewActionsHandler(nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil))).Definitions()
+
+	sdl, err := hasura.RenderActionsGraphQL(defs)
+	if err != nil {
+		log.Fatalf("rendering actions.graphql: %v", err)
+	}
+	meta, err := hasura.RenderActionsYAML(defs)
+	if err != nil {
+		log.Fatalf("rendering actions.yaml: %v", err)
+	}
+
+	for name, data := range map[string][]byte{"actions.graphql": sdl, "actions.yaml": meta} {
+		path := filepath.Join(*dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			log.Fatalf("writing %s: %v", path, err)
+		}
+		log.Printf("wrote %s", path)
+	}
+}
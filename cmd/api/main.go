@@ -5,20 +5,31 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 
+	"my-application/api/openapi"
 	"my-application/config"
 	"my-application/internal/api/handler"
 	"my-application/internal/api/middleware"
 	"my-application/internal/api/router"
+	"my-application/internal/api/rpc"
 	"my-application/internal/auth"
+	"my-application/internal/hasura"
+	"my-application/internal/i18n"
+	"my-application/internal/idempotency"
+	"my-application/internal/jobs"
+	"my-application/internal/realtime"
 	"my-application/internal/repository/postgres"
 	"my-application/internal/service"
+	"my-application/internal/webhook"
 	"my-application/pkg/database"
 	fbclient "my-application/pkg/firebase"
 	"my-application/pkg/logger"
@@ -91,12 +102,61 @@ func run() error {
 
 	// 6. Repository layer.
 	userRepo := postgres.NewUserPostgres(dbPool, log)
+	auditRepo := postgres.NewAuditPostgres(dbPool, log)
+	exportRepo := postgres.NewDataExportPostgres(dbPool, log)
+	retentionRepo := postgres.NewRetentionPostgres(dbPool, log)
+	notificationRepo := postgres.NewNotificationPostgres(dbPool, log)
+	residentRepo := postgres.NewResidentPostgres(dbPool, log)
+	outboxRepo := postgres.NewOutboxPostgres(dbPool, log)
+	webhookRepo := postgres.NewWebhookPostgres(dbPool, log)
+	robotRepo := postgres.NewRobotPostgres(dbPool, log)
+	incidentRepo := postgres.NewIncidentPostgres(dbPool, log)
+	importRepo := postgres.NewUserImportPostgres(dbPool, log)
+	invitationRepo := postgres.NewInvitationPostgres(dbPool, log)
+	txManager := database.NewTxManager(dbPool)
+	jobClient := jobs.NewClient(dbPool)
 
 	// 7. Service layer.
-	userSvc := service.NewUserService(userRepo, log)
+	userSvc := service.NewUserService(userRepo, outboxRepo, txManager, log)
+	invitationSvc := service.NewInvitationService(invitationRepo, userRepo, auditRepo, jobClient, txManager, service.InvitationConfig{
+		TTL: cfg.Invitations.TTL,
+	}, log)
+	importSvc := service.NewUserImportService(userSvc, invitationSvc, userRepo, importRepo, auditRepo, jobClient, txManager,
+		service.UserImportConfig{
+			InlineRows: cfg.Imports.InlineRows,
+			MaxRows:    cfg.Imports.MaxRows,
+		}, log)
+	exportSvc := service.NewDataExportService(userRepo, exportRepo, auditRepo, jobClient, txManager, log)
+	retentionSvc := service.NewRetentionService(retentionRepo, auditRepo, log)
+	notificationSvc := service.NewNotificationService(notificationRepo, log)
+	webhookSvc := service.NewWebhookService(webhookRepo, auditRepo,
+		webhook.NewSender(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateNetworks), service.WebhookConfig{
+			AllowHTTP:            cfg.Webhooks.AllowHTTP,
+			AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+		}, log)
+	robotSvc := service.NewRobotService(robotRepo, residentRepo, auditRepo, txManager, log)
+	incidentSvc := service.NewIncidentService(incidentRepo, residentRepo, auditRepo, outboxRepo, txManager, log)
+	caregiverSvc := service.NewCaregiverService(userRepo, residentRepo, auditRepo, txManager, log)
+
+	// Live events are received from Postgres by every replica.
+	hub := realtime.NewHub(realtime.Config{
+		BufferSize:   cfg.Realtime.BufferSize,
+		ClientBuffer: cfg.Realtime.ClientBuffer,
+	})
+	listenCtx, stopListening := context.WithCancel(ctx)
+	defer stopListening()
+	go realtime.NewListener(dbPool, hub, log).Run(listenCtx)
+	realtimeSvc := service.NewRealtimeService(hub, residentRepo, log)
+
+	// Row changes made through Hasura arrive as event triggers.
+	tableEventSvc := service.NewTableEventService(residentRepo, auditRepo, outboxRepo, log)
+	hasuraDispatcher := hasura.NewDispatcher(dbPool, cfg.Hasura.KeepProcessed, log)
+	hasura.Register(hasuraDispatcher, "public.incidents", tableEventSvc.IncidentChanged)
+	hasura.Register(hasuraDispatcher, "public.stories", tableEventSvc.StoryChanged)
 
 	// 8. Handler layer.
-	h := handler.NewHandler(userSvc, dbPool, log)
+	h := handler.NewHandler(userSvc, importSvc, exportSvc, retentionSvc, notificationSvc, realtimeSvc, webhookSvc, invitationSvc,
+		hasuraDispatcher, cfg.Realtime.Heartbeat, dbPool, log)
 
 	// 9. Auth module.
 	jwtManager := auth.NewJWTManager(auth.JWTConfig{
@@ -105,23 +165,56 @@ func run() error {
 		RefreshTokenExpiry: cfg.JWT.RefreshTokenExpiry,
 		Issuer:             cfg.JWT.Issuer,
 	})
-	authSvc := auth.NewService(userRepo, jwtManager, firebaseVerifier, log)
+	authSvc := auth.NewService(userRepo, outboxRepo, invitationRepo, auditRepo, txManager, jwtManager, firebaseVerifier, log)
 	authHandler := auth.NewHandler(authSvc, log)
-	actionsHandler := handler.NewActionsHandler(authSvc, log)
+	actionsHandler := handler.NewActionsHandler(authSvc, robotSvc, incidentSvc, caregiverSvc, log)
 
 	// 10. Router.
+	hasuraEventSecret := os.Getenv("HASURA_EVENT_SECRET")
+	hasuraActionSecret := os.Getenv("HASURA_ACTION_SECRET")
+	if (hasuraEventSecret == "" || hasuraActionSecret == "") && !cfg.Hasura.AllowMissingSecrets {
+		return fmt.Errorf("HASURA_EVENT_SECRET and HASURA_ACTION_SECRET must be set; " +
+			"hasura.allow_missing_secrets starts without them, rejecting every Hasura call")
+	}
+	if !i18n.IsSupported(cfg.I18n.DefaultLocale) {
+		return fmt.Errorf("i18n.default_locale %q has no catalog in internal/i18n/locales", cfg.I18n.DefaultLocale)
+	}
+	// Stored responses can hold the tokens of /auth/register, so they are
+	// encrypted under a key derived from the JWT secret.
+	idempotencyStore, err := idempotency.NewSealedStore(idempotency.NewPostgresStore(dbPool, log), cfg.JWT.Secret)
+	if err != nil {
+		return err
+	}
+	var openapiDoc *openapi3.T
+	if cfg.OpenAPI.ValidateRequests {
+		if openapiDoc, err = openapi.Load(); err != nil {
+			return err
+		}
+	}
 	r := router.New(h, authHandler, actionsHandler, jwtManager, router.Config{
 		CORSConfig: middleware.CORSConfig{
 			AllowedOrigins:   cfg.CORS.AllowedOrigins,
 			AllowedMethods:   cfg.CORS.AllowedMethods,
 			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
 			AllowCredentials: cfg.CORS.AllowCredentials,
 			MaxAge:           cfg.CORS.MaxAge,
 		},
-		RateLimitRPS:      cfg.RateLimit.RequestsPerSecond,
-		RateLimitBurst:    cfg.RateLimit.Burst,
-		GinMode:           ginMode,
-		InternalAPISecret: os.Getenv("INTERNAL_API_SECRET"),
+		RateLimitRPS:       cfg.RateLimit.RequestsPerSecond,
+		RateLimitBurst:     cfg.RateLimit.Burst,
+		GinMode:            ginMode,
+		InternalAPISecret:  os.Getenv("INTERNAL_API_SECRET"),
+		HasuraEventSecret:  hasuraEventSecret,
+		HasuraActionSecret: hasuraActionSecret,
+		ValidateRequests:   cfg.OpenAPI.ValidateRequests,
+		OpenAPI:            openapiDoc,
+		DefaultLocale:      cfg.I18n.DefaultLocale,
+		IdempotencyStore:   idempotencyStore,
+		IdempotencyConfig: middleware.IdempotencyConfig{
+			TTL:          cfg.Idempotency.TTL,
+			LockTimeout:  cfg.Idempotency.LockTimeout,
+			MaxBodyBytes: cfg.Idempotency.MaxBodyBytes,
+		},
 	}, log)
 
 	// 11. HTTP Server.
@@ -132,6 +225,25 @@ func run() error {
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
+	// Shutdown waits for open requests; ending the event streams lets it finish.
+	srv.RegisterOnShutdown(hub.Close)
+
+	// gRPC server, sharing the services above; grpc.port 0 disables it.
+	var grpcSrv *grpc.Server
+	if cfg.GRPC.Port != 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.GRPC.Port))
+		if err != nil {
+			return fmt.Errorf("grpc listen: %w", err)
+		}
+		grpcSrv = rpc.NewServer(rpc.Services{Auth: authSvc, User: userSvc, Robot: robotSvc}, jwtManager, log)
+		go func() {
+			log.Info("gRPC server listening", slog.String("addr", lis.Addr().String()))
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Error("grpc server error", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// 12. Graceful Shutdown.
 	go func() {
@@ -150,8 +262,26 @@ func run() error {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("server shutdown: %w", err)
+	// The gRPC server drains alongside the HTTP server; calls still open at
+	// the shutdown timeout are cancelled.
+	grpcStopped := make(chan struct{})
+	go func() {
+		if grpcSrv != nil {
+			grpcSrv.GracefulStop()
+		}
+		close(grpcStopped)
+	}()
+
+	httpErr := srv.Shutdown(shutdownCtx)
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+	}
+	if httpErr != nil {
+		return fmt.Errorf("server shutdown: %w", httpErr)
 	}
 
 	log.Info("server shutdown completed gracefully")
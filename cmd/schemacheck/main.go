@@ -0,0 +1,38 @@
+// cmd/schemacheck/main.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"my-application/internal/schemacheck"
+)
+
+// schemacheck reports drift between the Go roles, the migrations and the
+// Hasura metadata, and exits with status 1 if it finds any.
+func main() {
+	migrations := flag.String("migrations", "migrations", "Migrations directory")
+	metadata := flag.String("metadata", "hasura/metadata", "Hasura metadata directory")
+	flag.Parse()
+
+	schema, err := schemacheck.LoadSchema(*migrations)
+	if err != nil {
+		log.Fatalf("loading migrations: %v", err)
+	}
+	md, err := schemacheck.LoadMetadata(*metadata)
+	if err != nil {
+		log.Fatalf("loading hasura metadata: %v", err)
+	}
+
+	issues := schemacheck.Check(schemacheck.ProjectSpec(), schema, md)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		fmt.Fprintf(os.Stderr, "%d issue(s) found\n", len(issues))
+		os.Exit(1)
+	}
+	fmt.Println("roles, migrations and hasura metadata are consistent")
+}
@@ -0,0 +1,143 @@
+// cmd/userimport/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"my-application/config"
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+	"my-application/internal/jobs"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+	"my-application/pkg/database"
+	"my-application/pkg/logger"
+)
+
+// userimport imports users from a CSV or JSON lines file with the rules
+// of POST /api/v1/users/import, running it in the process however large it
+// is. It prints the outcome of each row and exits with status 1 if any row
+// failed.
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	file := flag.String("file", "", "CSV or JSON lines file of users (required)")
+	format := flag.String("format", "", "File format: csv or jsonl (default: from the file extension)")
+	mode := flag.String("mode", domain.ImportModeAtomic, "Import mode: atomic or best_effort")
+	dryRun := flag.Bool("dry-run", false, "Only validate the rows")
+	invite := flag.Bool("invite", true, "Email created users an invitation")
+	enterpriseID := flag.Int64("enterprise", 0, "Enterprise of the imported users (0 = none)")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		return fmt.Errorf("-file is required")
+	}
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*file)) {
+		case ".csv":
+			*format = domain.ImportFormatCSV
+		case ".jsonl", ".ndjson":
+			*format = domain.ImportFormatJSONL
+		default:
+			return fmt.Errorf("cannot tell the format of %s; use -format", *file)
+		}
+	}
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	cfg, err := config.Load("config", env)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	// Results go to stdout, logs to stderr.
+	log := logger.Setup(cfg.Log.Level, cfg.Log.Format, os.Stderr)
+
+	src, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint:errcheck // read-only
+	rows, err := service.ParseUserImport(src, *format, cfg.Imports.MaxRows)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dbPool, err := database.NewPostgresPool(ctx, database.PostgresConfig{
+		DSN:             cfg.Database.DSN(),
+		MaxConns:        cfg.Database.MaxConns,
+		MinConns:        cfg.Database.MinConns,
+		MaxConnLifetime: cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime: cfg.Database.MaxConnIdleTime,
+	}, log)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer dbPool.Close()
+
+	userRepo := postgres.NewUserPostgres(dbPool, log)
+	auditRepo := postgres.NewAuditPostgres(dbPool, log)
+	jobClient := jobs.NewClient(dbPool)
+	txManager := database.NewTxManager(dbPool)
+	userSvc := service.NewUserService(userRepo, postgres.NewOutboxPostgres(dbPool, log), txManager, log)
+	invitationSvc := service.NewInvitationService(postgres.NewInvitationPostgres(dbPool, log), userRepo, auditRepo,
+		jobClient, txManager, service.InvitationConfig{TTL: cfg.Invitations.TTL}, log)
+	importSvc := service.NewUserImportService(userSvc, invitationSvc, userRepo, postgres.NewUserImportPostgres(dbPool, log),
+		auditRepo, jobClient, txManager, service.UserImportConfig{
+			MaxRows: cfg.Imports.MaxRows,
+		}, log)
+
+	imp := &domain.UserImport{Mode: *mode, DryRun: *dryRun, Invite: *invite, Rows: rows}
+	if *enterpriseID != 0 {
+		imp.EnterpriseID = enterpriseID
+	}
+	if err := importSvc.RunImport(ctx, imp); err != nil {
+		return err
+	}
+
+	printResults(imp)
+	if n := imp.Count(domain.ImportRowFailed); n > 0 {
+		return fmt.Errorf("%d of %d rows failed", n, len(imp.Results))
+	}
+	return nil
+}
+
+func printResults(imp *domain.UserImport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tSTATUS\tUSER\tERRORS")
+	for _, r := range imp.Results {
+		user := ""
+		if r.UserID != nil {
+			user = fmt.Sprint(*r.UserID)
+		}
+		var errs []string
+		for field, msg := range i18n.Violations(r.Violations, i18n.SourceLocale) {
+			errs = append(errs, field+": "+msg)
+		}
+		sort.Strings(errs)
+		if len(errs) == 0 && r.Code != "" {
+			errs = append(errs, string(r.Code))
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.Line, r.Status, user, strings.Join(errs, "; "))
+	}
+	w.Flush() //nolint:errcheck // stdout
+
+	fmt.Printf("%d valid, %d created, %d failed, %d skipped\n",
+		imp.Count(domain.ImportRowValid), imp.Count(domain.ImportRowCreated),
+		imp.Count(domain.ImportRowFailed), imp.Count(domain.ImportRowSkipped))
+}
@@ -1,6 +1,220 @@
 // cmd/worker/main.go
 package main
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"my-application/config"
+	"my-application/internal/domain"
+	"my-application/internal/email"
+	"my-application/internal/hasura"
+	"my-application/internal/idempotency"
+	"my-application/internal/jobs"
+	"my-application/internal/outbox"
+	"my-application/internal/push"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+	"my-application/internal/webhook"
+	"my-application/internal/worker"
+	"my-application/pkg/database"
+	fbclient "my-application/pkg/firebase"
+	"my-application/pkg/logger"
+)
+
 func main() {
-	// TODO: implement background worker (email, notifications).
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// 1. Configuration.
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+
+	cfg, err := config.Load("config", env)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	// 2. Logger.
+	log := logger.Setup(cfg.Log.Level, cfg.Log.Format, os.Stdout)
+	log.Info("starting worker", slog.String("env", env))
+
+	// 3. Database.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbPool, err := database.NewPostgresPool(ctx, database.PostgresConfig{
+		DSN:             cfg.Database.DSN(),
+		MaxConns:        cfg.Database.MaxConns,
+		MinConns:        cfg.Database.MinConns,
+		MaxConnLifetime: cfg.Database.MaxConnLifetime,
+		MaxConnIdleTime: cfg.Database.MaxConnIdleTime,
+	}, log)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer dbPool.Close()
+
+	// 4. Repositories and job handlers.
+	userRepo := postgres.NewUserPostgres(dbPool, log)
+	importRepo := postgres.NewUserImportPostgres(dbPool, log)
+	invitationRepo := postgres.NewInvitationPostgres(dbPool, log)
+	outboxRepo := postgres.NewOutboxPostgres(dbPool, log)
+	exportRepo := postgres.NewDataExportPostgres(dbPool, log)
+	auditRepo := postgres.NewAuditPostgres(dbPool, log)
+	retentionRepo := postgres.NewRetentionPostgres(dbPool, log)
+	notificationRepo := postgres.NewNotificationPostgres(dbPool, log)
+	webhookRepo := postgres.NewWebhookPostgres(dbPool, log)
+	txManager := database.NewTxManager(dbPool)
+	jobClient := jobs.NewClient(dbPool)
+
+	exportWorker := worker.NewExportWorker(exportRepo, auditRepo, jobClient, worker.ExportConfig{
+		Dir:           cfg.Exports.Dir,
+		LinkTTL:       cfg.Exports.LinkTTL,
+		MaxMediaBytes: cfg.Exports.MaxMediaBytes,
+		MediaHosts:    cfg.Exports.MediaHosts,
+	}, log)
+	retentionWorker := worker.NewRetentionWorker(retentionRepo, auditRepo, txManager, cfg.Retention.BatchSize, log)
+
+	renderer, err := email.NewRenderer(cfg.Email.DefaultLocale)
+	if err != nil {
+		return fmt.Errorf("loading email templates: %w", err)
+	}
+	sender, err := email.NewSender(email.Config{
+		Driver:  cfg.Email.Driver,
+		From:    cfg.Email.From,
+		FileDir: cfg.Email.FileDir,
+		SMTP: email.SMTPConfig{
+			Host:     cfg.Email.SMTP.Host,
+			Port:     cfg.Email.SMTP.Port,
+			Username: cfg.Email.SMTP.Username,
+			Password: cfg.Email.SMTP.Password,
+			TLSMode:  cfg.Email.SMTP.TLSMode,
+			Timeout:  cfg.Email.SMTP.Timeout,
+		},
+	}, log)
+	if err != nil {
+		return fmt.Errorf("creating email sender: %w", err)
+	}
+	emailWorker := worker.NewEmailWorker(renderer, sender, log)
+	invitationWorker := worker.NewInvitationWorker(invitationRepo, emailWorker, cfg.Notifications.AppURL, log)
+
+	var pushSender push.Sender
+	switch cfg.Notifications.PushDriver {
+	case push.DriverFCM:
+		fbClient, err := fbclient.NewClient(ctx, fbclient.Config{
+			ProjectID:       cfg.Firebase.ProjectID,
+			CredentialsFile: cfg.Firebase.CredentialsFile,
+		}, log)
+		if err != nil {
+			return fmt.Errorf("initializing firebase: %w", err)
+		}
+		pushSender = push.NewFCMSender(fbClient)
+	case push.DriverFake:
+		pushSender = push.NewFakeSender(log)
+	default:
+		return fmt.Errorf("unknown push driver %q", cfg.Notifications.PushDriver)
+	}
+	notificationWorker := worker.NewNotificationWorker(notificationRepo, jobClient, pushSender, worker.NotificationConfig{
+		AppURL:            cfg.Notifications.AppURL,
+		RobotOfflineAfter: cfg.Notifications.RobotOfflineAfter,
+	}, log)
+
+	webhookWorker := worker.NewWebhookWorker(webhookRepo, auditRepo, jobClient, txManager, webhook.NewSender(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateNetworks),
+		domain.WebhookDisablePolicy{
+			Failures: cfg.Webhooks.DisableAfterFailures,
+			After:    cfg.Webhooks.DisableAfter,
+		}, log)
+
+	// Large user imports run here, through the same services as the API.
+	userSvc := service.NewUserService(userRepo, outboxRepo, txManager, log)
+	invitationSvc := service.NewInvitationService(invitationRepo, userRepo, auditRepo, jobClient, txManager, service.InvitationConfig{
+		TTL: cfg.Invitations.TTL,
+	}, log)
+	importSvc := service.NewUserImportService(userSvc, invitationSvc, userRepo, importRepo, auditRepo, jobClient, txManager,
+		service.UserImportConfig{
+			InlineRows: cfg.Imports.InlineRows,
+			MaxRows:    cfg.Imports.MaxRows,
+		}, log)
+	importWorker := worker.NewUserImportWorker(importRepo, importSvc, log)
+
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, exportWorker.Build)
+	jobs.Register(registry, exportWorker.Maintain)
+	jobs.Register(registry, retentionWorker.Enforce)
+	jobs.Register(registry, emailWorker.Send)
+	jobs.Register(registry, invitationWorker.Send)
+	jobs.Register(registry, notificationWorker.Fanout)
+	jobs.Register(registry, notificationWorker.CheckRobots)
+	jobs.Register(registry, notificationWorker.Push)
+	jobs.Register(registry, webhookWorker.Deliver)
+	jobs.Register(registry, importWorker.Run)
+
+	// Domain events: in-process subscribers and the outbox relay.
+	bus := outbox.NewBus(dbPool, log)
+	bus.Subscribe("notification.revoke_devices", notificationWorker.RevokeDevices,
+		domain.EventUserDeactivated, domain.EventUserDeleted, domain.EventUserPurged)
+	bus.Subscribe("webhook.fanout", webhookWorker.Fanout, domain.WebhookEventTypes...)
+	relay := outbox.NewRelay(dbPool, bus, jobClient, outbox.RelayConfig{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+		KeepRelayed:  cfg.Outbox.KeepRelayed,
+	}, log)
+	jobs.Register(registry, bus.Deliver)
+	jobs.Register(registry, relay.Prune)
+
+	// The API receives Hasura events; the worker only prunes their log.
+	hasuraDispatcher := hasura.NewDispatcher(dbPool, cfg.Hasura.KeepProcessed, log)
+	jobs.Register(registry, hasuraDispatcher.Prune)
+	jobs.Register(registry, idempotency.NewPostgresStore(dbPool, log).Prune)
+
+	// 5. Job runner.
+	runner := jobs.NewRunner(dbPool, registry, jobs.RunnerConfig{
+		Queues:        cfg.Worker.Queues,
+		PollInterval:  cfg.Worker.PollInterval,
+		StaleAfter:    cfg.Worker.StaleAfter,
+		KeepCompleted: cfg.Worker.KeepCompleted,
+	}, log)
+	runner.AddPeriodic(cfg.Exports.MaintenanceInterval, jobs.ExportMaintenanceArgs{}, jobs.WithQueue(jobs.QueueMaintenance))
+	runner.AddPeriodic(cfg.Retention.Interval, jobs.RetentionEnforceArgs{}, jobs.WithQueue(jobs.QueueMaintenance))
+	runner.AddPeriodic(cfg.Notifications.RobotCheckInterval, jobs.RobotOfflineCheckArgs{}, jobs.WithQueue(jobs.QueueMaintenance))
+	runner.AddPeriodic(cfg.Outbox.PruneInterval, jobs.OutboxPruneArgs{}, jobs.WithQueue(jobs.QueueMaintenance))
+	runner.AddPeriodic(cfg.Hasura.PruneInterval, jobs.HasuraEventPruneArgs{}, jobs.WithQueue(jobs.QueueMaintenance))
+	runner.AddPeriodic(cfg.Idempotency.PruneInterval, jobs.IdempotencyPruneArgs{}, jobs.WithQueue(jobs.QueueMaintenance))
+	runner.Start(ctx)
+
+	relayCtx, stopRelay := context.WithCancel(ctx)
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		relay.Run(relayCtx)
+	}()
+
+	// 6. Graceful Shutdown.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	log.Info("shutdown signal received", slog.String("signal", sig.String()))
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Worker.ShutdownTimeout)
+	defer shutdownCancel()
+
+	stopRelay()
+	<-relayDone
+	if err := runner.Stop(shutdownCtx); err != nil {
+		return fmt.Errorf("worker shutdown: %w", err)
+	}
+
+	log.Info("worker shutdown completed gracefully")
+	return nil
 }
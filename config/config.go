@@ -11,13 +11,28 @@ import (
 
 // Config is the root configuration structure.
 type Config struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Log       LogConfig       `mapstructure:"log"`
-	CORS      CORSConfig      `mapstructure:"cors"`
-	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
-	JWT       JWTConfig       `mapstructure:"jwt"`
-	Firebase  FirebaseConfig  `mapstructure:"firebase"`
+	Server        ServerConfig        `mapstructure:"server"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Log           LogConfig           `mapstructure:"log"`
+	CORS          CORSConfig          `mapstructure:"cors"`
+	RateLimit     RateLimitConfig     `mapstructure:"rate_limit"`
+	JWT           JWTConfig           `mapstructure:"jwt"`
+	Firebase      FirebaseConfig      `mapstructure:"firebase"`
+	Worker        WorkerConfig        `mapstructure:"worker"`
+	Exports       ExportsConfig       `mapstructure:"exports"`
+	Imports       ImportsConfig       `mapstructure:"imports"`
+	Invitations   InvitationsConfig   `mapstructure:"invitations"`
+	Retention     RetentionConfig     `mapstructure:"retention"`
+	Email         EmailConfig         `mapstructure:"email"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Realtime      RealtimeConfig      `mapstructure:"realtime"`
+	Outbox        OutboxConfig        `mapstructure:"outbox"`
+	Webhooks      WebhooksConfig      `mapstructure:"webhooks"`
+	Hasura        HasuraConfig        `mapstructure:"hasura"`
+	Idempotency   IdempotencyConfig   `mapstructure:"idempotency"`
+	GRPC          GRPCConfig          `mapstructure:"grpc"`
+	OpenAPI       OpenAPIConfig       `mapstructure:"openapi"`
+	I18n          I18nConfig          `mapstructure:"i18n"`
 }
 
 // FirebaseConfig holds Firebase integration settings.
@@ -74,6 +89,7 @@ type CORSConfig struct {
 	AllowedOrigins   []string `mapstructure:"allowed_origins"`
 	AllowedMethods   []string `mapstructure:"allowed_methods"`
 	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string `mapstructure:"exposed_headers"` // response headers browsers may read
 	AllowCredentials bool     `mapstructure:"allow_credentials"`
 	MaxAge           int      `mapstructure:"max_age"`
 }
@@ -92,6 +108,129 @@ type JWTConfig struct {
 	Issuer             string        `mapstructure:"issuer"`
 }
 
+// WorkerConfig holds background worker settings.
+type WorkerConfig struct {
+	PollInterval    time.Duration  `mapstructure:"poll_interval"`
+	ShutdownTimeout time.Duration  `mapstructure:"shutdown_timeout"`
+	Queues          map[string]int `mapstructure:"queues"` // queue name → concurrency
+	StaleAfter      time.Duration  `mapstructure:"stale_after"`
+	KeepCompleted   time.Duration  `mapstructure:"keep_completed"`
+}
+
+// ExportsConfig holds personal data export settings.
+type ExportsConfig struct {
+	Dir                 string        `mapstructure:"dir"`
+	LinkTTL             time.Duration `mapstructure:"link_ttl"`
+	MaxMediaBytes       int64         `mapstructure:"max_media_bytes"`
+	MediaHosts          []string      `mapstructure:"media_hosts"`
+	MaintenanceInterval time.Duration `mapstructure:"maintenance_interval"`
+}
+
+// ImportsConfig holds bulk user import settings.
+type ImportsConfig struct {
+	InlineRows int `mapstructure:"inline_rows"` // larger files are run by the worker
+	MaxRows    int `mapstructure:"max_rows"`
+}
+
+// InvitationsConfig holds user invitation settings.
+type InvitationsConfig struct {
+	TTL time.Duration `mapstructure:"ttl"` // how long an invitation link can be accepted
+}
+
+// EmailConfig holds email delivery settings.
+type EmailConfig struct {
+	Driver        string     `mapstructure:"driver"` // smtp, file or memory
+	From          string     `mapstructure:"from"`
+	DefaultLocale string     `mapstructure:"default_locale"`
+	FileDir       string     `mapstructure:"file_dir"`
+	SMTP          SMTPConfig `mapstructure:"smtp"`
+}
+
+// SMTPConfig holds SMTP relay settings.
+type SMTPConfig struct {
+	Host     string        `mapstructure:"host"`
+	Port     int           `mapstructure:"port"`
+	Username string        `mapstructure:"username"`
+	Password string        `mapstructure:"password"`
+	TLSMode  string        `mapstructure:"tls_mode"` // starttls, tls or none
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// NotificationsConfig holds notification fan-out settings.
+type NotificationsConfig struct {
+	PushDriver         string        `mapstructure:"push_driver"` // fcm or fake
+	AppURL             string        `mapstructure:"app_url"`
+	RobotOfflineAfter  time.Duration `mapstructure:"robot_offline_after"`
+	RobotCheckInterval time.Duration `mapstructure:"robot_check_interval"`
+}
+
+// OutboxConfig holds settings for the domain event relay.
+type OutboxConfig struct {
+	PollInterval  time.Duration `mapstructure:"poll_interval"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	KeepRelayed   time.Duration `mapstructure:"keep_relayed"`
+	PruneInterval time.Duration `mapstructure:"prune_interval"`
+}
+
+// RealtimeConfig holds settings for the live event stream.
+type RealtimeConfig struct {
+	BufferSize   int           `mapstructure:"buffer_size"`
+	ClientBuffer int           `mapstructure:"client_buffer"`
+	Heartbeat    time.Duration `mapstructure:"heartbeat"`
+}
+
+// WebhooksConfig holds outbound webhook settings.
+type WebhooksConfig struct {
+	Timeout              time.Duration `mapstructure:"timeout"`
+	AllowHTTP            bool          `mapstructure:"allow_http"`
+	AllowPrivateNetworks bool          `mapstructure:"allow_private_networks"`
+	DisableAfterFailures int           `mapstructure:"disable_after_failures"`
+	DisableAfter         time.Duration `mapstructure:"disable_after"`
+}
+
+// HasuraConfig holds settings for receiving Hasura event triggers and
+// Actions. The shared secrets are read from HASURA_EVENT_SECRET and
+// HASURA_ACTION_SECRET; the API refuses to start without them unless
+// AllowMissingSecrets, which leaves both routes rejecting every call.
+type HasuraConfig struct {
+	KeepProcessed       time.Duration `mapstructure:"keep_processed"`
+	PruneInterval       time.Duration `mapstructure:"prune_interval"`
+	AllowMissingSecrets bool          `mapstructure:"allow_missing_secrets"`
+}
+
+// IdempotencyConfig holds settings of Idempotency-Key handling.
+type IdempotencyConfig struct {
+	TTL           time.Duration `mapstructure:"ttl"`
+	LockTimeout   time.Duration `mapstructure:"lock_timeout"`
+	PruneInterval time.Duration `mapstructure:"prune_interval"`
+	MaxBodyBytes  int64         `mapstructure:"max_body_bytes"`
+}
+
+// GRPCConfig holds gRPC server settings. It listens on the HTTP server's
+// host; port 0 disables it.
+type GRPCConfig struct {
+	Port int `mapstructure:"port"`
+}
+
+// OpenAPIConfig holds settings of the REST API's OpenAPI document.
+type OpenAPIConfig struct {
+	// ValidateRequests rejects requests that don't match the document.
+	ValidateRequests bool `mapstructure:"validate_requests"`
+}
+
+// I18nConfig holds settings of localized API messages.
+type I18nConfig struct {
+	// DefaultLocale is used when neither the user's profile nor the
+	// Accept-Language header names a supported language.
+	DefaultLocale string `mapstructure:"default_locale"`
+}
+
+// RetentionConfig holds data retention enforcement settings.
+type RetentionConfig struct {
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+}
+
 // Load reads configuration from YAML files and environment variables.
 // env parameter selects the overlay file: "dev", "staging", "prod".
 func Load(configPath, env string) (*Config, error) {
@@ -1,175 +1,295 @@
+// internal/api/handler/actions_handler.go
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"reflect"
+	"slices"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/middleware"
+	"my-application/internal/api/request"
 	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/internal/hasura"
+	"my-application/internal/i18n"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
 )
 
-// ActionsHandler handles Hasura Action webhook requests.
-type ActionsHandler struct {
-	authService auth.Service
-	logger      *slog.Logger
-}
+// ActionFunc runs one Hasura Action. input is the action's raw "input"
+// object; the result is returned to Hasura as the action's output. The
+// caller's identity, when the action was called by a signed-in user, is
+// set on c as middleware.Auth would.
+type ActionFunc func(c *gin.Context, input json.RawMessage) (interface{}, error)
 
-// NewActionsHandler creates an ActionsHandler.
-func NewActionsHandler(authService auth.Service, logger *slog.Logger) *ActionsHandler {
-	return &ActionsHandler{authService: authService, logger: logger}
+// Action is a typed Hasura Action. Its input is decoded into In and
+// validated with In's binding tags before Run is called, and its Hasura
+// metadata is generated from In and Out (see cmd/actiongen).
+type Action[In, Out any] struct {
+	Name string
+	// Kind defaults to hasura.ActionMutation.
+	Kind hasura.ActionKind
+	// Roles are the Hasura roles allowed to call the action. Without
+	// roles, only the admin role can.
+	Roles []string
+	Run   func(c *gin.Context, in *In) (*Out, error)
 }
 
-// hasuraActionPayload is the standard envelope Hasura sends for synchronous Actions.
-type hasuraActionPayload struct {
-	Action struct {
-		Name string `json:"name"`
-	} `json:"action"`
-	Input       map[string]interface{} `json:"input"`
-	SessionVars map[string]string      `json:"session_variables"`
+// ActionsHandler dispatches Hasura Action calls to the function registered
+// for the action's name.
+type ActionsHandler struct {
+	actions map[string]ActionFunc
+	defs    []hasura.ActionDefinition
+	logger  *slog.Logger
 }
 
-// inputString safely extracts a string value from the Hasura action input map.
-func (p *hasuraActionPayload) inputString(key string) (string, bool) {
-	v, ok := p.Input[key].(string)
-	return v, ok
-}
+// contextKeyActionRole holds the Hasura role an action was called with.
+const contextKeyActionRole = "action_role"
 
-// Login handles the Hasura "login" action.
-func (h *ActionsHandler) Login(c *gin.Context) {
-	var payload hasuraActionPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payload"})
-		return
-	}
-
-	email, ok := payload.inputString("email")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "email is required and must be a string"})
-		return
-	}
-	password, ok := payload.inputString("password")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "password is required and must be a string"})
-		return
-	}
+// NewActionsHandler creates an ActionsHandler with every action registered.
+// The services are only used when an action runs, so the metadata can be
+// generated from a handler built with nil services.
+func NewActionsHandler(
+	authService auth.Service,
+	robotService service.RobotService,
+	incidentService service.IncidentService,
+	caregiverService service.CaregiverService,
+	logger *slog.Logger,
+) *ActionsHandler {
+	h := &ActionsHandler{actions: make(map[string]ActionFunc), logger: logger}
 
-	resp, err := h.authService.Login(c.Request.Context(), auth.LoginRequest{
-		Email:    email,
-		Password: password,
+	RegisterAction(h, Action[auth.LoginRequest, auth.AuthResponse]{
+		Name:  "login",
+		Roles: []string{"anonymous"},
+		Run: func(c *gin.Context, in *auth.LoginRequest) (*auth.AuthResponse, error) {
+			return authService.Login(c.Request.Context(), *in)
+		},
+	})
+	RegisterAction(h, Action[auth.RegisterRequest, auth.AuthResponse]{
+		Name:  "register",
+		Roles: []string{"anonymous"},
+		Run: func(c *gin.Context, in *auth.RegisterRequest) (*auth.AuthResponse, error) {
+			return authService.Register(c.Request.Context(), *in)
+		},
+	})
+	RegisterAction(h, Action[auth.RefreshRequest, auth.TokenPair]{
+		Name:  "refreshToken",
+		Roles: []string{"anonymous"},
+		Run: func(c *gin.Context, in *auth.RefreshRequest) (*auth.TokenPair, error) {
+			return authService.RefreshToken(c.Request.Context(), *in)
+		},
+	})
+	RegisterAction(h, Action[auth.AcceptInvitationRequest, auth.AuthResponse]{
+		Name:  "acceptInvitation",
+		Roles: []string{"anonymous"},
+		Run: func(c *gin.Context, in *auth.AcceptInvitationRequest) (*auth.AuthResponse, error) {
+			return authService.AcceptInvitation(c.Request.Context(), *in)
+		},
+	})
+	// Called server-to-server with the admin secret, like POST /auth/sync-user.
+	RegisterAction(h, Action[auth.SyncUserRequest, auth.AuthResponse]{
+		Name: "syncUser",
+		Run: func(c *gin.Context, in *auth.SyncUserRequest) (*auth.AuthResponse, error) {
+			return authService.SyncUser(c.Request.Context(), *in)
+		},
 	})
-	if err != nil {
-		h.logger.Warn("action login failed", slog.String("error", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-		return
-	}
 
-	c.JSON(http.StatusOK, resp)
+	RegisterAction(h, Action[request.ProvisionRobotInput, domain.Robot]{
+		Name:  "provisionRobot",
+		Roles: []string{"mta", "eta"},
+		Run: func(c *gin.Context, in *request.ProvisionRobotInput) (*domain.Robot, error) {
+			actorID, err := actionActor(c)
+			if err != nil {
+				return nil, err
+			}
+			if !canAccessEnterprise(c, in.EnterpriseID) {
+				return nil, domain.NewAppError(domain.ErrForbidden, "access denied: enterprise out of scope").
+					WithCode(domain.CodeEnterpriseOutOfScope)
+			}
+			robot := &domain.Robot{
+				EnterpriseID:       in.EnterpriseID,
+				SerialNumber:       in.SerialNumber,
+				FirmwareVersion:    in.FirmwareVersion,
+				AssignedResidentID: in.AssignedResidentID,
+			}
+			if err := robotService.ProvisionRobot(c.Request.Context(), robot, actorID); err != nil {
+				return nil, err
+			}
+			return robot, nil
+		},
+	})
+	RegisterAction(h, Action[request.ResolveIncidentInput, domain.Incident]{
+		Name:  "resolveIncident",
+		Roles: []string{"mta", "eta", "caregiver"},
+		Run: func(c *gin.Context, in *request.ResolveIncidentInput) (*domain.Incident, error) {
+			actorID, err := actionActor(c)
+			if err != nil {
+				return nil, err
+			}
+			return incidentService.ResolveIncident(c.Request.Context(), in.IncidentID, in.Resolution,
+				enterpriseScope(c), actorID, c.GetString(middleware.ContextKeyUserRole))
+		},
+	})
+	RegisterAction(h, Action[request.AssignCaregiverInput, domain.CaregiverAssignment]{
+		Name:  "assignCaregiver",
+		Roles: []string{"mta", "eta"},
+		Run: func(c *gin.Context, in *request.AssignCaregiverInput) (*domain.CaregiverAssignment, error) {
+			actorID, err := actionActor(c)
+			if err != nil {
+				return nil, err
+			}
+			return caregiverService.AssignResident(c.Request.Context(), in.CaregiverID, in.ResidentID,
+				enterpriseScope(c), actorID)
+		},
+	})
+	return h
 }
 
-// Register handles the Hasura "register" action.
-func (h *ActionsHandler) Register(c *gin.Context) {
-	var payload hasuraActionPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payload"})
-		return
+// Register sets fn as the function for the named action. It panics if the
+// name is already taken. Actions registered this way are not described in
+// the generated metadata; use RegisterAction for those.
+func (h *ActionsHandler) Register(name string, fn ActionFunc) {
+	if _, dup := h.actions[name]; dup {
+		panic(fmt.Sprintf("action %q registered twice", name))
 	}
+	h.actions[name] = fn
+}
 
-	username, ok := payload.inputString("username")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "username is required and must be a string"})
-		return
-	}
-	email, ok := payload.inputString("email")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "email is required and must be a string"})
-		return
-	}
-	password, ok := payload.inputString("password")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "password is required and must be a string"})
-		return
-	}
-	fullName, ok := payload.inputString("full_name")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "full_name is required and must be a string"})
-		return
+// RegisterAction registers a typed action and records its definition for
+// the metadata. Calls from roles not in a.Roles are refused, in case the
+// Hasura permissions drift from the definition.
+func RegisterAction[In, Out any](h *ActionsHandler, a Action[In, Out]) {
+	if a.Kind == "" {
+		a.Kind = hasura.ActionMutation
 	}
-
-	resp, err := h.authService.Register(c.Request.Context(), auth.RegisterRequest{
-		Username: username,
-		Email:    email,
-		Password: password,
-		FullName: fullName,
+	h.Register(a.Name, func(c *gin.Context, input json.RawMessage) (interface{}, error) {
+		if role := c.GetString(contextKeyActionRole); role != "admin" && !slices.Contains(a.Roles, role) {
+			return nil, domain.NewAppError(domain.ErrForbidden, fmt.Sprintf("role %q may not call %s", role, a.Name)).
+				WithCode(domain.CodeRoleNotAllowed)
+		}
+		var in In
+		if err := bindActionInput(input, &in); err != nil {
+			return nil, err
+		}
+		return a.Run(c, &in)
 	})
-	if err != nil {
-		h.logger.Error("action register failed", slog.String("error", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-		return
-	}
+	h.defs = append(h.defs, hasura.ActionDefinition{
+		Name:   a.Name,
+		Kind:   a.Kind,
+		Roles:  a.Roles,
+		Input:  reflect.TypeOf((*In)(nil)).Elem(),
+		Output: reflect.TypeOf((*Out)(nil)).Elem(),
+	})
+}
+
+// Definitions returns the typed actions in the order they were registered.
+func (h *ActionsHandler) Definitions() []hasura.ActionDefinition {
+	return slices.Clone(h.defs)
+}
 
-	c.JSON(http.StatusOK, resp)
+// hasuraActionPayload is the standard envelope Hasura sends for synchronous Actions.
+type hasuraActionPayload struct {
+	Action struct {
+		Name string `json:"name"`
+	} `json:"action"`
+	Input       json.RawMessage   `json:"input"`
+	SessionVars map[string]string `json:"session_variables"`
 }
 
-// Refresh handles the Hasura "refreshToken" action.
-func (h *ActionsHandler) Refresh(c *gin.Context) {
+// Dispatch handles POST /api/v1/actions
+func (h *ActionsHandler) Dispatch(c *gin.Context) {
 	var payload hasuraActionPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payload"})
+		respondActionError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid action payload"))
 		return
 	}
 
-	refreshToken, ok := payload.inputString("refresh_token")
+	fn, ok := h.actions[payload.Action.Name]
 	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "refresh_token is required and must be a string"})
+		respondActionError(c, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("unknown action %q", payload.Action.Name)))
 		return
 	}
 
-	tokens, err := h.authService.RefreshToken(c.Request.Context(), auth.RefreshRequest{
-		RefreshToken: refreshToken,
-	})
+	s := hasura.ParseSession(payload.SessionVars)
+	c.Set(contextKeyActionRole, s.Role)
+	if s.UserID != 0 {
+		middleware.SetIdentity(c, s.UserID, s.Role, s.EnterpriseID)
+	}
+	log := logger.FromContext(c.Request.Context()).With(slog.String("action", payload.Action.Name))
+
+	out, err := fn(c, payload.Input)
 	if err != nil {
-		h.logger.Warn("action refresh failed", slog.String("error", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		log.Warn("action failed", slog.String("error", err.Error()))
+		respondActionError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, tokens)
+	c.JSON(http.StatusOK, out)
 }
 
-// SyncUser handles the Hasura "syncUser" action.
-func (h *ActionsHandler) SyncUser(c *gin.Context) {
-	var payload hasuraActionPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid payload"})
-		return
+// actionActor returns the signed-in user calling an action. Business
+// actions are not available to Hasura's admin role, which has no user.
+func actionActor(c *gin.Context) (int64, error) {
+	if id := authUserID(c); id != 0 {
+		return id, nil
 	}
+	return 0, domain.NewAppError(domain.ErrUnauthorized, "action requires a signed-in user")
+}
 
-	firebaseUID, ok := payload.inputString("firebase_uid")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "firebase_uid is required and must be a string"})
-		return
-	}
-	email, ok := payload.inputString("email")
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "email is required and must be a string"})
-		return
+// bindActionInput decodes an action's input into req and validates it with
+// req's binding tags.
+func bindActionInput(input json.RawMessage, req interface{}) error {
+	if err := binding.JSON.BindBody(input, req); err != nil {
+		return request.BindingError(err)
 	}
+	return nil
+}
 
-	req := auth.SyncUserRequest{
-		FirebaseUID: firebaseUID,
-		Email:       email,
+// respondActionError writes err in the error format Hasura expects from an
+// Action, with the status and code of its domain error. Internal failures
+// are reported without their message, which may reveal implementation
+// details to GraphQL clients.
+func respondActionError(c *gin.Context, err error) {
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || errors.Is(err, domain.ErrDatabaseOperation) || errors.Is(err, domain.ErrInternal) {
+		interceptor.ActionFail(c, http.StatusInternalServerError, "internal server error", actionErrorCode(err),
+			domain.CodeInternal, nil)
+		return
 	}
-	if dn, ok := payload.inputString("display_name"); ok {
-		req.DisplayName = dn
+	locale, code := interceptor.Locale(c), domain.ErrorCodeOf(err)
+	var details interface{}
+	if d := i18n.Details(appErr, locale); d != nil {
+		details = d
 	}
+	interceptor.ActionFail(c, domain.HTTPStatusFromError(appErr.Err), i18n.Message(locale, code, appErr.Message),
+		actionErrorCode(err), code, details)
+}
 
-	resp, err := h.authService.SyncUser(c.Request.Context(), req)
-	if err != nil {
-		h.logger.Error("action sync-user failed", slog.String("error", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-		return
+// actionErrorCode returns the extensions.code for err, in the style of
+// Hasura's own error codes.
+func actionErrorCode(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return "not-found"
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return "already-exists"
+	case errors.Is(err, domain.ErrInvalidInput):
+		return "validation-failed"
+	case errors.Is(err, domain.ErrUnauthorized):
+		return "access-denied"
+	case errors.Is(err, domain.ErrForbidden):
+		return "permission-denied"
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return "precondition-failed"
+	default:
+		return "unexpected"
 	}
-
-	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,130 @@
+// internal/api/handler/data_export_handler.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/response"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
+)
+
+// DataExportHandler handles personal data export requests.
+type DataExportHandler struct {
+	exportService service.DataExportService
+	logger        *slog.Logger
+}
+
+// NewDataExportHandler creates a DataExportHandler.
+func NewDataExportHandler(exportService service.DataExportService, logger *slog.Logger) *DataExportHandler {
+	return &DataExportHandler{exportService: exportService, logger: logger}
+}
+
+// RequestOwn handles POST /api/v1/users/me/export
+func (h *DataExportHandler) RequestOwn(c *gin.Context) {
+	callerID := authUserID(c)
+	h.request(c, callerID, callerID, nil)
+}
+
+// RequestForUser handles POST /api/v1/users/:id/export
+//
+// eta can only export users of their own enterprise.
+func (h *DataExportHandler) RequestForUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID"))
+		return
+	}
+	h.request(c, id, authUserID(c), enterpriseScope(c))
+}
+
+func (h *DataExportHandler) request(c *gin.Context, userID, requestedBy int64, enterpriseID *int64) {
+	log := logger.FromContext(c.Request.Context())
+
+	export, err := h.exportService.RequestExport(c.Request.Context(), userID, requestedBy, enterpriseID)
+	if err != nil {
+		log.Error("failed to request data export", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusAccepted, toDataExportResponse(export))
+}
+
+// GetByID handles GET /api/v1/exports/:id
+func (h *DataExportHandler) GetByID(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid export ID"))
+		return
+	}
+
+	// Exports of users outside the caller's enterprise are not found.
+	export, err := h.exportService.GetExport(c.Request.Context(), id, enterpriseScope(c))
+	if err != nil {
+		log.Error("failed to get data export", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	// Only the subject, the requester and admins may see an export (and its link).
+	callerID := authUserID(c)
+	isOwner := export.UserID == callerID || (export.RequestedBy != nil && *export.RequestedBy == callerID)
+	if !isOwner && !hasRole(c, "mta", "eta") {
+		respondError(c, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("data export with id %d not found", id)))
+		return
+	}
+	if err := h.exportService.IssueDownloadLink(c.Request.Context(), export); err != nil {
+		log.Error("failed to issue data export link", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, toDataExportResponse(export))
+}
+
+// Download handles GET /api/v1/exports/:id/download?token=...
+// The token in the expiring link is the only credential.
+func (h *DataExportHandler) Download(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid export ID"))
+		return
+	}
+
+	export, err := h.exportService.OpenDownload(c.Request.Context(), id, c.Query("token"))
+	if err != nil {
+		log.Warn("data export download rejected", slog.Int64("export_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	c.FileAttachment(export.FilePath, fmt.Sprintf("personal-data-%d.zip", export.UserID))
+}
+
+func toDataExportResponse(e *domain.DataExport) response.DataExportResponse {
+	resp := response.DataExportResponse{
+		ID:          e.ID,
+		UserID:      e.UserID,
+		Status:      e.Status,
+		ExpiresAt:   e.ExpiresAt,
+		Error:       e.Error,
+		CompletedAt: e.CompletedAt,
+		CreatedAt:   e.CreatedAt,
+	}
+	if e.Status == domain.ExportStatusCompleted && e.DownloadToken != "" {
+		resp.DownloadURL = fmt.Sprintf("/api/v1/exports/%d/download?token=%s", e.ID, e.DownloadToken)
+	}
+	return resp
+}
@@ -0,0 +1,38 @@
+// internal/api/handler/docs_handler.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/api/openapi"
+	"my-application/internal/domain"
+)
+
+// DocsHandler serves the OpenAPI document and the page that renders it.
+type DocsHandler struct {
+	logger *slog.Logger
+}
+
+// NewDocsHandler creates a DocsHandler.
+func NewDocsHandler(logger *slog.Logger) *DocsHandler {
+	return &DocsHandler{logger: logger}
+}
+
+// Spec handles GET /openapi.json
+func (h *DocsHandler) Spec(c *gin.Context) {
+	spec, err := openapi.JSON()
+	if err != nil {
+		h.logger.Error("failed to render openapi document", slog.String("error", err.Error()))
+		respondError(c, domain.NewAppError(domain.ErrInternal, "failed to render openapi document"))
+		return
+	}
+	c.Data(http.StatusOK, "application/json", spec)
+}
+
+// UI handles GET /docs
+func (h *DocsHandler) UI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsHTML())
+}
@@ -0,0 +1,135 @@
+// internal/api/handler/event_stream_handler.go
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/middleware"
+	"my-application/internal/realtime"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
+)
+
+// streamWriteTimeout bounds each write to a stream, so a client that stops
+// reading is disconnected instead of holding the connection open.
+const streamWriteTimeout = 10 * time.Second
+
+// streamRetry is the reconnect delay suggested to EventSource clients.
+const streamRetry = 3 * time.Second
+
+// EventStreamHandler serves live dashboard events as Server-Sent Events.
+type EventStreamHandler struct {
+	realtimeService service.RealtimeService
+	heartbeat       time.Duration
+	logger          *slog.Logger
+}
+
+// NewEventStreamHandler creates an EventStreamHandler that sends a heartbeat
+// comment whenever the stream has been idle for the given interval.
+func NewEventStreamHandler(realtimeService service.RealtimeService, heartbeat time.Duration, logger *slog.Logger) *EventStreamHandler {
+	return &EventStreamHandler{realtimeService: realtimeService, heartbeat: heartbeat, logger: logger}
+}
+
+// Stream handles GET /api/v1/events/stream
+//
+// Events are sent as "id", "event" and "data" fields, the data being the
+// JSON-encoded domain.RealtimeEvent and the id the hub's cursor rather than
+// the event's own ID. A client resuming with a Last-Event-ID header (or
+// last_event_id query parameter) receives the events it missed, or a single
+// "reset" event when they are no longer buffered, the cursor came from
+// another replica, or it must otherwise reload its state. The server closes the stream when the client falls too
+// far behind; the client then reconnects and resumes.
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub, err := h.realtimeService.Subscribe(c.Request.Context(),
+		authUserID(c),
+		c.GetString(middleware.ContextKeyUserRole),
+		c.GetInt64(middleware.ContextKeyEnterpriseID),
+		lastEventID,
+	)
+	if err != nil {
+		log.Error("failed to subscribe to event stream", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	write := func(frame string) error {
+		// Replaces the server-wide write timeout, which would end the stream.
+		err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if _, err := io.WriteString(c.Writer, frame); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	writeEvent := func(m realtime.Message) error {
+		data, err := json.Marshal(m.Event)
+		if err != nil {
+			return err
+		}
+		return write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", m.Cursor, m.Event.Type, data))
+	}
+
+	if err := write(fmt.Sprintf("retry: %d\n\n", streamRetry.Milliseconds())); err != nil {
+		return
+	}
+	if sub.Reset {
+		if err := write("event: reset\ndata: {}\n\n"); err != nil {
+			return
+		}
+	}
+	for _, m := range sub.Replay {
+		if err := writeEvent(m); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case m, ok := <-sub.Events:
+			if !ok {
+				log.Debug("event stream closed by server", slog.Int64("user_id", authUserID(c)))
+				return
+			}
+			if err := writeEvent(m); err != nil {
+				log.Debug("event stream write failed", slog.String("error", err.Error()))
+				return
+			}
+			heartbeat.Reset(h.heartbeat)
+		case <-heartbeat.C:
+			if err := write(": heartbeat\n\n"); err != nil {
+				log.Debug("event stream write failed", slog.String("error", err.Error()))
+				return
+			}
+		}
+	}
+}
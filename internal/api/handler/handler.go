@@ -5,32 +5,65 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"my-application/internal/api/interceptor"
+	"my-application/internal/api/middleware"
 	"my-application/internal/domain"
+	"my-application/internal/hasura"
+	"my-application/internal/i18n"
 	"my-application/internal/service"
 )
 
 // Handler aggregates all route handlers and shared dependencies.
 type Handler struct {
-	Health *HealthHandler
-	User   *UserHandler
-	logger *slog.Logger
+	Health       *HealthHandler
+	User         *UserHandler
+	UserImport   *UserImportHandler
+	DataExport   *DataExportHandler
+	Retention    *RetentionHandler
+	Notification *NotificationHandler
+	EventStream  *EventStreamHandler
+	Webhook      *WebhookHandler
+	Invitation   *InvitationHandler
+	HasuraEvent  *HasuraEventHandler
+	Docs         *DocsHandler
+	logger       *slog.Logger
 }
 
 // NewHandler creates a Handler with all sub-handlers wired up.
 func NewHandler(
 	userService service.UserService,
+	importService service.UserImportService,
+	exportService service.DataExportService,
+	retentionService service.RetentionService,
+	notificationService service.NotificationService,
+	realtimeService service.RealtimeService,
+	webhookService service.WebhookService,
+	invitationService service.InvitationService,
+	hasuraDispatcher *hasura.Dispatcher,
+	streamHeartbeat time.Duration,
 	dbPool *pgxpool.Pool,
 	logger *slog.Logger,
 ) *Handler {
 	return &Handler{
-		Health: NewHealthHandler(dbPool, logger),
-		User:   NewUserHandler(userService, logger),
-		logger: logger,
+		Health:       NewHealthHandler(dbPool, logger),
+		User:         NewUserHandler(userService, logger),
+		UserImport:   NewUserImportHandler(importService, logger),
+		DataExport:   NewDataExportHandler(exportService, logger),
+		Retention:    NewRetentionHandler(retentionService, logger),
+		Notification: NewNotificationHandler(notificationService, logger),
+		EventStream:  NewEventStreamHandler(realtimeService, streamHeartbeat, logger),
+		Webhook:      NewWebhookHandler(webhookService, logger),
+		Invitation:   NewInvitationHandler(invitationService, logger),
+		HasuraEvent:  NewHasuraEventHandler(hasuraDispatcher, logger),
+		Docs:         NewDocsHandler(logger),
+		logger:       logger,
 	}
 }
 
@@ -39,8 +72,116 @@ func respondError(c *gin.Context, err error) {
 	var appErr *domain.AppError
 	if errors.As(err, &appErr) {
 		status := domain.HTTPStatusFromError(appErr.Err)
-		interceptor.Fail(c, status, appErr.Message, appErr.Details)
+		interceptor.FailWithCode(c, status, domain.ErrorCodeOf(err), appErr.Message,
+			i18n.Details(appErr, interceptor.Locale(c)))
 		return
 	}
 	interceptor.Fail(c, http.StatusInternalServerError, "internal server error", nil)
 }
+
+// authUserID returns the authenticated user's ID set by middleware.Auth.
+func authUserID(c *gin.Context) int64 {
+	return c.GetInt64(middleware.ContextKeyUserID)
+}
+
+// hasRole reports whether the authenticated user has one of the given roles.
+func hasRole(c *gin.Context, roles ...string) bool {
+	role := c.GetString(middleware.ContextKeyUserRole)
+	for _, r := range roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRoleChange refuses to give the user with userID role when the caller
+// may not assign it, or when it would change the caller's own role. userID
+// is 0 for a new user.
+func checkRoleChange(c *gin.Context, userID int64, role string) error {
+	callerRole := c.GetString(middleware.ContextKeyUserRole)
+	if userID != 0 && userID == authUserID(c) && role != callerRole {
+		return domain.NewAppError(domain.ErrForbidden, "users may not change their own role").
+			WithCode(domain.CodeRoleNotAllowed)
+	}
+	if !domain.CanAssignRole(callerRole, role) {
+		return domain.NewAppError(domain.ErrForbidden, "only mta may assign the "+role+" role").
+			WithCode(domain.CodeRoleNotAllowed)
+	}
+	return nil
+}
+
+// enterpriseScope returns the enterprise the caller is confined to, or nil
+// for mta which operates across all enterprises.
+func enterpriseScope(c *gin.Context) *int64 {
+	if hasRole(c, "mta") {
+		return nil
+	}
+	id := c.GetInt64(middleware.ContextKeyEnterpriseID)
+	return &id
+}
+
+// canAccessEnterprise reports whether the caller may act on enterpriseID.
+func canAccessEnterprise(c *gin.Context, enterpriseID int64) bool {
+	scope := enterpriseScope(c)
+	return scope == nil || *scope == enterpriseID
+}
+
+// enterpriseParam parses the :id enterprise parameter and enforces that
+// eta callers only touch their own enterprise.
+func enterpriseParam(c *gin.Context) (int64, bool) {
+	enterpriseID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid enterprise ID"))
+		return 0, false
+	}
+	if !canAccessEnterprise(c, enterpriseID) {
+		respondError(c, domain.NewAppError(domain.ErrForbidden, "access denied: enterprise out of scope").
+			WithCode(domain.CodeEnterpriseOutOfScope))
+		return 0, false
+	}
+	return enterpriseID, true
+}
+
+// versionETag returns the entity tag of a resource version.
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch returns the version named by the request's If-Match header, or 0
+// when there is none or it is "*", which any current version matches. Only
+// a single strong entity tag is accepted: weak tags never match under the
+// strong comparison If-Match requires, and a tag that is not a version
+// matches no version either.
+func ifMatch(c *gin.Context) (int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return 0, domain.NewAppError(domain.ErrPreconditionFailed, "If-Match needs a strong entity tag")
+	}
+	tag, ok := strings.CutPrefix(header, `"`)
+	if tag, ok = strings.CutSuffix(tag, `"`); !ok || strings.Contains(tag, `"`) {
+		return 0, domain.NewAppError(domain.ErrInvalidInput, `If-Match must be "*" or a single entity tag`)
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, domain.NewAppError(domain.ErrPreconditionFailed, "If-Match names no version of this resource")
+	}
+	return version, nil
+}
+
+// notModified answers 304 Not Modified and reports true when the request's
+// If-None-Match header names etag, comparing weakly as RFC 9110 requires.
+func notModified(c *gin.Context, etag string) bool {
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			c.Header("ETag", etag)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
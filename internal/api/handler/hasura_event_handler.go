@@ -0,0 +1,62 @@
+// internal/api/handler/hasura_event_handler.go
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/domain"
+	"my-application/internal/hasura"
+	"my-application/pkg/logger"
+)
+
+// HasuraEventHandler receives Hasura event trigger deliveries.
+type HasuraEventHandler struct {
+	dispatcher *hasura.Dispatcher
+	logger     *slog.Logger
+}
+
+// NewHasuraEventHandler creates a HasuraEventHandler.
+func NewHasuraEventHandler(dispatcher *hasura.Dispatcher, logger *slog.Logger) *HasuraEventHandler {
+	return &HasuraEventHandler{dispatcher: dispatcher, logger: logger}
+}
+
+// Receive handles POST /api/v1/events/hasura
+//
+// Hasura only looks at the status: anything but 2xx is retried as the
+// trigger's retry_conf allows. The body shows up in its invocation logs.
+func (h *HasuraEventHandler) Receive(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	var event hasura.Event
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid event payload"})
+		return
+	}
+
+	processed, err := h.dispatcher.Dispatch(c.Request.Context(), &event)
+	if err != nil {
+		log.Error("failed to process hasura event",
+			slog.String("event_id", event.ID),
+			slog.String("trigger", event.Trigger.Name),
+			slog.Int("retry", event.DeliveryInfo.CurrentRetry),
+			slog.String("error", err.Error()),
+		)
+		var appErr *domain.AppError
+		if errors.As(err, &appErr) {
+			c.JSON(domain.HTTPStatusFromError(appErr.Err), gin.H{"message": appErr.Message})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "internal server error"})
+		return
+	}
+
+	message := "processed"
+	if !processed {
+		message = "already processed"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "event_id": event.ID})
+}
@@ -0,0 +1,147 @@
+// internal/api/handler/invitation_handler.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/request"
+	"my-application/internal/api/response"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
+)
+
+// InvitationHandler handles the invitations of an enterprise's users.
+// Invitees accept through the auth routes.
+type InvitationHandler struct {
+	invitationService service.InvitationService
+	logger            *slog.Logger
+}
+
+// NewInvitationHandler creates an InvitationHandler.
+func NewInvitationHandler(invitationService service.InvitationService, logger *slog.Logger) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService, logger: logger}
+}
+
+// List handles GET /api/v1/enterprises/:id/invitations
+//
+// Pending and expired invitations are listed; accepted and revoked ones
+// are not.
+func (h *InvitationHandler) List(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, ok := enterpriseParam(c)
+	if !ok {
+		return
+	}
+
+	invs, err := h.invitationService.ListInvitations(c.Request.Context(), enterpriseID)
+	if err != nil {
+		log.Error("failed to list invitations", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	now := time.Now()
+	resp := make([]response.InvitationResponse, len(invs))
+	for i := range invs {
+		resp[i] = toInvitationResponse(&invs[i], now)
+	}
+	interceptor.Success(c, http.StatusOK, resp)
+}
+
+// Create handles POST /api/v1/enterprises/:id/invitations
+func (h *InvitationHandler) Create(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, ok := enterpriseParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, request.BindingError(err))
+		return
+	}
+
+	inv := &domain.Invitation{
+		EnterpriseID: &enterpriseID,
+		Email:        req.Email,
+		Role:         req.Role,
+		FullName:     req.FullName,
+		Locale:       req.Locale,
+	}
+	if err := h.invitationService.Invite(c.Request.Context(), inv, authUserID(c)); err != nil {
+		log.Error("failed to create invitation", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusCreated, toInvitationResponse(inv, time.Now()))
+}
+
+// Resend handles POST /api/v1/enterprises/:id/invitations/:invitation_id/resend
+//
+// The invitation gets a new link, valid for a new period, and the previous
+// link stops working.
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, id, ok := invitationParams(c)
+	if !ok {
+		return
+	}
+
+	inv, err := h.invitationService.Resend(c.Request.Context(), enterpriseID, id, authUserID(c))
+	if err != nil {
+		log.Error("failed to resend invitation", slog.Int64("invitation_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, toInvitationResponse(inv, time.Now()))
+}
+
+// Revoke handles DELETE /api/v1/enterprises/:id/invitations/:invitation_id
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, id, ok := invitationParams(c)
+	if !ok {
+		return
+	}
+
+	inv, err := h.invitationService.Revoke(c.Request.Context(), enterpriseID, id, authUserID(c))
+	if err != nil {
+		log.Error("failed to revoke invitation", slog.Int64("invitation_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, toInvitationResponse(inv, time.Now()))
+}
+
+// invitationParams parses the enterprise and invitation IDs from the path.
+func invitationParams(c *gin.Context) (enterpriseID, id int64, ok bool) {
+	enterpriseID, ok = enterpriseParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(c.Param("invitation_id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid invitation ID"))
+		return 0, 0, false
+	}
+	return enterpriseID, id, true
+}
+
+func toInvitationResponse(inv *domain.Invitation, now time.Time) response.InvitationResponse {
+	return response.InvitationResponse{Invitation: *inv, Status: inv.Status(now)}
+}
@@ -0,0 +1,174 @@
+// internal/api/handler/notification_handler.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/request"
+	"my-application/internal/api/response"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
+)
+
+// NotificationHandler handles the caller's inbox, notification settings and
+// push devices. Every route acts on the authenticated user only.
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	logger              *slog.Logger
+}
+
+// NewNotificationHandler creates a NotificationHandler.
+func NewNotificationHandler(notificationService service.NotificationService, logger *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService, logger: logger}
+}
+
+// List handles GET /api/v1/notifications
+func (h *NotificationHandler) List(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	filter := domain.NotificationFilter{
+		UnreadOnly: c.Query("unread") == "true",
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil {
+			filter.Limit = v
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if v, err := strconv.Atoi(offsetStr); err == nil {
+			filter.Offset = v
+		}
+	}
+	filter.Normalize()
+
+	notifications, total, unread, err := h.notificationService.ListNotifications(c.Request.Context(), authUserID(c), filter)
+	if err != nil {
+		log.Error("failed to list notifications", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, response.NotificationListResponse{
+		Notifications: notifications,
+		Total:         total,
+		Unread:        unread,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	})
+}
+
+// MarkRead handles POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid notification ID"))
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), authUserID(c), id); err != nil {
+		log.Error("failed to mark notification read", slog.Int64("notification_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.SuccessWithMessage(c, http.StatusOK, "notification marked as read", nil)
+}
+
+// MarkAllRead handles POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	n, err := h.notificationService.MarkAllRead(c.Request.Context(), authUserID(c))
+	if err != nil {
+		log.Error("failed to mark notifications read", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, gin.H{"updated": n})
+}
+
+// GetSettings handles GET /api/v1/notifications/preferences
+func (h *NotificationHandler) GetSettings(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	settings, err := h.notificationService.GetSettings(c.Request.Context(), authUserID(c))
+	if err != nil {
+		log.Error("failed to get notification settings", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, settings)
+}
+
+// UpdateSettings handles PUT /api/v1/notifications/preferences
+func (h *NotificationHandler) UpdateSettings(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	var req request.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, request.BindingError(err))
+		return
+	}
+
+	settings := &domain.NotificationSettings{
+		Preferences: make([]domain.NotificationPreference, len(req.Preferences)),
+	}
+	if req.QuietHours != nil {
+		settings.QuietHours = &domain.QuietHours{Start: req.QuietHours.Start, End: req.QuietHours.End}
+	}
+	for i, p := range req.Preferences {
+		settings.Preferences[i] = domain.NotificationPreference{Type: p.Type, InApp: p.InApp, Push: p.Push, Email: p.Email}
+	}
+
+	updated, err := h.notificationService.UpdateSettings(c.Request.Context(), authUserID(c), settings)
+	if err != nil {
+		log.Error("failed to update notification settings", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, updated)
+}
+
+// RegisterDevice handles POST /api/v1/notifications/devices
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	var req request.RegisterPushDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, request.BindingError(err))
+		return
+	}
+
+	device := &domain.PushDevice{UserID: authUserID(c), Token: req.Token, Platform: req.Platform}
+	if err := h.notificationService.RegisterDevice(c.Request.Context(), device); err != nil {
+		log.Error("failed to register push device", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusCreated, device)
+}
+
+// UnregisterDevice handles DELETE /api/v1/notifications/devices/:token
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	if err := h.notificationService.UnregisterDevice(c.Request.Context(), authUserID(c), c.Param("token")); err != nil {
+		log.Error("failed to unregister push device", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.SuccessWithMessage(c, http.StatusOK, "push device unregistered", nil)
+}
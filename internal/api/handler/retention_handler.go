@@ -0,0 +1,131 @@
+// internal/api/handler/retention_handler.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/request"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
+)
+
+// RetentionHandler handles retention policy and legal hold requests.
+type RetentionHandler struct {
+	retentionService service.RetentionService
+	logger           *slog.Logger
+}
+
+// NewRetentionHandler creates a RetentionHandler.
+func NewRetentionHandler(retentionService service.RetentionService, logger *slog.Logger) *RetentionHandler {
+	return &RetentionHandler{retentionService: retentionService, logger: logger}
+}
+
+// ListPolicies handles GET /api/v1/enterprises/:id/retention-policies
+func (h *RetentionHandler) ListPolicies(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, ok := enterpriseParam(c)
+	if !ok {
+		return
+	}
+
+	policies, err := h.retentionService.ListPolicies(c.Request.Context(), enterpriseID)
+	if err != nil {
+		log.Error("failed to list retention policies", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, policies)
+}
+
+// SetPolicy handles PUT /api/v1/enterprises/:id/retention-policies/:table
+func (h *RetentionHandler) SetPolicy(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, ok := enterpriseParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.SetRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, request.BindingError(err))
+		return
+	}
+
+	policy := &domain.RetentionPolicy{
+		EnterpriseID:  enterpriseID,
+		TableName:     c.Param("table"),
+		RetentionDays: req.RetentionDays,
+		Action:        req.Action,
+		IsActive:      true,
+	}
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+
+	if err := h.retentionService.SetPolicy(c.Request.Context(), policy, authUserID(c)); err != nil {
+		log.Error("failed to set retention policy", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, policy)
+}
+
+// DeletePolicy handles DELETE /api/v1/enterprises/:id/retention-policies/:table
+func (h *RetentionHandler) DeletePolicy(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, ok := enterpriseParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.retentionService.DeletePolicy(c.Request.Context(), enterpriseID, c.Param("table"), authUserID(c)); err != nil {
+		log.Error("failed to delete retention policy", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.SuccessWithMessage(c, http.StatusOK, "retention policy deleted successfully", nil)
+}
+
+// SetLegalHold handles PUT /api/v1/residents/:id/legal-hold
+func (h *RetentionHandler) SetLegalHold(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	residentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid resident ID"))
+		return
+	}
+
+	var req request.SetLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, request.BindingError(err))
+		return
+	}
+	if req.LegalHold == nil {
+		respondError(c, domain.NewValidationError("validation failed", map[string]string{
+			"legal_hold": "legal_hold is required",
+		}))
+		return
+	}
+
+	err = h.retentionService.SetLegalHold(c.Request.Context(), residentID, enterpriseScope(c), *req.LegalHold, authUserID(c))
+	if err != nil {
+		log.Error("failed to set legal hold", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, gin.H{"resident_id": residentID, "legal_hold": *req.LegalHold})
+}
@@ -31,43 +31,55 @@ func NewUserHandler(userService service.UserService, logger *slog.Logger) *UserH
 func (h *UserHandler) List(c *gin.Context) {
 	log := logger.FromContext(c.Request.Context())
 
-	filter := domain.UserFilter{
-		Role: c.Query("role"),
+	var query request.ListUsersQuery
+	if err := request.BindQuery(c.Request.URL.Query(), &query); err != nil {
+		respondError(c, err)
+		return
 	}
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if v, err := strconv.Atoi(limitStr); err == nil {
-			filter.Limit = v
-		}
+	// Deleted users stay hidden from everyone but mta until they are purged.
+	if query.Deleted && !hasRole(c, "mta") {
+		respondError(c, domain.NewAppError(domain.ErrForbidden, "only mta may list deleted users").
+			WithCode(domain.CodeRoleNotAllowed))
+		return
 	}
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if v, err := strconv.Atoi(offsetStr); err == nil {
-			filter.Offset = v
-		}
+	filter := domain.UserFilter{
+		Role:          query.Role,
+		IsActive:      query.IsActive,
+		Deleted:       query.Deleted,
+		EnterpriseID:  query.EnterpriseID,
+		Search:        query.Search,
+		CreatedAfter:  query.CreatedAfter,
+		CreatedBefore: query.CreatedBefore,
+		Sort:          query.Sort,
+		Cursor:        query.Cursor,
+		WithTotal:     query.IncludeTotal,
 	}
-	if activeStr := c.Query("is_active"); activeStr != "" {
-		v := activeStr == "true"
-		filter.IsActive = &v
+	if query.Limit != nil {
+		filter.Limit = *query.Limit
 	}
 
-	users, total, err := h.userService.ListUsers(c.Request.Context(), filter)
+	page, err := h.userService.ListUsers(c.Request.Context(), filter)
 	if err != nil {
 		log.Error("failed to list users", slog.String("error", err.Error()))
 		respondError(c, err)
 		return
 	}
 
-	userResponses := make([]response.UserResponse, len(users))
-	for i, u := range users {
+	userResponses := make([]response.UserResponse, len(page.Users))
+	for i, u := range page.Users {
 		userResponses[i] = toUserResponse(u)
 	}
 
-	interceptor.Success(c, http.StatusOK, response.UserListResponse{
-		Users:  userResponses,
-		Total:  total,
-		Limit:  filter.Limit,
-		Offset: filter.Offset,
-	})
+	filter.Normalize()
+	resp := response.UserListResponse{
+		Users: userResponses,
+		Limit: filter.Limit,
+		Total: page.Total,
+	}
+	if page.NextCursor != "" {
+		resp.NextCursor = &page.NextCursor
+	}
+	interceptor.Success(c, http.StatusOK, resp)
 }
 
 // GetByID handles GET /api/v1/users/:id
@@ -87,6 +99,11 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	etag := versionETag(user.Version)
+	if notModified(c, etag) {
+		return
+	}
+	c.Header("ETag", etag)
 	interceptor.Success(c, http.StatusOK, toUserResponse(*user))
 }
 
@@ -96,7 +113,7 @@ func (h *UserHandler) Create(c *gin.Context) {
 
 	var req request.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid JSON: "+err.Error()))
+		respondError(c, request.BindingError(err))
 		return
 	}
 
@@ -105,6 +122,15 @@ func (h *UserHandler) Create(c *gin.Context) {
 		Email:    req.Email,
 		FullName: req.FullName,
 		Role:     req.Role,
+		Locale:   req.Locale,
+	}
+	if err := checkRoleChange(c, 0, user.Role); err != nil {
+		respondError(c, err)
+		return
+	}
+	// eta can only create users in their own enterprise.
+	if scope := enterpriseScope(c); scope != nil && *scope != 0 {
+		user.EnterpriseID = scope
 	}
 
 	if err := h.userService.CreateUser(c.Request.Context(), user); err != nil {
@@ -113,10 +139,13 @@ func (h *UserHandler) Create(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", versionETag(user.Version))
 	interceptor.Success(c, http.StatusCreated, toUserResponse(*user))
 }
 
-// Update handles PUT /api/v1/users/:id
+// Update handles PUT /api/v1/users/:id, replacing the user's profile with
+// the body. With If-Match, the user is only updated if it is still that
+// version.
 func (h *UserHandler) Update(c *gin.Context) {
 	log := logger.FromContext(c.Request.Context())
 
@@ -125,10 +154,15 @@ func (h *UserHandler) Update(c *gin.Context) {
 		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID"))
 		return
 	}
+	version, err := ifMatch(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
 	var req request.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid JSON: "+err.Error()))
+		respondError(c, request.BindingError(err))
 		return
 	}
 
@@ -138,9 +172,13 @@ func (h *UserHandler) Update(c *gin.Context) {
 		Email:    req.Email,
 		FullName: req.FullName,
 		Role:     req.Role,
+		Locale:   req.Locale,
+		IsActive: *req.IsActive,
+		Version:  version,
 	}
-	if req.IsActive != nil {
-		user.IsActive = *req.IsActive
+	if err := checkRoleChange(c, id, user.Role); err != nil {
+		respondError(c, err)
+		return
 	}
 
 	if err := h.userService.UpdateUser(c.Request.Context(), user); err != nil {
@@ -149,6 +187,65 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", versionETag(user.Version))
+	interceptor.Success(c, http.StatusOK, toUserResponse(*user))
+}
+
+// Patch handles PATCH /api/v1/users/:id, applying a JSON Merge Patch to
+// the user's profile. With If-Match, the user is only updated if it is
+// still that version.
+func (h *UserHandler) Patch(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID"))
+		return
+	}
+	if ct := c.ContentType(); ct != request.MIMEMergePatch && ct != gin.MIMEJSON {
+		interceptor.FailWithCode(c, http.StatusUnsupportedMediaType, domain.CodeUnsupportedMediaType,
+			"content type must be "+request.MIMEMergePatch, nil)
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid request body"))
+		return
+	}
+	var req request.PatchUserRequest
+	if err := request.BindMergePatch(body, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	patch := domain.UserPatch{
+		Username: req.Username,
+		Email:    req.Email,
+		FullName: req.FullName,
+		Role:     req.Role,
+		Locale:   req.Locale,
+		IsActive: req.IsActive,
+	}
+	if patch.Role != nil {
+		if err := checkRoleChange(c, id, *patch.Role); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+	user, err := h.userService.PatchUser(c.Request.Context(), id, patch, version)
+	if err != nil {
+		log.Error("failed to patch user", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(user.Version))
 	interceptor.Success(c, http.StatusOK, toUserResponse(*user))
 }
 
@@ -171,6 +268,46 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	interceptor.SuccessWithMessage(c, http.StatusOK, "user deleted successfully", nil)
 }
 
+// Restore handles POST /api/v1/users/:id/restore
+func (h *UserHandler) Restore(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID"))
+		return
+	}
+
+	user, err := h.userService.RestoreUser(c.Request.Context(), id)
+	if err != nil {
+		log.Error("failed to restore user", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(user.Version))
+	interceptor.Success(c, http.StatusOK, toUserResponse(*user))
+}
+
+// Purge handles POST /api/v1/users/:id/purge
+func (h *UserHandler) Purge(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID"))
+		return
+	}
+
+	if err := h.userService.PurgeUser(c.Request.Context(), id); err != nil {
+		log.Error("failed to purge user", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.SuccessWithMessage(c, http.StatusOK, "user purged successfully", nil)
+}
+
 func toUserResponse(u domain.User) response.UserResponse {
 	return response.UserResponse{
 		ID:        u.ID,
@@ -178,8 +315,11 @@ func toUserResponse(u domain.User) response.UserResponse {
 		Email:     u.Email,
 		FullName:  u.FullName,
 		Role:      u.Role,
+		Locale:    u.Locale,
 		IsActive:  u.IsActive,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
+		DeletedAt: u.DeletedAt,
+		Version:   u.Version,
 	}
 }
@@ -0,0 +1,166 @@
+// internal/api/handler/user_import_handler.go
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/request"
+	"my-application/internal/api/response"
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
+)
+
+// maxImportBytes is the largest user import file accepted.
+const maxImportBytes = 10 << 20
+
+// UserImportHandler handles bulk user imports.
+type UserImportHandler struct {
+	importService service.UserImportService
+	logger        *slog.Logger
+}
+
+// NewUserImportHandler creates a UserImportHandler.
+func NewUserImportHandler(importService service.UserImportService, logger *slog.Logger) *UserImportHandler {
+	return &UserImportHandler{importService: importService, logger: logger}
+}
+
+// Import handles POST /api/v1/users/import. The body is a CSV or JSON lines
+// file of users. Small files are imported within the request and answered
+// with 200; larger ones are run by the worker and answered with 202 and
+// the import's location.
+func (h *UserImportHandler) Import(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	var format string
+	switch c.ContentType() {
+	case request.MIMECSV:
+		format = domain.ImportFormatCSV
+	case request.MIMEJSONLines:
+		format = domain.ImportFormatJSONL
+	default:
+		interceptor.FailWithCode(c, http.StatusUnsupportedMediaType, domain.CodeUnsupportedMediaType,
+			"content type must be "+request.MIMECSV+" or "+request.MIMEJSONLines, nil)
+		return
+	}
+
+	var query request.ImportUsersQuery
+	if err := request.BindQuery(c.Request.URL.Query(), &query); err != nil {
+		respondError(c, err)
+		return
+	}
+	imp := &domain.UserImport{
+		Mode:         query.Mode,
+		DryRun:       query.DryRun,
+		Invite:       query.Invite == nil || *query.Invite,
+		EnterpriseID: query.EnterpriseID,
+	}
+	if imp.Mode == "" {
+		imp.Mode = domain.ImportModeAtomic
+	}
+	if callerID := authUserID(c); callerID != 0 {
+		imp.RequestedBy = &callerID
+	}
+	// eta can only import users into their own enterprise.
+	if scope := enterpriseScope(c); scope != nil && *scope != 0 {
+		if imp.EnterpriseID != nil && *imp.EnterpriseID != *scope {
+			respondError(c, domain.NewAppError(domain.ErrForbidden, "access denied: enterprise out of scope").
+				WithCode(domain.CodeEnterpriseOutOfScope))
+			return
+		}
+		imp.EnterpriseID = scope
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, domain.NewAppError(domain.ErrInvalidInput,
+				fmt.Sprintf("an import file may be at most %d MiB", maxImportBytes>>20)))
+			return
+		}
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "failed to read the import file"))
+		return
+	}
+
+	if err := h.importService.ImportUsers(c.Request.Context(), imp, bytes.NewReader(body), format); err != nil {
+		log.Error("failed to import users", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	if imp.Status == domain.ImportStatusPending {
+		c.Header("Location", fmt.Sprintf("/api/v1/users/imports/%d", imp.ID))
+		interceptor.Success(c, http.StatusAccepted, toUserImportResponse(c, imp))
+		return
+	}
+	interceptor.Success(c, http.StatusOK, toUserImportResponse(c, imp))
+}
+
+// GetByID handles GET /api/v1/users/imports/:id
+func (h *UserImportHandler) GetByID(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid import ID"))
+		return
+	}
+
+	imp, err := h.importService.GetImport(c.Request.Context(), id)
+	if err != nil {
+		log.Error("failed to get user import", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	// eta only sees the imports into their own enterprise.
+	if scope := enterpriseScope(c); scope != nil && (imp.EnterpriseID == nil || *imp.EnterpriseID != *scope) {
+		respondError(c, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user import with id %d not found", id)))
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, toUserImportResponse(c, imp))
+}
+
+func toUserImportResponse(c *gin.Context, imp *domain.UserImport) response.UserImportResponse {
+	locale := interceptor.Locale(c)
+	resp := response.UserImportResponse{
+		ID:           imp.ID,
+		Status:       imp.Status,
+		Mode:         imp.Mode,
+		DryRun:       imp.DryRun,
+		Invite:       imp.Invite,
+		EnterpriseID: imp.EnterpriseID,
+		Valid:        imp.Count(domain.ImportRowValid),
+		Created:      imp.Count(domain.ImportRowCreated),
+		Failed:       imp.Count(domain.ImportRowFailed),
+		Skipped:      imp.Count(domain.ImportRowSkipped),
+		Results:      make([]response.UserImportRowResponse, len(imp.Results)),
+		Error:        imp.Error,
+		CompletedAt:  imp.CompletedAt,
+	}
+	if !imp.CreatedAt.IsZero() {
+		resp.CreatedAt = &imp.CreatedAt
+	}
+	for i, r := range imp.Results {
+		resp.Results[i] = response.UserImportRowResponse{
+			Line:   r.Line,
+			Status: r.Status,
+			UserID: r.UserID,
+			Code:   string(r.Code),
+			Errors: i18n.Violations(r.Violations, locale),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,226 @@
+// internal/api/handler/webhook_handler.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/request"
+	"my-application/internal/api/response"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+	"my-application/pkg/logger"
+)
+
+// WebhookHandler handles an enterprise's webhook subscriptions.
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	logger         *slog.Logger
+}
+
+// NewWebhookHandler creates a WebhookHandler.
+func NewWebhookHandler(webhookService service.WebhookService, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService, logger: logger}
+}
+
+// List handles GET /api/v1/enterprises/:id/webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, ok := enterpriseParam(c)
+	if !ok {
+		return
+	}
+
+	subs, err := h.webhookService.ListSubscriptions(c.Request.Context(), enterpriseID)
+	if err != nil {
+		log.Error("failed to list webhooks", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, subs)
+}
+
+// Create handles POST /api/v1/enterprises/:id/webhooks
+func (h *WebhookHandler) Create(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, ok := enterpriseParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, request.BindingError(err))
+		return
+	}
+
+	sub := &domain.WebhookSubscription{
+		EnterpriseID: enterpriseID,
+		URL:          req.URL,
+		Description:  req.Description,
+		EventTypes:   req.EventTypes,
+	}
+	if err := h.webhookService.CreateSubscription(c.Request.Context(), sub, authUserID(c)); err != nil {
+		log.Error("failed to create webhook", slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusCreated, response.WebhookCreatedResponse{
+		WebhookSubscription: *sub,
+		Secret:              sub.Secret,
+	})
+}
+
+// GetByID handles GET /api/v1/enterprises/:id/webhooks/:webhook_id
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookService.GetSubscription(c.Request.Context(), enterpriseID, id)
+	if err != nil {
+		log.Error("failed to get webhook", slog.Int64("webhook_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, sub)
+}
+
+// Update handles PUT /api/v1/enterprises/:id/webhooks/:webhook_id
+func (h *WebhookHandler) Update(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, request.BindingError(err))
+		return
+	}
+
+	sub := &domain.WebhookSubscription{
+		ID:           id,
+		EnterpriseID: enterpriseID,
+		URL:          req.URL,
+		Description:  req.Description,
+		EventTypes:   req.EventTypes,
+		IsActive:     true,
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+
+	if err := h.webhookService.UpdateSubscription(c.Request.Context(), sub, authUserID(c)); err != nil {
+		log.Error("failed to update webhook", slog.Int64("webhook_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, sub)
+}
+
+// Delete handles DELETE /api/v1/enterprises/:id/webhooks/:webhook_id
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), enterpriseID, id, authUserID(c)); err != nil {
+		log.Error("failed to delete webhook", slog.Int64("webhook_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.SuccessWithMessage(c, http.StatusOK, "webhook deleted successfully", nil)
+}
+
+// ListDeliveries handles GET /api/v1/enterprises/:id/webhooks/:webhook_id/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	var filter domain.WebhookDeliveryFilter
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil {
+			filter.Limit = v
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if v, err := strconv.Atoi(offsetStr); err == nil {
+			filter.Offset = v
+		}
+	}
+	filter.Normalize()
+
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request.Context(), enterpriseID, id, filter)
+	if err != nil {
+		log.Error("failed to list webhook deliveries", slog.Int64("webhook_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, response.WebhookDeliveryListResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      filter.Limit,
+		Offset:     filter.Offset,
+	})
+}
+
+// SendTest handles POST /api/v1/enterprises/:id/webhooks/:webhook_id/test
+//
+// The outcome of the test delivery is returned with 200 even when the
+// receiver failed; the delivery's status and response code tell.
+func (h *WebhookHandler) SendTest(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	enterpriseID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.SendTest(c.Request.Context(), enterpriseID, id)
+	if err != nil {
+		log.Error("failed to send webhook test event", slog.Int64("webhook_id", id), slog.String("error", err.Error()))
+		respondError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, delivery)
+}
+
+// webhookParams parses the enterprise and webhook IDs from the path.
+func webhookParams(c *gin.Context) (enterpriseID, id int64, ok bool) {
+	enterpriseID, ok = enterpriseParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(c.Param("webhook_id"), 10, 64)
+	if err != nil {
+		respondError(c, domain.NewAppError(domain.ErrInvalidInput, "invalid webhook ID"))
+		return 0, 0, false
+	}
+	return enterpriseID, id, true
+}
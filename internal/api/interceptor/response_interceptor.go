@@ -2,25 +2,51 @@
 package interceptor
 
 import (
+	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
 )
 
 // RequestIDKey is the gin context key for the request ID.
 const RequestIDKey = "request_id"
 
+// LocaleKey is the gin context key for the locale of response messages.
+const LocaleKey = "locale"
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details. Clients
+// that prefer it in Accept receive errors as a Problem instead of an
+// APIResponse.
+const MIMEProblemJSON = "application/problem+json"
+
 // APIResponse is the standard JSON envelope for ALL API responses.
 type APIResponse struct {
 	Success   bool        `json:"success"`
 	Message   string      `json:"message"`
+	Code      string      `json:"code,omitempty"` // domain.ErrorCode, set on errors only
 	Data      interface{} `json:"data"`
 	Errors    interface{} `json:"errors"`
 	RequestID string      `json:"request_id"`
 	Timestamp string      `json:"timestamp"`
 }
 
+// Problem is an RFC 7807 problem details object. Type is always
+// "about:blank"; clients tell problems apart by Code.
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail"`
+	Instance  string      `json:"instance"`
+	Code      string      `json:"code"`
+	Errors    interface{} `json:"errors,omitempty"`
+	RequestID string      `json:"request_id"`
+}
+
 // newEnvelope constructs a standard API response envelope.
 func newEnvelope(c *gin.Context, success bool, message string, data, errors interface{}) APIResponse {
 	rid, _ := c.Get(RequestIDKey)
@@ -46,20 +72,118 @@ func SuccessWithMessage(c *gin.Context, status int, message string, data interfa
 	c.JSON(status, newEnvelope(c, true, message, data, nil))
 }
 
-// Fail sends an error JSON response wrapped in the standard envelope.
+// Fail sends an error JSON response wrapped in the standard envelope, with
+// the generic error code of status.
 func Fail(c *gin.Context, status int, message string, errors interface{}) {
-	c.JSON(status, newEnvelope(c, false, message, nil, errors))
+	FailWithCode(c, status, domain.ErrorCodeForStatus(status), message, errors)
+}
+
+// FailWithCode sends an error response carrying code: the standard envelope,
+// or a Problem when the client asked for application/problem+json. The
+// message is translated by code into the request's locale.
+func FailWithCode(c *gin.Context, status int, code domain.ErrorCode, message string, errors interface{}) {
+	locale := Locale(c)
+	message = i18n.Message(locale, code, message)
+	c.Header("Content-Language", locale)
+
+	if prefersProblem(c) {
+		c.Render(status, problemRender{newProblem(c, status, code, message, errors)})
+		return
+	}
+	resp := newEnvelope(c, false, message, nil, errors)
+	resp.Code = string(code)
+	c.JSON(status, resp)
 }
 
 // Abort sends an error response and aborts the middleware chain.
 func Abort(c *gin.Context, status int, message string, errors interface{}) {
-	c.AbortWithStatusJSON(status, newEnvelope(c, false, message, nil, errors))
+	AbortWithCode(c, status, domain.ErrorCodeForStatus(status), message, errors)
+}
+
+// AbortWithCode sends an error response carrying code and aborts the
+// middleware chain.
+func AbortWithCode(c *gin.Context, status int, code domain.ErrorCode, message string, errors interface{}) {
+	c.Abort()
+	FailWithCode(c, status, code, message, errors)
+}
+
+// Locale returns the locale of response messages chosen for the request by
+// the middleware, or i18n.SourceLocale.
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(LocaleKey); locale != "" {
+		return locale
+	}
+	return i18n.SourceLocale
+}
+
+// prefersProblem reports whether the Accept header ranks problem+json above
+// plain JSON. Clients that send no Accept header get the envelope.
+func prefersProblem(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, MIMEProblemJSON) == MIMEProblemJSON
+}
+
+func newProblem(c *gin.Context, status int, code domain.ErrorCode, message string, errors interface{}) Problem {
+	rid, _ := c.Get(RequestIDKey)
+	requestID, _ := rid.(string) //nolint:errcheck // type assertion fallback to empty string is intended
+
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    message,
+		Instance:  c.Request.URL.Path,
+		Code:      string(code),
+		Errors:    errors,
+		RequestID: requestID,
+	}
+}
+
+// problemRender writes a Problem as JSON with the problem+json content type.
+type problemRender struct{ problem Problem }
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", MIMEProblemJSON)
+}
+
+// ActionError is the error body Hasura expects from an Action handler. Hasura
+// passes message and extensions on to the GraphQL client.
+type ActionError struct {
+	Message    string                `json:"message"`
+	Extensions ActionErrorExtensions `json:"extensions"`
+}
+
+// ActionErrorExtensions holds the machine-readable part of an ActionError:
+// Code in the style of Hasura's own codes, and ErrorCode as in APIResponse.
+type ActionErrorExtensions struct {
+	Code      string      `json:"code"`
+	ErrorCode string      `json:"error_code"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// ActionFail sends an error response to a Hasura Action call.
+func ActionFail(c *gin.Context, status int, message, code string, errorCode domain.ErrorCode, details interface{}) {
+	c.JSON(status, ActionError{Message: message, Extensions: ActionErrorExtensions{
+		Code: code, ErrorCode: string(errorCode), Details: details,
+	}})
+}
+
+// ActionAbort sends an error response to a Hasura Action call and aborts the
+// middleware chain.
+func ActionAbort(c *gin.Context, status int, message, code string, errorCode domain.ErrorCode) {
+	c.AbortWithStatusJSON(status, ActionError{Message: message, Extensions: ActionErrorExtensions{
+		Code: code, ErrorCode: string(errorCode),
+	}})
 }
 
 // HandleNoRoute returns a handler for unmatched routes (404).
 func HandleNoRoute() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		Fail(c, http.StatusNotFound, "route not found", nil)
+		FailWithCode(c, http.StatusNotFound, domain.CodeRouteNotFound, "route not found", nil)
 	}
 }
 
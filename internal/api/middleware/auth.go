@@ -2,6 +2,7 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -10,12 +11,15 @@ import (
 
 	"my-application/internal/api/interceptor"
 	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/pkg/database"
 )
 
 // Context keys for authenticated user data.
 const (
-	ContextKeyUserID   = "auth_user_id"
-	ContextKeyUserRole = "auth_user_role"
+	ContextKeyUserID       = "auth_user_id"
+	ContextKeyUserRole     = "auth_user_role"
+	ContextKeyEnterpriseID = "auth_enterprise_id" // int64; 0 when the user has no enterprise
 )
 
 // Auth returns a middleware that validates JWT tokens using the auth module.
@@ -24,18 +28,18 @@ func Auth(jwtManager *auth.JWTManager, logger *slog.Logger) gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 
 		if authHeader == "" {
-			interceptor.Abort(c, http.StatusUnauthorized, "missing authorization header", nil)
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthTokenMissing, "missing authorization header", nil)
 			return
 		}
 
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			interceptor.Abort(c, http.StatusUnauthorized, "invalid authorization format", nil)
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthTokenInvalid, "invalid authorization format", nil)
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == "" {
-			interceptor.Abort(c, http.StatusUnauthorized, "empty token", nil)
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthTokenInvalid, "empty token", nil)
 			return
 		}
 
@@ -44,19 +48,18 @@ func Auth(jwtManager *auth.JWTManager, logger *slog.Logger) gin.HandlerFunc {
 			logger.Debug("token validation failed",
 				slog.String("error", err.Error()),
 			)
-			interceptor.Abort(c, http.StatusUnauthorized, "invalid or expired token", nil)
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthTokenInvalid, "invalid or expired token", nil)
 			return
 		}
 
 		// Ensure this is an access token, not a refresh token.
 		if claims.Type != auth.AccessToken {
-			interceptor.Abort(c, http.StatusUnauthorized, "invalid token type", nil)
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthTokenInvalid, "invalid token type", nil)
 			return
 		}
 
-		// Set user info on context for downstream handlers.
-		c.Set(ContextKeyUserID, claims.UserID)
-		c.Set(ContextKeyUserRole, claims.Role)
+		SetIdentity(c, claims.UserID, claims.Role, claims.EnterpriseID)
+		setProfileLocale(c, claims.Locale)
 
 		logger.Debug("auth middleware passed",
 			slog.Int64("user_id", claims.UserID),
@@ -67,6 +70,22 @@ func Auth(jwtManager *auth.JWTManager, logger *slog.Logger) gin.HandlerFunc {
 	}
 }
 
+// SetIdentity sets the authenticated user's info on the context for
+// downstream handlers and scopes the request's database work to them for
+// row-level security; mta works across enterprises.
+func SetIdentity(c *gin.Context, userID int64, role string, enterpriseID int64) {
+	c.Set(ContextKeyUserID, userID)
+	c.Set(ContextKeyUserRole, role)
+	c.Set(ContextKeyEnterpriseID, enterpriseID)
+
+	c.Request = c.Request.WithContext(database.WithTenant(c.Request.Context(), database.Tenant{
+		UserID:       userID,
+		EnterpriseID: enterpriseID,
+		Role:         role,
+		Bypass:       role == "mta",
+	}))
+}
+
 // InternalAuth returns a middleware that validates server-to-server calls
 // using a shared secret in the X-Internal-Secret header.
 func InternalAuth(secret string, logger *slog.Logger) gin.HandlerFunc {
@@ -81,7 +100,58 @@ func InternalAuth(secret string, logger *slog.Logger) gin.HandlerFunc {
 		provided := c.GetHeader("X-Internal-Secret")
 		if provided == "" || provided != secret {
 			logger.Debug("internal auth failed: invalid or missing secret")
-			interceptor.Abort(c, http.StatusUnauthorized, "unauthorized: invalid internal secret", nil)
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthSecretInvalid,
+				"unauthorized: invalid internal secret", nil)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasuraEventAuth returns a middleware that validates Hasura event trigger
+// deliveries using a shared secret in the X-Hasura-Event-Secret header.
+// Without a secret configured every delivery is rejected.
+func HasuraEventAuth(secret string, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			logger.Warn("hasura event auth middleware: no secret configured, rejecting request")
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthSecretInvalid,
+				"unauthorized: invalid event secret", nil)
+			return
+		}
+
+		provided := c.GetHeader("X-Hasura-Event-Secret")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) != 1 {
+			logger.Debug("hasura event auth failed: invalid or missing secret")
+			interceptor.AbortWithCode(c, http.StatusUnauthorized, domain.CodeAuthSecretInvalid,
+				"unauthorized: invalid event secret", nil)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasuraActionAuth returns a middleware that validates Hasura Action calls
+// using a shared secret in the X-Hasura-Action-Secret header. Errors are
+// written in the Action error format so Hasura passes them on. Without a
+// secret configured every call is rejected: the body's session variables
+// are trusted as the caller's identity.
+func HasuraActionAuth(secret string, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			logger.Warn("hasura action auth middleware: no secret configured, rejecting request")
+			interceptor.ActionAbort(c, http.StatusUnauthorized, "unauthorized: invalid action secret", "access-denied",
+				domain.CodeAuthSecretInvalid)
+			return
+		}
+
+		provided := c.GetHeader("X-Hasura-Action-Secret")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) != 1 {
+			logger.Debug("hasura action auth failed: invalid or missing secret")
+			interceptor.ActionAbort(c, http.StatusUnauthorized, "unauthorized: invalid action secret", "access-denied",
+				domain.CodeAuthSecretInvalid)
 			return
 		}
 
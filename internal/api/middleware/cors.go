@@ -13,6 +13,7 @@ type CORSConfig struct {
 	AllowedOrigins   []string
 	AllowedMethods   []string
 	AllowedHeaders   []string
+	ExposedHeaders   []string
 	AllowCredentials bool
 	MaxAge           int
 }
@@ -23,6 +24,7 @@ func CORS(cfg CORSConfig) gin.HandlerFunc {
 		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     cfg.AllowedMethods,
 		AllowHeaders:     cfg.AllowedHeaders,
+		ExposeHeaders:    cfg.ExposedHeaders,
 		AllowCredentials: cfg.AllowCredentials,
 		MaxAge:           time.Duration(cfg.MaxAge) * time.Second,
 	})
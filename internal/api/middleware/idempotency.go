@@ -0,0 +1,209 @@
+// internal/api/middleware/idempotency.go
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/domain"
+	"my-application/internal/idempotency"
+)
+
+// Idempotency headers.
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed" // "true" on a replayed response
+)
+
+// maxIdempotencyKey is the longest Idempotency-Key accepted.
+const maxIdempotencyKey = 255
+
+// defaultIdempotencyBodyBytes is the body limit when the config sets none.
+const defaultIdempotencyBodyBytes = 1 << 20
+
+// IdempotencyConfig holds settings of the Idempotency middleware.
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed to retries of its request.
+	TTL time.Duration
+	// LockTimeout is how long a request may hold its key. A retry after it
+	// runs again, so a replica that died mid-request doesn't block a key
+	// until the TTL.
+	LockTimeout time.Duration
+	// MaxBodyBytes caps the body of a request sent with a key, which is read
+	// into memory to fingerprint it; larger ones are answered 413. Zero
+	// means 1 MiB.
+	MaxBodyBytes int64
+}
+
+// replayedHeaders are the response headers stored and replayed with the
+// status and body.
+var replayedHeaders = []string{"Content-Type", "Content-Language", "ETag", "Location"}
+
+// Idempotency returns a middleware that makes requests sent with an
+// Idempotency-Key header safe to retry. The first request with a key runs
+// and its response is stored; a retry with the same key, method, path and
+// body gets that response again, marked with Idempotent-Replayed. Reusing
+// the key for a different request is answered 422, and a retry while the
+// first request still runs 409. Keys are scoped to the authenticated user,
+// so it must run after Auth on protected routes. Server errors are not
+// stored: the request can be retried.
+//
+// Before sign-in there is no user to scope keys to, so an anonymous key is
+// scoped to its request: only a client sending the same body, which holds
+// the credentials on the auth routes, gets the stored response, and another
+// body with the same key simply runs.
+func Idempotency(store idempotency.Store, cfg IdempotencyConfig, logger *slog.Logger) gin.HandlerFunc {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaultIdempotencyBodyBytes
+	}
+	return func(c *gin.Context) {
+		value := c.GetHeader(HeaderIdempotencyKey)
+		if value == "" {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(value) {
+			interceptor.Abort(c, http.StatusBadRequest, "Idempotency-Key must be 1 to 255 printable ASCII characters", nil)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				interceptor.Abort(c, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("request body must be at most %d bytes", cfg.MaxBodyBytes), nil)
+				return
+			}
+			interceptor.Abort(c, http.StatusBadRequest, "invalid request body", nil)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		fingerprint := requestFingerprint(c.Request, body)
+		key := idempotency.Key{Scope: idempotencyScope(c, fingerprint), Value: value}
+		record, err := store.Claim(ctx, key, fingerprint, time.Now().Add(cfg.LockTimeout))
+		if err != nil {
+			logger.Error("idempotency store failed", slog.String("error", err.Error()))
+			interceptor.Abort(c, http.StatusInternalServerError, "internal server error", nil)
+			return
+		}
+		switch {
+		case record == nil:
+		case record.Fingerprint != fingerprint:
+			interceptor.AbortWithCode(c, http.StatusUnprocessableEntity, domain.CodeIdempotencyKeyReused,
+				"Idempotency-Key was already used for a different request", nil)
+			return
+		case record.Response == nil:
+			c.Header("Retry-After", "1")
+			interceptor.AbortWithCode(c, http.StatusConflict, domain.CodeIdempotencyKeyInFlight,
+				"a request with this Idempotency-Key is still being processed", nil)
+			return
+		default:
+			replay(c, record.Response)
+			return
+		}
+
+		// The key is claimed: run the request, then store its response or,
+		// on a server error or panic, give the key up.
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		stored := false
+		defer func() {
+			if stored {
+				return
+			}
+			if err := store.Release(context.WithoutCancel(ctx), key); err != nil {
+				logger.Error("releasing idempotency key", slog.String("error", err.Error()))
+			}
+		}()
+
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		resp := &idempotency.Response{Status: w.Status(), Header: make(http.Header), Body: w.body.Bytes()}
+		for _, h := range replayedHeaders {
+			if v := w.Header().Values(h); len(v) > 0 {
+				resp.Header[h] = v
+			}
+		}
+		if err := store.Complete(context.WithoutCancel(ctx), key, resp, time.Now().Add(cfg.TTL)); err != nil {
+			logger.Error("storing idempotent response", slog.String("error", err.Error()))
+			return
+		}
+		stored = true
+	}
+}
+
+// validIdempotencyKey reports whether key is 1 to 255 printable ASCII
+// characters.
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKey {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// idempotencyScope returns the namespace of the caller's keys: the
+// authenticated user, or before sign-in the request itself, so one client
+// cannot replay another's response by guessing its key.
+func idempotencyScope(c *gin.Context, fingerprint string) string {
+	if id := c.GetInt64(ContextKeyUserID); id != 0 {
+		return "user:" + strconv.FormatInt(id, 10)
+	}
+	return "anonymous:" + fingerprint
+}
+
+// requestFingerprint identifies a request by its method, URI and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay writes a stored response and aborts the chain.
+func replay(c *gin.Context, resp *idempotency.Response) {
+	for h, v := range resp.Header {
+		c.Writer.Header()[h] = v
+	}
+	c.Header(HeaderIdempotentReplayed, "true")
+	c.Status(resp.Status)
+	_, _ = c.Writer.Write(resp.Body) //nolint:errcheck // the client went away; nothing to do
+	c.Abort()
+}
+
+// recordingWriter keeps a copy of the response body.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,31 @@
+// internal/api/middleware/locale.go
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/i18n"
+)
+
+// Locale returns a middleware that picks the locale of response messages
+// from the Accept-Language header, or defaultLocale when it names no
+// supported language. Auth overrides it with the user's profile locale.
+func Locale(defaultLocale string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale, ok := i18n.Match(c.GetHeader("Accept-Language"))
+		if !ok {
+			locale = defaultLocale
+		}
+		c.Set(interceptor.LocaleKey, locale)
+		c.Next()
+	}
+}
+
+// setProfileLocale makes the user's preferred locale, if set and supported,
+// the locale of response messages.
+func setProfileLocale(c *gin.Context, preference string) {
+	if locale, ok := i18n.Match(preference); ok {
+		c.Set(interceptor.LocaleKey, locale)
+	}
+}
@@ -0,0 +1,99 @@
+// internal/api/middleware/openapi.go
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/interceptor"
+	"my-application/internal/domain"
+)
+
+func init() {
+	// JSON lines bodies (user imports) are checked as text; the handler
+	// parses them.
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.PlainBodyDecoder)
+}
+
+// ValidateRequest returns a middleware that checks parameters and JSON
+// bodies against the operation of the OpenAPI document, answering 400 with
+// one message per offending field before the handler runs. Requests the
+// document doesn't describe pass through, as do security requirements,
+// which the auth middleware enforces. It panics if doc has invalid servers.
+func ValidateRequest(doc *openapi3.T, logger *slog.Logger) gin.HandlerFunc {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		panic(fmt.Sprintf("openapi router: %v", err))
+	}
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			if !errors.Is(err, routers.ErrPathNotFound) && !errors.Is(err, routers.ErrMethodNotAllowed) {
+				logger.Warn("openapi route lookup failed", slog.String("error", err.Error()))
+			}
+			c.Next()
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			interceptor.AbortWithCode(c, http.StatusBadRequest, domain.CodeValidationFailed,
+				"request does not match the API specification",
+				validationDetails(err))
+			return
+		}
+		c.Next()
+	}
+}
+
+// validationDetails flattens a request validation error into the field to
+// message map used by the envelope's errors.
+func validationDetails(err error) map[string]string {
+	details := make(map[string]string)
+	var add func(err error, field string)
+	add = func(err error, field string) {
+		switch e := err.(type) {
+		case openapi3.MultiError:
+			for _, inner := range e {
+				add(inner, field)
+			}
+		case *openapi3filter.RequestError:
+			if e.Parameter != nil {
+				field = e.Parameter.Name
+			}
+			if e.Err != nil {
+				add(e.Err, field)
+			} else {
+				details[field] = e.Reason
+			}
+		case *openapi3.SchemaError:
+			if pointer := e.JSONPointer(); len(pointer) > 0 {
+				field = strings.Join(pointer, ".")
+			}
+			details[field] = e.Reason
+		default:
+			details[field] = err.Error()
+		}
+	}
+	add(err, "body")
+	return details
+}
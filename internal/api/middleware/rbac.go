@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"my-application/internal/api/interceptor"
+	"my-application/internal/domain"
 )
 
 // RequireRole returns a middleware that checks if the authenticated user
@@ -20,18 +21,18 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get(ContextKeyUserRole)
 		if !exists {
-			interceptor.Abort(c, http.StatusForbidden, "access denied: no role found", nil)
+			interceptor.AbortWithCode(c, http.StatusForbidden, domain.CodeRoleNotAllowed, "access denied: no role found", nil)
 			return
 		}
 
 		roleStr, ok := role.(string)
 		if !ok {
-			interceptor.Abort(c, http.StatusForbidden, "access denied: invalid role", nil)
+			interceptor.AbortWithCode(c, http.StatusForbidden, domain.CodeRoleNotAllowed, "access denied: invalid role", nil)
 			return
 		}
 
 		if _, allowed := roleSet[roleStr]; !allowed {
-			interceptor.Abort(c, http.StatusForbidden, "access denied: insufficient permissions", nil)
+			interceptor.AbortWithCode(c, http.StatusForbidden, domain.CodeRoleNotAllowed, "access denied: insufficient permissions", nil)
 			return
 		}
 
@@ -0,0 +1,22 @@
+// internal/api/request/action_request.go
+package request
+
+// ProvisionRobotInput is the input of the provisionRobot action.
+type ProvisionRobotInput struct {
+	EnterpriseID       int64   `json:"enterprise_id" binding:"required,gt=0"`
+	SerialNumber       string  `json:"serial_number" binding:"required,max=100"`
+	FirmwareVersion    *string `json:"firmware_version" binding:"omitempty,max=50"`
+	AssignedResidentID *int64  `json:"assigned_resident_id" binding:"omitempty,gt=0"`
+}
+
+// ResolveIncidentInput is the input of the resolveIncident action.
+type ResolveIncidentInput struct {
+	IncidentID int64  `json:"incident_id" binding:"required,gt=0"`
+	Resolution string `json:"resolution" binding:"required,max=2000"`
+}
+
+// AssignCaregiverInput is the input of the assignCaregiver action.
+type AssignCaregiverInput struct {
+	CaregiverID int64 `json:"caregiver_id" binding:"required,gt=0"`
+	ResidentID  int64 `json:"resident_id" binding:"required,gt=0"`
+}
@@ -0,0 +1,36 @@
+// internal/api/request/binding.go
+package request
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+	"my-application/internal/validator"
+	pkgvalidator "my-application/pkg/validator"
+)
+
+// Binding tags are checked by the application's validator rather than gin's
+// default, so request types can use its rules and failed fields are named
+// by their JSON path.
+func init() {
+	binding.Validator = validator.Default()
+}
+
+// BindingError converts an error from binding a request body into a domain
+// error. Failed binding tags become a validation error with a translatable
+// violation per JSON field; malformed bodies become an invalid input error
+// that doesn't echo the decoder's message.
+func BindingError(err error) error {
+	if _, ok := pkgvalidator.Fields(err); ok {
+		return validator.Error(err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return i18n.ValidationError(map[string]domain.Violation{typeErr.Field: {Rule: "type"}})
+	}
+	return domain.NewAppError(domain.ErrInvalidInput, "invalid request body")
+}
@@ -0,0 +1,12 @@
+// internal/api/request/invitation_request.go
+package request
+
+// CreateInvitationRequest is the JSON body for inviting a user to an
+// enterprise. The full name and locale are suggestions the invitee may
+// change when accepting.
+type CreateInvitationRequest struct {
+	Email    string  `json:"email" binding:"required,email,max=255"`
+	Role     string  `json:"role" binding:"required,oneof=eta caregiver family"`
+	FullName string  `json:"full_name" binding:"max=100"`
+	Locale   *string `json:"locale" binding:"omitempty,locale"`
+}
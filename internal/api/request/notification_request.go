@@ -0,0 +1,29 @@
+// internal/api/request/notification_request.go
+package request
+
+// UpdateNotificationSettingsRequest is the JSON body for replacing quiet
+// hours and channel preferences. Types not listed keep their current setting.
+type UpdateNotificationSettingsRequest struct {
+	QuietHours  *QuietHoursRequest              `json:"quiet_hours"`
+	Preferences []NotificationPreferenceRequest `json:"preferences"`
+}
+
+// QuietHoursRequest is a daily window in "HH:MM" local time.
+type QuietHoursRequest struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// NotificationPreferenceRequest sets the channels for one notification type.
+type NotificationPreferenceRequest struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Push  bool   `json:"push"`
+	Email bool   `json:"email"`
+}
+
+// RegisterPushDeviceRequest is the JSON body for registering a push token.
+type RegisterPushDeviceRequest struct {
+	Token    string `json:"token"`
+	Platform string `json:"platform"`
+}
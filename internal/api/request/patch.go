@@ -0,0 +1,66 @@
+// internal/api/request/patch.go
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+)
+
+// MIMEMergePatch is the media type of a JSON Merge Patch (RFC 7396).
+const MIMEMergePatch = "application/merge-patch+json"
+
+// BindMergePatch decodes a JSON Merge Patch into obj, a pointer to a struct
+// of pointer fields named by their "json" tags: a member of the patch sets
+// its field, an absent member leaves it nil. A null member removes the
+// value, which only fields tagged `patch:"nullable"` allow; they are set to
+// their zero value. Members that are not fields of obj get an "unknown"
+// violation, nulls on other fields a "required" one, and members of the
+// wrong type a "type" one. The patch's values are not validated further:
+// that is done on the patched resource.
+func BindMergePatch(body []byte, obj any) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return domain.NewAppError(domain.ErrInvalidInput, "request body must be a JSON object")
+	}
+
+	v := reflect.ValueOf(obj).Elem()
+	t := v.Type()
+	fields := make(map[string]int, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = i
+	}
+
+	violations := make(map[string]domain.Violation)
+	for name, raw := range members {
+		i, ok := fields[name]
+		if !ok {
+			violations[name] = domain.Violation{Rule: "unknown"}
+			continue
+		}
+		field := v.Field(i)
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if t.Field(i).Tag.Get("patch") != "nullable" {
+				violations[name] = domain.Violation{Rule: "required"}
+				continue
+			}
+			field.Set(reflect.New(field.Type().Elem()))
+			continue
+		}
+		value := reflect.New(field.Type().Elem())
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			violations[name] = domain.Violation{Rule: "type"}
+			continue
+		}
+		field.Set(value)
+	}
+	if len(violations) > 0 {
+		return i18n.ValidationError(violations)
+	}
+	return nil
+}
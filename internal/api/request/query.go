@@ -0,0 +1,80 @@
+// internal/api/request/query.go
+package request
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+	"my-application/internal/validator"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// BindQuery decodes query into obj, a pointer to a struct whose fields are
+// named by their "form" tags, and validates it with its binding tags.
+// Unlike gin's query binding it reports which parameter failed to parse:
+// each gets a "type" violation. Fields may be strings, bools, ints, RFC 3339
+// times, or pointers to those to tell an absent parameter from a zero one.
+func BindQuery(query url.Values, obj any) error {
+	v := reflect.ValueOf(obj).Elem()
+	t := v.Type()
+
+	violations := make(map[string]domain.Violation)
+	for i := range t.NumField() {
+		name := t.Field(i).Tag.Get("form")
+		raw, ok := query[name]
+		if name == "" || !ok || len(raw) == 0 || raw[0] == "" {
+			continue
+		}
+		if err := setQueryField(v.Field(i), raw[0]); err != nil {
+			violations[name] = domain.Violation{Rule: "type"}
+		}
+	}
+	if len(violations) > 0 {
+		return i18n.ValidationError(violations)
+	}
+	return validator.Error(validator.Default().Struct(obj))
+}
+
+func setQueryField(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setQueryField(elem.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if field.Type() == timeType {
+		ts, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(ts))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	default:
+		return strconv.ErrSyntax
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+// internal/api/request/retention_request.go
+package request
+
+// SetRetentionPolicyRequest is the JSON body for creating or replacing a retention policy.
+type SetRetentionPolicyRequest struct {
+	RetentionDays int    `json:"retention_days"`
+	Action        string `json:"action"`
+	IsActive      *bool  `json:"is_active"`
+}
+
+// SetLegalHoldRequest is the JSON body for toggling a resident's legal hold.
+type SetLegalHoldRequest struct {
+	LegalHold *bool `json:"legal_hold"`
+}
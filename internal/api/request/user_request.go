@@ -1,19 +1,66 @@
 // internal/api/request/user_request.go
 package request
 
+import "time"
+
 // CreateUserRequest is the JSON body for creating a user.
 type CreateUserRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	FullName string `json:"full_name"`
-	Role     string `json:"role"`
+	Username string  `json:"username" binding:"required,min=3,max=50"`
+	Email    string  `json:"email" binding:"required,email,max=255"`
+	FullName string  `json:"full_name" binding:"required,max=100"`
+	Role     string  `json:"role" binding:"omitempty,sona_role"`
+	Locale   *string `json:"locale" binding:"omitempty,locale"`
 }
 
-// UpdateUserRequest is the JSON body for updating a user.
+// UpdateUserRequest is the JSON body replacing a user's profile. An
+// omitted locale is cleared.
 type UpdateUserRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	FullName string `json:"full_name"`
-	Role     string `json:"role"`
-	IsActive *bool  `json:"is_active"`
+	Username string  `json:"username" binding:"required,min=3,max=50"`
+	Email    string  `json:"email" binding:"required,email,max=255"`
+	FullName string  `json:"full_name" binding:"required,max=100"`
+	Role     string  `json:"role" binding:"required,sona_role"`
+	Locale   *string `json:"locale" binding:"omitempty,locale"`
+	IsActive *bool   `json:"is_active" binding:"required"`
+}
+
+// PatchUserRequest is a JSON Merge Patch of a user's profile, decoded by
+// BindMergePatch. A null locale clears it.
+type PatchUserRequest struct {
+	Username *string `json:"username"`
+	Email    *string `json:"email"`
+	FullName *string `json:"full_name"`
+	Role     *string `json:"role"`
+	Locale   *string `json:"locale" patch:"nullable"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// ListUsersQuery is the query string of GET /api/v1/users, decoded by
+// BindQuery. The filter's rules are checked by the user service.
+type ListUsersQuery struct {
+	Role          string     `form:"role" json:"role"`
+	IsActive      *bool      `form:"is_active" json:"is_active"`
+	Deleted       bool       `form:"deleted" json:"deleted"`
+	EnterpriseID  *int64     `form:"enterprise_id" json:"enterprise_id"`
+	Search        string     `form:"search" json:"search"`
+	CreatedAfter  *time.Time `form:"created_after" json:"created_after"`
+	CreatedBefore *time.Time `form:"created_before" json:"created_before"`
+	Sort          string     `form:"sort" json:"sort"`
+	Cursor        string     `form:"cursor" json:"cursor"`
+	Limit         *int       `form:"limit" json:"limit" binding:"omitnil,min=1,max=100"`
+	IncludeTotal  bool       `form:"include_total" json:"include_total"`
+}
+
+// Media types of user import files.
+const (
+	MIMECSV       = "text/csv"
+	MIMEJSONLines = "application/x-ndjson"
+)
+
+// ImportUsersQuery is the query string of POST /api/v1/users/import,
+// decoded by BindQuery. Invite defaults to true.
+type ImportUsersQuery struct {
+	Mode         string `form:"mode" json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	DryRun       bool   `form:"dry_run" json:"dry_run"`
+	Invite       *bool  `form:"invite" json:"invite"`
+	EnterpriseID *int64 `form:"enterprise_id" json:"enterprise_id" binding:"omitnil,gt=0"`
 }
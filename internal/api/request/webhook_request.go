@@ -0,0 +1,18 @@
+// internal/api/request/webhook_request.go
+package request
+
+// CreateWebhookRequest is the JSON body for subscribing an endpoint to events.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+}
+
+// UpdateWebhookRequest is the JSON body for replacing a webhook subscription.
+// Setting is_active re-enables a subscription that was disabled by failures.
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+	IsActive    *bool    `json:"is_active"`
+}
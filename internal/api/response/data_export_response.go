@@ -0,0 +1,16 @@
+// internal/api/response/data_export_response.go
+package response
+
+import "time"
+
+// DataExportResponse is the JSON representation of a personal data export.
+type DataExportResponse struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Status      string     `json:"status"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
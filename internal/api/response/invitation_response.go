@@ -0,0 +1,11 @@
+// internal/api/response/invitation_response.go
+package response
+
+import "my-application/internal/domain"
+
+// InvitationResponse is an invitation with its status: pending, accepted,
+// revoked or expired.
+type InvitationResponse struct {
+	domain.Invitation
+	Status string `json:"status"`
+}
@@ -0,0 +1,13 @@
+// internal/api/response/notification_response.go
+package response
+
+import "my-application/internal/domain"
+
+// NotificationListResponse wraps a paginated page of the caller's inbox.
+type NotificationListResponse struct {
+	Notifications []domain.Notification `json:"notifications"`
+	Total         int64                 `json:"total"`
+	Unread        int64                 `json:"unread"`
+	Limit         int                   `json:"limit"`
+	Offset        int                   `json:"offset"`
+}
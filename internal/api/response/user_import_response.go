@@ -0,0 +1,32 @@
+// internal/api/response/user_import_response.go
+package response
+
+import "time"
+
+// UserImportResponse is the JSON representation of a user import. Imports
+// run within the request have no ID.
+type UserImportResponse struct {
+	ID           int64                   `json:"id,omitempty"`
+	Status       string                  `json:"status"`
+	Mode         string                  `json:"mode"`
+	DryRun       bool                    `json:"dry_run"`
+	Invite       bool                    `json:"invite"`
+	EnterpriseID *int64                  `json:"enterprise_id,omitempty"`
+	Valid        int                     `json:"valid"`
+	Created      int                     `json:"created"`
+	Failed       int                     `json:"failed"`
+	Skipped      int                     `json:"skipped"`
+	Results      []UserImportRowResponse `json:"results"`
+	Error        string                  `json:"error,omitempty"`
+	CompletedAt  *time.Time              `json:"completed_at,omitempty"`
+	CreatedAt    *time.Time              `json:"created_at,omitempty"`
+}
+
+// UserImportRowResponse is the outcome of one row of an import file.
+type UserImportRowResponse struct {
+	Line   int               `json:"line"`
+	Status string            `json:"status"`
+	UserID *int64            `json:"user_id,omitempty"`
+	Code   string            `json:"code,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
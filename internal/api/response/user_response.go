@@ -5,20 +5,24 @@ import "time"
 
 // UserResponse is the JSON representation of a single user.
 type UserResponse struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FullName  string    `json:"full_name"`
-	Role      string    `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	FullName  string     `json:"full_name"`
+	Role      string     `json:"role"`
+	Locale    *string    `json:"locale"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Version   int64      `json:"version"` // also sent as the ETag
 }
 
-// UserListResponse wraps a paginated list of users.
+// UserListResponse wraps a page of users. NextCursor is null on the last
+// page; Total is only present when requested.
 type UserListResponse struct {
-	Users  []UserResponse `json:"users"`
-	Total  int64          `json:"total"`
-	Limit  int            `json:"limit"`
-	Offset int            `json:"offset"`
+	Users      []UserResponse `json:"users"`
+	Limit      int            `json:"limit"`
+	NextCursor *string        `json:"next_cursor"`
+	Total      *int64         `json:"total,omitempty"`
 }
@@ -0,0 +1,19 @@
+// internal/api/response/webhook_response.go
+package response
+
+import "my-application/internal/domain"
+
+// WebhookCreatedResponse is a new subscription with its signing secret,
+// which is not shown again.
+type WebhookCreatedResponse struct {
+	domain.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryListResponse wraps a paginated page of a delivery log.
+type WebhookDeliveryListResponse struct {
+	Deliveries []domain.WebhookDelivery `json:"deliveries"`
+	Total      int64                    `json:"total"`
+	Limit      int                      `json:"limit"`
+	Offset     int                      `json:"offset"`
+}
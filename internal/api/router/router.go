@@ -4,21 +4,37 @@ package router
 import (
 	"log/slog"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
 
 	"my-application/internal/api/handler"
 	"my-application/internal/api/interceptor"
 	"my-application/internal/api/middleware"
 	"my-application/internal/auth"
+	"my-application/internal/idempotency"
 )
 
 // Config holds middleware configuration needed by the router.
 type Config struct {
-	CORSConfig        middleware.CORSConfig
-	RateLimitRPS      float64
-	RateLimitBurst    int
-	GinMode           string
-	InternalAPISecret string
+	CORSConfig         middleware.CORSConfig
+	RateLimitRPS       float64
+	RateLimitBurst     int
+	GinMode            string
+	InternalAPISecret  string
+	HasuraEventSecret  string
+	HasuraActionSecret string
+	// ValidateRequests checks requests against the OpenAPI document (see
+	// middleware.ValidateRequest) before they reach the handlers.
+	ValidateRequests bool
+	// OpenAPI is the document used by ValidateRequests.
+	OpenAPI *openapi3.T
+	// DefaultLocale is the locale of response messages when the request
+	// doesn't pick one (see middleware.Locale).
+	DefaultLocale string
+	// IdempotencyStore keeps responses to requests sent with an
+	// Idempotency-Key (see middleware.Idempotency); nil ignores the header.
+	IdempotencyStore  idempotency.Store
+	IdempotencyConfig middleware.IdempotencyConfig
 }
 
 // New creates and configures the Gin engine with all middleware and routes.
@@ -43,14 +59,29 @@ func New(
 	// Global middleware chain (outermost → innermost).
 	r.Use(middleware.Recovery(logger))
 	r.Use(middleware.RequestID())
+	r.Use(middleware.Locale(cfg.DefaultLocale))
 	r.Use(middleware.Logging(logger))
 	r.Use(middleware.CORS(cfg.CORSConfig))
 	r.Use(middleware.RateLimit(cfg.RateLimitRPS, cfg.RateLimitBurst, logger))
+	if cfg.ValidateRequests {
+		r.Use(middleware.ValidateRequest(cfg.OpenAPI, logger))
+	}
+
+	// Creating routes that clients retry over flaky networks honor
+	// Idempotency-Key.
+	idempotent := func(c *gin.Context) { c.Next() }
+	if cfg.IdempotencyStore != nil {
+		idempotent = middleware.Idempotency(cfg.IdempotencyStore, cfg.IdempotencyConfig, logger)
+	}
 
 	// Public routes (no auth required).
 	r.GET("/health", h.Health.HealthCheck)
 	r.GET("/ping", h.Health.Ping)
 
+	// API reference: the OpenAPI document and a page rendering it.
+	r.GET("/openapi.json", h.Docs.Spec)
+	r.GET("/docs", h.Docs.UI)
+
 	// API v1 routes.
 	v1 := r.Group("/api/v1")
 	{
@@ -58,9 +89,10 @@ func New(
 		authGroup := v1.Group("/auth")
 		{
 			authGroup.POST("/login", authHandler.Login)
-			authGroup.POST("/register", authHandler.Register)
+			authGroup.POST("/register", idempotent, authHandler.Register)
 			authGroup.POST("/refresh", authHandler.Refresh)
 			authGroup.POST("/firebase-login", authHandler.FirebaseLogin)
+			authGroup.POST("/invitations/accept", authHandler.AcceptInvitation)
 
 			// sync-user is called by Firebase Cloud Function (server-to-server).
 			syncUser := authGroup.Group("")
@@ -78,28 +110,90 @@ func New(
 			{
 				// mta and eta can list and create users.
 				users.GET("", middleware.RequireRole("mta", "eta"), h.User.List)
-				users.POST("", middleware.RequireRole("mta", "eta"), h.User.Create)
+				users.POST("", middleware.RequireRole("mta", "eta"), idempotent, h.User.Create)
+
+				// mta and eta can import users in bulk.
+				users.POST("/import", middleware.RequireRole("mta", "eta"), idempotent, h.UserImport.Import)
+				users.GET("/imports/:id", middleware.RequireRole("mta", "eta"), h.UserImport.GetByID)
 
 				// All authenticated roles can view a user by ID.
 				users.GET("/:id", middleware.RequireRole("mta", "eta", "caregiver", "family"), h.User.GetByID)
 
 				// mta and eta can update users.
 				users.PUT("/:id", middleware.RequireRole("mta", "eta"), h.User.Update)
+				users.PATCH("/:id", middleware.RequireRole("mta", "eta"), h.User.Patch)
 
-				// Only mta can delete users.
+				// Only mta can delete, restore and purge users.
 				users.DELETE("/:id", middleware.RequireRole("mta"), h.User.Delete)
+				users.POST("/:id/restore", middleware.RequireRole("mta"), h.User.Restore)
+				users.POST("/:id/purge", middleware.RequireRole("mta"), h.User.Purge)
+
+				// Personal data exports: self-service for everyone, on behalf of others for admins.
+				users.POST("/me/export", h.DataExport.RequestOwn)
+				users.POST("/:id/export", middleware.RequireRole("mta", "eta"), h.DataExport.RequestForUser)
+			}
+
+			protected.GET("/exports/:id", h.DataExport.GetByID)
+
+			// Data retention: mta for any enterprise, eta for their own.
+			retention := protected.Group("/enterprises/:id/retention-policies")
+			retention.Use(middleware.RequireRole("mta", "eta"))
+			{
+				retention.GET("", h.Retention.ListPolicies)
+				retention.PUT("/:table", h.Retention.SetPolicy)
+				retention.DELETE("/:table", h.Retention.DeletePolicy)
+			}
+			// Outbound webhooks: mta for any enterprise, eta for their own.
+			webhooks := protected.Group("/enterprises/:id/webhooks")
+			webhooks.Use(middleware.RequireRole("mta", "eta"))
+			{
+				webhooks.GET("", h.Webhook.List)
+				webhooks.POST("", h.Webhook.Create)
+				webhooks.GET("/:webhook_id", h.Webhook.GetByID)
+				webhooks.PUT("/:webhook_id", h.Webhook.Update)
+				webhooks.DELETE("/:webhook_id", h.Webhook.Delete)
+				webhooks.GET("/:webhook_id/deliveries", h.Webhook.ListDeliveries)
+				webhooks.POST("/:webhook_id/test", h.Webhook.SendTest)
 			}
+			protected.PUT("/residents/:id/legal-hold", middleware.RequireRole("mta", "eta"), h.Retention.SetLegalHold)
+
+			// Invitations: mta for any enterprise, eta for their own. Invitees
+			// accept at /auth/invitations/accept.
+			invitations := protected.Group("/enterprises/:id/invitations")
+			invitations.Use(middleware.RequireRole("mta", "eta"))
+			{
+				invitations.GET("", h.Invitation.List)
+				invitations.POST("", idempotent, h.Invitation.Create)
+				invitations.POST("/:invitation_id/resend", h.Invitation.Resend)
+				invitations.DELETE("/:invitation_id", h.Invitation.Revoke)
+			}
+
+			// Notifications: every route acts on the caller's own inbox and settings.
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", h.Notification.List)
+				notifications.POST("/read-all", h.Notification.MarkAllRead)
+				notifications.POST("/:id/read", h.Notification.MarkRead)
+				notifications.GET("/preferences", h.Notification.GetSettings)
+				notifications.PUT("/preferences", h.Notification.UpdateSettings)
+				notifications.POST("/devices", h.Notification.RegisterDevice)
+				notifications.DELETE("/devices/:token", h.Notification.UnregisterDevice)
+			}
+
+			// Live dashboard events (Server-Sent Events), filtered by role and enterprise.
+			protected.GET("/events/stream", middleware.RequireRole("mta", "eta", "caregiver"), h.EventStream.Stream)
 		}
 	}
 
-	// Hasura Actions webhook endpoints (called by Hasura, not clients directly).
-	actions := v1.Group("/actions")
-	{
-		actions.POST("/login", actionsHandler.Login)
-		actions.POST("/register", actionsHandler.Register)
-		actions.POST("/refresh", actionsHandler.Refresh)
-		actions.POST("/sync-user", actionsHandler.SyncUser)
-	}
+	// Export downloads are authorized by the expiring token in the link, not a JWT.
+	v1.GET("/exports/:id/download", h.DataExport.Download)
+
+	// Hasura Actions (called by Hasura, not clients directly), dispatched on
+	// the action name.
+	v1.POST("/actions", middleware.HasuraActionAuth(cfg.HasuraActionSecret, logger), actionsHandler.Dispatch)
+
+	// Hasura event triggers for row changes made through GraphQL.
+	v1.POST("/events/hasura", middleware.HasuraEventAuth(cfg.HasuraEventSecret, logger), h.HasuraEvent.Receive)
 
 	return r
 }
@@ -0,0 +1,89 @@
+// internal/api/rpc/auth_server.go
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"my-application/api/proto/sonav1"
+	"my-application/internal/auth"
+	"my-application/internal/validator"
+)
+
+// authServer implements sonav1.AuthServiceServer on top of auth.Service.
+type authServer struct {
+	sonav1.UnimplementedAuthServiceServer
+	authService auth.Service
+}
+
+// Login checks the binding tags of auth.LoginRequest, as the REST handler
+// does.
+func (s *authServer) Login(ctx context.Context, req *sonav1.LoginRequest) (*sonav1.AuthResponse, error) {
+	in := auth.LoginRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+	if err := validator.Struct(in); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.authService.Login(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return toAuthResponse(resp), nil
+}
+
+// Register checks the binding tags of auth.RegisterRequest, as the REST
+// handler does.
+func (s *authServer) Register(ctx context.Context, req *sonav1.RegisterRequest) (*sonav1.AuthResponse, error) {
+	in := auth.RegisterRequest{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		FullName: req.GetFullName(),
+	}
+	if err := validator.Struct(in); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.authService.Register(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return toAuthResponse(resp), nil
+}
+
+func (s *authServer) RefreshToken(ctx context.Context, req *sonav1.RefreshTokenRequest) (*sonav1.TokenPair, error) {
+	in := auth.RefreshRequest{RefreshToken: req.GetRefreshToken()}
+	if err := validator.Struct(in); err != nil {
+		return nil, err
+	}
+	tokens, err := s.authService.RefreshToken(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return toTokenPair(tokens), nil
+}
+
+func toAuthResponse(resp *auth.AuthResponse) *sonav1.AuthResponse {
+	return &sonav1.AuthResponse{
+		User: &sonav1.UserInfo{
+			Id:       resp.User.ID,
+			Username: resp.User.Username,
+			Email:    resp.User.Email,
+			FullName: resp.User.FullName,
+			Role:     resp.User.Role,
+		},
+		Tokens: toTokenPair(&resp.Tokens),
+	}
+}
+
+func toTokenPair(tokens *auth.TokenPair) *sonav1.TokenPair {
+	return &sonav1.TokenPair{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    timestamppb.New(tokens.ExpiresAt),
+	}
+}
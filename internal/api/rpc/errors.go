@@ -0,0 +1,82 @@
+// internal/api/rpc/errors.go
+package rpc
+
+import (
+	"errors"
+	"sort"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"my-application/internal/domain"
+)
+
+// CodeFromError maps domain errors to gRPC status codes, as
+// domain.HTTPStatusFromError maps them to HTTP statuses.
+func CodeFromError(err error) codes.Code {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return codes.AlreadyExists
+	case errors.Is(err, domain.ErrInvalidInput):
+		return codes.InvalidArgument
+	case errors.Is(err, domain.ErrUnauthorized):
+		return codes.Unauthenticated
+	case errors.Is(err, domain.ErrForbidden):
+		return codes.PermissionDenied
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return codes.Aborted
+	default:
+		return codes.Internal
+	}
+}
+
+// errorInfoDomain is the google.rpc.ErrorInfo domain of every error code.
+const errorInfoDomain = "sona.v1"
+
+// statusFromError returns err as a gRPC status error carrying its
+// domain.ErrorCode as the reason of a google.rpc.ErrorInfo. Validation
+// details become a google.rpc.BadRequest with one violation per field.
+// Internal and database failures are reported without their message, which
+// may reveal implementation details to clients.
+func statusFromError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	info := &errdetails.ErrorInfo{Reason: string(domain.ErrorCodeOf(err)), Domain: errorInfoDomain}
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || errors.Is(err, domain.ErrDatabaseOperation) || errors.Is(err, domain.ErrInternal) {
+		info.Reason = string(domain.CodeInternal)
+		return withDetails(status.New(codes.Internal, "internal server error"), info).Err()
+	}
+
+	st := withDetails(status.New(CodeFromError(appErr.Err), appErr.Message), info)
+	if len(appErr.Details) == 0 {
+		return st.Err()
+	}
+	fields := make([]string, 0, len(appErr.Details))
+	for field := range appErr.Details {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	br := &errdetails.BadRequest{}
+	for _, field := range fields {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: appErr.Details[field],
+		})
+	}
+	return withDetails(st, br).Err()
+}
+
+// withDetails returns st with detail attached, or st unchanged if detail
+// cannot be marshaled.
+func withDetails(st *status.Status, detail protoadapt.MessageV1) *status.Status {
+	if with, err := st.WithDetails(detail); err == nil {
+		return with
+	}
+	return st
+}
@@ -0,0 +1,69 @@
+// internal/api/rpc/identity.go
+package rpc
+
+import (
+	"context"
+
+	"my-application/internal/domain"
+	"my-application/pkg/database"
+)
+
+// Identity is the authenticated caller of a method, from their access token.
+type Identity struct {
+	UserID       int64
+	Role         string
+	EnterpriseID int64 // 0 when the user has no enterprise
+	RobotID      int64 // set for robot tokens
+}
+
+type identityKey struct{}
+
+// withIdentity stores id in ctx and scopes the call's database work to the
+// caller for row-level security, as middleware.SetIdentity does for HTTP.
+func withIdentity(ctx context.Context, id Identity) context.Context {
+	ctx = context.WithValue(ctx, identityKey{}, id)
+	return database.WithTenant(ctx, database.Tenant{
+		UserID:       id.UserID,
+		EnterpriseID: id.EnterpriseID,
+		Role:         id.Role,
+		Bypass:       id.Role == "mta",
+	})
+}
+
+// IdentityFromContext returns the caller set by the Auth interceptor; the
+// zero Identity for public methods.
+func IdentityFromContext(ctx context.Context) Identity {
+	id, _ := ctx.Value(identityKey{}).(Identity) //nolint:errcheck // public methods have no identity
+	return id
+}
+
+// enterpriseScope returns the enterprise the caller is confined to, or nil
+// for mta which operates across all enterprises.
+func (id Identity) enterpriseScope() *int64 {
+	if id.Role == "mta" {
+		return nil
+	}
+	eid := id.EnterpriseID
+	return &eid
+}
+
+// canAccessEnterprise reports whether the caller may act on enterpriseID.
+func (id Identity) canAccessEnterprise(enterpriseID int64) bool {
+	scope := id.enterpriseScope()
+	return scope == nil || *scope == enterpriseID
+}
+
+// checkRoleChange refuses to give the user with userID role when the caller
+// may not assign it, or when it would change the caller's own role. userID
+// is 0 for a new user.
+func (id Identity) checkRoleChange(userID int64, role string) error {
+	if userID != 0 && userID == id.UserID && role != id.Role {
+		return domain.NewAppError(domain.ErrForbidden, "users may not change their own role").
+			WithCode(domain.CodeRoleNotAllowed)
+	}
+	if !domain.CanAssignRole(id.Role, role) {
+		return domain.NewAppError(domain.ErrForbidden, "only mta may assign the "+role+" role").
+			WithCode(domain.CodeRoleNotAllowed)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// internal/api/rpc/interceptors.go
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/pkg/logger"
+)
+
+// requestIDHeader carries the request ID in both directions, as
+// X-Request-ID does over HTTP.
+const requestIDHeader = "x-request-id"
+
+// Recovery turns a panic in a handler into an Internal error and logs it
+// with its stack trace.
+func Recovery(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logPanic(log, info.FullMethod, rec)
+				err = statusFromError(domain.NewAppError(domain.ErrInternal, "panic"))
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery is Recovery for streaming methods.
+func StreamRecovery(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logPanic(log, info.FullMethod, rec)
+				err = statusFromError(domain.NewAppError(domain.ErrInternal, "panic"))
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func logPanic(log *slog.Logger, method string, rec any) {
+	log.Error("panic recovered",
+		slog.String("panic", fmt.Sprintf("%v", rec)),
+		slog.String("stack", string(debug.Stack())),
+		slog.String("method", method),
+	)
+}
+
+// RequestID takes the caller's x-request-id metadata, or generates one, and
+// returns it in the response header.
+func RequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := firstMetadata(ctx, requestIDHeader)
+		if id == "" {
+			id = uuid.New().String()
+		}
+		//nolint:errcheck // only fails once headers were sent, which cannot have happened yet
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+		return handler(context.WithValue(ctx, requestIDKey{}, id), req)
+	}
+}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID set by RequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string) //nolint:errcheck // missing ID yields ""
+	return id
+}
+
+// Logging puts a request-scoped logger in the context and logs each call
+// with its status code and duration.
+func Logging(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		remote := ""
+		if p, ok := peer.FromContext(ctx); ok {
+			remote = p.Addr.String()
+		}
+		reqLogger := log.With(
+			slog.String("request_id", RequestIDFromContext(ctx)),
+			slog.String("method", info.FullMethod),
+			slog.String("remote_addr", remote),
+		)
+
+		resp, err := handler(logger.WithContext(ctx, reqLogger), req)
+
+		reqLogger.Info("rpc completed",
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("duration", time.Since(start)),
+		)
+		return resp, err
+	}
+}
+
+// Auth validates the access token in the authorization metadata, checks
+// the caller's role against methodRoles and puts their identity in the
+// context. Public methods pass through.
+func Auth(jwtManager *auth.JWTManager, log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, info.FullMethod, jwtManager, log)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth is Auth for streaming methods.
+func StreamAuth(jwtManager *auth.JWTManager, log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, err := authenticate(ss.Context(), info.FullMethod, jwtManager, log); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authError is the status of a call rejected for its credentials.
+func authError(code domain.ErrorCode, message string) error {
+	return statusFromError(domain.NewAppError(domain.ErrUnauthorized, message).WithCode(code))
+}
+
+func authenticate(ctx context.Context, method string, jwtManager *auth.JWTManager, log *slog.Logger) (context.Context, error) {
+	if publicMethods[method] {
+		return ctx, nil
+	}
+
+	header := firstMetadata(ctx, "authorization")
+	if header == "" {
+		return nil, authError(domain.CodeAuthTokenMissing, "missing authorization metadata")
+	}
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return nil, authError(domain.CodeAuthTokenInvalid, "invalid authorization format")
+	}
+
+	claims, err := jwtManager.ValidateToken(token)
+	if err != nil {
+		log.Debug("token validation failed", slog.String("error", err.Error()))
+		return nil, authError(domain.CodeAuthTokenInvalid, "invalid or expired token")
+	}
+	if claims.Type != auth.AccessToken {
+		return nil, authError(domain.CodeAuthTokenInvalid, "invalid token type")
+	}
+
+	roles, known := methodRoles[method]
+	if !known || !slices.Contains(roles, claims.Role) {
+		return nil, statusFromError(domain.NewAppError(domain.ErrForbidden, "insufficient permissions").
+			WithCode(domain.CodeRoleNotAllowed))
+	}
+
+	return withIdentity(ctx, Identity{
+		UserID:       claims.UserID,
+		Role:         claims.Role,
+		EnterpriseID: claims.EnterpriseID,
+		RobotID:      claims.RobotID,
+	}), nil
+}
+
+// Errors converts the errors handlers return into gRPC statuses; see
+// statusFromError.
+func Errors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			logger.FromContext(ctx).Warn("rpc failed", slog.String("error", err.Error()))
+			return nil, statusFromError(err)
+		}
+		return resp, nil
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
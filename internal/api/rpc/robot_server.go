@@ -0,0 +1,66 @@
+// internal/api/rpc/robot_server.go
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"my-application/api/proto/sonav1"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+)
+
+// robotServer implements sonav1.RobotServiceServer.
+type robotServer struct {
+	sonav1.UnimplementedRobotServiceServer
+	robotService service.RobotService
+}
+
+func (s *robotServer) ProvisionRobot(ctx context.Context, req *sonav1.ProvisionRobotRequest) (*sonav1.Robot, error) {
+	id := IdentityFromContext(ctx)
+	if !id.canAccessEnterprise(req.GetEnterpriseId()) {
+		return nil, domain.NewAppError(domain.ErrForbidden, "access denied: enterprise out of scope").
+			WithCode(domain.CodeEnterpriseOutOfScope)
+	}
+	robot := &domain.Robot{
+		EnterpriseID:       req.GetEnterpriseId(),
+		SerialNumber:       req.GetSerialNumber(),
+		FirmwareVersion:    req.FirmwareVersion,
+		AssignedResidentID: req.AssignedResidentId,
+	}
+	if err := s.robotService.ProvisionRobot(ctx, robot, id.UserID); err != nil {
+		return nil, err
+	}
+	return toRobot(robot), nil
+}
+
+// Heartbeat records the heartbeat of the robot the token was issued to.
+func (s *robotServer) Heartbeat(ctx context.Context, req *sonav1.HeartbeatRequest) (*sonav1.Robot, error) {
+	id := IdentityFromContext(ctx)
+	if id.RobotID == 0 {
+		return nil, domain.NewAppError(domain.ErrForbidden, "token is not bound to a robot")
+	}
+	robot, err := s.robotService.RecordHeartbeat(ctx, id.RobotID, req.GetStatus(), req.FirmwareVersion)
+	if err != nil {
+		return nil, err
+	}
+	return toRobot(robot), nil
+}
+
+func toRobot(r *domain.Robot) *sonav1.Robot {
+	out := &sonav1.Robot{
+		Id:                 r.ID,
+		SerialNumber:       r.SerialNumber,
+		EnterpriseId:       r.EnterpriseID,
+		AssignedResidentId: r.AssignedResidentID,
+		Status:             r.Status,
+		FirmwareVersion:    r.FirmwareVersion,
+		CreatedAt:          timestamppb.New(r.CreatedAt),
+		UpdatedAt:          timestamppb.New(r.UpdatedAt),
+	}
+	if r.LastHeartbeat != nil {
+		out.LastHeartbeat = timestamppb.New(*r.LastHeartbeat)
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+// internal/api/rpc/server.go
+
+// Package rpc serves the gRPC API defined in api/proto/service.proto.
+//
+// It is the binary counterpart of the REST handlers and calls the same
+// service layer. The interceptors mirror the Gin middleware: panics are
+// recovered, every call gets a request ID and a log line, access tokens are
+// validated by auth.JWTManager with the same role rules as the router, and
+// domain errors are mapped to gRPC status codes as respondError maps them to
+// HTTP statuses.
+package rpc
+
+import (
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"my-application/api/proto/sonav1"
+	"my-application/internal/auth"
+	"my-application/internal/service"
+)
+
+// Services are the service-layer dependencies of the gRPC API.
+type Services struct {
+	Auth  auth.Service
+	User  service.UserService
+	Robot service.RobotService
+}
+
+// methodRoles lists the roles allowed to call each authenticated method;
+// methods missing here (and from publicMethods) are refused.
+var methodRoles = map[string][]string{
+	sonav1.UserService_GetUser_FullMethodName:         {"mta", "eta", "caregiver", "family"},
+	sonav1.UserService_ListUsers_FullMethodName:       {"mta", "eta"},
+	sonav1.UserService_CreateUser_FullMethodName:      {"mta", "eta"},
+	sonav1.UserService_UpdateUser_FullMethodName:      {"mta", "eta"},
+	sonav1.UserService_DeleteUser_FullMethodName:      {"mta"},
+	sonav1.RobotService_ProvisionRobot_FullMethodName: {"mta", "eta"},
+	sonav1.RobotService_Heartbeat_FullMethodName:      {"robot"},
+}
+
+// publicMethods need no access token.
+var publicMethods = map[string]bool{
+	sonav1.AuthService_Login_FullMethodName:        true,
+	sonav1.AuthService_Register_FullMethodName:     true,
+	sonav1.AuthService_RefreshToken_FullMethodName: true,
+	healthpb.Health_Check_FullMethodName:           true,
+	healthpb.Health_Watch_FullMethodName:           true,
+}
+
+// NewServer creates the gRPC server with every service and the standard
+// health service registered.
+func NewServer(svcs Services, jwtManager *auth.JWTManager, logger *slog.Logger) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			Recovery(logger),
+			RequestID(),
+			Logging(logger),
+			Auth(jwtManager, logger),
+			Errors(),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamRecovery(logger),
+			StreamAuth(jwtManager, logger),
+		),
+	)
+
+	sonav1.RegisterAuthServiceServer(srv, &authServer{authService: svcs.Auth})
+	sonav1.RegisterUserServiceServer(srv, &userServer{userService: svcs.User})
+	sonav1.RegisterRobotServiceServer(srv, &robotServer{robotService: svcs.Robot})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
@@ -0,0 +1,139 @@
+// internal/api/rpc/user_server.go
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"my-application/api/proto/sonav1"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+)
+
+// userServer implements sonav1.UserServiceServer with the behaviour of the
+// /api/v1/users handlers.
+type userServer struct {
+	sonav1.UnimplementedUserServiceServer
+	userService service.UserService
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *sonav1.GetUserRequest) (*sonav1.User, error) {
+	if req.GetId() <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID")
+	}
+	user, err := s.userService.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *sonav1.ListUsersRequest) (*sonav1.ListUsersResponse, error) {
+	if req.GetDeleted() && IdentityFromContext(ctx).Role != "mta" {
+		return nil, domain.NewAppError(domain.ErrForbidden, "only mta may list deleted users").
+			WithCode(domain.CodeRoleNotAllowed)
+	}
+	filter := domain.UserFilter{
+		Role:     req.GetRole(),
+		IsActive: req.IsActive,
+		Deleted:  req.GetDeleted(),
+		Limit:    int(req.GetLimit()),
+		Offset:   int(req.GetOffset()),
+		// The gRPC API predates cursors and always reports the total.
+		WithTotal: true,
+	}
+	page, err := s.userService.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sonav1.ListUsersResponse{
+		Users:  make([]*sonav1.User, len(page.Users)),
+		Total:  *page.Total,
+		Limit:  req.GetLimit(),
+		Offset: req.GetOffset(),
+	}
+	for i := range page.Users {
+		resp.Users[i] = toUser(&page.Users[i])
+	}
+	return resp, nil
+}
+
+func (s *userServer) CreateUser(ctx context.Context, req *sonav1.CreateUserRequest) (*sonav1.User, error) {
+	user := &domain.User{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		FullName: req.GetFullName(),
+		Role:     req.GetRole(),
+	}
+	caller := IdentityFromContext(ctx)
+	if err := caller.checkRoleChange(0, user.Role); err != nil {
+		return nil, err
+	}
+	// eta can only create users in their own enterprise.
+	if scope := caller.enterpriseScope(); scope != nil && *scope != 0 {
+		user.EnterpriseID = scope
+	}
+
+	if err := s.userService.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+// UpdateUser sets the user's username, email and full name, and its role
+// and is_active when given. The message has no locale, so it is left as it is.
+func (s *userServer) UpdateUser(ctx context.Context, req *sonav1.UpdateUserRequest) (*sonav1.User, error) {
+	if req.GetId() <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID")
+	}
+	username, email, fullName := req.GetUsername(), req.GetEmail(), req.GetFullName()
+	patch := domain.UserPatch{
+		Username: &username,
+		Email:    &email,
+		FullName: &fullName,
+		IsActive: req.IsActive,
+	}
+	if role := req.GetRole(); role != "" {
+		if err := IdentityFromContext(ctx).checkRoleChange(req.GetId(), role); err != nil {
+			return nil, err
+		}
+		patch.Role = &role
+	}
+
+	user, err := s.userService.PatchUser(ctx, req.GetId(), patch, 0)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+func (s *userServer) DeleteUser(ctx context.Context, req *sonav1.DeleteUserRequest) (*emptypb.Empty, error) {
+	if req.GetId() <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "invalid user ID")
+	}
+	if err := s.userService.DeleteUser(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toUser(u *domain.User) *sonav1.User {
+	out := &sonav1.User{
+		Id:           u.ID,
+		Username:     u.Username,
+		Email:        u.Email,
+		FullName:     u.FullName,
+		Role:         u.Role,
+		EnterpriseId: u.EnterpriseID,
+		IsActive:     u.IsActive,
+		CreatedAt:    timestamppb.New(u.CreatedAt),
+		UpdatedAt:    timestamppb.New(u.UpdatedAt),
+	}
+	if u.DeletedAt != nil {
+		out.DeletedAt = timestamppb.New(*u.DeletedAt)
+	}
+	return out
+}
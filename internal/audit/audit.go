@@ -0,0 +1,22 @@
+// internal/audit/audit.go
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+)
+
+// Record writes event to the audit trail on behalf of a request. By then the
+// audited change has been made, so a failure is logged and never fails the
+// request.
+func Record(ctx context.Context, repo repository.AuditRepository, logger *slog.Logger, event *domain.AuditEvent) {
+	if err := repo.Record(ctx, event); err != nil {
+		logger.Error("failed to record audit event",
+			slog.String("action", event.Action),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"my-application/internal/api/interceptor"
+	"my-application/internal/api/request"
 	"my-application/internal/domain"
+	"my-application/internal/i18n"
 	"my-application/pkg/logger"
 )
 
@@ -30,7 +32,7 @@ func (h *Handler) Login(c *gin.Context) {
 
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		interceptor.Fail(c, http.StatusBadRequest, "invalid request: "+err.Error(), nil)
+		respondAuthError(c, request.BindingError(err))
 		return
 	}
 
@@ -50,7 +52,7 @@ func (h *Handler) Register(c *gin.Context) {
 
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		interceptor.Fail(c, http.StatusBadRequest, "invalid request: "+err.Error(), nil)
+		respondAuthError(c, request.BindingError(err))
 		return
 	}
 
@@ -70,7 +72,7 @@ func (h *Handler) Refresh(c *gin.Context) {
 
 	var req RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		interceptor.Fail(c, http.StatusBadRequest, "invalid request: "+err.Error(), nil)
+		respondAuthError(c, request.BindingError(err))
 		return
 	}
 
@@ -90,7 +92,7 @@ func (h *Handler) SyncUser(c *gin.Context) {
 
 	var req SyncUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		interceptor.Fail(c, http.StatusBadRequest, "invalid request: "+err.Error(), nil)
+		respondAuthError(c, request.BindingError(err))
 		return
 	}
 
@@ -113,7 +115,7 @@ func (h *Handler) FirebaseLogin(c *gin.Context) {
 
 	var req FirebaseLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		interceptor.Fail(c, http.StatusBadRequest, "invalid request: "+err.Error(), nil)
+		respondAuthError(c, request.BindingError(err))
 		return
 	}
 
@@ -127,12 +129,33 @@ func (h *Handler) FirebaseLogin(c *gin.Context) {
 	interceptor.Success(c, http.StatusOK, resp)
 }
 
+// AcceptInvitation handles POST /api/v1/auth/invitations/accept
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context())
+
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondAuthError(c, request.BindingError(err))
+		return
+	}
+
+	resp, err := h.authService.AcceptInvitation(c.Request.Context(), req)
+	if err != nil {
+		log.Warn("invitation acceptance failed", slog.String("error", err.Error()))
+		respondAuthError(c, err)
+		return
+	}
+
+	interceptor.Success(c, http.StatusOK, resp)
+}
+
 // respondAuthError maps domain errors to HTTP responses.
 func respondAuthError(c *gin.Context, err error) {
 	var appErr *domain.AppError
 	if errors.As(err, &appErr) {
 		status := domain.HTTPStatusFromError(appErr.Err)
-		interceptor.Fail(c, status, appErr.Message, appErr.Details)
+		interceptor.FailWithCode(c, status, domain.ErrorCodeOf(err), appErr.Message,
+			i18n.Details(appErr, interceptor.Locale(c)))
 		return
 	}
 	interceptor.Fail(c, http.StatusInternalServerError, "internal server error", nil)
@@ -24,6 +24,7 @@ type Claims struct {
 	Role         string    `json:"role"`
 	EnterpriseID int64     `json:"enterprise_id,omitempty"`
 	RobotID      int64     `json:"robot_id,omitempty"`
+	Locale       string    `json:"locale,omitempty"` // the user's preferred locale, if set
 	Type         TokenType `json:"type"`
 }
 
@@ -33,6 +34,7 @@ type TokenInput struct {
 	Role         string
 	EnterpriseID *int64
 	RobotID      *int64
+	Locale       *string
 }
 
 // JWTConfig holds the settings needed by JWT operations.
@@ -74,6 +76,9 @@ func (m *JWTManager) GenerateAccessToken(input TokenInput) (string, error) {
 	if input.RobotID != nil {
 		claims.RobotID = *input.RobotID
 	}
+	if input.Locale != nil {
+		claims.Locale = *input.Locale
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(m.config.Secret))
@@ -31,3 +31,15 @@ type SyncUserRequest struct {
 type FirebaseLoginRequest struct {
 	IDToken string `json:"id_token" binding:"required"`
 }
+
+// AcceptInvitationRequest is the JSON body for POST
+// /api/v1/auth/invitations/accept. The invitee sets either a password or
+// links the Firebase account of id_token. Username and full name are only
+// used when the invitation creates the user.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"omitempty,min=3,max=50"`
+	FullName string `json:"full_name" binding:"omitempty,max=100"`
+	Password string `json:"password" binding:"omitempty,min=8,max=72"`
+	IDToken  string `json:"id_token"`
+}
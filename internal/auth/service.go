@@ -8,8 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"my-application/internal/audit"
 	"my-application/internal/domain"
+	"my-application/internal/i18n"
 	"my-application/internal/repository"
+	"my-application/internal/validator"
 )
 
 // FirebaseVerifier abstracts Firebase ID token verification.
@@ -31,6 +34,10 @@ type Service interface {
 	RefreshToken(ctx context.Context, req RefreshRequest) (*TokenPair, error)
 	SyncUser(ctx context.Context, req SyncUserRequest) (*AuthResponse, error)
 	FirebaseLogin(ctx context.Context, req FirebaseLoginRequest) (*AuthResponse, error)
+	// AcceptInvitation gives the invitee the credentials of req, creating
+	// the user unless the invitation is for an existing one, and signs
+	// them in.
+	AcceptInvitation(ctx context.Context, req AcceptInvitationRequest) (*AuthResponse, error)
 }
 
 // Compile-time interface check.
@@ -38,6 +45,10 @@ var _ Service = (*authService)(nil)
 
 type authService struct {
 	userRepo         repository.UserRepository
+	outboxRepo       repository.OutboxRepository
+	invitationRepo   repository.InvitationRepository
+	auditRepo        repository.AuditRepository
+	tx               repository.Transactor
 	jwtManager       *JWTManager
 	firebaseVerifier FirebaseVerifier
 	logger           *slog.Logger
@@ -47,12 +58,20 @@ type authService struct {
 // firebaseVerifier can be nil if Firebase is not configured.
 func NewService(
 	userRepo repository.UserRepository,
+	outboxRepo repository.OutboxRepository,
+	invitationRepo repository.InvitationRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
 	jwtManager *JWTManager,
 	firebaseVerifier FirebaseVerifier,
 	logger *slog.Logger,
 ) Service {
 	return &authService{
 		userRepo:         userRepo,
+		outboxRepo:       outboxRepo,
+		invitationRepo:   invitationRepo,
+		auditRepo:        auditRepo,
+		tx:               tx,
 		jwtManager:       jwtManager,
 		firebaseVerifier: firebaseVerifier,
 		logger:           logger,
@@ -66,19 +85,22 @@ func (s *authService) Login(ctx context.Context, req LoginRequest) (*AuthRespons
 		// Map "not found" to "unauthorized" so we don't leak whether emails exist.
 		var appErr *domain.AppError
 		if errors.As(err, &appErr) && errors.Is(appErr.Err, domain.ErrNotFound) {
-			return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid email or password")
+			return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid email or password").
+				WithCode(domain.CodeAuthInvalidCredentials)
 		}
 		return nil, err
 	}
 
 	// 2. Check if user is active.
 	if !user.IsActive {
-		return nil, domain.NewAppError(domain.ErrUnauthorized, "account is deactivated")
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "account is deactivated").
+			WithCode(domain.CodeAuthAccountDisabled)
 	}
 
 	// 3. Verify password.
 	if pwErr := CheckPassword(req.Password, user.PasswordHash); pwErr != nil {
-		return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid email or password")
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid email or password").
+			WithCode(domain.CodeAuthInvalidCredentials)
 	}
 
 	// 4. Generate tokens.
@@ -113,7 +135,7 @@ func (s *authService) Register(ctx context.Context, req RegisterRequest) (*AuthR
 	}
 
 	// 3. Persist (the repository handles unique constraint violations).
-	if createErr := s.userRepo.Create(ctx, user); createErr != nil {
+	if createErr := s.createUser(ctx, user); createErr != nil {
 		return nil, createErr
 	}
 
@@ -134,21 +156,25 @@ func (s *authService) RefreshToken(ctx context.Context, req RefreshRequest) (*To
 	// 1. Validate the refresh token.
 	claims, err := s.jwtManager.ValidateToken(req.RefreshToken)
 	if err != nil {
-		return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid or expired refresh token")
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid or expired refresh token").
+			WithCode(domain.CodeAuthRefreshTokenInvalid)
 	}
 
 	// 2. Ensure it is actually a refresh token.
 	if claims.Type != RefreshToken {
-		return nil, domain.NewAppError(domain.ErrUnauthorized, "token is not a refresh token")
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "token is not a refresh token").
+			WithCode(domain.CodeAuthRefreshTokenInvalid)
 	}
 
 	// 3. Verify the user still exists and is active.
 	user, err := s.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil {
-		return nil, domain.NewAppError(domain.ErrUnauthorized, "user not found")
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "user not found").
+			WithCode(domain.CodeAuthRefreshTokenInvalid)
 	}
 	if !user.IsActive {
-		return nil, domain.NewAppError(domain.ErrUnauthorized, "account is deactivated")
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "account is deactivated").
+			WithCode(domain.CodeAuthAccountDisabled)
 	}
 
 	// 4. Generate a new token pair.
@@ -173,7 +199,8 @@ func (s *authService) FirebaseLogin(ctx context.Context, req FirebaseLoginReques
 	fbUser, err := s.firebaseVerifier.VerifyIDToken(ctx, req.IDToken)
 	if err != nil {
 		s.logger.Warn("firebase token verification failed", slog.String("error", err.Error()))
-		return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid or expired firebase token")
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid or expired firebase token").
+			WithCode(domain.CodeAuthFirebaseTokenInvalid)
 	}
 
 	// 2. Find or create the local user.
@@ -187,7 +214,8 @@ func (s *authService) findOrCreateFirebaseUser(ctx context.Context, firebaseUID,
 	if err == nil {
 		// User exists — check active, generate tokens and return.
 		if !user.IsActive {
-			return nil, domain.NewAppError(domain.ErrUnauthorized, "account is deactivated")
+			return nil, domain.NewAppError(domain.ErrUnauthorized, "account is deactivated").
+				WithCode(domain.CodeAuthAccountDisabled)
 		}
 		tokens, tokenErr := s.generateTokenPair(user)
 		if tokenErr != nil {
@@ -218,12 +246,12 @@ func (s *authService) findOrCreateFirebaseUser(ctx context.Context, firebaseUID,
 		IsActive:    true,
 	}
 
-	if createErr := s.userRepo.Create(ctx, user); createErr != nil {
+	if createErr := s.createUser(ctx, user); createErr != nil {
 		// If username conflict, append part of firebase_uid to make it unique.
 		var appErr *domain.AppError
 		if errors.As(createErr, &appErr) && errors.Is(appErr.Err, domain.ErrAlreadyExists) {
 			user.Username = username + "_" + firebaseUID[:8]
-			if retryErr := s.userRepo.Create(ctx, user); retryErr != nil {
+			if retryErr := s.createUser(ctx, user); retryErr != nil {
 				return nil, retryErr
 			}
 		} else {
@@ -244,11 +272,139 @@ func (s *authService) findOrCreateFirebaseUser(ctx context.Context, firebaseUID,
 	}, nil
 }
 
+func (s *authService) AcceptInvitation(ctx context.Context, req AcceptInvitationRequest) (*AuthResponse, error) {
+	// 1. Exactly one of the credentials is set.
+	switch {
+	case req.Password == "" && req.IDToken == "":
+		return nil, i18n.ValidationError(map[string]domain.Violation{"password": {Rule: "required"}})
+	case req.Password != "" && req.IDToken != "":
+		return nil, i18n.ValidationError(map[string]domain.Violation{
+			"id_token": {Rule: "exclusive", Params: map[string]string{"other": "password"}},
+		})
+	}
+
+	// 2. Find the pending invitation. Every failure is reported alike so
+	// tokens cannot be probed.
+	invalid := domain.NewAppError(domain.ErrNotFound, "invitation link is invalid or has expired").
+		WithCode(domain.CodeInvitationInvalid)
+	inv, err := s.invitationRepo.GetByTokenHash(ctx, domain.InvitationTokenHash(req.Token))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status(time.Now()) != domain.InvitationPending {
+		return nil, invalid
+	}
+
+	// 3. Check the credentials before anything is written.
+	var hash string
+	var firebaseUID *string
+	if req.Password != "" {
+		if hash, err = HashPassword(req.Password); err != nil {
+			s.logger.Error("failed to hash password", slog.String("error", err.Error()))
+			return nil, domain.NewAppError(domain.ErrInternal, "failed to accept invitation")
+		}
+	} else {
+		if s.firebaseVerifier == nil {
+			return nil, domain.NewAppError(domain.ErrInternal, "firebase authentication is not configured")
+		}
+		fbUser, err := s.firebaseVerifier.VerifyIDToken(ctx, req.IDToken)
+		if err != nil {
+			s.logger.Warn("firebase token verification failed", slog.String("error", err.Error()))
+			return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid or expired firebase token").
+				WithCode(domain.CodeAuthFirebaseTokenInvalid)
+		}
+		if !strings.EqualFold(fbUser.Email, inv.Email) {
+			return nil, domain.NewAppError(domain.ErrForbidden, "firebase account email is not the invited address").
+				WithCode(domain.CodeInvitationEmailMismatch)
+		}
+		firebaseUID = &fbUser.UID
+	}
+
+	// 4. Give the existing user its credentials or create the user, and
+	// use up the invitation.
+	var user *domain.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if inv.UserID != nil {
+			if user, err = s.userRepo.GetByID(ctx, *inv.UserID); err != nil {
+				if errors.Is(err, domain.ErrNotFound) {
+					return invalid
+				}
+				return err
+			}
+			if err := s.userRepo.SetCredentials(ctx, user.ID, hash, firebaseUID); err != nil {
+				return err
+			}
+		} else {
+			user = &domain.User{
+				Username:     strings.TrimSpace(req.Username),
+				Email:        inv.Email,
+				PasswordHash: hash,
+				FullName:     strings.TrimSpace(req.FullName),
+				Role:         inv.Role,
+				EnterpriseID: inv.EnterpriseID,
+				FirebaseUID:  firebaseUID,
+				Locale:       inv.Locale,
+				IsActive:     true,
+			}
+			if user.FullName == "" {
+				user.FullName = inv.FullName
+			}
+			if err := validator.User(user); err != nil {
+				return err
+			}
+			if err := s.createUser(ctx, user); err != nil {
+				return err
+			}
+		}
+		return s.invitationRepo.Accept(ctx, inv, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	audit.Record(ctx, s.auditRepo, s.logger, inv.AuditEvent(&user.ID, "invitation.accepted"))
+
+	if !user.IsActive {
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "account is deactivated").
+			WithCode(domain.CodeAuthAccountDisabled)
+	}
+
+	// 5. Generate tokens.
+	tokens, err := s.generateTokenPair(user)
+	if err != nil {
+		s.logger.Error("failed to generate tokens", slog.String("error", err.Error()))
+		return nil, domain.NewAppError(domain.ErrInternal, "failed to generate tokens")
+	}
+
+	return &AuthResponse{
+		User:   toUserInfo(user),
+		Tokens: *tokens,
+	}, nil
+}
+
+// createUser inserts user and records user.created in one transaction.
+func (s *authService) createUser(ctx context.Context, user *domain.User) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		event, err := domain.NewUserEvent(domain.EventUserCreated, user)
+		if err != nil {
+			return err
+		}
+		return s.outboxRepo.Append(ctx, event)
+	})
+}
+
 func (s *authService) generateTokenPair(user *domain.User) (*TokenPair, error) {
 	input := TokenInput{
 		UserID:       user.ID,
 		Role:         user.Role,
 		EnterpriseID: user.EnterpriseID,
+		Locale:       user.Locale,
 	}
 
 	accessToken, err := s.jwtManager.GenerateAccessToken(input)
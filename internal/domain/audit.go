@@ -0,0 +1,16 @@
+// internal/domain/audit.go
+package domain
+
+import "time"
+
+// AuditEvent is a single entry in the audit trail.
+type AuditEvent struct {
+	ID           int64                  `json:"id"`
+	EnterpriseID *int64                 `json:"enterprise_id,omitempty"`
+	ActorID      *int64                 `json:"actor_id,omitempty"`
+	Action       string                 `json:"action"`
+	EntityType   string                 `json:"entity_type"`
+	EntityID     *int64                 `json:"entity_id,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
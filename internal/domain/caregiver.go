@@ -0,0 +1,15 @@
+// internal/domain/caregiver.go
+package domain
+
+import "time"
+
+// MaxResidentsPerCaregiver is the most residents one caregiver may be
+// assigned. The database enforces it as well (migration 000007).
+const MaxResidentsPerCaregiver = 5
+
+// CaregiverAssignment puts a resident in a caregiver's care.
+type CaregiverAssignment struct {
+	CaregiverID int64     `json:"caregiver_id"`
+	ResidentID  int64     `json:"resident_id"`
+	AssignedAt  time.Time `json:"assigned_at"`
+}
@@ -0,0 +1,102 @@
+// internal/domain/data_export.go
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Data export statuses.
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+	ExportStatusExpired    = "expired"
+)
+
+// DataExport tracks an asynchronous personal data export for a single user.
+type DataExport struct {
+	ID          int64  `json:"id"`
+	UserID      int64  `json:"user_id"`
+	RequestedBy *int64 `json:"requested_by,omitempty"`
+	Status      string `json:"status"`
+	FilePath    string `json:"-"`
+	// DownloadToken is only known when a download link is issued; the
+	// database keeps DownloadTokenHash.
+	DownloadToken     string     `json:"-"`
+	DownloadTokenHash []byte     `json:"-"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	Error             string     `json:"error,omitempty"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// RotateToken gives the export a new random download token, replacing any
+// previous one.
+func (e *DataExport) RotateToken() error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("generating download token: %w", err)
+	}
+	e.DownloadToken = hex.EncodeToString(b)
+	e.DownloadTokenHash = ExportTokenHash(e.DownloadToken)
+	return nil
+}
+
+// ExportTokenHash returns the hash a download token is stored and compared
+// by.
+func ExportTokenHash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// PersonalData is everything the platform holds about one user, as written
+// to the data.json file of an export archive.
+type PersonalData struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Profile     User             `json:"profile"`
+	Stories     []StoryRecord    `json:"stories"`
+	Incidents   []IncidentRecord `json:"incidents"`
+	AuditTrail  []AuditEvent     `json:"audit_trail"`
+	Sessions    []SessionRecord  `json:"sessions"`
+}
+
+// StoryRecord is a story authored by the exported user.
+type StoryRecord struct {
+	ID               int64     `json:"id"`
+	ResidentID       int64     `json:"resident_id"`
+	ContentEncrypted string    `json:"content_encrypted"`
+	MediaURL         *string   `json:"media_url,omitempty"`
+	MediaFile        string    `json:"media_file,omitempty"`
+	StoryType        string    `json:"story_type"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// IncidentRecord is an incident reported by the exported user.
+type IncidentRecord struct {
+	ID          int64      `json:"id"`
+	ResidentID  int64      `json:"resident_id"`
+	Severity    string     `json:"severity"`
+	Description string     `json:"description"`
+	Resolution  *string    `json:"resolution,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// SessionRecord is robot session metadata for a resident the exported user
+// is assigned to. Conversation summaries belong to the resident and are
+// never included.
+type SessionRecord struct {
+	ID          int64      `json:"id"`
+	RobotID     int64      `json:"robot_id"`
+	ResidentID  int64      `json:"resident_id"`
+	SessionType string     `json:"session_type"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+}
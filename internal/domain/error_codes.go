@@ -0,0 +1,181 @@
+// internal/domain/error_codes.go
+package domain
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable identifier of an error, sent to
+// clients next to the human-readable message so they never have to match on
+// the message text. Codes are never renamed or reused; every code must be
+// listed in ErrorCodes.
+type ErrorCode string
+
+// Generic codes, one per sentinel error. ErrorCodeOf falls back to these
+// when an AppError carries no specific code.
+const (
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeAlreadyExists      ErrorCode = "ALREADY_EXISTS"
+	CodeInvalidInput       ErrorCode = "INVALID_INPUT"
+	CodeValidationFailed   ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	CodeForbidden          ErrorCode = "FORBIDDEN"
+	CodePreconditionFailed ErrorCode = "PRECONDITION_FAILED"
+	CodeInternal           ErrorCode = "INTERNAL_ERROR"
+)
+
+// Codes of failures outside the service layer.
+const (
+	CodeRouteNotFound          ErrorCode = "ROUTE_NOT_FOUND"
+	CodeMethodNotAllowed       ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeRateLimited            ErrorCode = "RATE_LIMITED"
+	CodeUnsupportedMediaType   ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRequestTooLarge        ErrorCode = "REQUEST_TOO_LARGE"
+	CodeIdempotencyKeyReused   ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInFlight ErrorCode = "IDEMPOTENCY_KEY_IN_FLIGHT"
+)
+
+// Authentication and authorization codes.
+const (
+	CodeAuthTokenMissing         ErrorCode = "AUTH_TOKEN_MISSING"
+	CodeAuthTokenInvalid         ErrorCode = "AUTH_TOKEN_INVALID"
+	CodeAuthInvalidCredentials   ErrorCode = "AUTH_INVALID_CREDENTIALS"
+	CodeAuthAccountDisabled      ErrorCode = "AUTH_ACCOUNT_DISABLED"
+	CodeAuthRefreshTokenInvalid  ErrorCode = "AUTH_REFRESH_TOKEN_INVALID"
+	CodeAuthFirebaseTokenInvalid ErrorCode = "AUTH_FIREBASE_TOKEN_INVALID"
+	CodeAuthSecretInvalid        ErrorCode = "AUTH_SECRET_INVALID"
+	CodeRoleNotAllowed           ErrorCode = "ROLE_NOT_ALLOWED"
+	CodeEnterpriseOutOfScope     ErrorCode = "ENTERPRISE_OUT_OF_SCOPE"
+	CodeResidentNotAssigned      ErrorCode = "RESIDENT_NOT_ASSIGNED"
+)
+
+// Codes of specific business rules.
+const (
+	CodeUserEmailTaken           ErrorCode = "USER_EMAIL_TAKEN"
+	CodeUserUsernameTaken        ErrorCode = "USER_USERNAME_TAKEN"
+	CodeRobotSerialTaken         ErrorCode = "ROBOT_SERIAL_TAKEN"
+	CodeEnterpriseRobotLimit     ErrorCode = "ENTERPRISE_ROBOT_LIMIT"
+	CodeCaregiverResidentLimit   ErrorCode = "CAREGIVER_RESIDENT_LIMIT"
+	CodeCaregiverAlreadyAssigned ErrorCode = "CAREGIVER_ALREADY_ASSIGNED"
+	CodeIncidentNotOpen          ErrorCode = "INCIDENT_NOT_OPEN"
+	CodeExportLinkInvalid        ErrorCode = "EXPORT_LINK_INVALID"
+	CodeInvitationInvalid        ErrorCode = "INVITATION_INVALID"
+	CodeInvitationPending        ErrorCode = "INVITATION_PENDING"
+	CodeInvitationNotPending     ErrorCode = "INVITATION_NOT_PENDING"
+	CodeInvitationEmailMismatch  ErrorCode = "INVITATION_EMAIL_MISMATCH"
+)
+
+// ErrorCodeInfo documents an ErrorCode.
+type ErrorCodeInfo struct {
+	Code        ErrorCode
+	Status      int // HTTP status the code is sent with
+	Description string
+}
+
+// ErrorCodes is the registry of every code clients may receive. It is the
+// reference for client developers and is checked against the OpenAPI
+// document's ErrorCode enum by the tests.
+var ErrorCodes = []ErrorCodeInfo{
+	{CodeNotFound, http.StatusNotFound, "The resource does not exist or is outside the caller's enterprise."},
+	{CodeAlreadyExists, http.StatusConflict, "The request conflicts with an existing resource."},
+	{CodeInvalidInput, http.StatusBadRequest, "The request is malformed, e.g. a non-numeric ID or invalid JSON."},
+	{CodeValidationFailed, http.StatusBadRequest, "One or more fields are invalid; errors maps each field to its message."},
+	{CodeUnauthorized, http.StatusUnauthorized, "The caller is not authenticated."},
+	{CodeForbidden, http.StatusForbidden, "The caller may not perform this operation."},
+	{CodePreconditionFailed, http.StatusPreconditionFailed, "The resource changed since the version named in If-Match; fetch it again and reapply the change."},
+	{CodeInternal, http.StatusInternalServerError, "Unexpected server error; the message carries no details."},
+
+	{CodeRouteNotFound, http.StatusNotFound, "No route matches the path."},
+	{CodeMethodNotAllowed, http.StatusMethodNotAllowed, "The path exists but not for this HTTP method."},
+	{CodeRateLimited, http.StatusTooManyRequests, "Too many requests; retry later."},
+	{CodeUnsupportedMediaType, http.StatusUnsupportedMediaType, "The request body's Content-Type is not accepted by the endpoint."},
+	{CodeRequestTooLarge, http.StatusRequestEntityTooLarge, "The request body is larger than the endpoint accepts."},
+	{CodeIdempotencyKeyReused, http.StatusUnprocessableEntity, "The Idempotency-Key was already used for a request with another method, path or body."},
+	{CodeIdempotencyKeyInFlight, http.StatusConflict, "A request with the same Idempotency-Key is still running; retry after Retry-After seconds."},
+
+	{CodeAuthTokenMissing, http.StatusUnauthorized, "No bearer token was sent."},
+	{CodeAuthTokenInvalid, http.StatusUnauthorized, "The bearer token is malformed, expired or not an access token; log in again or refresh."},
+	{CodeAuthInvalidCredentials, http.StatusUnauthorized, "The email or password is wrong."},
+	{CodeAuthAccountDisabled, http.StatusUnauthorized, "The account is deactivated."},
+	{CodeAuthRefreshTokenInvalid, http.StatusUnauthorized, "The refresh token is invalid or expired, or its user no longer exists; log in again."},
+	{CodeAuthFirebaseTokenInvalid, http.StatusUnauthorized, "The Firebase ID token is invalid or expired."},
+	{CodeAuthSecretInvalid, http.StatusUnauthorized, "A server-to-server call carried a wrong or no shared secret."},
+	{CodeRoleNotAllowed, http.StatusForbidden, "The caller's role may not perform this operation."},
+	{CodeEnterpriseOutOfScope, http.StatusForbidden, "The target belongs to another enterprise than the caller's."},
+	{CodeResidentNotAssigned, http.StatusForbidden, "The resident is not assigned to the calling caregiver."},
+
+	{CodeUserEmailTaken, http.StatusConflict, "Another user has this email address."},
+	{CodeUserUsernameTaken, http.StatusConflict, "Another user has this username."},
+	{CodeRobotSerialTaken, http.StatusConflict, "A robot with this serial number is already provisioned."},
+	{CodeEnterpriseRobotLimit, http.StatusForbidden, "The enterprise has as many robots as its max_robots allows."},
+	{CodeCaregiverResidentLimit, http.StatusForbidden, "The caregiver already has the maximum number of residents."},
+	{CodeCaregiverAlreadyAssigned, http.StatusConflict, "The caregiver is already assigned to the resident."},
+	{CodeIncidentNotOpen, http.StatusConflict, "The incident is already resolved."},
+	{CodeExportLinkInvalid, http.StatusNotFound, "The export download link is invalid or has expired."},
+	{CodeInvitationInvalid, http.StatusNotFound, "The invitation link is invalid, expired, revoked or already used."},
+	{CodeInvitationPending, http.StatusConflict, "The email address already has a pending invitation; resend or revoke it."},
+	{CodeInvitationNotPending, http.StatusConflict, "The invitation was already accepted or revoked."},
+	{CodeInvitationEmailMismatch, http.StatusForbidden, "The Firebase account's email address is not the invited one."},
+}
+
+// ErrorCodeOf returns the code to report for err: the AppError's own code,
+// or the generic code of its sentinel. Database and unknown errors are
+// CodeInternal.
+func ErrorCodeOf(err error) ErrorCode {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return CodeInternal
+	}
+	if appErr.Code != "" {
+		return appErr.Code
+	}
+	switch {
+	case errors.Is(appErr.Err, ErrNotFound):
+		return CodeNotFound
+	case errors.Is(appErr.Err, ErrAlreadyExists):
+		return CodeAlreadyExists
+	case errors.Is(appErr.Err, ErrInvalidInput):
+		if len(appErr.Details) > 0 {
+			return CodeValidationFailed
+		}
+		return CodeInvalidInput
+	case errors.Is(appErr.Err, ErrUnauthorized):
+		return CodeUnauthorized
+	case errors.Is(appErr.Err, ErrForbidden):
+		return CodeForbidden
+	case errors.Is(appErr.Err, ErrPreconditionFailed):
+		return CodePreconditionFailed
+	default:
+		return CodeInternal
+	}
+}
+
+// ErrorCodeForStatus returns the generic code of an HTTP error status, for
+// responses written without a domain error.
+func ErrorCodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidInput
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeAlreadyExists
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusRequestEntityTooLarge:
+		return CodeRequestTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	default:
+		return CodeInternal
+	}
+}
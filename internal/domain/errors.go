@@ -10,13 +10,14 @@ import (
 // Sentinel errors for the application. Every layer returns these;
 // the handler/interceptor maps them to HTTP status codes.
 var (
-	ErrNotFound          = errors.New("resource not found")
-	ErrAlreadyExists     = errors.New("resource already exists")
-	ErrInvalidInput      = errors.New("invalid input")
-	ErrUnauthorized      = errors.New("unauthorized")
-	ErrForbidden         = errors.New("forbidden")
-	ErrInternal          = errors.New("internal server error")
-	ErrDatabaseOperation = errors.New("database operation failed")
+	ErrNotFound           = errors.New("resource not found")
+	ErrAlreadyExists      = errors.New("resource already exists")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrInternal           = errors.New("internal server error")
+	ErrDatabaseOperation  = errors.New("database operation failed")
 )
 
 // AppError wraps a sentinel error with a contextual message and optional field-level details.
@@ -24,14 +25,31 @@ type AppError struct {
 	Err     error             // The sentinel error (e.g., ErrNotFound)
 	Message string            // Human-readable message
 	Details map[string]string // Optional field-level validation errors
+	Cause   error             // Optional underlying error, e.g. from the database driver
+	Code    ErrorCode         // Optional specific code; see ErrorCodeOf
+
+	// Violations is the translatable form of Details, for fields whose
+	// message comes from a validation rule; see the i18n package.
+	Violations map[string]Violation
+}
+
+// Violation is a field-level validation failure by rule: Rule names a
+// message in the i18n catalogs and Params fill its placeholders.
+type Violation struct {
+	Rule   string            `json:"rule"`
+	Params map[string]string `json:"params,omitempty"`
 }
 
 func (e *AppError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Err.Error(), e.Message)
 }
 
-func (e *AppError) Unwrap() error {
-	return e.Err
+// Unwrap exposes both the sentinel and the cause to errors.Is and errors.As.
+func (e *AppError) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Err, e.Cause}
+	}
+	return []error{e.Err}
 }
 
 // NewAppError creates a new AppError wrapping a sentinel.
@@ -39,6 +57,19 @@ func NewAppError(err error, message string) *AppError {
 	return &AppError{Err: err, Message: message}
 }
 
+// WithCode sets the error's specific code and returns it, for chaining onto
+// the constructors.
+func (e *AppError) WithCode(code ErrorCode) *AppError {
+	e.Code = code
+	return e
+}
+
+// NewDatabaseError wraps a database driver error, keeping it as the cause so
+// callers can still inspect it (e.g. to retry serialization failures).
+func NewDatabaseError(err error) *AppError {
+	return &AppError{Err: ErrDatabaseOperation, Message: err.Error(), Cause: err}
+}
+
 // NewValidationError creates an AppError with field-level details.
 func NewValidationError(message string, details map[string]string) *AppError {
 	return &AppError{Err: ErrInvalidInput, Message: message, Details: details}
@@ -57,6 +88,8 @@ func HTTPStatusFromError(err error) int {
 		return http.StatusUnauthorized
 	case errors.Is(err, ErrForbidden):
 		return http.StatusForbidden
+	case errors.Is(err, ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
 	default:
 		return http.StatusInternalServerError
 	}
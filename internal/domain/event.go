@@ -0,0 +1,133 @@
+// internal/domain/event.go
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Domain event types.
+const (
+	EventUserCreated     = "user.created"
+	EventUserUpdated     = "user.updated"
+	EventUserDeactivated = "user.deactivated"
+	EventUserDeleted     = "user.deleted"
+	EventUserRestored    = "user.restored"
+	EventUserPurged      = "user.purged"
+
+	EventIncidentCreated         = "incident.created"
+	EventIncidentSeverityChanged = "incident.severity_changed"
+	EventIncidentResolved        = "incident.resolved"
+	EventIncidentDeleted         = "incident.deleted"
+
+	EventStoryCreated = "story.created"
+	EventStoryDeleted = "story.deleted"
+
+	// Recorded by database triggers (migration 000029), since devices and
+	// Hasura change robots and sessions as well as the API.
+	EventRobotStatusChanged = "robot.status_changed"
+	EventSessionStarted     = "session.started"
+)
+
+// Aggregate types events are recorded against.
+const (
+	AggregateUser     = "user"
+	AggregateIncident = "incident"
+	AggregateStory    = "story"
+	AggregateRobot    = "robot"
+	AggregateSession  = "session"
+)
+
+// DomainEvent is a state change recorded in the outbox in the same
+// transaction as the change itself, then relayed to subscribers.
+type DomainEvent struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	EnterpriseID  *int64          `json:"enterprise_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// UserEventPayload is the payload of user.* events. Personal details are
+// left out; subscribers load the user if they need them.
+type UserEventPayload struct {
+	UserID       int64  `json:"user_id"`
+	Role         string `json:"role,omitempty"`
+	EnterpriseID *int64 `json:"enterprise_id,omitempty"`
+	IsActive     bool   `json:"is_active"`
+}
+
+// NewUserEvent builds a user.* event for u.
+func NewUserEvent(eventType string, u *User) (*DomainEvent, error) {
+	return newEvent(eventType, AggregateUser, u.ID, u.EnterpriseID, UserEventPayload{
+		UserID:       u.ID,
+		Role:         u.Role,
+		EnterpriseID: u.EnterpriseID,
+		IsActive:     u.IsActive,
+	})
+}
+
+// IncidentEventPayload is the payload of incident.* events. ActorID is the
+// user who made the change, when known.
+type IncidentEventPayload struct {
+	IncidentID       int64  `json:"incident_id"`
+	ResidentID       int64  `json:"resident_id"`
+	Severity         string `json:"severity"`
+	PreviousSeverity string `json:"previous_severity,omitempty"`
+	ActorID          *int64 `json:"actor_id,omitempty"`
+}
+
+// NewIncidentEvent builds an incident.* event for the incident in p.
+func NewIncidentEvent(eventType string, enterpriseID *int64, p IncidentEventPayload) (*DomainEvent, error) {
+	return newEvent(eventType, AggregateIncident, p.IncidentID, enterpriseID, p)
+}
+
+// StoryEventPayload is the payload of story.* events. The content is left
+// out; it is encrypted for the reader anyway.
+type StoryEventPayload struct {
+	StoryID    int64  `json:"story_id"`
+	ResidentID int64  `json:"resident_id"`
+	AuthorID   int64  `json:"author_id"`
+	StoryType  string `json:"story_type"`
+	ActorID    *int64 `json:"actor_id,omitempty"`
+}
+
+// NewStoryEvent builds a story.* event for the story in p.
+func NewStoryEvent(eventType string, enterpriseID *int64, p StoryEventPayload) (*DomainEvent, error) {
+	return newEvent(eventType, AggregateStory, p.StoryID, enterpriseID, p)
+}
+
+// RobotEventPayload is the payload of robot.status_changed events.
+type RobotEventPayload struct {
+	RobotID        int64  `json:"robot_id"`
+	SerialNumber   string `json:"serial_number"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+	ResidentID     *int64 `json:"resident_id"`
+}
+
+// SessionEventPayload is the payload of session.started events.
+type SessionEventPayload struct {
+	SessionID   int64  `json:"session_id"`
+	RobotID     int64  `json:"robot_id"`
+	SessionType string `json:"session_type"`
+	ResidentID  int64  `json:"resident_id"`
+}
+
+func newEvent(eventType, aggregateType string, aggregateID int64, enterpriseID *int64, payload interface{}) (*DomainEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s payload: %w", eventType, err)
+	}
+	return &DomainEvent{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EnterpriseID:  enterpriseID,
+		Payload:       data,
+		OccurredAt:    time.Now(),
+	}, nil
+}
@@ -0,0 +1,26 @@
+// internal/domain/incident.go
+package domain
+
+import "time"
+
+// Incident severities.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Incident is a reported problem with a resident's care. Incidents are
+// written through Hasura; the API sees them as row changes.
+type Incident struct {
+	ID          int64      `json:"id"`
+	ResidentID  int64      `json:"resident_id"`
+	ReporterID  int64      `json:"reporter_id"`
+	Severity    string     `json:"severity"`
+	Description string     `json:"description"`
+	Resolution  *string    `json:"resolution"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,110 @@
+// internal/domain/invitation.go
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Invitation statuses. They follow from the invitation's timestamps and are
+// not stored.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired" // not accepted in time; resending renews it
+)
+
+// InvitableRoles are the roles users can be invited with. mta accounts and
+// robots are provisioned otherwise.
+var InvitableRoles = []string{RoleETA, RoleCaregiver, RoleFamily}
+
+// Invitation asks someone to join by email. The invitee accepts with the
+// token from the emailed link, choosing a password or linking a Firebase
+// account, and gets the role and enterprise of the invitation.
+//
+// An invitation either creates its user on acceptance or, when UserID is
+// set from the start, gives credentials to a user created without any
+// (such as an imported one).
+type Invitation struct {
+	ID           int64   `json:"id"`
+	EnterpriseID *int64  `json:"enterprise_id,omitempty"`
+	Email        string  `json:"email"`
+	Role         string  `json:"role"`
+	FullName     string  `json:"full_name,omitempty"`
+	Locale       *string `json:"locale,omitempty"`
+	// UserID is the invited user: the existing one, or the one created
+	// when the invitation was accepted.
+	UserID    *int64 `json:"user_id,omitempty"`
+	InvitedBy *int64 `json:"invited_by,omitempty"`
+	// Token is only known when the invitation's email is sent; the
+	// database keeps TokenHash.
+	Token      string     `json:"-"`
+	TokenHash  []byte     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     time.Time  `json:"sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Status returns the invitation's status at now.
+func (inv *Invitation) Status(now time.Time) string {
+	switch {
+	case inv.AcceptedAt != nil:
+		return InvitationAccepted
+	case inv.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(inv.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+// NewToken gives the invitation a new random token valid for ttl from now,
+// replacing any previous one.
+func (inv *Invitation) NewToken(ttl time.Duration) error {
+	if err := inv.RotateToken(); err != nil {
+		return err
+	}
+	inv.ExpiresAt = time.Now().Add(ttl)
+	return nil
+}
+
+// RotateToken replaces the invitation's token, keeping its expiry.
+func (inv *Invitation) RotateToken() error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("generating invitation token: %w", err)
+	}
+	inv.Token = hex.EncodeToString(b)
+	inv.TokenHash = InvitationTokenHash(inv.Token)
+	return nil
+}
+
+// AuditEvent describes action on the invitation by actorID.
+func (inv *Invitation) AuditEvent(actorID *int64, action string) *AuditEvent {
+	return &AuditEvent{
+		EnterpriseID: inv.EnterpriseID,
+		ActorID:      actorID,
+		Action:       action,
+		EntityType:   "invitation",
+		EntityID:     &inv.ID,
+		Metadata: map[string]interface{}{
+			"email": inv.Email,
+			"role":  inv.Role,
+		},
+	}
+}
+
+// InvitationTokenHash returns the hash an invitation token is stored and
+// looked up by.
+func InvitationTokenHash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,169 @@
+// internal/domain/notification.go
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Notification types.
+const (
+	NotificationTypeIncidentEscalated = "incident_escalated"
+	NotificationTypeFamilyStory       = "family_story"
+	NotificationTypeRobotOffline      = "robot_offline"
+)
+
+// NotificationTypes lists every notification type.
+var NotificationTypes = []string{
+	NotificationTypeIncidentEscalated,
+	NotificationTypeFamilyStory,
+	NotificationTypeRobotOffline,
+}
+
+// Push device platforms.
+const (
+	PushPlatformIOS     = "ios"
+	PushPlatformAndroid = "android"
+	PushPlatformWeb     = "web"
+)
+
+// Notification is an entry in a user's in-app inbox.
+type Notification struct {
+	ID        int64                  `json:"id"`
+	UserID    int64                  `json:"user_id"`
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data"`
+	DedupeKey string                 `json:"-"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// NotificationFilter holds optional query parameters for listing notifications.
+type NotificationFilter struct {
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// Normalize applies pagination defaults and clamps values to safe bounds.
+func (f *NotificationFilter) Normalize() {
+	if f.Limit <= 0 {
+		f.Limit = DefaultPageSize
+	}
+	if f.Limit > MaxPageSize {
+		f.Limit = MaxPageSize
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
+
+// NotificationPreference is a user's channel opt-in for one notification type.
+type NotificationPreference struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Push  bool   `json:"push"`
+	Email bool   `json:"email"`
+}
+
+// DefaultNotificationPreference applies when a user has not chosen otherwise:
+// every type goes to the inbox and to push, only escalations go to email.
+func DefaultNotificationPreference(notificationType string) NotificationPreference {
+	return NotificationPreference{
+		Type:  notificationType,
+		InApp: true,
+		Push:  true,
+		Email: notificationType == NotificationTypeIncidentEscalated,
+	}
+}
+
+// QuietHours is a daily window, in the enterprise's time zone, during which
+// push and email are held back. The window may wrap midnight (22:00–07:00).
+type QuietHours struct {
+	Start string `json:"start"` // "HH:MM"
+	End   string `json:"end"`   // "HH:MM"
+}
+
+// Validate checks both bounds are valid clock times.
+func (q *QuietHours) Validate() error {
+	for _, v := range []string{q.Start, q.End} {
+		if _, err := time.Parse("15:04", v); err != nil {
+			return fmt.Errorf("%q is not a HH:MM time", v)
+		}
+	}
+	return nil
+}
+
+// Until reports whether now falls inside the window in loc and, if so, when
+// the window ends.
+func (q *QuietHours) Until(now time.Time, loc *time.Location) (time.Time, bool) {
+	start, errStart := time.Parse("15:04", q.Start)
+	end, errEnd := time.Parse("15:04", q.End)
+	if errStart != nil || errEnd != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	s := start.Hour()*60 + start.Minute()
+	e := end.Hour()*60 + end.Minute()
+
+	var inside bool
+	switch {
+	case s == e:
+		inside = false
+	case s < e:
+		inside = minute >= s && minute < e
+	default: // wraps midnight
+		inside = minute >= s || minute < e
+	}
+	if !inside {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if minute >= e {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// NotificationSettings are a user's quiet hours and per-type preferences.
+type NotificationSettings struct {
+	QuietHours  *QuietHours              `json:"quiet_hours"`
+	Preferences []NotificationPreference `json:"preferences"`
+}
+
+// PushDevice is a registered push token of one of a user's devices.
+type PushDevice struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Token      string    `json:"token"`
+	Platform   string    `json:"platform"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// NotificationRecipient is a user an event fans out to, with everything
+// needed to pick channels.
+type NotificationRecipient struct {
+	UserID     int64
+	Email      string
+	Locale     string // the user's locale, empty for the default
+	TimeZone   string // IANA name of the user's enterprise time zone
+	QuietHours *QuietHours
+	Preference NotificationPreference // for the event's type, defaults applied
+}
+
+// NotificationEvent is a source event resolved for fan-out.
+type NotificationEvent struct {
+	Type     string
+	EntityID int64
+	// Urgent events bypass quiet hours.
+	Urgent bool
+	// Fields describe the event for templates and the notification payload.
+	Fields     map[string]interface{}
+	Recipients []NotificationRecipient
+}
@@ -0,0 +1,48 @@
+// internal/domain/realtime.go
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Real-time event types streamed to dashboards.
+const (
+	RealtimeIncidentCreated    = "incident.created"
+	RealtimeIncidentResolved   = "incident.resolved"
+	RealtimeRobotStatusChanged = "robot.status_changed"
+	RealtimeSessionStarted     = "session.started"
+)
+
+// RealtimeEvent is a change published by the database for live dashboards.
+// It carries identifiers only; clients load details through the API.
+type RealtimeEvent struct {
+	ID           int64           `json:"id"`
+	Type         string          `json:"type"`
+	EnterpriseID *int64          `json:"enterprise_id"`
+	ResidentID   *int64          `json:"resident_id"`
+	Data         json.RawMessage `json:"data"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// RealtimeAudience is the set of events a subscriber may see.
+type RealtimeAudience struct {
+	// All grants every event (mta).
+	All bool
+	// EnterpriseID grants every event of one enterprise (eta).
+	EnterpriseID int64
+	// ResidentIDs grants events about these residents only (caregiver).
+	ResidentIDs map[int64]bool
+}
+
+// Allows reports whether the audience may see e.
+func (a *RealtimeAudience) Allows(e *RealtimeEvent) bool {
+	switch {
+	case a.All:
+		return true
+	case a.EnterpriseID != 0:
+		return e.EnterpriseID != nil && *e.EnterpriseID == a.EnterpriseID
+	default:
+		return e.ResidentID != nil && a.ResidentIDs[*e.ResidentID]
+	}
+}
@@ -0,0 +1,38 @@
+// internal/domain/retention.go
+package domain
+
+import "time"
+
+// Tables that retention policies may target.
+const (
+	RetentionTableRobotSessions = "robot_sessions"
+	RetentionTableIncidents     = "incidents"
+	RetentionTableStories       = "stories"
+)
+
+// What happens to a row once its retention period has passed.
+const (
+	RetentionActionDelete    = "delete"
+	RetentionActionAnonymize = "anonymize"
+)
+
+// RetentionTables lists every table a retention policy may target.
+var RetentionTables = []string{RetentionTableRobotSessions, RetentionTableIncidents, RetentionTableStories}
+
+// RetentionPolicy defines how long an enterprise keeps rows of one table.
+// Incidents only become eligible once resolved.
+type RetentionPolicy struct {
+	ID            int64     `json:"id"`
+	EnterpriseID  int64     `json:"enterprise_id"`
+	TableName     string    `json:"table_name"`
+	RetentionDays int       `json:"retention_days"`
+	Action        string    `json:"action"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Cutoff returns the instant before which rows are expired under this policy.
+func (p *RetentionPolicy) Cutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -p.RetentionDays)
+}
@@ -0,0 +1,27 @@
+// internal/domain/robot.go
+package domain
+
+import "time"
+
+// Robot statuses.
+const (
+	RobotProvisioned    = "provisioned"
+	RobotActive         = "active"
+	RobotIdle           = "idle"
+	RobotMaintenance    = "maintenance"
+	RobotDecommissioned = "decommissioned"
+)
+
+// Robot is a care robot belonging to an enterprise. Decommissioned robots
+// do not count against the enterprise's max_robots.
+type Robot struct {
+	ID                 int64      `json:"id"`
+	SerialNumber       string     `json:"serial_number"`
+	EnterpriseID       int64      `json:"enterprise_id"`
+	AssignedResidentID *int64     `json:"assigned_resident_id"`
+	Status             string     `json:"status"`
+	FirmwareVersion    *string    `json:"firmware_version"`
+	LastHeartbeat      *time.Time `json:"last_heartbeat"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+// internal/domain/role.go
+package domain
+
+import "slices"
+
+// User roles. The users_role_check constraint and the Hasura permissions
+// must use the same set; cmd/schemacheck reports any drift.
+const (
+	RoleMTA       = "mta"
+	RoleETA       = "eta"
+	RoleCaregiver = "caregiver"
+	RoleFamily    = "family"
+	RoleRobot     = "robot"
+)
+
+// Roles lists every user role.
+var Roles = []string{RoleMTA, RoleETA, RoleCaregiver, RoleFamily, RoleRobot}
+
+// IsValidRole reports whether role is one of Roles.
+func IsValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// CanAssignRole reports whether a user with role assigner may give role to
+// a user. Only mta may create or promote mta and robot accounts.
+func CanAssignRole(assigner, role string) bool {
+	if role == RoleMTA || role == RoleRobot {
+		return assigner == RoleMTA
+	}
+	return true
+}
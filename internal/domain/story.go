@@ -0,0 +1,16 @@
+// internal/domain/story.go
+package domain
+
+import "time"
+
+// Story is a memory or media item shared about a resident, usually by
+// family. The content is encrypted by the client and never read here.
+type Story struct {
+	ID         int64     `json:"id"`
+	ResidentID int64     `json:"resident_id"`
+	AuthorID   int64     `json:"author_id"`
+	MediaURL   *string   `json:"media_url"`
+	StoryType  string    `json:"story_type"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
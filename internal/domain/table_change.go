@@ -0,0 +1,24 @@
+// internal/domain/table_change.go
+package domain
+
+// Row change operations, as named by Hasura event triggers.
+const (
+	ChangeInsert = "INSERT"
+	ChangeUpdate = "UPDATE"
+	ChangeDelete = "DELETE"
+	ChangeManual = "MANUAL"
+)
+
+// TableChange is a change to one row of T made outside the API, typically a
+// GraphQL mutation. Old is nil for inserts and New is nil for deletes.
+type TableChange[T any] struct {
+	EventID string
+	Trigger string
+	Op      string
+	Old     *T
+	New     *T
+	// ActorID and ActorRole come from the session that made the change.
+	// ActorID is nil for admin and direct database changes.
+	ActorID   *int64
+	ActorRole string
+}
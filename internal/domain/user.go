@@ -11,29 +11,90 @@ const (
 
 // User represents the core user domain entity.
 type User struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	FullName     string    `json:"full_name"`
-	Role         string    `json:"role"`
-	EnterpriseID *int64    `json:"enterprise_id,omitempty"`
-	FirebaseUID  *string   `json:"firebase_uid,omitempty"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	Email        string     `json:"email"`
+	PasswordHash string     `json:"-"`
+	FullName     string     `json:"full_name"`
+	Role         string     `json:"role"`
+	EnterpriseID *int64     `json:"enterprise_id,omitempty"`
+	FirebaseUID  *string    `json:"firebase_uid,omitempty"`
+	Locale       *string    `json:"locale,omitempty"` // preferred language of API messages
+	IsActive     bool       `json:"is_active"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	PurgedAt     *time.Time `json:"purged_at,omitempty"`
+	Version      int64      `json:"version"` // bumped on every profile change; the ETag of the API
 }
 
+// UserPatch is a partial update of a user's profile: nil fields are left as
+// they are. A Locale pointing to "" clears the locale.
+type UserPatch struct {
+	Username *string
+	Email    *string
+	FullName *string
+	Role     *string
+	Locale   *string
+	IsActive *bool
+}
+
+// Apply sets the fields of u that p changes.
+func (p UserPatch) Apply(u *User) {
+	if p.Username != nil {
+		u.Username = *p.Username
+	}
+	if p.Email != nil {
+		u.Email = *p.Email
+	}
+	if p.FullName != nil {
+		u.FullName = *p.FullName
+	}
+	if p.Role != nil {
+		u.Role = *p.Role
+	}
+	if p.Locale != nil {
+		u.Locale = p.Locale
+	}
+	if p.IsActive != nil {
+		u.IsActive = *p.IsActive
+	}
+}
+
+// UserSortFields are the fields users can be listed in order of.
+var UserSortFields = []string{"created_at", "updated_at", "username", "email", "full_name"}
+
+// DefaultUserSort lists the newest users first.
+const DefaultUserSort = "-created_at"
+
 // UserFilter holds optional query parameters for listing users.
 type UserFilter struct {
-	Role     string
-	IsActive *bool
-	Limit    int
-	Offset   int
+	Role          string
+	IsActive      *bool
+	Deleted       bool       // list soft-deleted users instead of live ones
+	EnterpriseID  *int64     // users of one enterprise
+	Search        string     // case-insensitive substring of username, email or full name
+	CreatedAfter  *time.Time // inclusive
+	CreatedBefore *time.Time // exclusive
+	Sort          string     // a UserSortFields field, prefixed with "-" for descending
+	Cursor        string     // UserPage.NextCursor of the previous page
+	WithTotal     bool       // count every matching user into UserPage.Total
+	Limit         int
+	Offset        int // superseded by Cursor; kept for the gRPC API
+}
+
+// UserPage is one page of a user listing.
+type UserPage struct {
+	Users      []User
+	NextCursor string // empty on the last page
+	Total      *int64 // set when the filter asked for it
 }
 
 // Normalize applies pagination defaults and clamps values to safe bounds.
 func (f *UserFilter) Normalize() {
+	if f.Sort == "" {
+		f.Sort = DefaultUserSort
+	}
 	if f.Limit <= 0 {
 		f.Limit = DefaultPageSize
 	}
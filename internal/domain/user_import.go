@@ -0,0 +1,89 @@
+// internal/domain/user_import.go
+package domain
+
+import "time"
+
+// Formats of user import files.
+const (
+	ImportFormatCSV   = "csv"   // a header row naming the columns, then one user per row
+	ImportFormatJSONL = "jsonl" // one JSON object per line
+)
+
+// User import modes.
+const (
+	// ImportModeAtomic creates every user or, if any row fails, none.
+	ImportModeAtomic = "atomic"
+	// ImportModeBestEffort creates the users of the rows that pass and
+	// reports the others.
+	ImportModeBestEffort = "best_effort"
+)
+
+// User import statuses. Imports that are run in the worker are pending
+// until it picks them up.
+const (
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed" // the import could not run; rows failing don't fail it
+)
+
+// Outcomes of an import row.
+const (
+	ImportRowValid   = "valid"   // a dry run would create the user
+	ImportRowCreated = "created" // the user was created
+	ImportRowFailed  = "failed"  // see the row's code and violations
+	ImportRowSkipped = "skipped" // valid, but not created because another row failed an atomic import
+)
+
+// UserImportRow is a user read from an import file.
+type UserImportRow struct {
+	Line     int     `json:"line"` // line of the file the user starts on
+	Username string  `json:"username"`
+	Email    string  `json:"email"`
+	FullName string  `json:"full_name"`
+	Role     string  `json:"role,omitempty"`
+	Locale   *string `json:"locale,omitempty"`
+}
+
+// UserImportResult is the outcome of one row.
+type UserImportResult struct {
+	Line       int                  `json:"line"`
+	Status     string               `json:"status"`
+	UserID     *int64               `json:"user_id,omitempty"`
+	Code       ErrorCode            `json:"code,omitempty"`
+	Violations map[string]Violation `json:"violations,omitempty"`
+}
+
+// UserImport is a bulk creation of users from a file. Rows hold personal
+// data and are only kept until the import has run; Results don't.
+type UserImport struct {
+	ID           int64              `json:"id"`
+	EnterpriseID *int64             `json:"enterprise_id,omitempty"` // of every imported user
+	RequestedBy  *int64             `json:"requested_by,omitempty"`
+	Mode         string             `json:"mode"`
+	DryRun       bool               `json:"dry_run"` // validate only
+	Invite       bool               `json:"invite"`  // email created users an invitation
+	Status       string             `json:"status"`
+	Rows         []UserImportRow    `json:"-"`
+	Results      []UserImportResult `json:"results"`
+	Error        string             `json:"error,omitempty"`
+	CompletedAt  *time.Time         `json:"completed_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// Count returns the number of results with status.
+func (imp *UserImport) Count(status string) int {
+	n := 0
+	for _, r := range imp.Results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// IsValidImportMode reports whether mode is a user import mode.
+func IsValidImportMode(mode string) bool {
+	return mode == ImportModeAtomic || mode == ImportModeBestEffort
+}
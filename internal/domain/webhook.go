@@ -0,0 +1,125 @@
+// internal/domain/webhook.go
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventTypes lists the domain events an enterprise may subscribe to.
+// They match the dashboard events (see realtime.go).
+var WebhookEventTypes = []string{
+	EventIncidentCreated,
+	EventIncidentResolved,
+	EventRobotStatusChanged,
+	EventSessionStarted,
+}
+
+// WebhookEventTest is sent by the "send test event" endpoint only.
+const WebhookEventTest = "webhook.test"
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription is an enterprise endpoint that receives signed event
+// payloads. The secret is only ever shown when the subscription is created.
+type WebhookSubscription struct {
+	ID                  int64      `json:"id"`
+	EnterpriseID        int64      `json:"enterprise_id"`
+	URL                 string     `json:"url"`
+	Description         string     `json:"description"`
+	EventTypes          []string   `json:"event_types"`
+	Secret              string     `json:"-"`
+	IsActive            bool       `json:"is_active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	FailingSince        *time.Time `json:"failing_since,omitempty"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription,
+// with the outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseCode   *int            `json:"response_code,omitempty"`
+	ResponseTimeMS *int            `json:"response_time_ms,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ApplyResult records the outcome of an attempt that got the given HTTP
+// status (0 when no response arrived) after elapsed and failed with err,
+// if non-nil. A failed delivery stays pending unless this was its final
+// attempt.
+func (d *WebhookDelivery) ApplyResult(statusCode int, elapsed time.Duration, err error, final bool) {
+	d.ResponseCode = nil
+	d.ResponseTimeMS = nil
+	if statusCode != 0 {
+		ms := int(elapsed.Milliseconds())
+		d.ResponseCode = &statusCode
+		d.ResponseTimeMS = &ms
+	}
+	if err == nil {
+		now := time.Now()
+		d.Status = WebhookDeliverySucceeded
+		d.LastError = nil
+		d.DeliveredAt = &now
+		return
+	}
+
+	msg := err.Error()
+	d.LastError = &msg
+	d.Status = WebhookDeliveryPending
+	if final {
+		d.Status = WebhookDeliveryFailed
+	}
+}
+
+// WebhookPayload is the JSON body POSTed to a subscription. Data is the
+// domain event's payload.
+type WebhookPayload struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	EnterpriseID int64           `json:"enterprise_id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// WebhookDisablePolicy decides when a failing subscription is switched off:
+// after at least Failures failed attempts in a row spanning at least After.
+type WebhookDisablePolicy struct {
+	Failures int
+	After    time.Duration
+}
+
+// WebhookDeliveryFilter holds pagination for a subscription's delivery log.
+type WebhookDeliveryFilter struct {
+	Limit  int
+	Offset int
+}
+
+// Normalize applies pagination defaults and clamps values to safe bounds.
+func (f *WebhookDeliveryFilter) Normalize() {
+	if f.Limit <= 0 {
+		f.Limit = DefaultPageSize
+	}
+	if f.Limit > MaxPageSize {
+		f.Limit = MaxPageSize
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
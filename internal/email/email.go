@@ -0,0 +1,125 @@
+// internal/email/email.go
+
+// Package email renders localized email templates and delivers messages
+// through a pluggable Sender: SMTP in production, a directory of .eml files
+// or an in-memory outbox in development and tests.
+//
+// Application code does not call a Sender directly; it enqueues an
+// "email.send" job (see jobs.EmailSendArgs) which the worker renders and
+// delivers with retries.
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+// Sender drivers.
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+)
+
+// SMTP transport security modes.
+const (
+	TLSModeStartTLS = "starttls" // plain connection upgraded with STARTTLS (port 587)
+	TLSModeImplicit = "tls"      // TLS from the first byte (port 465)
+	TLSModeNone     = "none"     // local relays and dev stand-ins only
+)
+
+// Message is a rendered email ready to be sent.
+type Message struct {
+	// ID becomes the local part of the Message-ID header. Reusing the same ID
+	// on retries lets receiving servers discard duplicates. A random ID is
+	// generated when empty.
+	ID      string
+	From    string // defaults to the sender's configured address
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers a message.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config holds settings for building a Sender.
+type Config struct {
+	Driver  string
+	From    string
+	FileDir string
+	SMTP    SMTPConfig
+}
+
+// SMTPConfig holds SMTP relay settings.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLSMode  string
+	Timeout  time.Duration
+}
+
+// NewSender builds the Sender selected by cfg.Driver.
+func NewSender(cfg Config, logger *slog.Logger) (Sender, error) {
+	if _, err := parseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("email: invalid from address: %w", err)
+	}
+
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPSender(cfg.SMTP, cfg.From, logger)
+	case DriverFile:
+		return NewFileSender(cfg.FileDir, cfg.From, logger), nil
+	case DriverMemory:
+		return NewMemorySender(cfg.From), nil
+	default:
+		return nil, fmt.Errorf("email: unknown driver %q", cfg.Driver)
+	}
+}
+
+// permanentError marks a delivery failure that will not succeed on retry,
+// e.g. a 5xx reply or an invalid recipient.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// IsPermanent reports whether retrying the delivery is pointless.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// addressRe matches an email address, keeping its domain in group 1.
+var addressRe = regexp.MustCompile(`[\w.!#$%&'*+/=?^{|}~-]+@([\w-]+(?:\.[\w-]+)+)`)
+
+// redactedError hides the addresses in the message of the error it wraps.
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return addressRe.ReplaceAllString(e.err.Error(), "[redacted]@$1")
+}
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Redact returns err with every email address in its message replaced by
+// its domain, for logs and stored job errors. SMTP replies often quote the
+// recipient. errors.Is and errors.As still see the original error.
+func Redact(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err}
+}
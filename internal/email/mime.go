@@ -0,0 +1,122 @@
+// internal/email/mime.go
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// envelope is a message validated and encoded for the wire.
+type envelope struct {
+	from      string   // bare sender address for MAIL FROM
+	to        []string // bare recipient addresses for RCPT TO
+	messageID string
+	raw       []byte
+}
+
+// parseAddress accepts "Name <addr>" or a bare address.
+func parseAddress(s string) (*mail.Address, error) {
+	if strings.ContainsAny(s, "\r\n") {
+		return nil, fmt.Errorf("address contains a line break")
+	}
+	return mail.ParseAddress(s)
+}
+
+// encode validates msg and renders it as a multipart/alternative MIME
+// message. Invalid input is a permanent error.
+func encode(msg *Message, defaultFrom string) (*envelope, error) {
+	fromHeader := msg.From
+	if fromHeader == "" {
+		fromHeader = defaultFrom
+	}
+	from, err := parseAddress(fromHeader)
+	if err != nil {
+		return nil, &permanentError{fmt.Errorf("invalid from address: %w", err)}
+	}
+	if len(msg.To) == 0 {
+		return nil, &permanentError{fmt.Errorf("message has no recipients")}
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, &permanentError{fmt.Errorf("message has no body")}
+	}
+
+	env := &envelope{from: from.Address}
+	toHeader := make([]string, 0, len(msg.To))
+	for _, t := range msg.To {
+		addr, err := parseAddress(t)
+		if err != nil {
+			return nil, &permanentError{fmt.Errorf("invalid recipient address: %w", err)}
+		}
+		env.to = append(env.to, addr.Address)
+		toHeader = append(toHeader, addr.String())
+	}
+
+	id := msg.ID
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("generating message id: %w", err)
+		}
+		id = hex.EncodeToString(b)
+	}
+	env.messageID = fmt.Sprintf("<%s@%s>", id, domainOf(from.Address))
+
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", from.String())
+	header("To", strings.Join(toHeader, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", env.messageID)
+	header("MIME-Version", "1.0")
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary()))
+	buf.WriteString("\r\n")
+
+	// Plain text first: clients show the last part they can render.
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	env.raw = buf.Bytes()
+	return env, nil
+}
+
+func domainOf(addr string) string {
+	if i := strings.LastIndexByte(addr, '@'); i >= 0 {
+		return addr[i+1:]
+	}
+	return "localhost"
+}
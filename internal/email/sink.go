@@ -0,0 +1,99 @@
+// internal/email/sink.go
+package email
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Compile-time interface checks.
+var (
+	_ Sender = (*FileSender)(nil)
+	_ Sender = (*MemorySender)(nil)
+)
+
+// FileSender writes each message as an .eml file for inspection in a mail
+// client. Intended for local development.
+type FileSender struct {
+	dir    string
+	from   string
+	logger *slog.Logger
+}
+
+// NewFileSender creates a FileSender writing to dir.
+func NewFileSender(dir, from string, logger *slog.Logger) *FileSender {
+	return &FileSender{dir: dir, from: from, logger: logger}
+}
+
+// Send writes msg to <dir>/<timestamp>-<message id>.eml.
+func (s *FileSender) Send(_ context.Context, msg *Message) error {
+	env, err := encode(msg, s.from)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("creating mail directory: %w", err)
+	}
+
+	id := strings.Trim(env.messageID, "<>")
+	name := filepath.Join(s.dir, fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), id))
+	if err := os.WriteFile(name, env.raw, 0o640); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+
+	s.logger.Debug("email written to file", slog.String("path", name))
+	return nil
+}
+
+// SentMessage is a message captured by MemorySender.
+type SentMessage struct {
+	Message
+	MessageID string
+	Raw       []byte
+}
+
+// MemorySender keeps sent messages in memory. Intended for tests.
+type MemorySender struct {
+	from string
+
+	mu   sync.Mutex
+	sent []SentMessage
+}
+
+// NewMemorySender creates an empty MemorySender.
+func NewMemorySender(from string) *MemorySender {
+	return &MemorySender{from: from}
+}
+
+// Send validates and records msg.
+func (s *MemorySender) Send(_ context.Context, msg *Message) error {
+	env, err := encode(msg, s.from)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, SentMessage{Message: *msg, MessageID: env.messageID, Raw: env.raw})
+	return nil
+}
+
+// Sent returns a copy of every message sent so far.
+func (s *MemorySender) Sent() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMessage(nil), s.sent...)
+}
+
+// Reset discards recorded messages.
+func (s *MemorySender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = nil
+}
@@ -0,0 +1,155 @@
+// internal/email/smtp.go
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// Compile-time interface check.
+var _ Sender = (*SMTPSender)(nil)
+
+// SMTPSender delivers messages through an SMTP relay, opening one connection
+// per message.
+type SMTPSender struct {
+	config SMTPConfig
+	from   string
+	logger *slog.Logger
+}
+
+// NewSMTPSender creates an SMTPSender.
+func NewSMTPSender(config SMTPConfig, from string, logger *slog.Logger) (*SMTPSender, error) {
+	if config.Host == "" || config.Port == 0 {
+		return nil, fmt.Errorf("email: smtp host and port are required")
+	}
+	switch config.TLSMode {
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, fmt.Errorf("email: unknown smtp tls mode %q", config.TLSMode)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{config: config, from: from, logger: logger}, nil
+}
+
+// Send delivers msg. 5xx replies to RCPT TO and DATA are hard bounces and
+// are returned as permanent errors; connection problems, 4xx replies and
+// failures before the message is addressed, such as rejected credentials,
+// are transient: they are the relay's or the configuration's, not the
+// message's.
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	env, err := encode(msg, s.from)
+	if err != nil {
+		return err
+	}
+	return s.deliver(ctx, env)
+}
+
+func (s *SMTPSender) deliver(ctx context.Context, env *envelope) error {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	tlsConfig := &tls.Config{ServerName: s.config.Host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: s.config.Timeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.config.TLSMode == TLSModeImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to smtp relay: %w", err)
+	}
+
+	deadline := time.Now().Add(s.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close() //nolint:errcheck // the connection is being abandoned
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close() //nolint:errcheck // the connection is being abandoned
+		return fmt.Errorf("smtp greeting: %w", err)
+	}
+	defer c.Close()
+
+	if s.config.TLSMode == TLSModeStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp relay does not support STARTTLS")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if s.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(env.from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+
+	// A rejected recipient does not stop delivery to the others; the message
+	// only fails when nobody accepted it.
+	accepted := 0
+	var rcptErr error
+	for _, to := range env.to {
+		if err := c.Rcpt(to); err != nil {
+			rcptErr = err
+			s.logger.Warn("smtp recipient rejected",
+				slog.String("message_id", env.messageID),
+				slog.String("recipient_domain", domainOf(to)),
+				slog.String("error", Redact(err).Error()),
+			)
+			continue
+		}
+		accepted++
+	}
+	if accepted == 0 {
+		return bounce(fmt.Errorf("smtp RCPT TO: %w", rcptErr))
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return bounce(fmt.Errorf("smtp DATA: %w", err))
+	}
+	if _, err := w.Write(env.raw); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return bounce(fmt.Errorf("smtp DATA: %w", err))
+	}
+
+	// The message is accepted once DATA completes; a failed QUIT is harmless.
+	if err := c.Quit(); err != nil {
+		s.logger.Debug("smtp QUIT failed", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// bounce marks a 5xx reply to RCPT TO or DATA as permanent.
+func bounce(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return &permanentError{err}
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+// internal/email/template.go
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template names.
+const (
+	TemplateVerifyEmail        = "verify_email"
+	TemplatePasswordReset      = "password_reset"
+	TemplateIncidentEscalation = "incident_escalation"
+	TemplateFamilyStory        = "family_story"
+	TemplateRobotOffline       = "robot_offline"
+	TemplateInvitation         = "invitation"
+)
+
+// ErrUnknownTemplate is returned when no locale provides the template.
+var ErrUnknownTemplate = errors.New("email: unknown template")
+
+// Templates live in templates/<locale>/<name>.{txt,html}.tmpl. The text file
+// defines "subject" and "body"; the HTML file defines "body". Both are
+// wrapped by the matching layout in templates/layouts, which pulls the
+// localized "footer" from templates/<locale>/common.tmpl.
+//
+//go:embed templates
+var templateFS embed.FS
+
+const (
+	htmlLayout = "templates/layouts/base.html.tmpl"
+	textLayout = "templates/layouts/base.txt.tmpl"
+)
+
+type templateSet struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Renderer renders localized templates into messages.
+type Renderer struct {
+	defaultLocale string
+	sets          map[string]templateSet // "<locale>/<name>" → templates
+}
+
+// NewRenderer parses every embedded template. Parsing happens once at
+// startup so a broken template fails the process instead of a delivery.
+func NewRenderer(defaultLocale string) (*Renderer, error) {
+	r := &Renderer{defaultLocale: defaultLocale, sets: make(map[string]templateSet)}
+
+	textFiles, err := fs.Glob(templateFS, "templates/*/*.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, textFile := range textFiles {
+		locale := path.Base(path.Dir(textFile))
+		if locale == "layouts" {
+			continue
+		}
+		name := strings.TrimSuffix(path.Base(textFile), ".txt.tmpl")
+		htmlFile := path.Join(path.Dir(textFile), name+".html.tmpl")
+		common := path.Join(path.Dir(textFile), "common.tmpl")
+
+		text, err := texttemplate.New(name).Option("missingkey=error").ParseFS(templateFS, textLayout, common, textFile)
+		if err != nil {
+			return nil, fmt.Errorf("email: parsing %s: %w", textFile, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("email: %s does not define a subject", textFile)
+		}
+		html, err := htmltemplate.New(name).Option("missingkey=error").ParseFS(templateFS, htmlLayout, common, htmlFile)
+		if err != nil {
+			return nil, fmt.Errorf("email: parsing %s: %w", htmlFile, err)
+		}
+
+		r.sets[locale+"/"+name] = templateSet{text: text, html: html}
+	}
+
+	for key := range r.sets {
+		if strings.HasPrefix(key, defaultLocale+"/") {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("email: no templates for default locale %q", defaultLocale)
+}
+
+// Render renders template name for locale. Locales fall back from "es-MX" to
+// "es" to the default locale.
+func (r *Renderer) Render(name, locale string, data interface{}) (*Message, error) {
+	set, ok := r.lookup(name, locale)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := set.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("email: rendering %s subject: %w", name, err)
+	}
+	if err := set.text.ExecuteTemplate(&text, "layout", data); err != nil {
+		return nil, fmt.Errorf("email: rendering %s text: %w", name, err)
+	}
+	if err := set.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, fmt.Errorf("email: rendering %s html: %w", name, err)
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func (r *Renderer) lookup(name, locale string) (templateSet, bool) {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, r.defaultLocale)
+
+	for _, l := range candidates {
+		if set, ok := r.sets[l+"/"+name]; ok {
+			return set, true
+		}
+	}
+	return templateSet{}, false
+}
@@ -0,0 +1,264 @@
+// internal/hasura/actions.go
+package hasura
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ActionKind is the GraphQL root type an action is added to.
+type ActionKind string
+
+const (
+	ActionMutation ActionKind = "mutation"
+	ActionQuery    ActionKind = "query"
+)
+
+// Action metadata shared by every action: all of them are served by the
+// API's single dispatch endpoint, which authenticates Hasura by this header.
+const (
+	ActionHandlerURL    = "{{ACTION_BASE_URL}}/api/v1/actions"
+	ActionSecretHeader  = "X-Hasura-Action-Secret"
+	ActionSecretEnvName = "HASURA_ACTION_SECRET"
+)
+
+// ActionDefinition describes one action for the Hasura metadata. Input is
+// the struct the action's arguments are decoded into, Output the type it
+// returns; both are described by their json and binding tags.
+type ActionDefinition struct {
+	Name   string
+	Kind   ActionKind
+	Roles  []string
+	Input  reflect.Type
+	Output reflect.Type
+}
+
+// sdlLineLimit is the width past which an action's arguments are put on
+// their own lines, as the Hasura console formats them.
+const sdlLineLimit = 80
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// RenderActionsGraphQL returns the actions.graphql metadata for defs: one
+// root field per action, followed by the object types of their outputs.
+func RenderActionsGraphQL(defs []ActionDefinition) ([]byte, error) {
+	var b strings.Builder
+	objects := newObjectSet()
+
+	for _, def := range defs {
+		args, err := actionArgs(def.Input)
+		if err != nil {
+			return nil, fmt.Errorf("action %s: %w", def.Name, err)
+		}
+		out, err := objects.typeRef(def.Output)
+		if err != nil {
+			return nil, fmt.Errorf("action %s: %w", def.Name, err)
+		}
+		out = strings.TrimSuffix(out, "!")
+
+		root := "Mutation"
+		if def.Kind == ActionQuery {
+			root = "Query"
+		}
+		fmt.Fprintf(&b, "type %s {\n", root)
+		line := fmt.Sprintf("  %s(%s): %s", def.Name, strings.Join(args, ", "), out)
+		if len(args) == 0 {
+			line = fmt.Sprintf("  %s: %s", def.Name, out)
+		}
+		if len(line) <= sdlLineLimit {
+			b.WriteString(line + "\n")
+		} else {
+			fmt.Fprintf(&b, "  %s(\n", def.Name)
+			for _, arg := range args {
+				fmt.Fprintf(&b, "    %s\n", arg)
+			}
+			fmt.Fprintf(&b, "  ): %s\n", out)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	for _, obj := range objects.ordered {
+		fmt.Fprintf(&b, "type %s {\n", obj.name)
+		for _, field := range obj.fields {
+			fmt.Fprintf(&b, "  %s\n", field)
+		}
+		b.WriteString("}\n\n")
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n")), nil
+}
+
+// RenderActionsYAML returns the actions.yaml metadata for defs. Actions
+// without roles get no permissions, so only the admin role can call them.
+func RenderActionsYAML(defs []ActionDefinition) ([]byte, error) {
+	var b strings.Builder
+	objects := newObjectSet()
+
+	b.WriteString("actions:\n")
+	for _, def := range defs {
+		if _, err := objects.typeRef(def.Output); err != nil {
+			return nil, fmt.Errorf("action %s: %w", def.Name, err)
+		}
+		fmt.Fprintf(&b, "  - name: %s\n", def.Name)
+		b.WriteString("    definition:\n")
+		b.WriteString("      kind: synchronous\n")
+		if def.Kind == ActionQuery {
+			b.WriteString("      type: query\n")
+		}
+		fmt.Fprintf(&b, "      handler: %q\n", ActionHandlerURL)
+		b.WriteString("      forward_client_headers: true\n")
+		b.WriteString("      headers:\n")
+		fmt.Fprintf(&b, "        - name: %s\n", ActionSecretHeader)
+		fmt.Fprintf(&b, "          value_from_env: %s\n", ActionSecretEnvName)
+		if len(def.Roles) > 0 {
+			b.WriteString("    permissions:\n")
+			for _, role := range def.Roles {
+				fmt.Fprintf(&b, "      - role: %s\n", role)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("custom_types:\n")
+	b.WriteString("  objects:\n")
+	for _, obj := range objects.ordered {
+		fmt.Fprintf(&b, "    - name: %s\n", obj.name)
+	}
+	return []byte(b.String()), nil
+}
+
+// actionArgs returns the GraphQL arguments for an action's input struct:
+// one per field, required when the field is a value with a "required"
+// binding.
+func actionArgs(t reflect.Type) ([]string, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("input %s is not a struct", t)
+	}
+	var args []string
+	for _, f := range reflect.VisibleFields(t) {
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		scalar, ok := scalarName(indirect(f.Type))
+		if !ok {
+			return nil, fmt.Errorf("input field %s.%s: unsupported type %s", t.Name(), f.Name, f.Type)
+		}
+		if f.Type.Kind() != reflect.Pointer && hasBinding(f, "required") {
+			scalar += "!"
+		}
+		args = append(args, name+": "+scalar)
+	}
+	return args, nil
+}
+
+type objectType struct {
+	name   string
+	fields []string
+}
+
+// objectSet collects the output object types in the order they are first
+// referenced, so the rendered metadata is stable.
+type objectSet struct {
+	byName  map[string]reflect.Type
+	ordered []*objectType
+}
+
+func newObjectSet() *objectSet {
+	return &objectSet{byName: make(map[string]reflect.Type)}
+}
+
+// typeRef returns the GraphQL type for t, adding the object types it
+// refers to. Values are non-null; pointers and slices may be null.
+func (s *objectSet) typeRef(t reflect.Type) (string, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		ref, err := s.typeRef(t.Elem())
+		return strings.TrimSuffix(ref, "!"), err
+	case reflect.Slice:
+		ref, err := s.typeRef(t.Elem())
+		return "[" + ref + "]", err
+	}
+	if scalar, ok := scalarName(t); ok {
+		return scalar + "!", nil
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return "", fmt.Errorf("unsupported output type %s", t)
+	}
+
+	if seen, ok := s.byName[t.Name()]; ok {
+		if seen != t {
+			return "", fmt.Errorf("output types %s and %s share the name %s", seen, t, t.Name())
+		}
+		return t.Name() + "!", nil
+	}
+	s.byName[t.Name()] = t
+	obj := &objectType{name: t.Name()}
+	s.ordered = append(s.ordered, obj)
+
+	for _, f := range reflect.VisibleFields(t) {
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		ref, err := s.typeRef(f.Type)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		obj.fields = append(obj.fields, name+": "+ref)
+	}
+	return t.Name() + "!", nil
+}
+
+// scalarName returns the GraphQL scalar for t, if it is one. Times are
+// sent as RFC 3339 strings.
+func scalarName(t reflect.Type) (string, bool) {
+	if t == timeType {
+		return "String", true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "String", true
+	case reflect.Bool:
+		return "Boolean", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Int", true
+	case reflect.Float32, reflect.Float64:
+		return "Float", true
+	}
+	return "", false
+}
+
+// jsonName returns the name f is encoded under, and false if it is not
+// encoded at all.
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() || f.Anonymous {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return f.Name, true
+}
+
+func hasBinding(f reflect.StructField, rule string) bool {
+	for _, r := range strings.Split(f.Tag.Get("binding"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,161 @@
+// internal/hasura/dispatcher.go
+package hasura
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/pkg/database"
+)
+
+// handlerFunc decodes an event's rows and runs the registered handler.
+type handlerFunc func(ctx context.Context, e *Event, s Session) error
+
+// Dispatcher routes events to the handler registered for their table.
+type Dispatcher struct {
+	pool     *pgxpool.Pool
+	tx       *database.TxManager
+	handlers map[string]handlerFunc
+	keep     time.Duration
+	logger   *slog.Logger
+}
+
+// NewDispatcher creates a Dispatcher. IDs of processed events are kept for
+// keep, which must exceed the time Hasura spends retrying an event.
+func NewDispatcher(pool *pgxpool.Pool, keep time.Duration, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		pool:     pool,
+		tx:       database.NewTxManager(pool),
+		handlers: make(map[string]handlerFunc),
+		keep:     keep,
+		logger:   logger,
+	}
+}
+
+// Register sets fn as the handler for changes to table, given as
+// "schema.name", whose rows decode into T. It panics if the table already
+// has a handler.
+func Register[T any](d *Dispatcher, table string, fn func(ctx context.Context, change *domain.TableChange[T]) error) {
+	if _, dup := d.handlers[table]; dup {
+		panic(fmt.Sprintf("hasura: handler for table %q registered twice", table))
+	}
+	d.handlers[table] = func(ctx context.Context, e *Event, s Session) error {
+		change := &domain.TableChange[T]{
+			EventID:   e.ID,
+			Trigger:   e.Trigger.Name,
+			Op:        e.Event.Op,
+			ActorRole: s.Role,
+		}
+		if s.UserID != 0 {
+			change.ActorID = &s.UserID
+		}
+		var err error
+		if change.Old, err = decodeRow[T](e.Event.Data.Old); err != nil {
+			return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("decoding old row of %s: %v", table, err))
+		}
+		if change.New, err = decodeRow[T](e.Event.Data.New); err != nil {
+			return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("decoding new row of %s: %v", table, err))
+		}
+		if err := checkRows(change.Op, change.Old != nil, change.New != nil); err != nil {
+			return err
+		}
+		return fn(ctx, change)
+	}
+}
+
+// checkRows verifies that an event carries the rows its operation implies,
+// so handlers can rely on them.
+func checkRows(op string, hasOld, hasNew bool) error {
+	var ok bool
+	switch op {
+	case domain.ChangeInsert, domain.ChangeManual:
+		ok = hasNew
+	case domain.ChangeUpdate:
+		ok = hasOld && hasNew
+	case domain.ChangeDelete:
+		ok = hasOld
+	default:
+		return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("unknown operation %q", op))
+	}
+	if !ok {
+		return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("%s event is missing row data", op))
+	}
+	return nil
+}
+
+// decodeRow decodes a row, returning nil when Hasura sent none.
+func decodeRow[T any](raw json.RawMessage) (*T, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	row := new(T)
+	if err := json.Unmarshal(raw, row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// Dispatch runs the handler for e in a transaction that also records e's
+// ID. It reports false, without running the handler, for an event that was
+// processed before. A handler error rolls the transaction back so that
+// Hasura's retry starts over.
+func (d *Dispatcher) Dispatch(ctx context.Context, e *Event) (bool, error) {
+	if _, err := uuid.Parse(e.ID); err != nil {
+		return false, domain.NewAppError(domain.ErrInvalidInput, "event ID must be a UUID")
+	}
+	table := e.TableName()
+	handle, ok := d.handlers[table]
+	if !ok {
+		return false, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("no handler for table %s", table))
+	}
+	session := e.Session()
+
+	processed := false
+	err := d.tx.WithinTx(ctx, func(ctx context.Context) error {
+		processed = false
+		tag, err := database.Conn(ctx, d.pool).Exec(ctx,
+			`INSERT INTO hasura_processed_events (event_id, trigger_name, table_name)
+			 VALUES ($1, $2, $3)
+			 ON CONFLICT (event_id) DO NOTHING`, e.ID, e.Trigger.Name, table)
+		if err != nil {
+			return domain.NewDatabaseError(err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+		processed = true
+		return handle(ctx, e, session)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if !processed {
+		d.logger.Info("duplicate hasura event skipped",
+			slog.String("event_id", e.ID),
+			slog.String("trigger", e.Trigger.Name),
+		)
+	}
+	return processed, nil
+}
+
+// Prune is the handler for jobs.HasuraEventPruneArgs.
+func (d *Dispatcher) Prune(ctx context.Context, _ *jobs.Job, _ jobs.HasuraEventPruneArgs) error {
+	tag, err := d.pool.Exec(ctx,
+		`DELETE FROM hasura_processed_events WHERE processed_at < $1`, time.Now().Add(-d.keep))
+	if err != nil {
+		return fmt.Errorf("pruning hasura event log: %w", err)
+	}
+	if n := tag.RowsAffected(); n > 0 {
+		d.logger.Info("hasura event log pruned", slog.Int64("events", n))
+	}
+	return nil
+}
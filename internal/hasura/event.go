@@ -0,0 +1,52 @@
+// internal/hasura/event.go
+
+// Package hasura receives Hasura event triggers.
+//
+// Hasura captures row changes on tracked tables (see the event_triggers in
+// hasura/metadata) and POSTs them to /api/v1/events/hasura, retrying until
+// it gets a 2xx response. The Dispatcher decodes the row into the Go type
+// registered for its table and runs the handler in a transaction that also
+// records the event ID, so a redelivered event is skipped and handlers
+// doing their work through the context see each event exactly once.
+package hasura
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is the envelope of an event trigger delivery.
+type Event struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Trigger   struct {
+		Name string `json:"name"`
+	} `json:"trigger"`
+	Table struct {
+		Schema string `json:"schema"`
+		Name   string `json:"name"`
+	} `json:"table"`
+	DeliveryInfo struct {
+		MaxRetries   int `json:"max_retries"`
+		CurrentRetry int `json:"current_retry"`
+	} `json:"delivery_info"`
+	Event struct {
+		Op               string            `json:"op"`
+		SessionVariables map[string]string `json:"session_variables"`
+		Data             struct {
+			Old json.RawMessage `json:"old"`
+			New json.RawMessage `json:"new"`
+		} `json:"data"`
+	} `json:"event"`
+}
+
+// TableName returns the schema-qualified name of the changed table.
+func (e *Event) TableName() string {
+	return e.Table.Schema + "." + e.Table.Name
+}
+
+// Session parses the session variables of the user who made the change.
+// Hasura omits them for admin and direct database changes.
+func (e *Event) Session() Session {
+	return ParseSession(e.Event.SessionVariables)
+}
@@ -0,0 +1,38 @@
+// internal/hasura/session.go
+package hasura
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Session variables Hasura forwards with events and Action calls.
+const (
+	SessionRole         = "x-hasura-role"
+	SessionUserID       = "x-hasura-user-id"
+	SessionEnterpriseID = "x-hasura-enterprise-id"
+)
+
+// Session is the parsed form of Hasura session variables.
+type Session struct {
+	Role         string
+	UserID       int64 // 0 for anonymous and admin sessions
+	EnterpriseID int64 // 0 when the user has no enterprise
+}
+
+// ParseSession parses Hasura session variables, as forwarded with events and
+// Action calls. Their names are case-insensitive.
+func ParseSession(vars map[string]string) Session {
+	var s Session
+	for k, v := range vars {
+		switch strings.ToLower(k) {
+		case SessionRole:
+			s.Role = v
+		case SessionUserID:
+			s.UserID, _ = strconv.ParseInt(v, 10, 64) //nolint:errcheck // unparsable means unknown
+		case SessionEnterpriseID:
+			s.EnterpriseID, _ = strconv.ParseInt(v, 10, 64) //nolint:errcheck // unparsable means unknown
+		}
+	}
+	return s
+}
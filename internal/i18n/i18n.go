@@ -0,0 +1,232 @@
+// internal/i18n/i18n.go
+//
+// Package i18n translates the messages the API sends to clients: error
+// messages by domain.ErrorCode and field-level validation messages by rule.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"my-application/internal/domain"
+)
+
+// SourceLocale is the language messages are written in in the code. Its
+// catalog is the reference the other catalogs must match key for key.
+const SourceLocale = "en"
+
+// Catalogs live in locales/<locale>.json as a flat map of key to message.
+// Keys are "error.<CODE>" for every domain.ErrorCode, "validation.<rule>"
+// for every validation rule and "field.<name>" for the label of a JSON
+// field. Messages refer to parameters as {name}.
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	catalogs map[string]map[string]string // locale → key → message
+	locales  []string                     // SourceLocale first, then sorted
+	matcher  language.Matcher
+)
+
+// The catalogs are embedded, so a broken one is a build defect; failing at
+// startup beats sending untranslated messages.
+func init() {
+	var err error
+	if catalogs, err = load(localeFS); err != nil {
+		panic(err)
+	}
+	for locale := range catalogs {
+		if locale != SourceLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	locales = append([]string{SourceLocale}, locales...)
+
+	tags := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tags[i] = language.Make(locale)
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+func load(fsys fs.FS) (map[string]map[string]string, error) {
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("i18n: parsing %s: %w", file, err)
+		}
+		loaded[strings.TrimSuffix(path.Base(file), ".json")] = catalog
+	}
+	if _, ok := loaded[SourceLocale]; !ok {
+		return nil, fmt.Errorf("i18n: no catalog for source locale %q", SourceLocale)
+	}
+	return loaded, nil
+}
+
+// Locales returns the locales with a catalog, SourceLocale first.
+func Locales() []string {
+	return slices.Clone(locales)
+}
+
+// Catalog returns a copy of locale's catalog, or nil if there is none.
+func Catalog(locale string) map[string]string {
+	catalog, ok := catalogs[locale]
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(catalog))
+	for k, v := range catalog {
+		out[k] = v
+	}
+	return out
+}
+
+// Match returns the supported locale that best fits preference, an
+// Accept-Language header value or a single language tag such as "es-MX".
+// It reports false when preference is empty, malformed or names no
+// supported language.
+func Match(preference string) (string, bool) {
+	tags, _, err := language.ParseAcceptLanguage(preference)
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return locales[index], true
+}
+
+// T returns the message for key in locale with params substituted. Lookup
+// falls back from "es-MX" to "es" to SourceLocale; a key missing everywhere
+// is returned as is.
+func T(locale, key string, params map[string]string) string {
+	for _, l := range fallbacks(locale) {
+		if msg, ok := catalogs[l][key]; ok {
+			return substitute(msg, params)
+		}
+	}
+	return key
+}
+
+// Message returns the message to send for an error with code. In
+// SourceLocale that is message itself, which is usually more specific than
+// the catalog's; other locales get the catalog's translation of the code, or
+// message when the locale has none.
+func Message(locale string, code domain.ErrorCode, message string) string {
+	for _, l := range fallbacks(locale) {
+		if l == SourceLocale {
+			break
+		}
+		if msg, ok := catalogs[l]["error."+string(code)]; ok {
+			return msg
+		}
+	}
+	return message
+}
+
+// Details returns appErr's field-level details in locale. Fields with a
+// Violation are rendered from the catalog; others keep their Details text.
+func Details(appErr *domain.AppError, locale string) map[string]string {
+	if len(appErr.Details) == 0 {
+		return nil
+	}
+	details := make(map[string]string, len(appErr.Details))
+	for field, msg := range appErr.Details {
+		if v, ok := appErr.Violations[field]; ok {
+			msg = render(locale, field, v)
+		}
+		details[field] = msg
+	}
+	return details
+}
+
+// Violations renders violations in locale, keyed by field like Details.
+func Violations(violations map[string]domain.Violation, locale string) map[string]string {
+	if len(violations) == 0 {
+		return nil
+	}
+	msgs := make(map[string]string, len(violations))
+	for field, v := range violations {
+		msgs[field] = render(locale, field, v)
+	}
+	return msgs
+}
+
+// ValidationError returns a validation error for violations, keyed by JSON
+// field name, with Details rendered in SourceLocale.
+func ValidationError(violations map[string]domain.Violation) *domain.AppError {
+	details := make(map[string]string, len(violations))
+	for field, v := range violations {
+		details[field] = render(SourceLocale, field, v)
+	}
+	appErr := domain.NewValidationError("validation failed", details)
+	appErr.Violations = violations
+	return appErr
+}
+
+// LocaleViolation is the violation of a field that must name a supported
+// locale.
+func LocaleViolation() domain.Violation {
+	return domain.Violation{Rule: "locale", Params: map[string]string{"values": strings.Join(locales, ", ")}}
+}
+
+// IsSupported reports whether locale has a catalog of its own.
+func IsSupported(locale string) bool {
+	return slices.Contains(locales, locale)
+}
+
+// render renders a violation of field, labelling the field from the
+// catalog's "field.<name>" or with its JSON name.
+func render(locale, field string, v domain.Violation) string {
+	params := make(map[string]string, len(v.Params)+1)
+	for k, val := range v.Params {
+		params[k] = val
+	}
+	label := T(locale, "field."+field, nil)
+	if label == "field."+field {
+		label = field
+	}
+	params["field"] = label
+	return T(locale, "validation."+v.Rule, params)
+}
+
+// fallbacks returns the catalogs to try for locale, most specific first.
+func fallbacks(locale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, base)
+	}
+	return append(candidates, SourceLocale)
+}
+
+func substitute(msg string, params map[string]string) string {
+	if len(params) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, 2*len(params))
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
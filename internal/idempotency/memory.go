@@ -0,0 +1,58 @@
+// internal/idempotency/memory.go
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Compile-time interface check.
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store in process memory, for tests and single-process
+// development. Expired records are dropped when their key is claimed again.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[Key]*memoryRecord
+	now     func() time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[Key]*memoryRecord), now: time.Now}
+}
+
+func (s *MemoryStore) Claim(_ context.Context, key Key, fingerprint string, lockedUntil time.Time) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[key]; ok && s.now().Before(r.expiresAt) {
+		record := r.Record
+		return &record, nil
+	}
+	s.records[key] = &memoryRecord{Record: Record{Fingerprint: fingerprint}, expiresAt: lockedUntil}
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key Key, resp *Response, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[key]; ok && r.Response == nil {
+		r.Response, r.expiresAt = resp, expiresAt
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[key]; ok && r.Response == nil {
+		delete(s.records, key)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// internal/idempotency/postgres.go
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/jobs"
+)
+
+// Compile-time interface check.
+var _ Store = (*PostgresStore)(nil)
+
+// PostgresStore is a Store in the idempotency_keys table, shared by every
+// API replica.
+type PostgresStore struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewPostgresStore creates a PostgresStore.
+func NewPostgresStore(pool *pgxpool.Pool, logger *slog.Logger) *PostgresStore {
+	return &PostgresStore{pool: pool, logger: logger}
+}
+
+func (s *PostgresStore) Claim(ctx context.Context, key Key, fingerprint string, lockedUntil time.Time) (*Record, error) {
+	// An expired record is taken over in place; a live one is left alone and
+	// read instead. The read can find nothing if the record's claim was
+	// released in between, so the claim is tried again.
+	for range 3 {
+		tag, err := s.pool.Exec(ctx,
+			`INSERT INTO idempotency_keys (scope, idempotency_key, fingerprint, expires_at)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (scope, idempotency_key) DO UPDATE
+			 SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, response = NULL,
+			     expires_at = EXCLUDED.expires_at, created_at = NOW()
+			 WHERE idempotency_keys.expires_at <= NOW()`,
+			key.Scope, key.Value, fingerprint, lockedUntil)
+		if err != nil {
+			return nil, fmt.Errorf("claiming idempotency key: %w", err)
+		}
+		if tag.RowsAffected() == 1 {
+			return nil, nil
+		}
+
+		var (
+			record   Record
+			response []byte
+		)
+		err = s.pool.QueryRow(ctx,
+			`SELECT fingerprint, response FROM idempotency_keys
+			 WHERE scope = $1 AND idempotency_key = $2 AND expires_at > NOW()`,
+			key.Scope, key.Value).Scan(&record.Fingerprint, &response)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading idempotency key: %w", err)
+		}
+		if response != nil {
+			record.Response = new(Response)
+			if err := json.Unmarshal(response, record.Response); err != nil {
+				return nil, fmt.Errorf("decoding stored response: %w", err)
+			}
+		}
+		return &record, nil
+	}
+	return nil, fmt.Errorf("claiming idempotency key: contended")
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, key Key, resp *Response, expiresAt time.Time) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("encoding response: %w", err)
+	}
+	_, err = s.pool.Exec(ctx,
+		`UPDATE idempotency_keys SET status_code = $3, response = $4, expires_at = $5
+		 WHERE scope = $1 AND idempotency_key = $2 AND status_code IS NULL`,
+		key.Scope, key.Value, resp.Status, data, expiresAt)
+	if err != nil {
+		return fmt.Errorf("storing response: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key Key) error {
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2 AND status_code IS NULL`,
+		key.Scope, key.Value)
+	if err != nil {
+		return fmt.Errorf("releasing idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Prune is the handler for jobs.IdempotencyPruneArgs.
+func (s *PostgresStore) Prune(ctx context.Context, _ *jobs.Job, _ jobs.IdempotencyPruneArgs) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return fmt.Errorf("pruning idempotency keys: %w", err)
+	}
+	if n := tag.RowsAffected(); n > 0 {
+		s.logger.Info("idempotency keys pruned", slog.Int64("keys", n))
+	}
+	return nil
+}
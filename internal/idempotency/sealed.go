@@ -0,0 +1,84 @@
+// internal/idempotency/sealed.go
+package idempotency
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Compile-time interface check.
+var _ Store = (*SealedStore)(nil)
+
+// SealedStore encrypts response bodies with AES-GCM before they reach the
+// underlying Store, since they can carry credentials (the tokens returned
+// by /auth/register) and personal data.
+type SealedStore struct {
+	store Store
+	aead  cipher.AEAD
+}
+
+// NewSealedStore wraps store, deriving the encryption key from secret.
+// Anyone holding secret can read the stored bodies, so it must be kept
+// like the JWT signing secret, which is what the API passes.
+func NewSealedStore(store Store, secret string) (*SealedStore, error) {
+	if secret == "" {
+		return nil, errors.New("idempotency: empty encryption secret")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("idempotency response encryption"))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: %w", err)
+	}
+	return &SealedStore{store: store, aead: aead}, nil
+}
+
+func (s *SealedStore) Claim(ctx context.Context, key Key, fingerprint string, lockedUntil time.Time) (*Record, error) {
+	record, err := s.store.Claim(ctx, key, fingerprint, lockedUntil)
+	if err != nil || record == nil || record.Response == nil {
+		return record, err
+	}
+
+	body := record.Response.Body
+	size := s.aead.NonceSize()
+	if len(body) < size {
+		return nil, errors.New("opening stored response: too short")
+	}
+	// The key binds the body to its record, so one can't be swapped for another.
+	plain, err := s.aead.Open(nil, body[:size], body[size:], additionalData(key))
+	if err != nil {
+		return nil, fmt.Errorf("opening stored response: %w", err)
+	}
+	resp := *record.Response
+	resp.Body = plain
+	return &Record{Fingerprint: record.Fingerprint, Response: &resp}, nil
+}
+
+func (s *SealedStore) Complete(ctx context.Context, key Key, resp *Response, expiresAt time.Time) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("sealing response: %w", err)
+	}
+	sealed := *resp
+	sealed.Body = s.aead.Seal(nonce, nonce, resp.Body, additionalData(key))
+	return s.store.Complete(ctx, key, &sealed, expiresAt)
+}
+
+func (s *SealedStore) Release(ctx context.Context, key Key) error {
+	return s.store.Release(ctx, key)
+}
+
+func additionalData(key Key) []byte {
+	return []byte(key.Scope + "\x00" + key.Value)
+}
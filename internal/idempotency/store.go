@@ -0,0 +1,51 @@
+// internal/idempotency/store.go
+//
+// Package idempotency stores the responses to requests sent with an
+// Idempotency-Key header, so that a client retrying a request it didn't get
+// an answer to receives the first response instead of repeating the
+// change. A key is claimed while its first request runs; concurrent
+// duplicates see the claim and are turned away rather than run twice.
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Key identifies a request: the client's Idempotency-Key within a scope,
+// such as the authenticated user, so that clients can't collide.
+type Key struct {
+	Scope string
+	Value string
+}
+
+// Response is a stored response, replayed to retries.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Record is what a Store holds for a key: the fingerprint of the request
+// that claimed it and, once that request has completed, its response.
+type Record struct {
+	Fingerprint string
+	Response    *Response // nil while the request is in flight
+}
+
+// Store persists claims and responses. Implementations must make Claim
+// atomic, so that of two concurrent requests with the same key exactly one
+// gets the claim.
+type Store interface {
+	// Claim records that a request with fingerprint is in flight under key
+	// until lockedUntil, and returns nil. When key has a live record, a
+	// response not yet expired or a claim not yet timed out, Claim returns
+	// it and changes nothing.
+	Claim(ctx context.Context, key Key, fingerprint string, lockedUntil time.Time) (*Record, error)
+	// Complete stores the response to key's claimed request until expiresAt.
+	Complete(ctx context.Context, key Key, resp *Response, expiresAt time.Time) error
+	// Release drops key's claim without a response, so the request can be
+	// retried.
+	Release(ctx context.Context, key Key) error
+}
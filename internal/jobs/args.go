@@ -0,0 +1,175 @@
+// internal/jobs/args.go
+package jobs
+
+import "fmt"
+
+// Queues used by the application.
+const (
+	QueueEmail       = "email"
+	QueueMaintenance = "maintenance"
+	QueueWebhooks    = "webhooks"
+)
+
+// ExportBuildArgs assembles the archive of one personal data export.
+type ExportBuildArgs struct {
+	ExportID int64 `json:"export_id"`
+}
+
+// Kind implements Args.
+func (ExportBuildArgs) Kind() string { return "export.build" }
+
+// ExportBuildOptions returns the enqueue options for building an export:
+// at most one active build per export and a small retry budget.
+func ExportBuildOptions(exportID int64) []Option {
+	return []Option{
+		WithUniqueKey(fmt.Sprintf("export:%d", exportID)),
+		WithMaxAttempts(5),
+	}
+}
+
+// ExportMaintenanceArgs removes expired export archives and re-enqueues
+// pending exports whose build job was lost.
+type ExportMaintenanceArgs struct{}
+
+// Kind implements Args.
+func (ExportMaintenanceArgs) Kind() string { return "export.maintenance" }
+
+// UserImportArgs runs a user import too large to run within its request.
+type UserImportArgs struct {
+	ImportID int64 `json:"import_id"`
+}
+
+// Kind implements Args.
+func (UserImportArgs) Kind() string { return "user.import" }
+
+// UserImportOptions returns the enqueue options for running an import: at
+// most one active run per import.
+func UserImportOptions(importID int64) []Option {
+	return []Option{
+		WithUniqueKey(fmt.Sprintf("user_import:%d", importID)),
+		WithMaxAttempts(5),
+	}
+}
+
+// RetentionEnforceArgs applies every active retention policy once.
+type RetentionEnforceArgs struct{}
+
+// Kind implements Args.
+func (RetentionEnforceArgs) Kind() string { return "retention.enforce" }
+
+// EmailSendArgs renders an email template and delivers it. Data must not
+// hold secrets beyond what the message itself contains: payloads are stored
+// in the jobs table until pruned.
+type EmailSendArgs struct {
+	To       []string               `json:"to"`
+	Template string                 `json:"template"`
+	Locale   string                 `json:"locale"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// Kind implements Args.
+func (EmailSendArgs) Kind() string { return "email.send" }
+
+// EmailSendOptions returns the enqueue options for an email.
+func EmailSendOptions() []Option {
+	return []Option{WithQueue(QueueEmail)}
+}
+
+// InvitationSendArgs emails an invitation its link. The worker mints the
+// link's token when it sends, so the token is never stored in a job.
+type InvitationSendArgs struct {
+	InvitationID int64 `json:"invitation_id"`
+}
+
+// Kind implements Args.
+func (InvitationSendArgs) Kind() string { return "invitation.send" }
+
+// InvitationSendOptions returns the enqueue options for an invitation email.
+// The key names the token the invitation was given when it was created or
+// resent, so each of those sends once.
+func InvitationSendOptions(inv int64, tokenHash []byte) []Option {
+	return []Option{WithQueue(QueueEmail), WithUniqueKey(fmt.Sprintf("invitation:%d:%x", inv, tokenHash[:8]))}
+}
+
+// NotificationFanoutArgs delivers one source event to every interested user.
+// The database enqueues these from triggers on incidents and stories (see
+// migration 000015), so the JSON shape must stay in sync with
+// enqueue_notification_fanout().
+type NotificationFanoutArgs struct {
+	Type     string `json:"type"`
+	EntityID int64  `json:"entity_id"`
+}
+
+// Kind implements Args.
+func (NotificationFanoutArgs) Kind() string { return "notification.fanout" }
+
+// RobotOfflineCheckArgs looks for robots whose heartbeat has gone stale.
+type RobotOfflineCheckArgs struct{}
+
+// Kind implements Args.
+func (RobotOfflineCheckArgs) Kind() string { return "robot.offline_check" }
+
+// PushSendArgs sends a push notification to every device of a user.
+type PushSendArgs struct {
+	UserID int64             `json:"user_id"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data"`
+}
+
+// Kind implements Args.
+func (PushSendArgs) Kind() string { return "push.send" }
+
+// OutboxDeliverArgs hands one outbox event to one subscriber.
+type OutboxDeliverArgs struct {
+	EventID    int64  `json:"event_id"`
+	Subscriber string `json:"subscriber"`
+}
+
+// Kind implements Args.
+func (OutboxDeliverArgs) Kind() string { return "outbox.deliver" }
+
+// OutboxDeliverOptions returns the enqueue options for a delivery. The unique
+// key keeps a relay that is retried after a crash from queueing it twice.
+func OutboxDeliverOptions(eventID int64, subscriber string) []Option {
+	return []Option{WithUniqueKey(fmt.Sprintf("outbox:%d:%s", eventID, subscriber))}
+}
+
+// OutboxPruneArgs deletes relayed outbox events past their retention.
+type OutboxPruneArgs struct{}
+
+// Kind implements Args.
+func (OutboxPruneArgs) Kind() string { return "outbox.prune" }
+
+// WebhookDeliverArgs sends one webhook delivery.
+type WebhookDeliverArgs struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// Kind implements Args.
+func (WebhookDeliverArgs) Kind() string { return "webhook.deliver" }
+
+// WebhookDeliverOptions returns the enqueue options for a delivery: the
+// webhooks queue, and 16 attempts, which spread retries over about four
+// hours.
+func WebhookDeliverOptions(deliveryID int64) []Option {
+	return []Option{
+		WithQueue(QueueWebhooks),
+		WithMaxAttempts(16),
+		WithUniqueKey(fmt.Sprintf("webhook:%d", deliveryID)),
+	}
+}
+
+// HasuraEventPruneArgs deletes IDs of processed Hasura events past their
+// retention.
+type HasuraEventPruneArgs struct{}
+
+// Kind implements Args.
+func (HasuraEventPruneArgs) Kind() string { return "hasura.event_prune" }
+
+// IdempotencyPruneArgs deletes expired idempotency keys and their stored
+// responses.
+type IdempotencyPruneArgs struct{}
+
+// Kind implements Args.
+func (IdempotencyPruneArgs) Kind() string { return "idempotency.prune" }
@@ -0,0 +1,69 @@
+// internal/jobs/client.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/pkg/database"
+)
+
+// DBTX is satisfied by *pgxpool.Pool, pgx.Tx and *pgx.Conn, which lets a job
+// be inserted in the same transaction as the write that caused it.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// Compile-time interface check.
+var _ Enqueuer = (*Client)(nil)
+
+// Client inserts jobs into the queue.
+type Client struct {
+	pool *pgxpool.Pool
+}
+
+// NewClient creates a Client.
+func NewClient(pool *pgxpool.Pool) *Client {
+	return &Client{pool: pool}
+}
+
+// Enqueue inserts a job in the transaction carried by ctx (see
+// database.TxManager), or on its own connection when there is none.
+func (c *Client) Enqueue(ctx context.Context, args Args, opts ...Option) (int64, error) {
+	return c.EnqueueTx(ctx, database.Conn(ctx, c.pool), args, opts...)
+}
+
+// EnqueueTx inserts a job through db, typically an open pgx.Tx, so that the
+// job only becomes visible if the surrounding transaction commits.
+func (c *Client) EnqueueTx(ctx context.Context, db DBTX, args Args, opts ...Option) (int64, error) {
+	o := buildOptions(opts)
+
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return 0, fmt.Errorf("encoding %s job payload: %w", args.Kind(), err)
+	}
+
+	var uniqueKey *string
+	if o.UniqueKey != "" {
+		uniqueKey = &o.UniqueKey
+	}
+
+	// enqueue_job runs as the table owner, so tenant-scoped transactions,
+	// which have no privileges on jobs, can enqueue too (migration 000032).
+	var id *int64
+	err = db.QueryRow(ctx, `SELECT enqueue_job($1, $2, $3, $4, $5, $6)`,
+		o.Queue, args.Kind(), payload, o.MaxAttempts, o.RunAt, uniqueKey).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("enqueueing %s job: %w", args.Kind(), err)
+	}
+	if id == nil {
+		return 0, nil // an identical unique job is already queued
+	}
+	return *id, nil
+}
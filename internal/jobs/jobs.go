@@ -0,0 +1,141 @@
+// internal/jobs/jobs.go
+
+// Package jobs implements a durable job queue stored in PostgreSQL.
+//
+// Jobs are fetched with SELECT ... FOR UPDATE SKIP LOCKED so any number of
+// worker processes can share a queue. Failed jobs are retried with
+// exponential backoff until max_attempts, after which they are kept in the
+// "dead" state for inspection. A running job's lock is renewed while its
+// handler runs; jobs whose lock goes stale, because their worker died, are
+// rescued and run again.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// DefaultQueue is used when a job does not name a queue.
+const DefaultQueue = "default"
+
+// Default retry settings.
+const (
+	DefaultMaxAttempts = 10
+	maxBackoff         = time.Hour
+)
+
+// Job statuses.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusDead      = "dead"
+)
+
+// Args is implemented by every job payload. Kind identifies the handler.
+type Args interface {
+	Kind() string
+}
+
+// Job is a claimed job as seen by a handler.
+type Job struct {
+	ID          int64
+	Queue       string
+	Kind        string
+	Payload     []byte
+	Attempts    int // including the current attempt
+	MaxAttempts int
+	RunAt       time.Time
+	CreatedAt   time.Time
+}
+
+// Options control how a job is enqueued.
+type Options struct {
+	Queue       string
+	RunAt       time.Time
+	MaxAttempts int
+	// UniqueKey deduplicates jobs: while a pending or running job holds the
+	// key, enqueueing another one with the same key is a no-op.
+	UniqueKey string
+}
+
+// Option configures Options.
+type Option func(*Options)
+
+// WithQueue routes the job to a named queue.
+func WithQueue(queue string) Option {
+	return func(o *Options) { o.Queue = queue }
+}
+
+// WithRunAt schedules the job for a specific time.
+func WithRunAt(t time.Time) Option {
+	return func(o *Options) { o.RunAt = t }
+}
+
+// WithDelay schedules the job to run after d.
+func WithDelay(d time.Duration) Option {
+	return func(o *Options) { o.RunAt = time.Now().Add(d) }
+}
+
+// WithMaxAttempts overrides how often the job is tried before it is dead.
+func WithMaxAttempts(n int) Option {
+	return func(o *Options) { o.MaxAttempts = n }
+}
+
+// WithUniqueKey deduplicates the job on key.
+func WithUniqueKey(key string) Option {
+	return func(o *Options) { o.UniqueKey = key }
+}
+
+func buildOptions(opts []Option) Options {
+	o := Options{Queue: DefaultQueue, MaxAttempts: DefaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.RunAt.IsZero() {
+		o.RunAt = time.Now()
+	}
+	return o
+}
+
+// Enqueuer is the contract services depend on to schedule background work.
+type Enqueuer interface {
+	// Enqueue inserts a job and returns its ID, or 0 when an identical
+	// unique job is already pending.
+	Enqueue(ctx context.Context, args Args, opts ...Option) (int64, error)
+}
+
+// permanentError marks a failure that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job goes straight to the dead state.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Backoff returns the delay before retrying after the given attempt:
+// 1s, 2s, 4s, ... capped at one hour, with ±10% jitter.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := maxBackoff
+	if attempt <= 12 {
+		d = min(time.Second<<(attempt-1), maxBackoff)
+	}
+	jitter := time.Duration(rand.Int64N(int64(d)/5+1)) - d/10 //nolint:gosec // jitter does not need a CSPRNG
+	return d + jitter
+}
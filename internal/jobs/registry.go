@@ -0,0 +1,48 @@
+// internal/jobs/registry.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// HandlerFunc processes one job with its decoded arguments. Returning an
+// error schedules a retry unless the error is wrapped with Permanent.
+type HandlerFunc[T Args] func(ctx context.Context, job *Job, args T) error
+
+// handler is the untyped form stored in the registry.
+type handler func(ctx context.Context, job *Job) error
+
+// Registry maps job kinds to their handlers.
+type Registry struct {
+	handlers map[string]handler
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]handler)}
+}
+
+// Register adds a typed handler for the kind of T. It panics on duplicate
+// registration, which is always a wiring bug.
+func Register[T Args](r *Registry, fn HandlerFunc[T]) {
+	var zero T
+	kind := zero.Kind()
+	if _, exists := r.handlers[kind]; exists {
+		panic(fmt.Sprintf("jobs: handler for %q registered twice", kind))
+	}
+
+	r.handlers[kind] = func(ctx context.Context, job *Job) error {
+		var args T
+		if err := json.Unmarshal(job.Payload, &args); err != nil {
+			return Permanent(fmt.Errorf("decoding %s payload: %w", kind, err))
+		}
+		return fn(ctx, job, args)
+	}
+}
+
+func (r *Registry) lookup(kind string) (handler, bool) {
+	h, ok := r.handlers[kind]
+	return h, ok
+}
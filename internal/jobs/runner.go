@@ -0,0 +1,404 @@
+// internal/jobs/runner.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// statusUpdateTimeout bounds the bookkeeping query after a job finishes, which
+// runs even when the work context has already been cancelled.
+const statusUpdateTimeout = 10 * time.Second
+
+// defaultMaintenanceInterval is how often stale jobs are rescued and old
+// ones pruned unless RunnerConfig says otherwise.
+const defaultMaintenanceInterval = time.Minute
+
+// lockHeld matches a job ($1) still locked by this runner ($2) for the
+// same attempt ($3). Once a job is rescued and claimed again, the first
+// run no longer holds it and its outcome is discarded.
+const lockHeld = `id = $1 AND status = 'running' AND locked_by = $2 AND attempts = $3`
+
+// RunnerConfig holds settings for a Runner.
+type RunnerConfig struct {
+	Queues        map[string]int // queue name → maximum concurrent jobs
+	PollInterval  time.Duration  // idle wait between fetches on an empty queue
+	StaleAfter    time.Duration  // running jobs whose lock was not renewed for this long are rescued
+	KeepCompleted time.Duration  // completed jobs older than this are deleted
+	// MaintenanceInterval is how often stale jobs are rescued and completed
+	// ones pruned; one minute when zero.
+	MaintenanceInterval time.Duration
+}
+
+// periodicJob is a job enqueued once per interval across all replicas.
+type periodicJob struct {
+	interval time.Duration
+	args     Args
+	opts     []Option
+}
+
+// Runner fetches and executes jobs for a set of queues.
+type Runner struct {
+	pool     *pgxpool.Pool
+	registry *Registry
+	config   RunnerConfig
+	workerID string
+	periodic []periodicJob
+	logger   *slog.Logger
+
+	wg          sync.WaitGroup
+	fetchCancel context.CancelFunc
+	workCancel  context.CancelFunc
+}
+
+// NewRunner creates a Runner.
+func NewRunner(pool *pgxpool.Pool, registry *Registry, config RunnerConfig, logger *slog.Logger) *Runner {
+	host, _ := os.Hostname() //nolint:errcheck // the worker ID is informational only
+	return &Runner{
+		pool:     pool,
+		registry: registry,
+		config:   config,
+		workerID: fmt.Sprintf("%s-%d", host, os.Getpid()),
+		logger:   logger,
+	}
+}
+
+// AddPeriodic schedules args to be enqueued once per interval. Intervals are
+// aligned to wall-clock buckets so several replicas enqueue it only once.
+// Must be called before Start.
+func (r *Runner) AddPeriodic(interval time.Duration, args Args, opts ...Option) {
+	r.periodic = append(r.periodic, periodicJob{interval: interval, args: args, opts: opts})
+}
+
+// Start launches the fetch loops, periodic schedulers and maintenance. It
+// returns immediately; call Stop to shut down.
+func (r *Runner) Start(ctx context.Context) {
+	fetchCtx, fetchCancel := context.WithCancel(ctx)
+	// In-flight jobs keep running after Stop until the shutdown deadline.
+	workCtx, workCancel := context.WithCancel(context.WithoutCancel(ctx))
+	r.fetchCancel = fetchCancel
+	r.workCancel = workCancel
+
+	for queue, concurrency := range r.config.Queues {
+		for i := 0; i < concurrency; i++ {
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				r.fetchLoop(fetchCtx, workCtx, queue)
+			}()
+		}
+		r.logger.Info("job queue started", slog.String("queue", queue), slog.Int("concurrency", concurrency))
+	}
+
+	for _, p := range r.periodic {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.periodicLoop(fetchCtx, p)
+		}()
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.maintenanceLoop(fetchCtx)
+	}()
+}
+
+// Stop stops fetching new jobs and waits for in-flight jobs to finish. If ctx
+// expires first, in-flight jobs are cancelled and an error is returned; they
+// will be rescued and retried once StaleAfter has passed without their lock
+// being renewed.
+func (r *Runner) Stop(ctx context.Context) error {
+	r.fetchCancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.workCancel()
+		return nil
+	case <-ctx.Done():
+		r.workCancel()
+		return fmt.Errorf("jobs: shutdown timed out with jobs in flight: %w", ctx.Err())
+	}
+}
+
+func (r *Runner) fetchLoop(fetchCtx, workCtx context.Context, queue string) {
+	for fetchCtx.Err() == nil {
+		job, err := r.fetch(fetchCtx, queue)
+		if err != nil {
+			if fetchCtx.Err() == nil {
+				r.logger.Error("fetching job", slog.String("queue", queue), slog.String("error", err.Error()))
+			}
+			sleep(fetchCtx, r.config.PollInterval)
+			continue
+		}
+		if job == nil {
+			sleep(fetchCtx, r.config.PollInterval)
+			continue
+		}
+		r.execute(workCtx, job)
+	}
+}
+
+// fetch claims the next due job of queue, or returns nil when there is none.
+func (r *Runner) fetch(ctx context.Context, queue string) (*Job, error) {
+	query := `UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_at = NOW(), locked_by = $2
+			  WHERE id = (
+				  SELECT id FROM jobs
+				  WHERE queue = $1 AND status = 'pending' AND run_at <= NOW()
+				  ORDER BY run_at, id
+				  LIMIT 1
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING id, queue, kind, payload, attempts, max_attempts, run_at, created_at`
+
+	var job Job
+	err := r.pool.QueryRow(ctx, query, queue, r.workerID).Scan(
+		&job.ID, &job.Queue, &job.Kind, &job.Payload, &job.Attempts, &job.MaxAttempts, &job.RunAt, &job.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *Runner) execute(ctx context.Context, job *Job) {
+	log := r.logger.With(
+		slog.Int64("job_id", job.ID),
+		slog.String("kind", job.Kind),
+		slog.String("queue", job.Queue),
+		slog.Int("attempt", job.Attempts),
+	)
+	start := time.Now()
+
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	stopHeartbeat := r.heartbeat(jobCtx, cancelJob, job, log)
+	err := r.run(jobCtx, job)
+	stopHeartbeat()
+	cancelJob()
+
+	updateCtx, cancel := context.WithTimeout(context.Background(), statusUpdateTimeout)
+	defer cancel()
+
+	if err == nil {
+		if held, dbErr := r.finish(updateCtx, job,
+			`status = 'completed', completed_at = NOW(), locked_at = NULL, last_error = NULL`,
+		); dbErr != nil {
+			log.Error("marking job completed", slog.String("error", dbErr.Error()))
+		} else if !held {
+			log.Warn("job completed after its lock was lost; outcome discarded")
+		} else {
+			log.Info("job completed", slog.Duration("duration", time.Since(start)))
+		}
+		return
+	}
+
+	if IsPermanent(err) || job.Attempts >= job.MaxAttempts {
+		if held, dbErr := r.finish(updateCtx, job,
+			`status = 'dead', locked_at = NULL, last_error = $4`, err.Error(),
+		); dbErr != nil {
+			log.Error("marking job dead", slog.String("error", dbErr.Error()))
+		} else if !held {
+			log.Warn("job failed after its lock was lost; outcome discarded", slog.String("error", err.Error()))
+		} else {
+			log.Error("job moved to dead letter", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	retryAt := time.Now().Add(Backoff(job.Attempts))
+	if held, dbErr := r.finish(updateCtx, job,
+		`status = 'pending', locked_at = NULL, locked_by = NULL, run_at = $4, last_error = $5`, retryAt, err.Error(),
+	); dbErr != nil {
+		log.Error("scheduling job retry", slog.String("error", dbErr.Error()))
+	} else if !held {
+		log.Warn("job failed after its lock was lost; outcome discarded", slog.String("error", err.Error()))
+	} else {
+		log.Warn("job failed, retry scheduled", slog.String("error", err.Error()), slog.Time("retry_at", retryAt))
+	}
+}
+
+// finish applies set (with args from $4 on) to job if this run still holds
+// it, and reports whether it did.
+func (r *Runner) finish(ctx context.Context, job *Job, set string, args ...interface{}) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE jobs SET `+set+` WHERE `+lockHeld,
+		append([]interface{}{job.ID, r.workerID, job.Attempts}, args...)...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// heartbeat renews job's lock while its handler runs, so that a job
+// running longer than StaleAfter is not rescued and run twice. If the lock
+// was lost anyway (the worker stalled past StaleAfter), cancel stops the
+// handler. The returned func stops the heartbeat.
+func (r *Runner) heartbeat(ctx context.Context, cancel context.CancelFunc, job *Job, log *slog.Logger) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(max(r.config.StaleAfter/3, time.Millisecond))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			tag, err := r.pool.Exec(ctx, `UPDATE jobs SET locked_at = NOW() WHERE `+lockHeld,
+				job.ID, r.workerID, job.Attempts)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warn("renewing job lock", slog.String("error", err.Error()))
+				}
+				continue
+			}
+			if tag.RowsAffected() == 0 {
+				log.Warn("job lock lost to a rescue; cancelling the handler")
+				cancel()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// run invokes the handler, converting a panic into a permanent failure.
+func (r *Runner) run(ctx context.Context, job *Job) (err error) {
+	h, ok := r.registry.lookup(job.Kind)
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for kind %q", job.Kind))
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			r.logger.Error("job handler panicked",
+				slog.Int64("job_id", job.ID),
+				slog.String("panic", fmt.Sprintf("%v", rec)),
+				slog.String("stack", string(debug.Stack())),
+			)
+			err = Permanent(fmt.Errorf("handler panicked: %v", rec))
+		}
+	}()
+
+	return h(ctx, job)
+}
+
+func (r *Runner) periodicLoop(ctx context.Context, p periodicJob) {
+	ticker := time.NewTicker(min(p.interval, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		if err := r.enqueuePeriodic(ctx, p); err != nil && ctx.Err() == nil {
+			r.logger.Error("enqueueing periodic job", slog.String("kind", p.args.Kind()), slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueuePeriodic inserts the job for the current interval bucket unless any
+// replica already did, even if that job has since completed.
+func (r *Runner) enqueuePeriodic(ctx context.Context, p periodicJob) error {
+	o := buildOptions(p.opts)
+	bucket := time.Now().Truncate(p.interval)
+	key := fmt.Sprintf("periodic:%s:%d", p.args.Kind(), bucket.Unix())
+
+	payload, err := json.Marshal(p.args)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO jobs (queue, kind, payload, max_attempts, run_at, unique_key)
+			  SELECT $1, $2, $3, $4, NOW(), $5
+			  WHERE NOT EXISTS (SELECT 1 FROM jobs WHERE unique_key = $5)
+			  ON CONFLICT (unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'running') DO NOTHING`
+
+	_, err = r.pool.Exec(ctx, query, o.Queue, p.args.Kind(), payload, o.MaxAttempts, key)
+	return err
+}
+
+func (r *Runner) maintenanceLoop(ctx context.Context) {
+	interval := r.config.MaintenanceInterval
+	if interval <= 0 {
+		interval = defaultMaintenanceInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		rescued, err := r.pool.Exec(ctx,
+			`UPDATE jobs
+			 SET status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+				 locked_at = NULL, locked_by = NULL, run_at = NOW(),
+				 last_error = 'rescued: worker stopped responding'
+			 WHERE status = 'running' AND locked_at < NOW() - $1::INTERVAL`,
+			r.config.StaleAfter,
+		)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.Error("rescuing stale jobs", slog.String("error", err.Error()))
+			}
+			continue
+		}
+		if n := rescued.RowsAffected(); n > 0 {
+			r.logger.Warn("rescued stale jobs", slog.Int64("count", n))
+		}
+
+		if _, err := r.pool.Exec(ctx,
+			`DELETE FROM jobs WHERE status = 'completed' AND completed_at < NOW() - $1::INTERVAL`,
+			r.config.KeepCompleted,
+		); err != nil && ctx.Err() == nil {
+			r.logger.Error("pruning completed jobs", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// sleep waits for d or until ctx is cancelled.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,135 @@
+// internal/outbox/bus.go
+
+// Package outbox relays domain events recorded by services to subscribers.
+//
+// Services append events to the outbox_events table in the same transaction
+// as the change they describe. The Relay turns every new event into one
+// outbox.deliver job per interested subscriber, and the Bus runs each job in
+// a transaction that also records the delivery, so a subscriber sees an
+// event exactly once even when the job is retried. Subscribers must do
+// their database work through the context they are given to benefit from
+// this; side effects outside the database happen at least once and should
+// be keyed on the event ID. Events are not guaranteed to arrive in order.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/pkg/database"
+)
+
+// Handler processes one event for a subscriber. Returning an error rolls
+// back the subscriber's writes and retries the delivery later.
+type Handler func(ctx context.Context, event *domain.DomainEvent) error
+
+type subscriber struct {
+	name   string
+	types  []string
+	handle Handler
+}
+
+// Bus holds the in-process subscribers and delivers events to them.
+type Bus struct {
+	pool   *pgxpool.Pool
+	tx     *database.TxManager
+	subs   map[string]subscriber
+	logger *slog.Logger
+}
+
+// NewBus creates a Bus with no subscribers.
+func NewBus(pool *pgxpool.Pool, logger *slog.Logger) *Bus {
+	return &Bus{
+		pool:   pool,
+		tx:     database.NewTxManager(pool),
+		subs:   make(map[string]subscriber),
+		logger: logger,
+	}
+}
+
+// Subscribe registers handle under a stable name for the given event types.
+// The name is stored with every delivery, so renaming a subscriber makes it
+// receive pending events again. It panics on a duplicate name.
+func (b *Bus) Subscribe(name string, handle Handler, types ...string) {
+	if _, dup := b.subs[name]; dup {
+		panic(fmt.Sprintf("outbox: subscriber %q registered twice", name))
+	}
+	b.subs[name] = subscriber{name: name, types: types, handle: handle}
+}
+
+// subscribersFor returns the names of the subscribers of eventType.
+func (b *Bus) subscribersFor(eventType string) []string {
+	var names []string
+	for _, s := range b.subs {
+		if slices.Contains(s.types, eventType) {
+			names = append(names, s.name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Deliver is the handler for jobs.OutboxDeliverArgs.
+func (b *Bus) Deliver(ctx context.Context, job *jobs.Job, args jobs.OutboxDeliverArgs) error {
+	sub, ok := b.subs[args.Subscriber]
+	if !ok {
+		return jobs.Permanent(fmt.Errorf("outbox: unknown subscriber %q", args.Subscriber))
+	}
+
+	return b.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tx, _ := database.TxFromContext(ctx)
+
+		// Load the event first: recording a delivery of a pruned event would
+		// violate the foreign key.
+		event, err := loadEvent(ctx, tx, args.EventID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil // pruned
+			}
+			return fmt.Errorf("loading event %d: %w", args.EventID, err)
+		}
+
+		tag, err := tx.Exec(ctx,
+			`INSERT INTO outbox_deliveries (event_id, subscriber) VALUES ($1, $2)
+			 ON CONFLICT (event_id, subscriber) DO NOTHING`,
+			args.EventID, args.Subscriber)
+		if err != nil {
+			return fmt.Errorf("recording delivery: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil // already delivered by an earlier attempt
+		}
+
+		if err := sub.handle(ctx, event); err != nil {
+			b.logger.Warn("outbox subscriber failed",
+				slog.String("subscriber", sub.name),
+				slog.Int64("event_id", event.ID),
+				slog.String("event_type", event.Type),
+				slog.Int("attempt", job.Attempts),
+				slog.String("error", err.Error()),
+			)
+			return err
+		}
+		return nil
+	})
+}
+
+func loadEvent(ctx context.Context, db database.DBTX, id int64) (*domain.DomainEvent, error) {
+	var e domain.DomainEvent
+	err := db.QueryRow(ctx,
+		`SELECT id, event_type, aggregate_type, aggregate_id, enterprise_id, payload, occurred_at
+		 FROM outbox_events WHERE id = $1`, id,
+	).Scan(&e.ID, &e.Type, &e.AggregateType, &e.AggregateID, &e.EnterpriseID, &e.Payload, &e.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,125 @@
+// internal/outbox/relay.go
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/jobs"
+)
+
+// RelayConfig holds settings for a Relay.
+type RelayConfig struct {
+	PollInterval time.Duration // wait between polls when the outbox is empty
+	BatchSize    int           // events relayed per transaction
+	KeepRelayed  time.Duration // relayed events older than this are pruned
+}
+
+// Relay moves new outbox events onto the job queue, one delivery job per
+// subscriber. Several workers may run it; each batch is claimed with
+// SKIP LOCKED.
+type Relay struct {
+	pool   *pgxpool.Pool
+	bus    *Bus
+	client *jobs.Client
+	config RelayConfig
+	logger *slog.Logger
+}
+
+// NewRelay creates a Relay.
+func NewRelay(pool *pgxpool.Pool, bus *Bus, client *jobs.Client, config RelayConfig, logger *slog.Logger) *Relay {
+	return &Relay{pool: pool, bus: bus, client: client, config: config, logger: logger}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.RelayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("relaying outbox events", slog.String("error", err.Error()))
+		}
+		if err != nil || n < r.config.BatchSize {
+			t := time.NewTimer(r.config.PollInterval)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+	}
+}
+
+// RelayBatch enqueues deliveries for up to BatchSize events and marks them
+// relayed, all in one transaction. It returns the number of events claimed.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	rows, err := tx.Query(ctx,
+		`SELECT id, event_type FROM outbox_events
+		 WHERE relayed_at IS NULL
+		 ORDER BY id
+		 LIMIT $1
+		 FOR UPDATE SKIP LOCKED`, r.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("claiming events: %w", err)
+	}
+	type pending struct {
+		id        int64
+		eventType string
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (pending, error) {
+		var p pending
+		err := row.Scan(&p.id, &p.eventType)
+		return p, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claiming events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.id
+		for _, name := range r.bus.subscribersFor(e.eventType) {
+			args := jobs.OutboxDeliverArgs{EventID: e.id, Subscriber: name}
+			if _, err := r.client.EnqueueTx(ctx, tx, args, jobs.OutboxDeliverOptions(e.id, name)...); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE outbox_events SET relayed_at = NOW() WHERE id = ANY($1)`, ids); err != nil {
+		return 0, fmt.Errorf("marking events relayed: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing relay: %w", err)
+	}
+
+	r.logger.Debug("outbox events relayed", slog.Int("count", len(events)))
+	return len(events), nil
+}
+
+// Prune is the handler for jobs.OutboxPruneArgs.
+func (r *Relay) Prune(ctx context.Context, _ *jobs.Job, _ jobs.OutboxPruneArgs) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM outbox_events WHERE relayed_at < $1`, time.Now().Add(-r.config.KeepRelayed))
+	if err != nil {
+		return fmt.Errorf("pruning outbox: %w", err)
+	}
+	if n := tag.RowsAffected(); n > 0 {
+		r.logger.Info("outbox pruned", slog.Int64("events", n))
+	}
+	return nil
+}
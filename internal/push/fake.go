@@ -0,0 +1,50 @@
+// internal/push/fake.go
+package push
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Compile-time interface check.
+var _ Sender = (*FakeSender)(nil)
+
+// invalidTokenPrefix lets tests and local clients exercise token cleanup.
+const invalidTokenPrefix = "invalid-"
+
+// FakeSender records messages instead of delivering them. Tokens starting
+// with "invalid-" are rejected with ErrInvalidToken.
+type FakeSender struct {
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewFakeSender creates a FakeSender.
+func NewFakeSender(logger *slog.Logger) *FakeSender {
+	return &FakeSender{logger: logger}
+}
+
+// Send implements Sender.
+func (s *FakeSender) Send(_ context.Context, msg *Message) error {
+	if strings.HasPrefix(msg.Token, invalidTokenPrefix) {
+		return ErrInvalidToken
+	}
+
+	s.mu.Lock()
+	s.sent = append(s.sent, *msg)
+	s.mu.Unlock()
+
+	s.logger.Debug("push message recorded by fake sender", slog.String("title", msg.Title))
+	return nil
+}
+
+// Sent returns a copy of every message sent so far.
+func (s *FakeSender) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.sent...)
+}
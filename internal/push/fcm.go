@@ -0,0 +1,37 @@
+// internal/push/fcm.go
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"my-application/pkg/firebase"
+)
+
+// Compile-time interface check.
+var _ Sender = (*FCMSender)(nil)
+
+// FCMSender delivers messages through Firebase Cloud Messaging.
+type FCMSender struct {
+	client *firebase.Client
+}
+
+// NewFCMSender creates an FCMSender.
+func NewFCMSender(client *firebase.Client) *FCMSender {
+	return &FCMSender{client: client}
+}
+
+// Send implements Sender.
+func (s *FCMSender) Send(ctx context.Context, msg *Message) error {
+	_, err := s.client.SendPush(ctx, firebase.PushMessage{
+		Token: msg.Token,
+		Title: msg.Title,
+		Body:  msg.Body,
+		Data:  msg.Data,
+	})
+	if errors.Is(err, firebase.ErrTokenUnregistered) {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+// internal/push/push.go
+
+// Package push delivers push notifications to device tokens through an
+// FCM-compatible Sender. FCMSender talks to Firebase Cloud Messaging;
+// FakeSender records messages locally for development and tests.
+package push
+
+import (
+	"context"
+	"errors"
+)
+
+// Sender drivers.
+const (
+	DriverFCM  = "fcm"
+	DriverFake = "fake"
+)
+
+// ErrInvalidToken means the token will never work again; the device should
+// be unregistered rather than retried.
+var ErrInvalidToken = errors.New("push: invalid device token")
+
+// Message is a notification addressed to one device.
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Sender delivers a push message.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
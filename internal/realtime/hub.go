@@ -0,0 +1,204 @@
+// internal/realtime/hub.go
+
+// Package realtime broadcasts database change events to streaming clients.
+// Every API replica listens on the same Postgres channel, keeps a short
+// in-memory buffer for Last-Event-ID replay and fans events out to its own
+// subscribers.
+//
+// Notifications arrive in commit order, which is not the order of the event
+// IDs drawn from the database sequence, so a hub numbers events itself as
+// they arrive and clients resume from that cursor rather than the event ID.
+package realtime
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"my-application/internal/domain"
+)
+
+// Config holds settings for a Hub.
+type Config struct {
+	BufferSize   int // events kept for Last-Event-ID replay
+	ClientBuffer int // events queued per subscriber before it is dropped
+}
+
+// Message is an event as delivered by a Hub.
+type Message struct {
+	// Cursor identifies the event's position in this hub's stream; it is
+	// sent to clients as the event ID to resume from.
+	Cursor string
+	Event  *domain.RealtimeEvent
+
+	seq int64
+}
+
+// Subscription is one client's view of the event stream. Events is closed
+// when the subscriber falls too far behind or the hub shuts down; the client
+// is expected to reconnect with the cursor of the last event it received.
+type Subscription struct {
+	// Events delivers live events the audience may see.
+	Events <-chan Message
+	// Replay holds missed events when the subscription resumed from a
+	// cursor still covered by the buffer.
+	Replay []Message
+	// Reset is set when the requested cursor is older than the buffer, was
+	// issued by another hub or is malformed, so events may have been
+	// missed; the client must reload its state.
+	Reset bool
+
+	events   chan Message
+	audience domain.RealtimeAudience
+	hub      *Hub
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Hub holds recent events and the subscribers of one API process.
+type Hub struct {
+	config Config
+	id     string // distinguishes this hub's cursors from other replicas'
+
+	mu     sync.Mutex
+	seq    int64     // position of the last published event
+	ring   []Message // oldest first, at most BufferSize
+	floor  int64     // resuming from a position below this may miss events
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewHub creates a Hub.
+func NewHub(config Config) *Hub {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("realtime: generating hub id: %v", err))
+	}
+	return &Hub{
+		config: config,
+		id:     hex.EncodeToString(b),
+		ring:   make([]Message, 0, config.BufferSize),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber. A non-empty lastCursor requests replay
+// of later events from the buffer.
+func (h *Hub) Subscribe(audience domain.RealtimeAudience, lastCursor string) *Subscription {
+	events := make(chan Message, h.config.ClientBuffer)
+	sub := &Subscription{Events: events, events: events, audience: audience, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(events)
+		return sub
+	}
+
+	if lastCursor != "" {
+		seq, ok := h.parseCursor(lastCursor)
+		if !ok || seq < h.floor || seq > h.seq {
+			sub.Reset = true
+		} else {
+			for _, m := range h.ring {
+				if m.seq > seq && audience.Allows(m.Event) {
+					sub.Replay = append(sub.Replay, m)
+				}
+			}
+		}
+	}
+
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+// parseCursor returns the position of a cursor issued by this hub.
+func (h *Hub) parseCursor(cursor string) (int64, bool) {
+	id, pos, ok := strings.Cut(cursor, "-")
+	if !ok || id != h.id {
+		return 0, false
+	}
+	seq, err := strconv.ParseInt(pos, 10, 64)
+	if err != nil || seq < 1 {
+		return 0, false
+	}
+	return seq, true
+}
+
+// Publish buffers e and delivers it to every subscriber allowed to see it.
+// Subscribers whose queue is full are dropped rather than blocking the
+// others; they resume from the buffer when they reconnect.
+func (h *Hub) Publish(e *domain.RealtimeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.seq++
+	m := Message{Cursor: fmt.Sprintf("%s-%d", h.id, h.seq), Event: e, seq: h.seq}
+
+	if len(h.ring) == h.config.BufferSize && h.config.BufferSize > 0 {
+		h.floor = max(h.floor, h.ring[0].seq)
+		copy(h.ring, h.ring[1:])
+		h.ring = h.ring[:len(h.ring)-1]
+	}
+	if h.config.BufferSize > 0 {
+		h.ring = append(h.ring, m)
+	}
+
+	for sub := range h.subs {
+		if !sub.audience.Allows(e) {
+			continue
+		}
+		select {
+		case sub.events <- m:
+		default:
+			delete(h.subs, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// MarkGap records that events may have been missed since the last one
+// published, e.g. while the database listener was reconnecting. Clients
+// resuming from a cursor issued before the gap are told to reset.
+func (h *Hub) MarkGap() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.floor = h.seq + 1
+}
+
+// Close ends every subscription and rejects new ones, so open streams finish
+// and the HTTP server can shut down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for sub := range h.subs {
+		close(sub.events)
+	}
+	clear(h.subs)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
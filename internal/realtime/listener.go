@@ -0,0 +1,86 @@
+// internal/realtime/listener.go
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+)
+
+// Channel is the Postgres notification channel events are published on by
+// publish_realtime_event (migration 000016).
+const Channel = "realtime_events"
+
+// reconnectDelay is the wait before re-establishing a lost LISTEN connection.
+const reconnectDelay = 2 * time.Second
+
+// Listener feeds a Hub from Postgres notifications.
+type Listener struct {
+	pool   *pgxpool.Pool
+	hub    *Hub
+	logger *slog.Logger
+}
+
+// NewListener creates a Listener.
+func NewListener(pool *pgxpool.Pool, hub *Hub, logger *slog.Logger) *Listener {
+	return &Listener{pool: pool, hub: hub, logger: logger}
+}
+
+// Run listens until ctx is cancelled, reconnecting whenever the connection
+// is lost. Events published while disconnected cannot be recovered, so each
+// (re)connect marks a gap in the hub.
+func (l *Listener) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Error("realtime listener disconnected", slog.String("error", err.Error()))
+
+		t := time.NewTimer(reconnectDelay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquiring connection: %w", err)
+	}
+	// The session is left in LISTEN state, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background()) //nolint:errcheck // the connection is discarded
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return fmt.Errorf("listening on %s: %w", Channel, err)
+	}
+
+	// Events committed before LISTEN took effect were never received.
+	l.hub.MarkGap()
+	l.logger.Info("realtime listener connected")
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event domain.RealtimeEvent
+		if err := json.Unmarshal([]byte(n.Payload), &event); err != nil {
+			l.logger.Error("invalid realtime event payload", slog.String("error", err.Error()))
+			continue
+		}
+		l.hub.Publish(&event)
+	}
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"my-application/internal/domain"
 )
@@ -13,8 +14,212 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
 	GetByFirebaseUID(ctx context.Context, firebaseUID string) (*domain.User, error)
-	List(ctx context.Context, filter domain.UserFilter) ([]domain.User, int64, error)
+	List(ctx context.Context, filter domain.UserFilter) (*domain.UserPage, error)
 	Create(ctx context.Context, user *domain.User) error
+	// Update writes the user's profile and reads back its new version. A
+	// non-zero user.Version makes the update conditional: a user with
+	// another version is not updated and a domain.ErrPreconditionFailed
+	// error is returned.
 	Update(ctx context.Context, user *domain.User) error
+	// Delete soft-deletes a user; the row is kept and can be restored.
 	Delete(ctx context.Context, id int64) error
+	// Restore clears the soft-delete marker of a deleted, unpurged user.
+	Restore(ctx context.Context, id int64) (*domain.User, error)
+	// Purge anonymizes the personal fields of a soft-deleted user while
+	// keeping the row so authored stories and incidents remain intact.
+	Purge(ctx context.Context, id int64) error
+	// Taken returns which of usernames and emails users already have,
+	// including deleted users, whose rows keep them.
+	Taken(ctx context.Context, usernames, emails []string) (takenUsernames, takenEmails []string, err error)
+	// SetCredentials sets the password hash of a user, when passwordHash is
+	// not empty, and links firebaseUID, when it is not nil.
+	SetCredentials(ctx context.Context, id int64, passwordHash string, firebaseUID *string) error
+}
+
+// AuditRepository defines the data access contract for the audit trail.
+type AuditRepository interface {
+	Record(ctx context.Context, event *domain.AuditEvent) error
+	ListByActor(ctx context.Context, actorID int64) ([]domain.AuditEvent, error)
+}
+
+// DataExportRepository defines the data access contract for personal data exports.
+type DataExportRepository interface {
+	Create(ctx context.Context, export *domain.DataExport) error
+	GetByID(ctx context.Context, id int64) (*domain.DataExport, error)
+	// Claim moves a pending (or interrupted processing) export to processing
+	// and returns it, or returns a domain.ErrNotFound error when the export is
+	// missing or already finished.
+	Claim(ctx context.Context, id int64) (*domain.DataExport, error)
+	// ListPendingIDs returns exports still pending that were created before the given time.
+	ListPendingIDs(ctx context.Context, before time.Time) ([]int64, error)
+	MarkCompleted(ctx context.Context, id int64, filePath string, expiresAt time.Time) error
+	// SetTokenHash stores the hash of a new download token for a completed
+	// export, invalidating the previous link.
+	SetTokenHash(ctx context.Context, id int64, hash []byte) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+	// ListExpired returns completed exports whose download link has expired.
+	ListExpired(ctx context.Context, now time.Time) ([]domain.DataExport, error)
+	MarkExpired(ctx context.Context, id int64) error
+	// CollectPersonalData gathers everything held about a user except the audit trail.
+	CollectPersonalData(ctx context.Context, userID int64) (*domain.PersonalData, error)
+}
+
+// UserImportRepository defines the data access contract for user imports
+// run in the worker.
+type UserImportRepository interface {
+	Create(ctx context.Context, imp *domain.UserImport) error
+	// GetByID returns an import without its rows.
+	GetByID(ctx context.Context, id int64) (*domain.UserImport, error)
+	// Claim moves a pending (or interrupted processing) import to processing
+	// and returns it with its rows, or returns a domain.ErrNotFound error
+	// when the import is missing or already finished.
+	Claim(ctx context.Context, id int64) (*domain.UserImport, error)
+	// Finish stores the status, results and error of an import that has run
+	// and drops its rows.
+	Finish(ctx context.Context, imp *domain.UserImport) error
+}
+
+// InvitationRepository defines the data access contract for user
+// invitations. An invitation is open until it is accepted or revoked, even
+// once it has expired.
+type InvitationRepository interface {
+	// ListOpen returns the enterprise's open invitations, newest first.
+	ListOpen(ctx context.Context, enterpriseID int64) ([]domain.Invitation, error)
+	Get(ctx context.Context, enterpriseID, id int64) (*domain.Invitation, error)
+	// GetByID returns an invitation whatever its enterprise.
+	GetByID(ctx context.Context, id int64) (*domain.Invitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash []byte) (*domain.Invitation, error)
+	// GetOpenByEmail returns the open invitation to email, whatever its
+	// enterprise.
+	GetOpenByEmail(ctx context.Context, email string) (*domain.Invitation, error)
+	// Create stores a new invitation. It fails with a domain.ErrAlreadyExists
+	// error when the email already has an open invitation.
+	Create(ctx context.Context, inv *domain.Invitation) error
+	// Renew stores the new token hash and expiry of an open invitation and
+	// marks it sent now.
+	Renew(ctx context.Context, inv *domain.Invitation) error
+	// Revoke marks an open invitation revoked.
+	Revoke(ctx context.Context, inv *domain.Invitation) error
+	// Accept marks a pending, unexpired invitation accepted by userID. It
+	// fails with a domain.ErrNotFound error when the invitation is no longer
+	// pending.
+	Accept(ctx context.Context, inv *domain.Invitation, userID int64) error
+}
+
+// RetentionRepository defines the data access contract for retention policies
+// and the batched purges that enforce them.
+type RetentionRepository interface {
+	ListPolicies(ctx context.Context, enterpriseID int64) ([]domain.RetentionPolicy, error)
+	ListActivePolicies(ctx context.Context) ([]domain.RetentionPolicy, error)
+	UpsertPolicy(ctx context.Context, policy *domain.RetentionPolicy) error
+	DeletePolicy(ctx context.Context, enterpriseID int64, tableName string) error
+	// PurgeBatch deletes or anonymizes up to limit expired rows under policy,
+	// skipping residents on legal hold, and returns the affected row IDs.
+	PurgeBatch(ctx context.Context, policy domain.RetentionPolicy, cutoff time.Time, limit int) ([]int64, error)
+	// SetLegalHold sets the legal-hold flag of a resident. When enterpriseID is
+	// non-nil the resident must belong to that enterprise.
+	SetLegalHold(ctx context.Context, residentID int64, enterpriseID *int64, hold bool) error
+}
+
+// NotificationRepository defines the data access contract for notifications,
+// notification settings and push devices.
+type NotificationRepository interface {
+	// Create inserts an inbox notification. It reports false when the user
+	// already has a notification with the same dedupe key.
+	Create(ctx context.Context, n *domain.Notification) (bool, error)
+	List(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, int64, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID, id int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+
+	// GetSettings returns the quiet hours and only the preferences the user
+	// has stored; defaults are applied by the caller.
+	GetSettings(ctx context.Context, userID int64) (*domain.NotificationSettings, error)
+	UpdateSettings(ctx context.Context, userID int64, settings *domain.NotificationSettings) error
+
+	UpsertDevice(ctx context.Context, device *domain.PushDevice) error
+	DeleteDevice(ctx context.Context, userID int64, token string) error
+	DeleteDeviceByToken(ctx context.Context, token string) error
+	// DeleteDevicesByUser removes every push device of a user.
+	DeleteDevicesByUser(ctx context.Context, userID int64) (int64, error)
+	ListDevices(ctx context.Context, userID int64) ([]domain.PushDevice, error)
+
+	// ResolveEvent loads a source event and the users it fans out to, or
+	// returns a domain.ErrNotFound error when the entity no longer exists.
+	ResolveEvent(ctx context.Context, eventType string, entityID int64) (*domain.NotificationEvent, error)
+	// EnqueueRobotOffline flags active robots whose last heartbeat is older
+	// than heartbeatBefore and enqueues one fan-out job per robot.
+	EnqueueRobotOffline(ctx context.Context, heartbeatBefore time.Time) ([]int64, error)
+}
+
+// ResidentRepository defines the data access contract for residents.
+type ResidentRepository interface {
+	// ListIDsByCaregiver returns the residents assigned to a caregiver.
+	ListIDsByCaregiver(ctx context.Context, caregiverID int64) ([]int64, error)
+	GetEnterpriseID(ctx context.Context, residentID int64) (int64, error)
+	// LockCaregiverAssignments serializes assignment changes for a caregiver
+	// and returns how many residents they have. It must run inside a
+	// Transactor unit of work.
+	LockCaregiverAssignments(ctx context.Context, caregiverID int64) (int, error)
+	AssignCaregiver(ctx context.Context, assignment *domain.CaregiverAssignment) error
+}
+
+// RobotRepository defines the data access contract for robots.
+type RobotRepository interface {
+	// LockQuota serializes provisioning for an enterprise and returns the
+	// robots counting against its quota and the quota itself. It must run
+	// inside a Transactor unit of work.
+	LockQuota(ctx context.Context, enterpriseID int64) (used, limit int, err error)
+	Create(ctx context.Context, robot *domain.Robot) error
+	// RecordHeartbeat stores a robot's heartbeat with its reported status
+	// and, when set, firmware version, and clears its offline notification.
+	// Decommissioned robots are not found.
+	RecordHeartbeat(ctx context.Context, robotID int64, status string, firmwareVersion *string) (*domain.Robot, error)
+}
+
+// IncidentRepository defines the data access contract for incidents.
+type IncidentRepository interface {
+	GetByID(ctx context.Context, id int64) (*domain.Incident, error)
+	// Resolve records the resolution of an open incident and sets its
+	// resolved_at and updated_at.
+	Resolve(ctx context.Context, incident *domain.Incident) error
+}
+
+// Transactor runs a unit of work in one transaction. Repositories called
+// with the context passed to fn take part in that transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OutboxRepository records domain events for reliable publication.
+type OutboxRepository interface {
+	// Append writes events to the outbox. It must run inside a Transactor
+	// unit of work so the events commit or roll back with the change.
+	Append(ctx context.Context, events ...*domain.DomainEvent) error
+}
+
+// WebhookRepository defines the data access contract for webhook
+// subscriptions and their delivery log.
+type WebhookRepository interface {
+	List(ctx context.Context, enterpriseID int64) ([]domain.WebhookSubscription, error)
+	Get(ctx context.Context, enterpriseID, id int64) (*domain.WebhookSubscription, error)
+	Create(ctx context.Context, sub *domain.WebhookSubscription) error
+	// Update saves the URL, description, event types and active flag.
+	// Reactivating a subscription clears its failure streak.
+	Update(ctx context.Context, sub *domain.WebhookSubscription) error
+	Delete(ctx context.Context, enterpriseID, id int64) error
+	// ListSubscribed returns the enterprise's active subscriptions to
+	// eventType.
+	ListSubscribed(ctx context.Context, enterpriseID int64, eventType string) ([]domain.WebhookSubscription, error)
+
+	CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int64, error)
+	// GetDelivery loads a delivery together with its subscription.
+	GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, *domain.WebhookSubscription, error)
+	// SaveAttempt stores the status, attempt count and outcome of a delivery.
+	SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error
+	// RecordResult updates the subscription's failure streak after an
+	// attempt and disables it when the streak exceeds policy. It reports
+	// whether this call disabled the subscription.
+	RecordResult(ctx context.Context, subscriptionID int64, ok bool, policy domain.WebhookDisablePolicy) (bool, error)
 }
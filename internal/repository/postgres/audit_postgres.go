@@ -0,0 +1,71 @@
+// internal/repository/postgres/audit_postgres.go
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.AuditRepository = (*AuditPostgres)(nil)
+
+// AuditPostgres implements repository.AuditRepository with PostgreSQL.
+type AuditPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewAuditPostgres creates a new AuditPostgres repository.
+func NewAuditPostgres(pool *pgxpool.Pool, logger *slog.Logger) *AuditPostgres {
+	return &AuditPostgres{pool: pool, logger: logger}
+}
+
+func (r *AuditPostgres) Record(ctx context.Context, event *domain.AuditEvent) error {
+	if event.Metadata == nil {
+		event.Metadata = map[string]interface{}{}
+	}
+
+	query := `INSERT INTO audit_log (enterprise_id, actor_id, action, entity_type, entity_id, metadata)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		event.EnterpriseID, event.ActorID, event.Action, event.EntityType, event.EntityID, event.Metadata,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *AuditPostgres) ListByActor(ctx context.Context, actorID int64) ([]domain.AuditEvent, error) {
+	query := `SELECT id, enterprise_id, actor_id, action, entity_type, entity_id, metadata, created_at
+			  FROM audit_log WHERE actor_id = $1 ORDER BY created_at`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, actorID)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	events := make([]domain.AuditEvent, 0)
+	for rows.Next() {
+		var e domain.AuditEvent
+		if err := rows.Scan(
+			&e.ID, &e.EnterpriseID, &e.ActorID, &e.Action, &e.EntityType, &e.EntityID, &e.Metadata, &e.CreatedAt,
+		); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return events, nil
+}
@@ -0,0 +1,257 @@
+// internal/repository/postgres/data_export_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.DataExportRepository = (*DataExportPostgres)(nil)
+
+// DataExportPostgres implements repository.DataExportRepository with PostgreSQL.
+type DataExportPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewDataExportPostgres creates a new DataExportPostgres repository.
+func NewDataExportPostgres(pool *pgxpool.Pool, logger *slog.Logger) *DataExportPostgres {
+	return &DataExportPostgres{pool: pool, logger: logger}
+}
+
+const dataExportColumns = `id, user_id, requested_by, status, COALESCE(file_path, ''), download_token_hash,
+	expires_at, COALESCE(error, ''), completed_at, created_at, updated_at`
+
+// scanDataExport scans a row into a domain.DataExport.
+func scanDataExport(row pgx.Row) (*domain.DataExport, error) {
+	var e domain.DataExport
+	err := row.Scan(
+		&e.ID, &e.UserID, &e.RequestedBy, &e.Status, &e.FilePath, &e.DownloadTokenHash,
+		&e.ExpiresAt, &e.Error, &e.CompletedAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+	return &e, err
+}
+
+func (r *DataExportPostgres) Create(ctx context.Context, export *domain.DataExport) error {
+	query := `INSERT INTO data_exports (user_id, requested_by, status)
+			  VALUES ($1, $2, $3)
+			  RETURNING id, created_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, export.UserID, export.RequestedBy, export.Status).
+		Scan(&export.ID, &export.CreatedAt, &export.UpdatedAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *DataExportPostgres) GetByID(ctx context.Context, id int64) (*domain.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = $1`
+
+	e, err := scanDataExport(database.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("data export with id %d not found", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return e, nil
+}
+
+func (r *DataExportPostgres) Claim(ctx context.Context, id int64) (*domain.DataExport, error) {
+	// A processing export is claimable again: its previous attempt crashed
+	// and the job queue guarantees a single active build per export.
+	query := `UPDATE data_exports SET status = 'processing'
+			  WHERE id = $1 AND status IN ('pending', 'processing')
+			  RETURNING ` + dataExportColumns
+
+	e, err := scanDataExport(database.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("no buildable data export with id %d", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return e, nil
+}
+
+func (r *DataExportPostgres) ListPendingIDs(ctx context.Context, before time.Time) ([]int64, error) {
+	query := `SELECT id FROM data_exports WHERE status = 'pending' AND created_at < $1 ORDER BY created_at`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, before)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return ids, nil
+}
+
+func (r *DataExportPostgres) MarkCompleted(ctx context.Context, id int64, filePath string, expiresAt time.Time) error {
+	query := `UPDATE data_exports
+			  SET status = 'completed', file_path = $2, expires_at = $3, completed_at = NOW(), error = NULL
+			  WHERE id = $1`
+
+	if _, err := database.Conn(ctx, r.pool).Exec(ctx, query, id, filePath, expiresAt); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *DataExportPostgres) SetTokenHash(ctx context.Context, id int64, hash []byte) error {
+	query := `UPDATE data_exports SET download_token_hash = $2 WHERE id = $1 AND status = 'completed'`
+
+	result, err := database.Conn(ctx, r.pool).Exec(ctx, query, id, hash)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("completed data export with id %d not found", id))
+	}
+	return nil
+}
+
+func (r *DataExportPostgres) MarkFailed(ctx context.Context, id int64, reason string) error {
+	if _, err := database.Conn(ctx, r.pool).Exec(ctx, `UPDATE data_exports SET status = 'failed', error = $2 WHERE id = $1`, id, reason); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *DataExportPostgres) ListExpired(ctx context.Context, now time.Time) ([]domain.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports
+			  WHERE status = 'completed' AND expires_at < $1`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, now)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	exports := make([]domain.DataExport, 0)
+	for rows.Next() {
+		e, err := scanDataExport(rows)
+		if err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		exports = append(exports, *e)
+	}
+	return exports, nil
+}
+
+func (r *DataExportPostgres) MarkExpired(ctx context.Context, id int64) error {
+	query := `UPDATE data_exports SET status = 'expired', file_path = NULL, download_token_hash = NULL WHERE id = $1`
+	if _, err := database.Conn(ctx, r.pool).Exec(ctx, query, id); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *DataExportPostgres) CollectPersonalData(ctx context.Context, userID int64) (*domain.PersonalData, error) {
+	// The profile is read directly (not via UserPostgres.GetByID) so that
+	// soft-deleted users can still be exported.
+	profile, err := scanUser(database.Conn(ctx, r.pool).QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", userID))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+
+	data := &domain.PersonalData{
+		GeneratedAt: time.Now().UTC(),
+		Profile:     *profile,
+		Stories:     make([]domain.StoryRecord, 0),
+		Incidents:   make([]domain.IncidentRecord, 0),
+		Sessions:    make([]domain.SessionRecord, 0),
+	}
+
+	if err := r.collectStories(ctx, userID, data); err != nil {
+		return nil, err
+	}
+	if err := r.collectIncidents(ctx, userID, data); err != nil {
+		return nil, err
+	}
+	if err := r.collectSessions(ctx, userID, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *DataExportPostgres) collectStories(ctx context.Context, userID int64, data *domain.PersonalData) error {
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, `SELECT id, resident_id, content_encrypted, media_url, story_type, created_at, updated_at
+		FROM stories WHERE author_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s domain.StoryRecord
+		if err := rows.Scan(&s.ID, &s.ResidentID, &s.ContentEncrypted, &s.MediaURL, &s.StoryType, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return domain.NewDatabaseError(err)
+		}
+		data.Stories = append(data.Stories, s)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *DataExportPostgres) collectIncidents(ctx context.Context, userID int64, data *domain.PersonalData) error {
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, `SELECT id, resident_id, severity, description, resolution, resolved_at, created_at
+		FROM incidents WHERE reporter_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i domain.IncidentRecord
+		if err := rows.Scan(&i.ID, &i.ResidentID, &i.Severity, &i.Description, &i.Resolution, &i.ResolvedAt, &i.CreatedAt); err != nil {
+			return domain.NewDatabaseError(err)
+		}
+		data.Incidents = append(data.Incidents, i)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *DataExportPostgres) collectSessions(ctx context.Context, userID int64, data *domain.PersonalData) error {
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, `SELECT s.id, s.robot_id, s.resident_id, s.session_type, s.started_at, s.ended_at
+		FROM robot_sessions s
+		JOIN caregiver_residents cr ON cr.resident_id = s.resident_id
+		WHERE cr.caregiver_id = $1 ORDER BY s.started_at`, userID)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s domain.SessionRecord
+		if err := rows.Scan(&s.ID, &s.RobotID, &s.ResidentID, &s.SessionType, &s.StartedAt, &s.EndedAt); err != nil {
+			return domain.NewDatabaseError(err)
+		}
+		data.Sessions = append(data.Sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// internal/repository/postgres/incident_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.IncidentRepository = (*IncidentPostgres)(nil)
+
+// IncidentPostgres implements repository.IncidentRepository with PostgreSQL.
+type IncidentPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewIncidentPostgres creates a new IncidentPostgres repository.
+func NewIncidentPostgres(pool *pgxpool.Pool, logger *slog.Logger) *IncidentPostgres {
+	return &IncidentPostgres{pool: pool, logger: logger}
+}
+
+func (r *IncidentPostgres) GetByID(ctx context.Context, id int64) (*domain.Incident, error) {
+	query := `SELECT id, resident_id, reporter_id, severity, description, resolution, resolved_at, created_at, updated_at
+			  FROM incidents WHERE id = $1`
+
+	var i domain.Incident
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(
+		&i.ID, &i.ResidentID, &i.ReporterID, &i.Severity, &i.Description,
+		&i.Resolution, &i.ResolvedAt, &i.CreatedAt, &i.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("incident with id %d not found", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return &i, nil
+}
+
+func (r *IncidentPostgres) Resolve(ctx context.Context, incident *domain.Incident) error {
+	query := `UPDATE incidents SET resolution = $1, resolved_at = NOW()
+			  WHERE id = $2 AND resolved_at IS NULL
+			  RETURNING resolved_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, incident.Resolution, incident.ID).
+		Scan(&incident.ResolvedAt, &incident.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewAppError(domain.ErrAlreadyExists, fmt.Sprintf("incident with id %d is not open", incident.ID)).
+				WithCode(domain.CodeIncidentNotOpen)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// internal/repository/postgres/invitation_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.InvitationRepository = (*InvitationPostgres)(nil)
+
+// InvitationPostgres implements repository.InvitationRepository with PostgreSQL.
+type InvitationPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewInvitationPostgres creates a new InvitationPostgres repository.
+func NewInvitationPostgres(pool *pgxpool.Pool, logger *slog.Logger) *InvitationPostgres {
+	return &InvitationPostgres{pool: pool, logger: logger}
+}
+
+const invitationColumns = `id, enterprise_id, email, role, full_name, locale, user_id, invited_by, token_hash,
+	expires_at, sent_at, accepted_at, revoked_at, created_at, updated_at`
+
+// openInvitation matches invitations neither accepted nor revoked.
+const openInvitation = `accepted_at IS NULL AND revoked_at IS NULL`
+
+func scanInvitation(row pgx.Row, inv *domain.Invitation) error {
+	return row.Scan(
+		&inv.ID, &inv.EnterpriseID, &inv.Email, &inv.Role, &inv.FullName, &inv.Locale, &inv.UserID, &inv.InvitedBy,
+		&inv.TokenHash, &inv.ExpiresAt, &inv.SentAt, &inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+}
+
+func (r *InvitationPostgres) ListOpen(ctx context.Context, enterpriseID int64) ([]domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations
+			  WHERE enterprise_id = $1 AND ` + openInvitation + `
+			  ORDER BY created_at DESC, id DESC`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, enterpriseID)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	invs := make([]domain.Invitation, 0)
+	for rows.Next() {
+		var inv domain.Invitation
+		if err := scanInvitation(rows, &inv); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		invs = append(invs, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return invs, nil
+}
+
+func (r *InvitationPostgres) Get(ctx context.Context, enterpriseID, id int64) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = $1 AND enterprise_id = $2`
+
+	var inv domain.Invitation
+	if err := scanInvitation(database.Conn(ctx, r.pool).QueryRow(ctx, query, id, enterpriseID), &inv); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("invitation with id %d not found", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return &inv, nil
+}
+
+func (r *InvitationPostgres) GetByID(ctx context.Context, id int64) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = $1`
+
+	var inv domain.Invitation
+	if err := scanInvitation(database.Conn(ctx, r.pool).QueryRow(ctx, query, id), &inv); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("invitation with id %d not found", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return &inv, nil
+}
+
+func (r *InvitationPostgres) GetByTokenHash(ctx context.Context, tokenHash []byte) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE token_hash = $1`
+
+	var inv domain.Invitation
+	if err := scanInvitation(database.Conn(ctx, r.pool).QueryRow(ctx, query, tokenHash), &inv); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, "invitation not found")
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return &inv, nil
+}
+
+func (r *InvitationPostgres) GetOpenByEmail(ctx context.Context, email string) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE lower(email) = lower($1) AND ` + openInvitation
+
+	var inv domain.Invitation
+	if err := scanInvitation(database.Conn(ctx, r.pool).QueryRow(ctx, query, email), &inv); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, "invitation not found")
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return &inv, nil
+}
+
+func (r *InvitationPostgres) Create(ctx context.Context, inv *domain.Invitation) error {
+	query := `INSERT INTO invitations (enterprise_id, email, role, full_name, locale, user_id, invited_by, token_hash, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			  RETURNING id, sent_at, created_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		inv.EnterpriseID, inv.Email, inv.Role, inv.FullName, inv.Locale, inv.UserID, inv.InvitedBy,
+		inv.TokenHash, inv.ExpiresAt,
+	).Scan(&inv.ID, &inv.SentAt, &inv.CreatedAt, &inv.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_invitations_open_email" {
+			return domain.NewAppError(domain.ErrAlreadyExists, "email already has a pending invitation").
+				WithCode(domain.CodeInvitationPending)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *InvitationPostgres) Renew(ctx context.Context, inv *domain.Invitation) error {
+	query := `UPDATE invitations SET token_hash = $2, expires_at = $3, sent_at = NOW()
+			  WHERE id = $1 AND ` + openInvitation + `
+			  RETURNING sent_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, inv.ID, inv.TokenHash, inv.ExpiresAt).
+		Scan(&inv.SentAt, &inv.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return invitationNotOpenError(inv.ID)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *InvitationPostgres) Revoke(ctx context.Context, inv *domain.Invitation) error {
+	query := `UPDATE invitations SET revoked_at = NOW()
+			  WHERE id = $1 AND ` + openInvitation + `
+			  RETURNING revoked_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, inv.ID).Scan(&inv.RevokedAt, &inv.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return invitationNotOpenError(inv.ID)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *InvitationPostgres) Accept(ctx context.Context, inv *domain.Invitation, userID int64) error {
+	query := `UPDATE invitations SET accepted_at = NOW(), user_id = $2
+			  WHERE id = $1 AND ` + openInvitation + ` AND expires_at > NOW()
+			  RETURNING user_id, accepted_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, inv.ID, userID).
+		Scan(&inv.UserID, &inv.AcceptedAt, &inv.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("invitation with id %d is not pending", inv.ID)).
+				WithCode(domain.CodeInvitationInvalid)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+// invitationNotOpenError reports that invitation id was already accepted
+// or revoked.
+func invitationNotOpenError(id int64) error {
+	return domain.NewAppError(domain.ErrAlreadyExists, fmt.Sprintf("invitation with id %d is not pending", id)).
+		WithCode(domain.CodeInvitationNotPending)
+}
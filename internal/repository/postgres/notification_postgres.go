@@ -0,0 +1,439 @@
+// internal/repository/postgres/notification_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.NotificationRepository = (*NotificationPostgres)(nil)
+
+// NotificationPostgres implements repository.NotificationRepository with PostgreSQL.
+type NotificationPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewNotificationPostgres creates a new NotificationPostgres repository.
+func NewNotificationPostgres(pool *pgxpool.Pool, logger *slog.Logger) *NotificationPostgres {
+	return &NotificationPostgres{pool: pool, logger: logger}
+}
+
+const notificationColumns = `id, user_id, type, title, body, data, read_at, created_at`
+
+func (r *NotificationPostgres) Create(ctx context.Context, n *domain.Notification) (bool, error) {
+	if n.Data == nil {
+		n.Data = map[string]interface{}{}
+	}
+
+	query := `INSERT INTO notifications (user_id, type, title, body, data, dedupe_key)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  ON CONFLICT (user_id, dedupe_key) DO NOTHING
+			  RETURNING id, created_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, n.UserID, n.Type, n.Title, n.Body, n.Data, n.DedupeKey).
+		Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, domain.NewDatabaseError(err)
+	}
+	return true, nil
+}
+
+func (r *NotificationPostgres) List(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, int64, error) {
+	where := `WHERE user_id = $1`
+	if filter.UnreadOnly {
+		where += ` AND read_at IS NULL`
+	}
+
+	var total int64
+	if err := database.Conn(ctx, r.pool).QueryRow(ctx, `SELECT COUNT(*) FROM notifications `+where, userID).Scan(&total); err != nil {
+		return nil, 0, domain.NewDatabaseError(err)
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications ` + where + `
+			  ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, userID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	notifications := make([]domain.Notification, 0)
+	for rows.Next() {
+		var n domain.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.Data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, 0, domain.NewDatabaseError(err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, domain.NewDatabaseError(err)
+	}
+	return notifications, total, nil
+}
+
+func (r *NotificationPostgres) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var n int64
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	if err != nil {
+		return 0, domain.NewDatabaseError(err)
+	}
+	return n, nil
+}
+
+func (r *NotificationPostgres) MarkRead(ctx context.Context, userID, id int64) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`
+
+	tag, err := database.Conn(ctx, r.pool).Exec(ctx, query, id, userID)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("notification with id %d not found", id))
+	}
+	return nil
+}
+
+func (r *NotificationPostgres) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	tag, err := database.Conn(ctx, r.pool).Exec(ctx, `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, domain.NewDatabaseError(err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *NotificationPostgres) GetSettings(ctx context.Context, userID int64) (*domain.NotificationSettings, error) {
+	var start, end *string
+	err := database.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT to_char(quiet_hours_start, 'HH24:MI'), to_char(quiet_hours_end, 'HH24:MI')
+		 FROM users WHERE id = $1 AND deleted_at IS NULL`, userID,
+	).Scan(&start, &end)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", userID))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+
+	settings := &domain.NotificationSettings{Preferences: make([]domain.NotificationPreference, 0)}
+	if start != nil && end != nil {
+		settings.QuietHours = &domain.QuietHours{Start: *start, End: *end}
+	}
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx,
+		`SELECT type, in_app, push, email FROM notification_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p domain.NotificationPreference
+		if err := rows.Scan(&p.Type, &p.InApp, &p.Push, &p.Email); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		settings.Preferences = append(settings.Preferences, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return settings, nil
+}
+
+func (r *NotificationPostgres) UpdateSettings(ctx context.Context, userID int64, settings *domain.NotificationSettings) error {
+	tx, err := database.Conn(ctx, r.pool).Begin(ctx)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	var start, end *string
+	if settings.QuietHours != nil {
+		start, end = &settings.QuietHours.Start, &settings.QuietHours.End
+	}
+	tag, err := tx.Exec(ctx,
+		`UPDATE users SET quiet_hours_start = $2::TIME, quiet_hours_end = $3::TIME
+		 WHERE id = $1 AND deleted_at IS NULL`, userID, start, end)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", userID))
+	}
+
+	for _, p := range settings.Preferences {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO notification_preferences (user_id, type, in_app, push, email)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (user_id, type) DO UPDATE
+			 SET in_app = EXCLUDED.in_app, push = EXCLUDED.push, email = EXCLUDED.email`,
+			userID, p.Type, p.InApp, p.Push, p.Email)
+		if err != nil {
+			return domain.NewDatabaseError(err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *NotificationPostgres) UpsertDevice(ctx context.Context, device *domain.PushDevice) error {
+	// A token moves to whoever registered it last, e.g. after a sign-out and
+	// sign-in as someone else on a shared tablet.
+	query := `INSERT INTO push_devices (user_id, token, platform)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (token) DO UPDATE
+			  SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, last_seen_at = NOW()
+			  RETURNING id, created_at, last_seen_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, device.UserID, device.Token, device.Platform).
+		Scan(&device.ID, &device.CreatedAt, &device.LastSeenAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *NotificationPostgres) DeleteDevice(ctx context.Context, userID int64, token string) error {
+	tag, err := database.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM push_devices WHERE user_id = $1 AND token = $2`, userID, token)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, "push device not found")
+	}
+	return nil
+}
+
+func (r *NotificationPostgres) DeleteDeviceByToken(ctx context.Context, token string) error {
+	if _, err := database.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM push_devices WHERE token = $1`, token); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *NotificationPostgres) DeleteDevicesByUser(ctx context.Context, userID int64) (int64, error) {
+	tag, err := database.Conn(ctx, r.pool).Exec(ctx, `DELETE FROM push_devices WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, domain.NewDatabaseError(err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *NotificationPostgres) ListDevices(ctx context.Context, userID int64) ([]domain.PushDevice, error) {
+	rows, err := database.Conn(ctx, r.pool).Query(ctx,
+		`SELECT id, user_id, token, platform, created_at, last_seen_at
+		 FROM push_devices WHERE user_id = $1 ORDER BY last_seen_at DESC`, userID)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	devices := make([]domain.PushDevice, 0)
+	for rows.Next() {
+		var d domain.PushDevice
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Token, &d.Platform, &d.CreatedAt, &d.LastSeenAt); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		devices = append(devices, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return devices, nil
+}
+
+func (r *NotificationPostgres) ResolveEvent(ctx context.Context, eventType string, entityID int64) (*domain.NotificationEvent, error) {
+	event := &domain.NotificationEvent{Type: eventType, EntityID: entityID}
+
+	var (
+		residentID   *int64
+		enterpriseID *int64 // eta of this enterprise are recipients
+		excludeID    int64
+		err          error
+	)
+
+	switch eventType {
+	case domain.NotificationTypeIncidentEscalated:
+		var (
+			severity, description, residentName string
+			reportedAt                          time.Time
+			rid, eid                            int64
+		)
+		// Incidents resolved before the fan-out ran are not announced.
+		err = database.Conn(ctx, r.pool).QueryRow(ctx,
+			`SELECT i.severity, i.description, i.created_at, r.id, r.full_name, r.enterprise_id
+			 FROM incidents i JOIN residents r ON r.id = i.resident_id
+			 WHERE i.id = $1 AND i.resolved_at IS NULL`, entityID,
+		).Scan(&severity, &description, &reportedAt, &rid, &residentName, &eid)
+		residentID, enterpriseID = &rid, &eid
+		event.Urgent = severity == "critical"
+		event.Fields = map[string]interface{}{
+			"IncidentID":   entityID,
+			"ResidentID":   rid,
+			"ResidentName": residentName,
+			"Severity":     severity,
+			"Description":  description,
+			"ReportedAt":   reportedAt,
+		}
+
+	case domain.NotificationTypeFamilyStory:
+		var (
+			storyType, authorName, residentName string
+			rid, authorID                       int64
+		)
+		err = database.Conn(ctx, r.pool).QueryRow(ctx,
+			`SELECT s.story_type, s.author_id, a.full_name, r.id, r.full_name
+			 FROM stories s
+			 JOIN users a ON a.id = s.author_id
+			 JOIN residents r ON r.id = s.resident_id
+			 WHERE s.id = $1`, entityID,
+		).Scan(&storyType, &authorID, &authorName, &rid, &residentName)
+		residentID, excludeID = &rid, authorID
+		event.Fields = map[string]interface{}{
+			"StoryID":      entityID,
+			"StoryType":    storyType,
+			"AuthorName":   authorName,
+			"ResidentID":   rid,
+			"ResidentName": residentName,
+		}
+
+	case domain.NotificationTypeRobotOffline:
+		var (
+			serial        string
+			lastHeartbeat *time.Time
+			residentName  *string
+			eid           int64
+		)
+		// A robot that came back before the fan-out ran is not announced.
+		err = database.Conn(ctx, r.pool).QueryRow(ctx,
+			`SELECT rb.serial_number, rb.last_heartbeat, rb.enterprise_id, rb.assigned_resident_id, r.full_name
+			 FROM robots rb LEFT JOIN residents r ON r.id = rb.assigned_resident_id
+			 WHERE rb.id = $1 AND rb.offline_notified_at IS NOT NULL`, entityID,
+		).Scan(&serial, &lastHeartbeat, &eid, &residentID, &residentName)
+		enterpriseID = &eid
+		event.Fields = map[string]interface{}{
+			"RobotID":       entityID,
+			"SerialNumber":  serial,
+			"LastHeartbeat": lastHeartbeat,
+			"ResidentName":  residentName,
+		}
+
+	default:
+		return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("unknown notification type %q", eventType))
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("%s source %d not found", eventType, entityID))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+
+	event.Recipients, err = r.recipients(ctx, eventType, residentID, enterpriseID, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// recipients returns the active caregivers of residentID and the eta of
+// enterpriseID, with their preference for eventType.
+func (r *NotificationPostgres) recipients(
+	ctx context.Context, eventType string, residentID, enterpriseID *int64, excludeID int64,
+) ([]domain.NotificationRecipient, error) {
+	query := `SELECT u.id, u.email, COALESCE(u.locale, ''), COALESCE(e.time_zone, 'UTC'),
+				  to_char(u.quiet_hours_start, 'HH24:MI'), to_char(u.quiet_hours_end, 'HH24:MI'),
+				  p.in_app, p.push, p.email
+			  FROM users u
+			  LEFT JOIN enterprises e ON e.id = u.enterprise_id
+			  LEFT JOIN notification_preferences p ON p.user_id = u.id AND p.type = $1
+			  WHERE u.is_active AND u.deleted_at IS NULL AND u.id <> $4
+				AND (
+					u.id IN (SELECT caregiver_id FROM caregiver_residents WHERE resident_id = $2)
+					OR (u.role = 'eta' AND u.enterprise_id = $3)
+				)`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, eventType, residentID, enterpriseID, excludeID)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	recipients := make([]domain.NotificationRecipient, 0)
+	for rows.Next() {
+		var (
+			rc                 domain.NotificationRecipient
+			start, end         *string
+			inApp, push, email *bool
+		)
+		if err := rows.Scan(&rc.UserID, &rc.Email, &rc.Locale, &rc.TimeZone, &start, &end, &inApp, &push, &email); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		if start != nil && end != nil {
+			rc.QuietHours = &domain.QuietHours{Start: *start, End: *end}
+		}
+		rc.Preference = domain.DefaultNotificationPreference(eventType)
+		if inApp != nil {
+			rc.Preference.InApp, rc.Preference.Push, rc.Preference.Email = *inApp, *push, *email
+		}
+		recipients = append(recipients, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return recipients, nil
+}
+
+func (r *NotificationPostgres) EnqueueRobotOffline(ctx context.Context, heartbeatBefore time.Time) ([]int64, error) {
+	// Flagging and enqueueing share a transaction so a crash cannot flag a
+	// robot without announcing it.
+	tx, err := database.Conn(ctx, r.pool).Begin(ctx)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	rows, err := tx.Query(ctx,
+		`UPDATE robots SET offline_notified_at = NOW()
+		 WHERE status IN ('active', 'idle') AND last_heartbeat < $1 AND offline_notified_at IS NULL
+		 RETURNING id`, heartbeatBefore)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`SELECT enqueue_notification_fanout('robot_offline', id) FROM unnest($1::BIGINT[]) AS id`, ids,
+	); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return ids, nil
+}
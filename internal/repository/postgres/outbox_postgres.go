@@ -0,0 +1,50 @@
+// internal/repository/postgres/outbox_postgres.go
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.OutboxRepository = (*OutboxPostgres)(nil)
+
+// OutboxPostgres implements repository.OutboxRepository with PostgreSQL.
+type OutboxPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewOutboxPostgres creates a new OutboxPostgres repository.
+func NewOutboxPostgres(pool *pgxpool.Pool, logger *slog.Logger) *OutboxPostgres {
+	return &OutboxPostgres{pool: pool, logger: logger}
+}
+
+func (r *OutboxPostgres) Append(ctx context.Context, events ...*domain.DomainEvent) error {
+	tx, ok := database.TxFromContext(ctx)
+	if !ok {
+		return domain.NewAppError(domain.ErrInternal, "outbox events must be appended inside a transaction")
+	}
+
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		// append_outbox_event runs as the table owner, so tenant-scoped
+		// transactions can append too (migration 000032).
+		batch.Queue(`SELECT append_outbox_event($1, $2, $3, $4, $5, $6)`,
+			e.Type, e.AggregateType, e.AggregateID, e.EnterpriseID, e.Payload, e.OccurredAt,
+		).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&e.ID)
+		})
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
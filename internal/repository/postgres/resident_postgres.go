@@ -0,0 +1,93 @@
+// internal/repository/postgres/resident_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.ResidentRepository = (*ResidentPostgres)(nil)
+
+// ResidentPostgres implements repository.ResidentRepository with PostgreSQL.
+type ResidentPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewResidentPostgres creates a new ResidentPostgres repository.
+func NewResidentPostgres(pool *pgxpool.Pool, logger *slog.Logger) *ResidentPostgres {
+	return &ResidentPostgres{pool: pool, logger: logger}
+}
+
+func (r *ResidentPostgres) ListIDsByCaregiver(ctx context.Context, caregiverID int64) ([]int64, error) {
+	rows, err := database.Conn(ctx, r.pool).Query(ctx,
+		`SELECT resident_id FROM caregiver_residents WHERE caregiver_id = $1`, caregiverID)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return ids, nil
+}
+
+func (r *ResidentPostgres) GetEnterpriseID(ctx context.Context, residentID int64) (int64, error) {
+	var enterpriseID int64
+	err := database.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT enterprise_id FROM residents WHERE id = $1`, residentID).Scan(&enterpriseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("resident with id %d not found", residentID))
+		}
+		return 0, domain.NewDatabaseError(err)
+	}
+	return enterpriseID, nil
+}
+
+func (r *ResidentPostgres) LockCaregiverAssignments(ctx context.Context, caregiverID int64) (int, error) {
+	conn := database.Conn(ctx, r.pool)
+	// The caregiver's user row is the lock; it is held until the transaction ends.
+	tag, err := conn.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, caregiverID)
+	if err != nil {
+		return 0, domain.NewDatabaseError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", caregiverID))
+	}
+
+	var n int
+	err = conn.QueryRow(ctx,
+		`SELECT COUNT(*) FROM caregiver_residents WHERE caregiver_id = $1`, caregiverID).Scan(&n)
+	if err != nil {
+		return 0, domain.NewDatabaseError(err)
+	}
+	return n, nil
+}
+
+func (r *ResidentPostgres) AssignCaregiver(ctx context.Context, a *domain.CaregiverAssignment) error {
+	err := database.Conn(ctx, r.pool).QueryRow(ctx,
+		`INSERT INTO caregiver_residents (caregiver_id, resident_id)
+		 VALUES ($1, $2)
+		 RETURNING assigned_at`, a.CaregiverID, a.ResidentID).Scan(&a.AssignedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.NewAppError(domain.ErrAlreadyExists, "caregiver is already assigned to this resident").
+				WithCode(domain.CodeCaregiverAlreadyAssigned)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// internal/repository/postgres/retention_postgres.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.RetentionRepository = (*RetentionPostgres)(nil)
+
+// RetentionPostgres implements repository.RetentionRepository with PostgreSQL.
+type RetentionPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewRetentionPostgres creates a new RetentionPostgres repository.
+func NewRetentionPostgres(pool *pgxpool.Pool, logger *slog.Logger) *RetentionPostgres {
+	return &RetentionPostgres{pool: pool, logger: logger}
+}
+
+// retentionTarget describes how a table is aged and anonymized. Only these
+// fixed fragments are ever interpolated into SQL.
+type retentionTarget struct {
+	expiresExpr   string // timestamp compared against the cutoff; NULL never expires
+	anonymizeSet  string // SET clause used by the anonymize action
+	notAnonymized string // excludes rows that were already anonymized
+}
+
+// redactedText replaces free-text clinical content on anonymization.
+const redactedText = "[redacted]"
+
+var retentionTargets = map[string]retentionTarget{
+	domain.RetentionTableRobotSessions: {
+		expiresExpr:   "COALESCE(t.ended_at, t.started_at)",
+		anonymizeSet:  "conversation_summary_encrypted = NULL",
+		notAnonymized: "t.conversation_summary_encrypted IS NOT NULL",
+	},
+	domain.RetentionTableIncidents: {
+		expiresExpr:   "t.resolved_at",
+		anonymizeSet:  "description = '" + redactedText + "', resolution = NULL",
+		notAnonymized: "t.description <> '" + redactedText + "'",
+	},
+	domain.RetentionTableStories: {
+		expiresExpr:   "t.created_at",
+		anonymizeSet:  "content_encrypted = '" + redactedText + "', media_url = NULL",
+		notAnonymized: "t.content_encrypted <> '" + redactedText + "'",
+	},
+}
+
+const retentionPolicyColumns = `id, enterprise_id, table_name, retention_days, action, is_active, created_at, updated_at`
+
+func (r *RetentionPostgres) ListPolicies(ctx context.Context, enterpriseID int64) ([]domain.RetentionPolicy, error) {
+	query := `SELECT ` + retentionPolicyColumns + ` FROM retention_policies WHERE enterprise_id = $1 ORDER BY table_name`
+	return r.queryPolicies(ctx, query, enterpriseID)
+}
+
+func (r *RetentionPostgres) ListActivePolicies(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	query := `SELECT ` + retentionPolicyColumns + ` FROM retention_policies WHERE is_active ORDER BY enterprise_id, table_name`
+	return r.queryPolicies(ctx, query)
+}
+
+func (r *RetentionPostgres) queryPolicies(ctx context.Context, query string, args ...interface{}) ([]domain.RetentionPolicy, error) {
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	policies := make([]domain.RetentionPolicy, 0)
+	for rows.Next() {
+		var p domain.RetentionPolicy
+		if err := rows.Scan(
+			&p.ID, &p.EnterpriseID, &p.TableName, &p.RetentionDays, &p.Action, &p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		policies = append(policies, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return policies, nil
+}
+
+func (r *RetentionPostgres) UpsertPolicy(ctx context.Context, policy *domain.RetentionPolicy) error {
+	query := `INSERT INTO retention_policies (enterprise_id, table_name, retention_days, action, is_active)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (enterprise_id, table_name) DO UPDATE
+			  SET retention_days = EXCLUDED.retention_days, action = EXCLUDED.action, is_active = EXCLUDED.is_active
+			  RETURNING id, created_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		policy.EnterpriseID, policy.TableName, policy.RetentionDays, policy.Action, policy.IsActive,
+	).Scan(&policy.ID, &policy.CreatedAt, &policy.UpdatedAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *RetentionPostgres) DeletePolicy(ctx context.Context, enterpriseID int64, tableName string) error {
+	result, err := database.Conn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM retention_policies WHERE enterprise_id = $1 AND table_name = $2`, enterpriseID, tableName)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("no retention policy for %s", tableName))
+	}
+	return nil
+}
+
+func (r *RetentionPostgres) PurgeBatch(ctx context.Context, policy domain.RetentionPolicy, cutoff time.Time, limit int) ([]int64, error) {
+	target, ok := retentionTargets[policy.TableName]
+	if !ok {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("table %q does not support retention", policy.TableName))
+	}
+
+	selectExpired := fmt.Sprintf(`SELECT t.id FROM %s t
+		JOIN residents r ON r.id = t.resident_id
+		WHERE r.enterprise_id = $1 AND NOT r.legal_hold AND %s < $2`,
+		policy.TableName, target.expiresExpr)
+
+	var mutate string
+	switch policy.Action {
+	case domain.RetentionActionDelete:
+		mutate = fmt.Sprintf(`DELETE FROM %s WHERE id IN (SELECT id FROM batch) RETURNING id`, policy.TableName)
+	case domain.RetentionActionAnonymize:
+		selectExpired += " AND " + target.notAnonymized
+		mutate = fmt.Sprintf(`UPDATE %s SET %s WHERE id IN (SELECT id FROM batch) RETURNING id`,
+			policy.TableName, target.anonymizeSet)
+	default:
+		return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("unknown retention action %q", policy.Action))
+	}
+
+	query := `WITH batch AS (` + selectExpired + ` ORDER BY t.id LIMIT $3 FOR UPDATE OF t SKIP LOCKED) ` + mutate
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, policy.EnterpriseID, cutoff, limit)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return ids, nil
+}
+
+func (r *RetentionPostgres) SetLegalHold(ctx context.Context, residentID int64, enterpriseID *int64, hold bool) error {
+	query := `UPDATE residents SET legal_hold = $2
+			  WHERE id = $1 AND ($3::BIGINT IS NULL OR enterprise_id = $3)`
+
+	result, err := database.Conn(ctx, r.pool).Exec(ctx, query, residentID, hold, enterpriseID)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("resident with id %d not found", residentID))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// internal/repository/postgres/robot_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.RobotRepository = (*RobotPostgres)(nil)
+
+// RobotPostgres implements repository.RobotRepository with PostgreSQL.
+type RobotPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewRobotPostgres creates a new RobotPostgres repository.
+func NewRobotPostgres(pool *pgxpool.Pool, logger *slog.Logger) *RobotPostgres {
+	return &RobotPostgres{pool: pool, logger: logger}
+}
+
+func (r *RobotPostgres) LockQuota(ctx context.Context, enterpriseID int64) (used, limit int, err error) {
+	conn := database.Conn(ctx, r.pool)
+	// The enterprise row is the lock; it is held until the transaction ends.
+	err = conn.QueryRow(ctx,
+		`SELECT max_robots FROM enterprises WHERE id = $1 FOR UPDATE`, enterpriseID).Scan(&limit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("enterprise with id %d not found", enterpriseID))
+		}
+		return 0, 0, domain.NewDatabaseError(err)
+	}
+
+	err = conn.QueryRow(ctx,
+		`SELECT COUNT(*) FROM robots WHERE enterprise_id = $1 AND status <> $2`,
+		enterpriseID, domain.RobotDecommissioned).Scan(&used)
+	if err != nil {
+		return 0, 0, domain.NewDatabaseError(err)
+	}
+	return used, limit, nil
+}
+
+func (r *RobotPostgres) Create(ctx context.Context, robot *domain.Robot) error {
+	query := `INSERT INTO robots (serial_number, enterprise_id, assigned_resident_id, status, firmware_version)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		robot.SerialNumber, robot.EnterpriseID, robot.AssignedResidentID, robot.Status, robot.FirmwareVersion,
+	).Scan(&robot.ID, &robot.CreatedAt, &robot.UpdatedAt)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.NewAppError(domain.ErrAlreadyExists, "robot with this serial number already exists").
+				WithCode(domain.CodeRobotSerialTaken)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *RobotPostgres) RecordHeartbeat(ctx context.Context, robotID int64, status string, firmwareVersion *string) (*domain.Robot, error) {
+	query := `UPDATE robots
+			  SET last_heartbeat = NOW(), status = $2,
+			      firmware_version = COALESCE($3, firmware_version),
+			      offline_notified_at = NULL
+			  WHERE id = $1 AND status <> $4
+			  RETURNING id, serial_number, enterprise_id, assigned_resident_id, status, firmware_version,
+			            last_heartbeat, created_at, updated_at`
+
+	var robot domain.Robot
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, robotID, status, firmwareVersion, domain.RobotDecommissioned).Scan(
+		&robot.ID, &robot.SerialNumber, &robot.EnterpriseID, &robot.AssignedResidentID, &robot.Status,
+		&robot.FirmwareVersion, &robot.LastHeartbeat, &robot.CreatedAt, &robot.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("robot with id %d not found", robotID))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return &robot, nil
+}
@@ -0,0 +1,106 @@
+// internal/repository/postgres/user_import_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.UserImportRepository = (*UserImportPostgres)(nil)
+
+// UserImportPostgres implements repository.UserImportRepository with PostgreSQL.
+type UserImportPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewUserImportPostgres creates a new UserImportPostgres repository.
+func NewUserImportPostgres(pool *pgxpool.Pool, logger *slog.Logger) *UserImportPostgres {
+	return &UserImportPostgres{pool: pool, logger: logger}
+}
+
+const userImportColumns = `id, enterprise_id, requested_by, mode, dry_run, invite, status, results,
+	COALESCE(error, ''), completed_at, created_at, updated_at`
+
+// scanUserImport scans a row of userImportColumns, followed by dest.
+func scanUserImport(row pgx.Row, dest ...any) (*domain.UserImport, error) {
+	var imp domain.UserImport
+	err := row.Scan(append([]any{
+		&imp.ID, &imp.EnterpriseID, &imp.RequestedBy, &imp.Mode, &imp.DryRun, &imp.Invite, &imp.Status, &imp.Results,
+		&imp.Error, &imp.CompletedAt, &imp.CreatedAt, &imp.UpdatedAt,
+	}, dest...)...)
+	return &imp, err
+}
+
+func (r *UserImportPostgres) Create(ctx context.Context, imp *domain.UserImport) error {
+	query := `INSERT INTO user_imports (enterprise_id, requested_by, mode, dry_run, invite, status, rows)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  RETURNING id, created_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		imp.EnterpriseID, imp.RequestedBy, imp.Mode, imp.DryRun, imp.Invite, imp.Status, imp.Rows,
+	).Scan(&imp.ID, &imp.CreatedAt, &imp.UpdatedAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *UserImportPostgres) GetByID(ctx context.Context, id int64) (*domain.UserImport, error) {
+	query := `SELECT ` + userImportColumns + ` FROM user_imports WHERE id = $1`
+
+	imp, err := scanUserImport(database.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user import with id %d not found", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return imp, nil
+}
+
+func (r *UserImportPostgres) Claim(ctx context.Context, id int64) (*domain.UserImport, error) {
+	// A processing import is claimable again: its previous attempt crashed
+	// and the job queue guarantees a single active run per import.
+	query := `UPDATE user_imports SET status = 'processing'
+			  WHERE id = $1 AND status IN ('pending', 'processing')
+			  RETURNING ` + userImportColumns + `, rows`
+
+	var rows []domain.UserImportRow
+	imp, err := scanUserImport(database.Conn(ctx, r.pool).QueryRow(ctx, query, id), &rows)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("no runnable user import with id %d", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	imp.Rows = rows
+	return imp, nil
+}
+
+func (r *UserImportPostgres) Finish(ctx context.Context, imp *domain.UserImport) error {
+	query := `UPDATE user_imports
+			  SET status = $2, results = $3, error = NULLIF($4, ''), completed_at = NOW(), rows = NULL
+			  WHERE id = $1
+			  RETURNING completed_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query, imp.ID, imp.Status, imp.Results, imp.Error).
+		Scan(&imp.CompletedAt, &imp.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user import with id %d not found", imp.ID))
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
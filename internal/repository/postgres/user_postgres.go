@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -13,6 +14,8 @@ import (
 
 	"my-application/internal/domain"
 	"my-application/internal/repository"
+	"my-application/pkg/database"
+	"my-application/pkg/pagination"
 )
 
 // Compile-time interface check.
@@ -30,172 +33,398 @@ func NewUserPostgres(pool *pgxpool.Pool, logger *slog.Logger) *UserPostgres {
 }
 
 // columns shared across single-row queries.
-const userColumns = `id, username, email, password_hash, full_name, role, enterprise_id, firebase_uid, is_active, created_at, updated_at`
+const userColumns = `id, username, email, password_hash, full_name, role, enterprise_id, firebase_uid, locale, is_active, created_at, updated_at, deleted_at, purged_at, version`
 
 // scanUser scans a row into a domain.User.
 func scanUser(row pgx.Row) (*domain.User, error) {
 	var u domain.User
 	err := row.Scan(
 		&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.FullName,
-		&u.Role, &u.EnterpriseID, &u.FirebaseUID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&u.Role, &u.EnterpriseID, &u.FirebaseUID, &u.Locale, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&u.DeletedAt, &u.PurgedAt, &u.Version,
 	)
 	return &u, err
 }
 
 func (r *UserPostgres) GetByID(ctx context.Context, id int64) (*domain.User, error) {
-	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
 
-	u, err := scanUser(r.pool.QueryRow(ctx, query, id))
+	u, err := scanUser(database.Conn(ctx, r.pool).QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", id))
 		}
-		return nil, domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return nil, domain.NewDatabaseError(err)
 	}
 	return u, nil
 }
 
 func (r *UserPostgres) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND deleted_at IS NULL`
 
-	u, err := scanUser(r.pool.QueryRow(ctx, query, email))
+	u, err := scanUser(database.Conn(ctx, r.pool).QueryRow(ctx, query, email))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
 		}
-		return nil, domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return nil, domain.NewDatabaseError(err)
 	}
 	return u, nil
 }
 
 func (r *UserPostgres) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
-	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1 AND deleted_at IS NULL`
 
-	u, err := scanUser(r.pool.QueryRow(ctx, query, username))
+	u, err := scanUser(database.Conn(ctx, r.pool).QueryRow(ctx, query, username))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
 		}
-		return nil, domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return nil, domain.NewDatabaseError(err)
 	}
 	return u, nil
 }
 
 func (r *UserPostgres) GetByFirebaseUID(ctx context.Context, firebaseUID string) (*domain.User, error) {
-	query := `SELECT ` + userColumns + ` FROM users WHERE firebase_uid = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE firebase_uid = $1 AND deleted_at IS NULL`
 
-	u, err := scanUser(r.pool.QueryRow(ctx, query, firebaseUID))
+	u, err := scanUser(database.Conn(ctx, r.pool).QueryRow(ctx, query, firebaseUID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
 		}
-		return nil, domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return nil, domain.NewDatabaseError(err)
 	}
 	return u, nil
 }
 
-func (r *UserPostgres) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, int64, error) {
-	baseQuery := `SELECT ` + userColumns + ` FROM users WHERE 1=1`
-	countQuery := `SELECT COUNT(*) FROM users WHERE 1=1`
-	args := []interface{}{}
-	argIdx := 1
+// userSortColumns are the columns of domain.UserSortFields.
+var userSortColumns = map[string]pagination.Column{
+	"created_at": {Expr: "created_at", Type: "timestamptz"},
+	"updated_at": {Expr: "updated_at", Type: "timestamptz"},
+	"username":   {Expr: "username", Type: "text"},
+	"email":      {Expr: "email", Type: "text"},
+	"full_name":  {Expr: "full_name", Type: "text"},
+}
+
+// userSortValue returns u's value of a domain.UserSortFields field.
+func userSortValue(u *domain.User, field string) any {
+	switch field {
+	case "updated_at":
+		return u.UpdatedAt
+	case "username":
+		return u.Username
+	case "email":
+		return u.Email
+	case "full_name":
+		return u.FullName
+	default:
+		return u.CreatedAt
+	}
+}
+
+// List returns a page of users in filter's order, seeking past the
+// filter's cursor when it has one. It reads one row past the page to know
+// whether there is a next one.
+func (r *UserPostgres) List(ctx context.Context, filter domain.UserFilter) (*domain.UserPage, error) {
+	// Ensure pagination bounds (defensive — service layer normalizes first).
+	filter.Normalize()
+	sort, err := pagination.ParseSort(filter.Sort, domain.UserSortFields)
+	if err != nil {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "invalid sort")
+	}
+	keyset := pagination.Keyset{Sort: sort, Column: userSortColumns[sort.Field], ID: "id"}
+	var cursor pagination.Cursor
+	if filter.Cursor != "" {
+		if cursor, err = keyset.Decode(filter.Cursor); err != nil {
+			return nil, domain.NewAppError(domain.ErrInvalidInput, "invalid cursor")
+		}
+	}
 
+	conditions := []string{"deleted_at IS NULL"}
+	if filter.Deleted {
+		conditions[0] = "deleted_at IS NOT NULL"
+	}
+	args := []interface{}{}
+	where := func(format string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
 	if filter.Role != "" {
-		condition := fmt.Sprintf(" AND role = $%d", argIdx)
-		baseQuery += condition
-		countQuery += condition
-		args = append(args, filter.Role)
-		argIdx++
+		where("role = $%d", filter.Role)
 	}
 	if filter.IsActive != nil {
-		condition := fmt.Sprintf(" AND is_active = $%d", argIdx)
-		baseQuery += condition
-		countQuery += condition
-		args = append(args, *filter.IsActive)
-		argIdx++
+		where("is_active = $%d", *filter.IsActive)
+	}
+	if filter.EnterpriseID != nil {
+		where("enterprise_id = $%d", *filter.EnterpriseID)
+	}
+	if filter.Search != "" {
+		where("(username ILIKE $%[1]d OR email ILIKE $%[1]d OR full_name ILIKE $%[1]d)", "%"+escapeLike(filter.Search)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		where("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		where("created_at < $%d", *filter.CreatedBefore)
 	}
 
-	// Total count.
-	var total int64
-	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, 0, domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+	page := &domain.UserPage{}
+	if filter.WithTotal {
+		var total int64
+		countQuery := `SELECT COUNT(*) FROM users WHERE ` + strings.Join(conditions, " AND ")
+		if err := database.Conn(ctx, r.pool).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		page.Total = &total
 	}
 
-	// Ensure pagination bounds (defensive — service layer normalizes first).
-	filter.Normalize()
+	if filter.Cursor != "" {
+		condition, cursorArgs := keyset.After(cursor, len(args)+1)
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
 
-	baseQuery += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, filter.Limit, filter.Offset)
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY ` + keyset.OrderBy() + fmt.Sprintf(" LIMIT $%d", len(args)+1)
+	args = append(args, filter.Limit+1)
+	if filter.Cursor == "" && filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", len(args)+1)
+		args = append(args, filter.Offset)
+	}
 
-	rows, err := r.pool.Query(ctx, baseQuery, args...)
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
-		return nil, 0, domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return nil, domain.NewDatabaseError(err)
 	}
 	defer rows.Close()
 
-	users := make([]domain.User, 0)
+	users := make([]domain.User, 0, filter.Limit+1)
 	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(
-			&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.FullName,
-			&u.Role, &u.EnterpriseID, &u.FirebaseUID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
-		); err != nil {
-			return nil, 0, domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, domain.NewDatabaseError(err)
 		}
-		users = append(users, u)
+		users = append(users, *u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
 	}
 
-	return users, total, nil
+	users, more := pagination.Trim(users, filter.Limit)
+	if more {
+		last := &users[len(users)-1]
+		page.NextCursor = pagination.NewCursor(sort, userSortValue(last, sort.Field), last.ID).Encode()
+	}
+	page.Users = users
+	return page, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, so s matches itself.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 func (r *UserPostgres) Create(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (username, email, password_hash, full_name, role, enterprise_id, firebase_uid, is_active)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			  RETURNING id, created_at, updated_at`
+	query := `INSERT INTO users (username, email, password_hash, full_name, role, enterprise_id, firebase_uid, locale, is_active)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			  RETURNING id, created_at, updated_at, version`
 
-	err := r.pool.QueryRow(ctx, query,
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
 		user.Username, user.Email, user.PasswordHash, user.FullName,
-		user.Role, user.EnterpriseID, user.FirebaseUID, user.IsActive,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		user.Role, user.EnterpriseID, user.FirebaseUID, user.Locale, user.IsActive,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.Version)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return domain.NewAppError(domain.ErrAlreadyExists, "user with this username or email already exists")
+			return userConflictError(pgErr)
 		}
-		return domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return domain.NewDatabaseError(err)
 	}
 	return nil
 }
 
+// Update replaces the user's profile. When user.Version is set, the row is
+// only updated if it still has that version; otherwise the update fails
+// with ErrPreconditionFailed. The new version is read back into user.
 func (r *UserPostgres) Update(ctx context.Context, user *domain.User) error {
-	query := `UPDATE users SET username=$1, email=$2, full_name=$3, role=$4, enterprise_id=$5, is_active=$6
-			  WHERE id=$7 RETURNING updated_at`
+	query := `UPDATE users SET username=$1, email=$2, full_name=$3, role=$4, enterprise_id=$5, locale=$6, is_active=$7
+			  WHERE id=$8 AND deleted_at IS NULL AND ($9::bigint = 0 OR version = $9)
+			  RETURNING updated_at, version`
 
-	err := r.pool.QueryRow(ctx, query,
-		user.Username, user.Email, user.FullName, user.Role, user.EnterpriseID, user.IsActive, user.ID,
-	).Scan(&user.UpdatedAt)
+	conn := database.Conn(ctx, r.pool)
+	err := conn.QueryRow(ctx, query,
+		user.Username, user.Email, user.FullName, user.Role, user.EnterpriseID, user.Locale, user.IsActive, user.ID,
+		user.Version,
+	).Scan(&user.UpdatedAt, &user.Version)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
+			if user.Version != 0 {
+				var exists bool
+				if err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`,
+					user.ID).Scan(&exists); err != nil {
+					return domain.NewDatabaseError(err)
+				}
+				if exists {
+					return userVersionError(user.ID, user.Version)
+				}
+			}
 			return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", user.ID))
 		}
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return domain.NewAppError(domain.ErrAlreadyExists, "user with this username or email already exists")
+			return userConflictError(pgErr)
 		}
-		return domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return domain.NewDatabaseError(err)
 	}
 	return nil
 }
 
+func (r *UserPostgres) SetCredentials(ctx context.Context, id int64, passwordHash string, firebaseUID *string) error {
+	query := `UPDATE users
+			  SET password_hash = CASE WHEN $2 = '' THEN password_hash ELSE $2 END,
+			      firebase_uid = COALESCE($3, firebase_uid)
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := database.Conn(ctx, r.pool).Exec(ctx, query, id, passwordHash, firebaseUID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return userConflictError(pgErr)
+		}
+		return domain.NewDatabaseError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", id))
+	}
+	return nil
+}
+
+// userVersionError reports that user id no longer has the expected version.
+func userVersionError(id, version int64) error {
+	return domain.NewAppError(domain.ErrPreconditionFailed,
+		fmt.Sprintf("user with id %d has changed since version %d", id, version))
+}
+
 func (r *UserPostgres) Delete(ctx context.Context, id int64) error {
-	result, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	result, err := database.Conn(ctx, r.pool).Exec(ctx, `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
 	if err != nil {
-		return domain.NewAppError(domain.ErrDatabaseOperation, err.Error())
+		return domain.NewDatabaseError(err)
 	}
 	if result.RowsAffected() == 0 {
 		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", id))
 	}
 	return nil
 }
+
+func (r *UserPostgres) Restore(ctx context.Context, id int64) (*domain.User, error) {
+	query := `UPDATE users SET deleted_at = NULL
+			  WHERE id = $1 AND deleted_at IS NOT NULL AND purged_at IS NULL
+			  RETURNING ` + userColumns
+
+	u, err := scanUser(database.Conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("deleted user with id %d not found", id))
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, userConflictError(pgErr) // the username or email was reused meanwhile
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return u, nil
+}
+
+// Purge replaces every personal field with a placeholder derived from the
+// user ID and drops resident assignments. Stories and incidents keep their
+// author_id/reporter_id, which now point at the anonymized tombstone.
+func (r *UserPostgres) Purge(ctx context.Context, id int64) error {
+	tx, err := database.Conn(ctx, r.pool).Begin(ctx)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	query := `UPDATE users SET
+				username = 'purged_' || id,
+				email = 'purged_' || id || '@purged.invalid',
+				full_name = 'Purged User',
+				password_hash = '',
+				firebase_uid = NULL,
+				is_active = false,
+				purged_at = NOW()
+			  WHERE id = $1 AND deleted_at IS NOT NULL AND purged_at IS NULL`
+
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("deleted user with id %d not found", id))
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM caregiver_residents WHERE caregiver_id = $1`, id); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *UserPostgres) Taken(ctx context.Context, usernames, emails []string) ([]string, []string, error) {
+	query := `SELECT username, email FROM users WHERE (username = ANY($1) OR email = ANY($2)) AND deleted_at IS NULL`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, usernames, emails)
+	if err != nil {
+		return nil, nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	wantUsernames := make(map[string]bool, len(usernames))
+	for _, u := range usernames {
+		wantUsernames[u] = true
+	}
+	wantEmails := make(map[string]bool, len(emails))
+	for _, e := range emails {
+		wantEmails[e] = true
+	}
+	var takenUsernames, takenEmails []string
+	for rows.Next() {
+		var username, email string
+		if err := rows.Scan(&username, &email); err != nil {
+			return nil, nil, domain.NewDatabaseError(err)
+		}
+		if wantUsernames[username] {
+			takenUsernames = append(takenUsernames, username)
+		}
+		if wantEmails[email] {
+			takenEmails = append(takenEmails, email)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, domain.NewDatabaseError(err)
+	}
+	return takenUsernames, takenEmails, nil
+}
+
+// userConflictError describes a unique violation on users, telling a taken
+// email from a taken username by the violated constraint.
+func userConflictError(pgErr *pgconn.PgError) error {
+	switch pgErr.ConstraintName {
+	case "users_email_key":
+		return domain.NewAppError(domain.ErrAlreadyExists, "user with this email already exists").
+			WithCode(domain.CodeUserEmailTaken)
+	case "users_username_key":
+		return domain.NewAppError(domain.ErrAlreadyExists, "user with this username already exists").
+			WithCode(domain.CodeUserUsernameTaken)
+	case "users_firebase_uid_key":
+		return domain.NewAppError(domain.ErrAlreadyExists, "user with this Firebase account already exists")
+	default:
+		return domain.NewAppError(domain.ErrAlreadyExists, "user with this username or email already exists")
+	}
+}
@@ -0,0 +1,282 @@
+// internal/repository/postgres/webhook_postgres.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/pkg/database"
+)
+
+// Compile-time interface check.
+var _ repository.WebhookRepository = (*WebhookPostgres)(nil)
+
+// WebhookPostgres implements repository.WebhookRepository with PostgreSQL.
+type WebhookPostgres struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewWebhookPostgres creates a new WebhookPostgres repository.
+func NewWebhookPostgres(pool *pgxpool.Pool, logger *slog.Logger) *WebhookPostgres {
+	return &WebhookPostgres{pool: pool, logger: logger}
+}
+
+const webhookSubscriptionColumns = `id, enterprise_id, url, description, event_types, secret, is_active,
+	consecutive_failures, failing_since, disabled_at, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, subscription_id, event_id::TEXT, event_type, payload, status, attempts,
+	response_code, response_time_ms, last_error, last_attempt_at, delivered_at, created_at`
+
+func scanWebhookSubscription(row pgx.Row, s *domain.WebhookSubscription) error {
+	return row.Scan(
+		&s.ID, &s.EnterpriseID, &s.URL, &s.Description, &s.EventTypes, &s.Secret, &s.IsActive,
+		&s.ConsecutiveFailures, &s.FailingSince, &s.DisabledAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+}
+
+func scanWebhookDelivery(row pgx.Row, d *domain.WebhookDelivery) error {
+	return row.Scan(
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.ResponseCode, &d.ResponseTimeMS, &d.LastError, &d.LastAttemptAt, &d.DeliveredAt, &d.CreatedAt,
+	)
+}
+
+func (r *WebhookPostgres) List(ctx context.Context, enterpriseID int64) ([]domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE enterprise_id = $1 ORDER BY id`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, enterpriseID)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	subs := make([]domain.WebhookSubscription, 0)
+	for rows.Next() {
+		var s domain.WebhookSubscription
+		if err := scanWebhookSubscription(rows, &s); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		subs = append(subs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return subs, nil
+}
+
+func (r *WebhookPostgres) ListSubscribed(ctx context.Context, enterpriseID int64, eventType string) ([]domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions
+			  WHERE enterprise_id = $1 AND is_active AND $2 = ANY (event_types)
+			  ORDER BY id`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, enterpriseID, eventType)
+	if err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	var subs []domain.WebhookSubscription
+	for rows.Next() {
+		var s domain.WebhookSubscription
+		if err := scanWebhookSubscription(rows, &s); err != nil {
+			return nil, domain.NewDatabaseError(err)
+		}
+		subs = append(subs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.NewDatabaseError(err)
+	}
+	return subs, nil
+}
+
+func (r *WebhookPostgres) Get(ctx context.Context, enterpriseID, id int64) (*domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1 AND enterprise_id = $2`
+
+	var s domain.WebhookSubscription
+	if err := scanWebhookSubscription(database.Conn(ctx, r.pool).QueryRow(ctx, query, id, enterpriseID), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("webhook with id %d not found", id))
+		}
+		return nil, domain.NewDatabaseError(err)
+	}
+	return &s, nil
+}
+
+func (r *WebhookPostgres) Create(ctx context.Context, sub *domain.WebhookSubscription) error {
+	query := `INSERT INTO webhook_subscriptions (enterprise_id, url, description, event_types, secret, is_active)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		sub.EnterpriseID, sub.URL, sub.Description, sub.EventTypes, sub.Secret, sub.IsActive,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) Update(ctx context.Context, sub *domain.WebhookSubscription) error {
+	query := `UPDATE webhook_subscriptions
+			  SET url = $3, description = $4, event_types = $5, is_active = $6,
+			      consecutive_failures = CASE WHEN $6 AND NOT is_active THEN 0 ELSE consecutive_failures END,
+			      failing_since = CASE WHEN $6 AND NOT is_active THEN NULL ELSE failing_since END,
+			      disabled_at = CASE WHEN $6 THEN NULL ELSE disabled_at END
+			  WHERE id = $1 AND enterprise_id = $2
+			  RETURNING consecutive_failures, failing_since, disabled_at, created_at, updated_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		sub.ID, sub.EnterpriseID, sub.URL, sub.Description, sub.EventTypes, sub.IsActive,
+	).Scan(&sub.ConsecutiveFailures, &sub.FailingSince, &sub.DisabledAt, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("webhook with id %d not found", sub.ID))
+		}
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) Delete(ctx context.Context, enterpriseID, id int64) error {
+	result, err := database.Conn(ctx, r.pool).Exec(ctx,
+		`DELETE FROM webhook_subscriptions WHERE id = $1 AND enterprise_id = $2`, id, enterpriseID)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("webhook with id %d not found", id))
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) CreateDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries
+			      (subscription_id, event_id, event_type, payload, status, attempts,
+			       response_code, response_time_ms, last_error, last_attempt_at, delivered_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			  RETURNING id, created_at`
+
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		d.SubscriptionID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts,
+		d.ResponseCode, d.ResponseTimeMS, d.LastError, d.LastAttemptAt, d.DeliveredAt,
+	).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) ListDeliveries(ctx context.Context, subscriptionID int64, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int64, error) {
+	var total int64
+	if err := database.Conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT COUNT(*) FROM webhook_deliveries WHERE subscription_id = $1`, subscriptionID,
+	).Scan(&total); err != nil {
+		return nil, 0, domain.NewDatabaseError(err)
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = $1
+			  ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`
+
+	rows, err := database.Conn(ctx, r.pool).Query(ctx, query, subscriptionID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, domain.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &d); err != nil {
+			return nil, 0, domain.NewDatabaseError(err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, domain.NewDatabaseError(err)
+	}
+	return deliveries, total, nil
+}
+
+func (r *WebhookPostgres) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, *domain.WebhookSubscription, error) {
+	var d domain.WebhookDelivery
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	if err := scanWebhookDelivery(database.Conn(ctx, r.pool).QueryRow(ctx, query, id), &d); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("webhook delivery with id %d not found", id))
+		}
+		return nil, nil, domain.NewDatabaseError(err)
+	}
+
+	var s domain.WebhookSubscription
+	query = `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	if err := scanWebhookSubscription(database.Conn(ctx, r.pool).QueryRow(ctx, query, d.SubscriptionID), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("webhook with id %d not found", d.SubscriptionID))
+		}
+		return nil, nil, domain.NewDatabaseError(err)
+	}
+	return &d, &s, nil
+}
+
+func (r *WebhookPostgres) SaveAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+			  SET status = $2, attempts = $3, response_code = $4, response_time_ms = $5, last_error = $6,
+			      last_attempt_at = $7, delivered_at = $8
+			  WHERE id = $1`
+
+	result, err := database.Conn(ctx, r.pool).Exec(ctx, query,
+		d.ID, d.Status, d.Attempts, d.ResponseCode, d.ResponseTimeMS, d.LastError, d.LastAttemptAt, d.DeliveredAt)
+	if err != nil {
+		return domain.NewDatabaseError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("webhook delivery with id %d not found", d.ID))
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) RecordResult(ctx context.Context, subscriptionID int64, ok bool, policy domain.WebhookDisablePolicy) (bool, error) {
+	if ok {
+		_, err := database.Conn(ctx, r.pool).Exec(ctx,
+			`UPDATE webhook_subscriptions SET consecutive_failures = 0, failing_since = NULL
+			 WHERE id = $1 AND consecutive_failures > 0`, subscriptionID)
+		if err != nil {
+			return false, domain.NewDatabaseError(err)
+		}
+		return false, nil
+	}
+
+	// The subscription row is locked while the streak is extended, so
+	// concurrent failures cannot both miss or both report the threshold.
+	query := `UPDATE webhook_subscriptions s
+			  SET consecutive_failures = s.consecutive_failures + 1,
+			      failing_since = COALESCE(s.failing_since, NOW()),
+			      is_active = s.is_active AND NOT v.trip,
+			      disabled_at = CASE WHEN s.is_active AND v.trip THEN NOW() ELSE s.disabled_at END
+			  FROM (SELECT id, is_active AS was_active,
+			               consecutive_failures + 1 >= $2
+			               AND COALESCE(failing_since, NOW()) <= NOW() - make_interval(secs => $3) AS trip
+			        FROM webhook_subscriptions WHERE id = $1 FOR UPDATE) v
+			  WHERE s.id = v.id
+			  RETURNING v.was_active AND v.trip`
+
+	var disabled bool
+	err := database.Conn(ctx, r.pool).QueryRow(ctx, query,
+		subscriptionID, policy.Failures, policy.After.Seconds(),
+	).Scan(&disabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil // deleted meanwhile
+		}
+		return false, domain.NewDatabaseError(err)
+	}
+	return disabled, nil
+}
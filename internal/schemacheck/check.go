@@ -0,0 +1,255 @@
+// internal/schemacheck/check.go
+
+// Package schemacheck compares the roles and tables defined in Go, the
+// schema built by the SQL migrations and the Hasura metadata, and reports
+// where they have drifted apart. cmd/schemacheck runs it against the
+// repository, and so does a test.
+package schemacheck
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Spec is what the Go code expects of the schema and metadata.
+type Spec struct {
+	// Roles are the user roles (domain.Roles). RoleColumn's CHECK
+	// constraint must allow exactly these.
+	Roles      []string
+	RoleColumn string // "table.column"
+	// HasuraRoles are roles that exist only in Hasura, such as the
+	// unauthenticated role of the auth actions.
+	HasuraRoles []string
+	// Untracked tables are internal to the API and worker and must not be
+	// tracked by Hasura.
+	Untracked []string
+	// Hidden columns, keyed by table, must not be selectable by any role.
+	// Every other column of a tracked table must be selectable by some role.
+	Hidden map[string][]string
+	// TenantRole is the database role of tenant-scoped transactions. Every
+	// table it holds privileges on must have row-level security enabled.
+	TenantRole string
+}
+
+// Issue kinds.
+const (
+	IssueRoleMismatch   = "role-mismatch"
+	IssueUnknownRole    = "unknown-role"
+	IssueUnusedRole     = "unused-role"
+	IssueInvalidDefault = "invalid-default"
+	IssueUnknownTable   = "unknown-table"
+	IssueUntrackedTable = "untracked-table"
+	IssueInternalTable  = "internal-table"
+	IssueUnknownColumn  = "unknown-column"
+	IssueUnselectable   = "unselectable-column"
+	IssueHiddenExposed  = "hidden-column-exposed"
+	IssueNoRowSecurity  = "no-row-security"
+)
+
+// Issue is one mismatch found by Check.
+type Issue struct {
+	Kind    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return i.Kind + ": " + i.Message
+}
+
+// Check compares schema and md against spec and returns the mismatches,
+// sorted.
+func Check(spec Spec, schema *Schema, md *Metadata) []Issue {
+	c := &checker{spec: spec, schema: schema, md: md}
+	c.checkRoleColumn()
+	c.checkDefaults()
+	c.checkTables()
+	c.checkRoles()
+	c.checkRowSecurity()
+
+	sort.Slice(c.issues, func(i, j int) bool {
+		if c.issues[i].Kind != c.issues[j].Kind {
+			return c.issues[i].Kind < c.issues[j].Kind
+		}
+		return c.issues[i].Message < c.issues[j].Message
+	})
+	return c.issues
+}
+
+type checker struct {
+	spec   Spec
+	schema *Schema
+	md     *Metadata
+	issues []Issue
+}
+
+func (c *checker) report(kind, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Kind: kind, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkRoleColumn compares the Go roles with the role column's CHECK.
+func (c *checker) checkRoleColumn() {
+	table, column, _ := strings.Cut(c.spec.RoleColumn, ".")
+	t, ok := c.schema.Tables[table]
+	if !ok || !t.HasColumn(column) {
+		c.report(IssueRoleMismatch, "role column %s does not exist", c.spec.RoleColumn)
+		return
+	}
+	allowed, ok := t.AllowedValues(column)
+	if !ok {
+		c.report(IssueRoleMismatch, "%s has no CHECK constraint listing the roles", c.spec.RoleColumn)
+		return
+	}
+	for _, role := range c.spec.Roles {
+		if !slices.Contains(allowed, role) {
+			c.report(IssueRoleMismatch, "role %q is defined in Go but not allowed by the CHECK on %s", role, c.spec.RoleColumn)
+		}
+	}
+	for _, role := range allowed {
+		if !slices.Contains(c.spec.Roles, role) {
+			c.report(IssueRoleMismatch, "role %q is allowed by the CHECK on %s but not defined in Go", role, c.spec.RoleColumn)
+		}
+	}
+}
+
+// checkDefaults reports column defaults their own CHECK rejects, which
+// make every insert relying on the default fail.
+func (c *checker) checkDefaults() {
+	for name, t := range c.schema.Tables {
+		for column, def := range t.Defaults {
+			if allowed, ok := t.AllowedValues(column); ok && !slices.Contains(allowed, def) {
+				c.report(IssueInvalidDefault, "default %q of %s.%s is not allowed by its CHECK (%s)",
+					def, name, column, strings.Join(allowed, ", "))
+			}
+		}
+	}
+}
+
+// checkTables compares the tracked tables and their permission columns
+// with the schema.
+func (c *checker) checkTables() {
+	tracked := make(map[string]bool)
+	for i := range c.md.Tables {
+		tm := &c.md.Tables[i]
+		name := tm.Table.Name
+		tracked[name] = true
+
+		t, ok := c.schema.Tables[name]
+		if !ok {
+			c.report(IssueUnknownTable, "%s tracks table %s, which no migration creates", tm.File, name)
+			continue
+		}
+		if slices.Contains(c.spec.Untracked, name) {
+			c.report(IssueInternalTable, "internal table %s is tracked by Hasura (%s)", name, tm.File)
+		}
+
+		selectable := make(map[string]bool)
+		for kind, perms := range tm.Permissions() {
+			for _, p := range perms {
+				columns := p.Permission.Columns.Names
+				if p.Permission.Columns.All {
+					columns = t.Columns
+				}
+				for _, col := range columns {
+					if !t.HasColumn(col) {
+						c.report(IssueUnknownColumn, "%s permission of %s on %s names unknown column %s", kind, p.Role, name, col)
+					}
+					if kind == "select" {
+						selectable[col] = true
+					}
+				}
+				for col := range p.Permission.Set {
+					if !t.HasColumn(col) {
+						c.report(IssueUnknownColumn, "%s permission of %s on %s presets unknown column %s", kind, p.Role, name, col)
+					}
+				}
+			}
+		}
+		for _, trigger := range tm.EventTriggers {
+			d := trigger.Definition
+			for _, op := range []*struct{ Columns Columns }{d.Insert, d.Update, d.Delete} {
+				if op == nil {
+					continue
+				}
+				for _, col := range op.Columns.Names {
+					if !t.HasColumn(col) {
+						c.report(IssueUnknownColumn, "event trigger %s on %s names unknown column %s", trigger.Name, name, col)
+					}
+				}
+			}
+		}
+
+		hidden := c.spec.Hidden[name]
+		for _, col := range t.Columns {
+			switch {
+			case slices.Contains(hidden, col) && selectable[col]:
+				c.report(IssueHiddenExposed, "hidden column %s.%s is selectable in Hasura", name, col)
+			case !slices.Contains(hidden, col) && !selectable[col]:
+				c.report(IssueUnselectable, "column %s.%s is not selectable by any role; add it to a select permission or to the hidden columns", name, col)
+			}
+		}
+		for _, col := range hidden {
+			if !t.HasColumn(col) {
+				c.report(IssueUnknownColumn, "hidden column %s.%s does not exist", name, col)
+			}
+		}
+	}
+
+	for name := range c.schema.Tables {
+		if !tracked[name] && !slices.Contains(c.spec.Untracked, name) {
+			c.report(IssueUntrackedTable, "table %s is not tracked by Hasura nor listed as internal", name)
+		}
+	}
+	for _, name := range c.spec.Untracked {
+		if _, ok := c.schema.Tables[name]; !ok {
+			c.report(IssueUnknownTable, "internal table %s does not exist", name)
+		}
+	}
+}
+
+// checkRoles reports Hasura roles unknown to Go, and Go roles without any
+// Hasura permission.
+func (c *checker) checkRoles() {
+	known := append(slices.Clone(c.spec.Roles), c.spec.HasuraRoles...)
+	used := make(map[string]bool)
+
+	for i := range c.md.Tables {
+		tm := &c.md.Tables[i]
+		for kind, perms := range tm.Permissions() {
+			for _, p := range perms {
+				used[p.Role] = true
+				if !slices.Contains(known, p.Role) {
+					c.report(IssueUnknownRole, "%s permission on %s is granted to unknown role %q", kind, tm.Table.Name, p.Role)
+				}
+			}
+		}
+	}
+	for _, a := range c.md.Actions {
+		for _, p := range a.Permissions {
+			used[p.Role] = true
+			if !slices.Contains(known, p.Role) {
+				c.report(IssueUnknownRole, "action %s is granted to unknown role %q", a.Name, p.Role)
+			}
+		}
+	}
+	for _, role := range known {
+		if !used[role] {
+			c.report(IssueUnusedRole, "role %q has no permission in the Hasura metadata", role)
+		}
+	}
+}
+
+// checkRowSecurity reports tables the tenant role may use without
+// row-level security, which would expose every enterprise's rows.
+func (c *checker) checkRowSecurity() {
+	if c.spec.TenantRole == "" {
+		return
+	}
+	for name, t := range c.schema.Tables {
+		if t.Granted(c.spec.TenantRole) && !t.RowSecurity {
+			c.report(IssueNoRowSecurity, "table %s is granted to %s (%s) without row-level security",
+				name, c.spec.TenantRole, strings.Join(t.Grants[c.spec.TenantRole], ", "))
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// internal/schemacheck/metadata.go
+package schemacheck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata is the part of the Hasura metadata the checks need: the tracked
+// tables with their permissions and event triggers, and the action
+// permissions.
+type Metadata struct {
+	Tables  []TableMetadata
+	Actions []ActionMetadata
+}
+
+// TableMetadata is one tracked table's metadata file.
+type TableMetadata struct {
+	File  string `yaml:"-"`
+	Table struct {
+		Name   string `yaml:"name"`
+		Schema string `yaml:"schema"`
+	} `yaml:"table"`
+	SelectPermissions []Permission   `yaml:"select_permissions"`
+	InsertPermissions []Permission   `yaml:"insert_permissions"`
+	UpdatePermissions []Permission   `yaml:"update_permissions"`
+	DeletePermissions []Permission   `yaml:"delete_permissions"`
+	EventTriggers     []EventTrigger `yaml:"event_triggers"`
+}
+
+// Permission is a role's permission on a table.
+type Permission struct {
+	Role       string `yaml:"role"`
+	Permission struct {
+		Columns Columns           `yaml:"columns"`
+		Set     map[string]string `yaml:"set"`
+	} `yaml:"permission"`
+}
+
+// EventTrigger is an event trigger on a table.
+type EventTrigger struct {
+	Name       string `yaml:"name"`
+	Definition struct {
+		Insert *struct{ Columns Columns } `yaml:"insert"`
+		Update *struct{ Columns Columns } `yaml:"update"`
+		Delete *struct{ Columns Columns } `yaml:"delete"`
+	} `yaml:"definition"`
+}
+
+// ActionMetadata is an action's entry in actions.yaml.
+type ActionMetadata struct {
+	Name        string `yaml:"name"`
+	Permissions []struct {
+		Role string `yaml:"role"`
+	} `yaml:"permissions"`
+}
+
+// Columns is a column list, or all columns when given as '*'.
+type Columns struct {
+	All   bool
+	Names []string
+}
+
+// UnmarshalYAML accepts either '*' or a list of column names.
+func (c *Columns) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Value == "*" {
+		c.All = true
+		return nil
+	}
+	return node.Decode(&c.Names)
+}
+
+// LoadMetadata reads the Hasura metadata in dir (hasura/metadata).
+func LoadMetadata(dir string) (*Metadata, error) {
+	tablesDir := filepath.Join(dir, "databases", "default", "tables")
+	var includes []string
+	if err := readYAML(filepath.Join(tablesDir, "tables.yaml"), &includes); err != nil {
+		return nil, err
+	}
+
+	md := &Metadata{}
+	for _, inc := range includes {
+		file, ok := strings.CutPrefix(inc, "!include ")
+		if !ok {
+			return nil, fmt.Errorf("tables.yaml: unsupported entry %q", inc)
+		}
+		var t TableMetadata
+		if err := readYAML(filepath.Join(tablesDir, file), &t); err != nil {
+			return nil, err
+		}
+		t.File = file
+		md.Tables = append(md.Tables, t)
+	}
+
+	var actions struct {
+		Actions []ActionMetadata `yaml:"actions"`
+	}
+	if err := readYAML(filepath.Join(dir, "actions.yaml"), &actions); err != nil {
+		return nil, err
+	}
+	md.Actions = actions.Actions
+	return md, nil
+}
+
+// Permissions returns the table's permissions keyed by their kind.
+func (t *TableMetadata) Permissions() map[string][]Permission {
+	return map[string][]Permission{
+		"select": t.SelectPermissions,
+		"insert": t.InsertPermissions,
+		"update": t.UpdatePermissions,
+		"delete": t.DeletePermissions,
+	}
+}
+
+func readYAML(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,447 @@
+// internal/schemacheck/schema.go
+package schemacheck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Schema is the database schema the up migrations build, as far as the
+// checks need it: tables, their columns, the values CHECK constraints allow
+// for enum-like columns, and which database roles may use each table.
+type Schema struct {
+	Tables map[string]*Table
+	// DefaultGrants holds the table privileges of each database role on
+	// tables created from now on (ALTER DEFAULT PRIVILEGES).
+	DefaultGrants map[string][]string
+}
+
+// Table is one table of the schema.
+type Table struct {
+	Name    string
+	Columns []string
+	// Defaults holds the literal default of each column that has one.
+	Defaults map[string]string
+	// Checks holds the "column IN (...)" CHECK constraints by name.
+	Checks map[string]CheckConstraint
+	// Grants holds the privileges of each database role on the table.
+	Grants map[string][]string
+	// RowSecurity reports whether row-level security is enabled.
+	RowSecurity bool
+}
+
+// CheckConstraint is a CHECK constraint restricting a column to a set of values.
+type CheckConstraint struct {
+	Column string
+	Values []string
+}
+
+// HasColumn reports whether the table has the column.
+func (t *Table) HasColumn(name string) bool {
+	return slices.Contains(t.Columns, name)
+}
+
+// Granted reports whether role holds any privilege on the table.
+func (t *Table) Granted(role string) bool {
+	return len(t.Grants[role]) > 0
+}
+
+// AllowedValues returns the values the CHECK constraints allow for column,
+// and false if none restricts it.
+func (t *Table) AllowedValues(column string) ([]string, bool) {
+	names := make([]string, 0, len(t.Checks))
+	for name := range t.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c := t.Checks[name]; c.Column == column {
+			return c.Values, true
+		}
+	}
+	return nil, false
+}
+
+// LoadSchema applies the *.up.sql migrations in dir in order.
+func LoadSchema(dir string) (*Schema, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	s := &Schema{Tables: make(map[string]*Table)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Apply(string(data)); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return s, nil
+}
+
+var (
+	createTableRe = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w.]+)\s*\((.*)\)`)
+	alterTableRe  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w.]+)\s+(.*)$`)
+	renameTableRe = regexp.MustCompile(`(?is)^RENAME\s+TO\s+(\w+)$`)
+	dropTableRe   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?([\w.,\s]+?)(?:\s+CASCADE|\s+RESTRICT)?$`)
+	checkInRe     = regexp.MustCompile(`(?is)CHECK\s*\(\s*(\w+)\s+IN\s*\(([^)]*)\)\s*\)`)
+	defaultRe     = regexp.MustCompile(`(?is)\bDEFAULT\s+'([^']*)'`)
+	constraintRe  = regexp.MustCompile(`(?is)^CONSTRAINT\s+(\w+)\s+(.*)$`)
+	grantRe       = regexp.MustCompile(`(?is)^GRANT\s+(.+?)\s+ON\s+(.+?)\s+TO\s+(.+?)(?:\s+WITH\s+GRANT\s+OPTION)?$`)
+	revokeRe      = regexp.MustCompile(`(?is)^REVOKE\s+(?:GRANT\s+OPTION\s+FOR\s+)?(.+?)\s+ON\s+(.+?)\s+FROM\s+(.+?)(?:\s+CASCADE|\s+RESTRICT)?$`)
+	defaultPrivRe = regexp.MustCompile(`(?is)^ALTER\s+DEFAULT\s+PRIVILEGES\s+(?:.*?\s+)?((?:GRANT|REVOKE)\s+.*)$`)
+	allTablesRe   = regexp.MustCompile(`(?is)^ALL\s+TABLES\s+IN\s+SCHEMA\s+`)
+	otherObjectRe = regexp.MustCompile(`(?is)^(?:ALL\s+\w+\s+IN\s+SCHEMA|SCHEMA|SEQUENCE|FUNCTION|PROCEDURE|ROUTINE|DATABASE|TYPE|DOMAIN|LANGUAGE|FOREIGN|LARGE|TABLESPACE|PARAMETER)\b`)
+	sqlCommentRe  = regexp.MustCompile(`--[^\n]*`)
+	dollarQuoteRe = regexp.MustCompile(`(?s)\$\$.*?\$\$`)
+)
+
+// Apply runs the DDL statements of one migration against the schema.
+// Statements it does not model, such as indexes, triggers, policies and
+// privileges on objects other than tables, are ignored.
+func (s *Schema) Apply(sql string) error {
+	sql = dollarQuoteRe.ReplaceAllString(sqlCommentRe.ReplaceAllString(sql, ""), "''")
+	for _, stmt := range strings.Split(sql, ";") {
+		stmt = strings.TrimSpace(stmt)
+		var err error
+		switch {
+		case createTableRe.MatchString(stmt):
+			m := createTableRe.FindStringSubmatch(stmt)
+			err = s.createTable(tableName(m[1]), m[2])
+		case alterTableRe.MatchString(stmt):
+			m := alterTableRe.FindStringSubmatch(stmt)
+			err = s.alterTable(tableName(m[1]), m[2])
+		case defaultPrivRe.MatchString(stmt):
+			s.defaultPrivileges(defaultPrivRe.FindStringSubmatch(stmt)[1])
+		case grantRe.MatchString(stmt):
+			m := grantRe.FindStringSubmatch(stmt)
+			err = s.privileges(true, m[1], m[2], m[3])
+		case revokeRe.MatchString(stmt):
+			m := revokeRe.FindStringSubmatch(stmt)
+			err = s.privileges(false, m[1], m[2], m[3])
+		case dropTableRe.MatchString(stmt):
+			for _, name := range strings.Split(dropTableRe.FindStringSubmatch(stmt)[1], ",") {
+				delete(s.Tables, tableName(name))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) createTable(name, body string) error {
+	if _, ok := s.Tables[name]; ok {
+		// CREATE TABLE IF NOT EXISTS of an existing table does nothing.
+		return nil
+	}
+	t := &Table{Name: name, Defaults: make(map[string]string), Checks: make(map[string]CheckConstraint),
+		Grants: make(map[string][]string)}
+	for role, privs := range s.DefaultGrants {
+		t.Grants[role] = slices.Clone(privs)
+	}
+	for _, item := range splitTopLevel(body) {
+		if isTableConstraint(item) {
+			t.addConstraint(item)
+			continue
+		}
+		t.addColumn(item)
+	}
+	s.Tables[name] = t
+	return nil
+}
+
+func (s *Schema) alterTable(name, actions string) error {
+	t, ok := s.Tables[name]
+	if !ok {
+		return fmt.Errorf("ALTER TABLE of unknown table %s", name)
+	}
+	if m := renameTableRe.FindStringSubmatch(actions); m != nil {
+		delete(s.Tables, name)
+		t.Name = m[1]
+		s.Tables[t.Name] = t
+		return nil
+	}
+
+	for _, action := range splitTopLevel(actions) {
+		words := strings.Fields(action)
+		switch {
+		case hasPrefixFold(words, "ADD", "COLUMN"):
+			t.addColumn(strings.Join(trimIfNotExists(words[2:]), " "))
+		case hasPrefixFold(words, "ADD", "CONSTRAINT"):
+			t.addConstraint(strings.Join(words[1:], " "))
+		case hasPrefixFold(words, "ADD"):
+			if isTableConstraint(strings.Join(words[1:], " ")) {
+				t.addConstraint(strings.Join(words[1:], " "))
+			} else {
+				t.addColumn(strings.Join(trimIfNotExists(words[1:]), " "))
+			}
+		case hasPrefixFold(words, "ENABLE", "ROW", "LEVEL", "SECURITY"):
+			t.RowSecurity = true
+		case hasPrefixFold(words, "DISABLE", "ROW", "LEVEL", "SECURITY"):
+			t.RowSecurity = false
+		case hasPrefixFold(words, "DROP", "COLUMN"):
+			t.dropColumn(firstWord(trimIfExists(words[2:])))
+		case hasPrefixFold(words, "DROP", "CONSTRAINT"):
+			delete(t.Checks, firstWord(trimIfExists(words[2:])))
+		case hasPrefixFold(words, "RENAME", "COLUMN") && len(words) >= 5:
+			t.renameColumn(words[2], words[4])
+		case hasPrefixFold(words, "ALTER", "COLUMN") && len(words) >= 4:
+			t.alterColumn(words[2], strings.Join(words[3:], " "))
+		case hasPrefixFold(words, "ALTER") && len(words) >= 3:
+			t.alterColumn(words[1], strings.Join(words[2:], " "))
+		}
+	}
+	return nil
+}
+
+// tablePrivileges are the privileges ALL stands for on a table.
+var tablePrivileges = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"}
+
+// privileges applies a GRANT (grant is true) or REVOKE of privs on objects
+// to roles. Only table privileges are modelled.
+func (s *Schema) privileges(grant bool, privs, objects, roles string) error {
+	var tables []*Table
+	switch {
+	case allTablesRe.MatchString(objects):
+		for _, t := range s.Tables {
+			tables = append(tables, t)
+		}
+	case otherObjectRe.MatchString(objects):
+		return nil
+	default:
+		if words := strings.Fields(objects); hasPrefixFold(words, "TABLE") {
+			objects = strings.Join(words[1:], " ")
+		}
+		for _, name := range strings.Split(objects, ",") {
+			t, ok := s.Tables[tableName(name)]
+			if !ok {
+				return fmt.Errorf("privileges on unknown table %s", tableName(name))
+			}
+			tables = append(tables, t)
+		}
+	}
+	for _, t := range tables {
+		if t.Grants == nil {
+			t.Grants = make(map[string][]string)
+		}
+		applyPrivileges(t.Grants, grant, privs, roles)
+	}
+	return nil
+}
+
+// defaultPrivileges applies the GRANT or REVOKE of an ALTER DEFAULT
+// PRIVILEGES statement to the privileges of tables created later.
+func (s *Schema) defaultPrivileges(stmt string) {
+	var grant bool
+	var m []string
+	if m = grantRe.FindStringSubmatch(stmt); m != nil {
+		grant = true
+	} else if m = revokeRe.FindStringSubmatch(stmt); m == nil {
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(m[2]), "TABLES") {
+		return
+	}
+	if s.DefaultGrants == nil {
+		s.DefaultGrants = make(map[string][]string)
+	}
+	applyPrivileges(s.DefaultGrants, grant, m[1], m[3])
+}
+
+// applyPrivileges adds privs to, or removes them from, each of roles in
+// grants.
+func applyPrivileges(grants map[string][]string, grant bool, privs, roles string) {
+	var list []string
+	for _, p := range splitTopLevel(privs) {
+		p = strings.ToUpper(firstWord(strings.Fields(p)))
+		if p == "ALL" {
+			list = append(list, tablePrivileges...)
+		} else if p != "" {
+			list = append(list, p)
+		}
+	}
+	for _, role := range strings.Split(roles, ",") {
+		role = unquote(strings.TrimSpace(role))
+		held := grants[role]
+		for _, p := range list {
+			switch {
+			case grant && !slices.Contains(held, p):
+				held = append(held, p)
+			case !grant:
+				held = slices.DeleteFunc(held, func(h string) bool { return h == p })
+			}
+		}
+		if len(held) == 0 {
+			delete(grants, role)
+		} else {
+			grants[role] = held
+		}
+	}
+}
+
+func (t *Table) addColumn(def string) {
+	name := unquote(firstWord(strings.Fields(def)))
+	if name == "" || t.HasColumn(name) {
+		return
+	}
+	t.Columns = append(t.Columns, name)
+	if m := defaultRe.FindStringSubmatch(def); m != nil {
+		t.Defaults[name] = m[1]
+	}
+	// An inline CHECK gets Postgres's default name, <table>_<column>_check.
+	if m := checkInRe.FindStringSubmatch(def); m != nil && m[1] == name {
+		t.Checks[t.Name+"_"+name+"_check"] = CheckConstraint{Column: name, Values: literals(m[2])}
+	}
+}
+
+func (t *Table) addConstraint(def string) {
+	name := ""
+	if m := constraintRe.FindStringSubmatch(def); m != nil {
+		name, def = m[1], m[2]
+	}
+	m := checkInRe.FindStringSubmatch(def)
+	if m == nil {
+		return
+	}
+	if name == "" {
+		name = t.Name + "_" + m[1] + "_check"
+	}
+	t.Checks[name] = CheckConstraint{Column: m[1], Values: literals(m[2])}
+}
+
+func (t *Table) dropColumn(name string) {
+	t.Columns = slices.DeleteFunc(t.Columns, func(c string) bool { return c == name })
+	delete(t.Defaults, name)
+	for k, c := range t.Checks {
+		if c.Column == name {
+			delete(t.Checks, k)
+		}
+	}
+}
+
+func (t *Table) renameColumn(from, to string) {
+	if i := slices.Index(t.Columns, from); i >= 0 {
+		t.Columns[i] = to
+	}
+	if d, ok := t.Defaults[from]; ok {
+		delete(t.Defaults, from)
+		t.Defaults[to] = d
+	}
+	for k, c := range t.Checks {
+		if c.Column == from {
+			c.Column = to
+			t.Checks[k] = c
+		}
+	}
+}
+
+func (t *Table) alterColumn(name, action string) {
+	words := strings.Fields(action)
+	switch {
+	case hasPrefixFold(words, "SET", "DEFAULT"):
+		if m := defaultRe.FindStringSubmatch(action); m != nil {
+			t.Defaults[name] = m[1]
+		} else {
+			delete(t.Defaults, name)
+		}
+	case hasPrefixFold(words, "DROP", "DEFAULT"):
+		delete(t.Defaults, name)
+	}
+}
+
+// splitTopLevel splits a comma-separated list, ignoring commas inside
+// parentheses and string literals.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start, quoted := 0, 0, false
+	for i, r := range s {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+func isTableConstraint(item string) bool {
+	words := strings.Fields(item)
+	for _, kw := range []string{"CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "EXCLUDE"} {
+		if hasPrefixFold(words, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// literals returns the values of a list of SQL string literals.
+func literals(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		values = append(values, strings.Trim(strings.TrimSpace(v), "'"))
+	}
+	return values
+}
+
+func hasPrefixFold(words []string, prefix ...string) bool {
+	if len(words) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if !strings.EqualFold(words[i], p) {
+			return false
+		}
+	}
+	return true
+}
+
+func trimIfNotExists(words []string) []string {
+	if hasPrefixFold(words, "IF", "NOT", "EXISTS") {
+		return words[3:]
+	}
+	return words
+}
+
+func trimIfExists(words []string) []string {
+	if hasPrefixFold(words, "IF", "EXISTS") {
+		return words[2:]
+	}
+	return words
+}
+
+func firstWord(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0]
+}
+
+// tableName strips the public schema and quotes from a table name.
+func tableName(name string) string {
+	return unquote(strings.TrimPrefix(strings.TrimSpace(name), "public."))
+}
+
+func unquote(name string) string {
+	return strings.Trim(name, `"`)
+}
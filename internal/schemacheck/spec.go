@@ -0,0 +1,46 @@
+// internal/schemacheck/spec.go
+package schemacheck
+
+import (
+	"my-application/internal/domain"
+	"my-application/pkg/database"
+)
+
+// ProjectSpec returns the expectations of this repository's Go code.
+func ProjectSpec() Spec {
+	return Spec{
+		Roles:      domain.Roles,
+		RoleColumn: "users.role",
+		// The auth actions are called before sign-in.
+		HasuraRoles: []string{"anonymous"},
+		// Served by the REST API or only used by the API and worker.
+		Untracked: []string{
+			"audit_log",
+			"data_exports",
+			"retention_policies",
+			"jobs",
+			"notifications",
+			"notification_preferences",
+			"push_devices",
+			"outbox_events",
+			"outbox_deliveries",
+			"webhook_subscriptions",
+			"webhook_deliveries",
+			"hasura_processed_events",
+			"idempotency_keys",
+			"user_imports",
+			"invitations",
+		},
+		Hidden: map[string][]string{
+			"users": {
+				"password_hash",
+				// Notification settings, served by /notifications/preferences.
+				"quiet_hours_start",
+				"quiet_hours_end",
+			},
+			// Worker bookkeeping for robot-offline notifications.
+			"robots": {"offline_notified_at"},
+		},
+		TenantRole: database.RoleTenant,
+	}
+}
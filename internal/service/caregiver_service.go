@@ -0,0 +1,96 @@
+// internal/service/caregiver_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ CaregiverService = (*caregiverService)(nil)
+
+type caregiverService struct {
+	userRepo     repository.UserRepository
+	residentRepo repository.ResidentRepository
+	auditRepo    repository.AuditRepository
+	tx           repository.Transactor
+	logger       *slog.Logger
+}
+
+// NewCaregiverService creates a new CaregiverService.
+func NewCaregiverService(
+	userRepo repository.UserRepository,
+	residentRepo repository.ResidentRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
+	logger *slog.Logger,
+) CaregiverService {
+	return &caregiverService{
+		userRepo:     userRepo,
+		residentRepo: residentRepo,
+		auditRepo:    auditRepo,
+		tx:           tx,
+		logger:       logger,
+	}
+}
+
+func (s *caregiverService) AssignResident(ctx context.Context, caregiverID, residentID int64, enterpriseID *int64, actorID int64) (*domain.CaregiverAssignment, error) {
+	if caregiverID <= 0 || residentID <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "caregiver and resident IDs must be positive")
+	}
+
+	residentEnterprise, err := s.residentRepo.GetEnterpriseID(ctx, residentID)
+	if err != nil {
+		return nil, err
+	}
+	if enterpriseID != nil && *enterpriseID != residentEnterprise {
+		return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("resident with id %d not found", residentID))
+	}
+
+	caregiver, err := s.userRepo.GetByID(ctx, caregiverID)
+	if err != nil {
+		return nil, err
+	}
+	if caregiver.Role != "caregiver" || !caregiver.IsActive {
+		return nil, domain.NewValidationError("validation failed", map[string]string{
+			"caregiver_id": "user is not an active caregiver",
+		})
+	}
+	if caregiver.EnterpriseID == nil || *caregiver.EnterpriseID != residentEnterprise {
+		return nil, domain.NewValidationError("validation failed", map[string]string{
+			"caregiver_id": "caregiver belongs to another enterprise",
+		})
+	}
+
+	assignment := &domain.CaregiverAssignment{CaregiverID: caregiverID, ResidentID: residentID}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		n, err := s.residentRepo.LockCaregiverAssignments(ctx, caregiverID)
+		if err != nil {
+			return err
+		}
+		if n >= domain.MaxResidentsPerCaregiver {
+			return domain.NewAppError(domain.ErrForbidden,
+				fmt.Sprintf("caregiver already has the maximum of %d residents", domain.MaxResidentsPerCaregiver)).
+				WithCode(domain.CodeCaregiverResidentLimit)
+		}
+		return s.residentRepo.AssignCaregiver(ctx, assignment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EnterpriseID: &residentEnterprise,
+		ActorID:      &actorID,
+		Action:       "caregiver.assigned",
+		EntityType:   "resident",
+		EntityID:     &residentID,
+		Metadata:     map[string]interface{}{"caregiver_id": caregiverID},
+	})
+	return assignment, nil
+}
@@ -0,0 +1,164 @@
+// internal/service/data_export_service.go
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ DataExportService = (*dataExportService)(nil)
+
+type dataExportService struct {
+	userRepo   repository.UserRepository
+	exportRepo repository.DataExportRepository
+	auditRepo  repository.AuditRepository
+	enqueuer   jobs.Enqueuer
+	tx         repository.Transactor
+	logger     *slog.Logger
+}
+
+// NewDataExportService creates a new DataExportService.
+func NewDataExportService(
+	userRepo repository.UserRepository,
+	exportRepo repository.DataExportRepository,
+	auditRepo repository.AuditRepository,
+	enqueuer jobs.Enqueuer,
+	tx repository.Transactor,
+	logger *slog.Logger,
+) DataExportService {
+	return &dataExportService{
+		userRepo:   userRepo,
+		exportRepo: exportRepo,
+		auditRepo:  auditRepo,
+		enqueuer:   enqueuer,
+		tx:         tx,
+		logger:     logger,
+	}
+}
+
+func (s *dataExportService) RequestExport(ctx context.Context, userID, requestedBy int64, enterpriseID *int64) (*domain.DataExport, error) {
+	if userID <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "user ID must be positive")
+	}
+
+	user, err := s.scopedUser(ctx, userID, enterpriseID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &domain.DataExport{
+		UserID:      userID,
+		RequestedBy: &requestedBy,
+		Status:      domain.ExportStatusPending,
+	}
+	// The build job commits with the export, so no export is left pending
+	// without one.
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.exportRepo.Create(ctx, export); err != nil {
+			return err
+		}
+		_, err := s.enqueuer.Enqueue(ctx, jobs.ExportBuildArgs{ExportID: export.ID}, jobs.ExportBuildOptions(export.ID)...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EnterpriseID: user.EnterpriseID,
+		ActorID:      &requestedBy,
+		Action:       "data_export.requested",
+		EntityType:   "user",
+		EntityID:     &userID,
+		Metadata:     map[string]interface{}{"export_id": export.ID},
+	})
+
+	return export, nil
+}
+
+func (s *dataExportService) GetExport(ctx context.Context, id int64, enterpriseID *int64) (*domain.DataExport, error) {
+	if id <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "export ID must be positive")
+	}
+	export, err := s.exportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if enterpriseID != nil {
+		if _, err := s.scopedUser(ctx, export.UserID, enterpriseID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("data export with id %d not found", id))
+			}
+			return nil, err
+		}
+	}
+	return export, nil
+}
+
+// scopedUser returns user id, reporting users outside a non-nil
+// enterpriseID as not found.
+func (s *dataExportService) scopedUser(ctx context.Context, id int64, enterpriseID *int64) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if enterpriseID != nil {
+		var userEnterprise int64
+		if user.EnterpriseID != nil {
+			userEnterprise = *user.EnterpriseID
+		}
+		if userEnterprise != *enterpriseID {
+			return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("user with id %d not found", id))
+		}
+	}
+	return user, nil
+}
+
+func (s *dataExportService) IssueDownloadLink(ctx context.Context, export *domain.DataExport) error {
+	if export.Status != domain.ExportStatusCompleted || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil
+	}
+	if err := export.RotateToken(); err != nil {
+		return err
+	}
+	return s.exportRepo.SetTokenHash(ctx, export.ID, export.DownloadTokenHash)
+}
+
+func (s *dataExportService) OpenDownload(ctx context.Context, id int64, token string) (*domain.DataExport, error) {
+	export, err := s.GetExport(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every mismatch is reported identically so the link cannot be probed.
+	denied := domain.NewAppError(domain.ErrNotFound, "download link is invalid or has expired").
+		WithCode(domain.CodeExportLinkInvalid)
+	if export.Status != domain.ExportStatusCompleted || len(export.DownloadTokenHash) == 0 {
+		return nil, denied
+	}
+	if subtle.ConstantTimeCompare(export.DownloadTokenHash, domain.ExportTokenHash(token)) != 1 {
+		return nil, denied
+	}
+	if export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, denied
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EntityType: "user",
+		Action:     "data_export.downloaded",
+		EntityID:   &export.UserID,
+		Metadata:   map[string]interface{}{"export_id": export.ID},
+	})
+
+	return export, nil
+}
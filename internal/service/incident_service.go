@@ -0,0 +1,114 @@
+// internal/service/incident_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ IncidentService = (*incidentService)(nil)
+
+type incidentService struct {
+	incidentRepo repository.IncidentRepository
+	residentRepo repository.ResidentRepository
+	auditRepo    repository.AuditRepository
+	outboxRepo   repository.OutboxRepository
+	tx           repository.Transactor
+	logger       *slog.Logger
+}
+
+// NewIncidentService creates a new IncidentService. Resolutions are
+// published as domain events in the outbox, in the same transaction.
+func NewIncidentService(
+	incidentRepo repository.IncidentRepository,
+	residentRepo repository.ResidentRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	tx repository.Transactor,
+	logger *slog.Logger,
+) IncidentService {
+	return &incidentService{
+		incidentRepo: incidentRepo,
+		residentRepo: residentRepo,
+		auditRepo:    auditRepo,
+		outboxRepo:   outboxRepo,
+		tx:           tx,
+		logger:       logger,
+	}
+}
+
+func (s *incidentService) ResolveIncident(ctx context.Context, id int64, resolution string, scope *int64, actorID int64, actorRole string) (*domain.Incident, error) {
+	if id <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "incident ID must be positive")
+	}
+	resolution = strings.TrimSpace(resolution)
+	if resolution == "" {
+		return nil, domain.NewValidationError("validation failed", map[string]string{
+			"resolution": "resolution is required",
+		})
+	}
+
+	incident, err := s.incidentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var enterpriseID *int64
+	if eid, err := s.residentRepo.GetEnterpriseID(ctx, incident.ResidentID); err == nil {
+		enterpriseID = &eid
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if scope != nil && (enterpriseID == nil || *enterpriseID != *scope) {
+		return nil, domain.NewAppError(domain.ErrNotFound, fmt.Sprintf("incident with id %d not found", id))
+	}
+
+	if actorRole == "caregiver" {
+		assigned, err := s.residentRepo.ListIDsByCaregiver(ctx, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(assigned, incident.ResidentID) {
+			return nil, domain.NewAppError(domain.ErrForbidden, "access denied: resident is not assigned to you").
+				WithCode(domain.CodeResidentNotAssigned)
+		}
+	}
+
+	incident.Resolution = &resolution
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.incidentRepo.Resolve(ctx, incident); err != nil {
+			return err
+		}
+		event, err := domain.NewIncidentEvent(domain.EventIncidentResolved, enterpriseID, domain.IncidentEventPayload{
+			IncidentID: incident.ID,
+			ResidentID: incident.ResidentID,
+			Severity:   incident.Severity,
+			ActorID:    &actorID,
+		})
+		if err != nil {
+			return err
+		}
+		return s.outboxRepo.Append(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EnterpriseID: enterpriseID,
+		ActorID:      &actorID,
+		Action:       domain.EventIncidentResolved,
+		EntityType:   domain.AggregateIncident,
+		EntityID:     &incident.ID,
+		Metadata:     map[string]interface{}{"resident_id": incident.ResidentID, "severity": incident.Severity},
+	})
+	return incident, nil
+}
@@ -3,15 +3,159 @@ package service
 
 import (
 	"context"
+	"io"
 
 	"my-application/internal/domain"
+	"my-application/internal/realtime"
 )
 
 // UserService defines business operations for Users.
 type UserService interface {
 	GetUser(ctx context.Context, id int64) (*domain.User, error)
-	ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, int64, error)
+	ListUsers(ctx context.Context, filter domain.UserFilter) (*domain.UserPage, error)
 	CreateUser(ctx context.Context, user *domain.User) error
+	// UpdateUser replaces the user's profile with user's fields; only the
+	// enterprise is kept. A non-zero user.Version must be the current one.
 	UpdateUser(ctx context.Context, user *domain.User) error
+	// PatchUser changes the fields of the user that patch sets and returns
+	// the result. A non-zero version must be the current one.
+	PatchUser(ctx context.Context, id int64, patch domain.UserPatch, version int64) (*domain.User, error)
 	DeleteUser(ctx context.Context, id int64) error
+	RestoreUser(ctx context.Context, id int64) (*domain.User, error)
+	PurgeUser(ctx context.Context, id int64) error
+}
+
+// UserImportService defines bulk user imports.
+type UserImportService interface {
+	// ImportUsers reads the users of src, a file in format (see
+	// ParseUserImport), and imports them as imp says. Small files are
+	// imported before it returns, with imp completed; larger ones are stored
+	// for the worker and imp is pending.
+	ImportUsers(ctx context.Context, imp *domain.UserImport, src io.Reader, format string) error
+	// GetImport returns an import run by the worker.
+	GetImport(ctx context.Context, id int64) (*domain.UserImport, error)
+	// RunImport imports imp.Rows, however many, and sets imp's results.
+	// Rows failing are reported in the results; an error means the import
+	// could not run.
+	RunImport(ctx context.Context, imp *domain.UserImport) error
+}
+
+// InvitationService defines business operations for user invitations.
+// Creating or resending an invitation emails its link.
+type InvitationService interface {
+	// ListInvitations returns the enterprise's pending and expired
+	// invitations.
+	ListInvitations(ctx context.Context, enterpriseID int64) ([]domain.Invitation, error)
+	// Invite invites inv.Email with inv's role and enterprise on behalf of
+	// actorID. An expired invitation to the same address is revoked; a
+	// pending one is a conflict.
+	Invite(ctx context.Context, inv *domain.Invitation, actorID int64) error
+	// InviteUser invites an existing user without credentials, such as an
+	// imported one, to set them. Users with roles outside
+	// domain.InvitableRoles cannot be invited. It runs in the caller's
+	// transaction, which records its own audit event.
+	InviteUser(ctx context.Context, user *domain.User, invitedBy *int64) (*domain.Invitation, error)
+	// Resend emails a new link of a pending or expired invitation, which
+	// is valid for a new period; the previous link stops working.
+	Resend(ctx context.Context, enterpriseID, id int64, actorID int64) (*domain.Invitation, error)
+	Revoke(ctx context.Context, enterpriseID, id int64, actorID int64) (*domain.Invitation, error)
+}
+
+// DataExportService defines business operations for personal data exports.
+type DataExportService interface {
+	// RequestExport queues an export of userID's data on behalf of
+	// requestedBy. A non-nil enterpriseID restricts it to users of that
+	// enterprise (0 for users without one).
+	RequestExport(ctx context.Context, userID, requestedBy int64, enterpriseID *int64) (*domain.DataExport, error)
+	// GetExport returns an export. A non-nil enterpriseID restricts it to
+	// exports of users of that enterprise.
+	GetExport(ctx context.Context, id int64, enterpriseID *int64) (*domain.DataExport, error)
+	// IssueDownloadLink gives a completed, unexpired export a new download
+	// token, leaving it in export.DownloadToken. Only the token's hash is
+	// stored, so every link issued replaces the previous one.
+	IssueDownloadLink(ctx context.Context, export *domain.DataExport) error
+	// OpenDownload validates a download token and returns the completed export.
+	OpenDownload(ctx context.Context, id int64, token string) (*domain.DataExport, error)
+}
+
+// RetentionService defines business operations for data retention policies.
+type RetentionService interface {
+	ListPolicies(ctx context.Context, enterpriseID int64) ([]domain.RetentionPolicy, error)
+	SetPolicy(ctx context.Context, policy *domain.RetentionPolicy, actorID int64) error
+	DeletePolicy(ctx context.Context, enterpriseID int64, tableName string, actorID int64) error
+	// SetLegalHold suspends (or resumes) purging for a resident. A non-nil
+	// enterpriseID restricts the change to residents of that enterprise.
+	SetLegalHold(ctx context.Context, residentID int64, enterpriseID *int64, hold bool, actorID int64) error
+}
+
+// NotificationService defines business operations for a user's notifications.
+type NotificationService interface {
+	// ListNotifications returns a page of the user's inbox, the total matching
+	// the filter and the overall unread count.
+	ListNotifications(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, int64, int64, error)
+	MarkRead(ctx context.Context, userID, id int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	// GetSettings returns quiet hours and a preference for every type.
+	GetSettings(ctx context.Context, userID int64) (*domain.NotificationSettings, error)
+	// UpdateSettings replaces quiet hours and the listed type preferences.
+	UpdateSettings(ctx context.Context, userID int64, settings *domain.NotificationSettings) (*domain.NotificationSettings, error)
+	RegisterDevice(ctx context.Context, device *domain.PushDevice) error
+	UnregisterDevice(ctx context.Context, userID int64, token string) error
+}
+
+// RealtimeService defines the live event stream for dashboards.
+type RealtimeService interface {
+	// Subscribe opens a stream of the events a user with the given role and
+	// enterprise may see, replaying events after lastEventID when the buffer
+	// still holds them. The caller must Close the subscription.
+	Subscribe(ctx context.Context, userID int64, role string, enterpriseID int64, lastEventID string) (*realtime.Subscription, error)
+}
+
+// WebhookService defines business operations for enterprise webhooks.
+type WebhookService interface {
+	ListSubscriptions(ctx context.Context, enterpriseID int64) ([]domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, enterpriseID, id int64) (*domain.WebhookSubscription, error)
+	// CreateSubscription generates the signing secret; it is only available
+	// on the subscription passed in.
+	CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription, actorID int64) error
+	UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription, actorID int64) error
+	DeleteSubscription(ctx context.Context, enterpriseID, id int64, actorID int64) error
+	ListDeliveries(ctx context.Context, enterpriseID, id int64, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int64, error)
+	// SendTest sends a webhook.test event right away and records the outcome
+	// in the delivery log.
+	SendTest(ctx context.Context, enterpriseID, id int64) (*domain.WebhookDelivery, error)
+}
+
+// TableEventService reacts to rows changed through Hasura rather than the
+// API, recording what the API would have: audit entries and domain events.
+// Both run in the caller's transaction and their errors are returned.
+type TableEventService interface {
+	IncidentChanged(ctx context.Context, change *domain.TableChange[domain.Incident]) error
+	StoryChanged(ctx context.Context, change *domain.TableChange[domain.Story]) error
+}
+
+// RobotService defines business operations for robots.
+type RobotService interface {
+	// ProvisionRobot registers a new robot within its enterprise's
+	// max_robots quota.
+	ProvisionRobot(ctx context.Context, robot *domain.Robot, actorID int64) error
+	// RecordHeartbeat stores a robot's heartbeat. status must be one of the
+	// statuses a running robot reports: active, idle or maintenance.
+	RecordHeartbeat(ctx context.Context, robotID int64, status string, firmwareVersion *string) (*domain.Robot, error)
+}
+
+// IncidentService defines business operations for incidents.
+type IncidentService interface {
+	// ResolveIncident closes an open incident. A non-nil enterpriseID
+	// restricts it to that enterprise; caregivers may only resolve
+	// incidents of the residents assigned to them.
+	ResolveIncident(ctx context.Context, id int64, resolution string, enterpriseID *int64, actorID int64, actorRole string) (*domain.Incident, error)
+}
+
+// CaregiverService defines business operations for caregiver assignments.
+type CaregiverService interface {
+	// AssignResident puts a resident in a caregiver's care, up to
+	// domain.MaxResidentsPerCaregiver residents. A non-nil enterpriseID
+	// restricts the change to that enterprise.
+	AssignResident(ctx context.Context, caregiverID, residentID int64, enterpriseID *int64, actorID int64) (*domain.CaregiverAssignment, error)
 }
@@ -0,0 +1,200 @@
+// internal/service/invitation_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ InvitationService = (*invitationService)(nil)
+
+// InvitationConfig holds settings for user invitations.
+type InvitationConfig struct {
+	// TTL is how long an invitation link can be accepted.
+	TTL time.Duration
+}
+
+type invitationService struct {
+	invitationRepo repository.InvitationRepository
+	userRepo       repository.UserRepository
+	auditRepo      repository.AuditRepository
+	enqueuer       jobs.Enqueuer
+	tx             repository.Transactor
+	config         InvitationConfig
+	logger         *slog.Logger
+}
+
+// NewInvitationService creates a new InvitationService.
+func NewInvitationService(
+	invitationRepo repository.InvitationRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	enqueuer jobs.Enqueuer,
+	tx repository.Transactor,
+	config InvitationConfig,
+	logger *slog.Logger,
+) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		enqueuer:       enqueuer,
+		tx:             tx,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context, enterpriseID int64) ([]domain.Invitation, error) {
+	return s.invitationRepo.ListOpen(ctx, enterpriseID)
+}
+
+func (s *invitationService) Invite(ctx context.Context, inv *domain.Invitation, actorID int64) error {
+	inv.Email = strings.ToLower(strings.TrimSpace(inv.Email))
+	if err := checkInvitableRole(inv.Role); err != nil {
+		return err
+	}
+	_, taken, err := s.userRepo.Taken(ctx, nil, []string{inv.Email})
+	if err != nil {
+		return err
+	}
+	if len(taken) > 0 {
+		return domain.NewAppError(domain.ErrAlreadyExists, "user with this email already exists").
+			WithCode(domain.CodeUserEmailTaken)
+	}
+	inv.InvitedBy = &actorID
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.create(ctx, inv)
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, inv.AuditEvent(&actorID, "invitation.created"))
+	return nil
+}
+
+func (s *invitationService) InviteUser(ctx context.Context, user *domain.User, invitedBy *int64) (*domain.Invitation, error) {
+	if err := checkInvitableRole(user.Role); err != nil {
+		return nil, err
+	}
+	inv := &domain.Invitation{
+		EnterpriseID: user.EnterpriseID,
+		Email:        user.Email,
+		Role:         user.Role,
+		FullName:     user.FullName,
+		Locale:       user.Locale,
+		UserID:       &user.ID,
+		InvitedBy:    invitedBy,
+	}
+	if err := s.create(ctx, inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// checkInvitableRole returns a validation error of the role field unless
+// users can be invited with role.
+func checkInvitableRole(role string) error {
+	if slices.Contains(domain.InvitableRoles, role) {
+		return nil
+	}
+	return i18n.ValidationError(map[string]domain.Violation{
+		"role": {Rule: "one_of", Params: map[string]string{"values": strings.Join(domain.InvitableRoles, ", ")}},
+	})
+}
+
+// create stores inv, revoking an expired invitation to the same address
+// first, and queues its email. It must run within a transaction.
+func (s *invitationService) create(ctx context.Context, inv *domain.Invitation) error {
+	open, err := s.invitationRepo.GetOpenByEmail(ctx, inv.Email)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+	case err != nil:
+		return err
+	case open.Status(time.Now()) == domain.InvitationExpired:
+		if err := s.invitationRepo.Revoke(ctx, open); err != nil {
+			return err
+		}
+	default:
+		return domain.NewAppError(domain.ErrAlreadyExists, "email already has a pending invitation").
+			WithCode(domain.CodeInvitationPending)
+	}
+
+	if err := inv.NewToken(s.config.TTL); err != nil {
+		return err
+	}
+	if err := s.invitationRepo.Create(ctx, inv); err != nil {
+		return err
+	}
+	return s.send(ctx, inv)
+}
+
+func (s *invitationService) Resend(ctx context.Context, enterpriseID, id int64, actorID int64) (*domain.Invitation, error) {
+	var inv *domain.Invitation
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if inv, err = s.getOpen(ctx, enterpriseID, id); err != nil {
+			return err
+		}
+		if err := inv.NewToken(s.config.TTL); err != nil {
+			return err
+		}
+		if err := s.invitationRepo.Renew(ctx, inv); err != nil {
+			return err
+		}
+		return s.send(ctx, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, inv.AuditEvent(&actorID, "invitation.resent"))
+	return inv, nil
+}
+
+func (s *invitationService) Revoke(ctx context.Context, enterpriseID, id int64, actorID int64) (*domain.Invitation, error) {
+	inv, err := s.getOpen(ctx, enterpriseID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.invitationRepo.Revoke(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, inv.AuditEvent(&actorID, "invitation.revoked"))
+	return inv, nil
+}
+
+// getOpen returns the invitation unless it was accepted or revoked.
+func (s *invitationService) getOpen(ctx context.Context, enterpriseID, id int64) (*domain.Invitation, error) {
+	inv, err := s.invitationRepo.Get(ctx, enterpriseID, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.AcceptedAt != nil || inv.RevokedAt != nil {
+		return nil, domain.NewAppError(domain.ErrAlreadyExists, fmt.Sprintf("invitation with id %d is not pending", id)).
+			WithCode(domain.CodeInvitationNotPending)
+	}
+	return inv, nil
+}
+
+// send queues the invitation's email. The worker replaces the token inv was
+// given here with the one it emails, so this one is never sent anywhere.
+func (s *invitationService) send(ctx context.Context, inv *domain.Invitation) error {
+	_, err := s.enqueuer.Enqueue(ctx, jobs.InvitationSendArgs{InvitationID: inv.ID},
+		jobs.InvitationSendOptions(inv.ID, inv.TokenHash)...)
+	return err
+}
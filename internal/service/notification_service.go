@@ -0,0 +1,137 @@
+// internal/service/notification_service.go
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ NotificationService = (*notificationService)(nil)
+
+// maxPushTokenLength matches push_devices.token.
+const maxPushTokenLength = 512
+
+var pushPlatforms = []string{domain.PushPlatformIOS, domain.PushPlatformAndroid, domain.PushPlatformWeb}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	logger           *slog.Logger
+}
+
+// NewNotificationService creates a new NotificationService.
+func NewNotificationService(notificationRepo repository.NotificationRepository, logger *slog.Logger) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		logger:           logger,
+	}
+}
+
+func (s *notificationService) ListNotifications(
+	ctx context.Context, userID int64, filter domain.NotificationFilter,
+) ([]domain.Notification, int64, int64, error) {
+	filter.Normalize()
+
+	notifications, total, err := s.notificationRepo.List(ctx, userID, filter)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, id int64) error {
+	if id <= 0 {
+		return domain.NewAppError(domain.ErrInvalidInput, "notification ID must be positive")
+	}
+	return s.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+func (s *notificationService) GetSettings(ctx context.Context, userID int64) (*domain.NotificationSettings, error) {
+	stored, err := s.notificationRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &domain.NotificationSettings{
+		QuietHours:  stored.QuietHours,
+		Preferences: make([]domain.NotificationPreference, 0, len(domain.NotificationTypes)),
+	}
+	for _, t := range domain.NotificationTypes {
+		pref := domain.DefaultNotificationPreference(t)
+		for _, p := range stored.Preferences {
+			if p.Type == t {
+				pref = p
+			}
+		}
+		settings.Preferences = append(settings.Preferences, pref)
+	}
+	return settings, nil
+}
+
+func (s *notificationService) UpdateSettings(
+	ctx context.Context, userID int64, settings *domain.NotificationSettings,
+) (*domain.NotificationSettings, error) {
+	details := make(map[string]string)
+	if settings.QuietHours != nil {
+		if err := settings.QuietHours.Validate(); err != nil {
+			details["quiet_hours"] = "quiet_hours start and end must be HH:MM times"
+		}
+	}
+	seen := make(map[string]bool)
+	for _, p := range settings.Preferences {
+		if !slices.Contains(domain.NotificationTypes, p.Type) {
+			details["preferences"] = "type must be one of: " + strings.Join(domain.NotificationTypes, ", ")
+			break
+		}
+		if seen[p.Type] {
+			details["preferences"] = "each type may appear only once"
+			break
+		}
+		seen[p.Type] = true
+	}
+	if len(details) > 0 {
+		return nil, domain.NewValidationError("validation failed", details)
+	}
+
+	if err := s.notificationRepo.UpdateSettings(ctx, userID, settings); err != nil {
+		return nil, err
+	}
+	return s.GetSettings(ctx, userID)
+}
+
+func (s *notificationService) RegisterDevice(ctx context.Context, device *domain.PushDevice) error {
+	device.Token = strings.TrimSpace(device.Token)
+
+	details := make(map[string]string)
+	if device.Token == "" || len(device.Token) > maxPushTokenLength {
+		details["token"] = "token is required and must be at most 512 characters"
+	}
+	if !slices.Contains(pushPlatforms, device.Platform) {
+		details["platform"] = "platform must be one of: " + strings.Join(pushPlatforms, ", ")
+	}
+	if len(details) > 0 {
+		return domain.NewValidationError("validation failed", details)
+	}
+
+	return s.notificationRepo.UpsertDevice(ctx, device)
+}
+
+func (s *notificationService) UnregisterDevice(ctx context.Context, userID int64, token string) error {
+	if token == "" {
+		return domain.NewAppError(domain.ErrInvalidInput, "token is required")
+	}
+	return s.notificationRepo.DeleteDevice(ctx, userID, token)
+}
@@ -0,0 +1,60 @@
+// internal/service/realtime_service.go
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"my-application/internal/domain"
+	"my-application/internal/realtime"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ RealtimeService = (*realtimeService)(nil)
+
+type realtimeService struct {
+	hub          *realtime.Hub
+	residentRepo repository.ResidentRepository
+	logger       *slog.Logger
+}
+
+// NewRealtimeService creates a new RealtimeService.
+func NewRealtimeService(hub *realtime.Hub, residentRepo repository.ResidentRepository, logger *slog.Logger) RealtimeService {
+	return &realtimeService{
+		hub:          hub,
+		residentRepo: residentRepo,
+		logger:       logger,
+	}
+}
+
+func (s *realtimeService) Subscribe(
+	ctx context.Context, userID int64, role string, enterpriseID int64, lastEventID string,
+) (*realtime.Subscription, error) {
+	var audience domain.RealtimeAudience
+	switch role {
+	case "mta":
+		audience.All = true
+	case "eta":
+		if enterpriseID == 0 {
+			return nil, domain.NewAppError(domain.ErrForbidden, "no enterprise assigned")
+		}
+		audience.EnterpriseID = enterpriseID
+	case "caregiver":
+		// Assignments are read once per connection; a caregiver sees a new
+		// resident's events after reconnecting.
+		ids, err := s.residentRepo.ListIDsByCaregiver(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		audience.ResidentIDs = make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			audience.ResidentIDs[id] = true
+		}
+	default:
+		return nil, domain.NewAppError(domain.ErrForbidden, "event stream not available for this role").
+			WithCode(domain.CodeRoleNotAllowed)
+	}
+
+	return s.hub.Subscribe(audience, lastEventID), nil
+}
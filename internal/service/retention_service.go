@@ -0,0 +1,128 @@
+// internal/service/retention_service.go
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ RetentionService = (*retentionService)(nil)
+
+// maxRetentionDays caps policies at 100 years to catch unit mistakes.
+const maxRetentionDays = 36500
+
+type retentionService struct {
+	retentionRepo repository.RetentionRepository
+	auditRepo     repository.AuditRepository
+	logger        *slog.Logger
+}
+
+// NewRetentionService creates a new RetentionService.
+func NewRetentionService(
+	retentionRepo repository.RetentionRepository,
+	auditRepo repository.AuditRepository,
+	logger *slog.Logger,
+) RetentionService {
+	return &retentionService{
+		retentionRepo: retentionRepo,
+		auditRepo:     auditRepo,
+		logger:        logger,
+	}
+}
+
+func (s *retentionService) ListPolicies(ctx context.Context, enterpriseID int64) ([]domain.RetentionPolicy, error) {
+	if enterpriseID <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "enterprise ID must be positive")
+	}
+	return s.retentionRepo.ListPolicies(ctx, enterpriseID)
+}
+
+func (s *retentionService) SetPolicy(ctx context.Context, policy *domain.RetentionPolicy, actorID int64) error {
+	if err := s.validatePolicy(policy); err != nil {
+		return err
+	}
+	if err := s.retentionRepo.UpsertPolicy(ctx, policy); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EnterpriseID: &policy.EnterpriseID,
+		ActorID:      &actorID,
+		Action:       "retention_policy.set",
+		EntityType:   "retention_policy",
+		EntityID:     &policy.ID,
+		Metadata: map[string]interface{}{
+			"table_name":     policy.TableName,
+			"retention_days": policy.RetentionDays,
+			"action":         policy.Action,
+			"is_active":      policy.IsActive,
+		},
+	})
+	return nil
+}
+
+func (s *retentionService) DeletePolicy(ctx context.Context, enterpriseID int64, tableName string, actorID int64) error {
+	if enterpriseID <= 0 {
+		return domain.NewAppError(domain.ErrInvalidInput, "enterprise ID must be positive")
+	}
+	if err := s.retentionRepo.DeletePolicy(ctx, enterpriseID, tableName); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EnterpriseID: &enterpriseID,
+		ActorID:      &actorID,
+		Action:       "retention_policy.deleted",
+		EntityType:   "retention_policy",
+		Metadata:     map[string]interface{}{"table_name": tableName},
+	})
+	return nil
+}
+
+func (s *retentionService) SetLegalHold(ctx context.Context, residentID int64, enterpriseID *int64, hold bool, actorID int64) error {
+	if residentID <= 0 {
+		return domain.NewAppError(domain.ErrInvalidInput, "resident ID must be positive")
+	}
+	if err := s.retentionRepo.SetLegalHold(ctx, residentID, enterpriseID, hold); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EnterpriseID: enterpriseID,
+		ActorID:      &actorID,
+		Action:       "resident.legal_hold_set",
+		EntityType:   "resident",
+		EntityID:     &residentID,
+		Metadata:     map[string]interface{}{"legal_hold": hold},
+	})
+	return nil
+}
+
+func (s *retentionService) validatePolicy(policy *domain.RetentionPolicy) error {
+	details := make(map[string]string)
+
+	if policy.EnterpriseID <= 0 {
+		details["enterprise_id"] = "enterprise ID must be positive"
+	}
+	if !slices.Contains(domain.RetentionTables, policy.TableName) {
+		details["table_name"] = "table_name must be one of: " + strings.Join(domain.RetentionTables, ", ")
+	}
+	if policy.RetentionDays <= 0 || policy.RetentionDays > maxRetentionDays {
+		details["retention_days"] = "retention_days must be between 1 and 36500"
+	}
+	if policy.Action != domain.RetentionActionDelete && policy.Action != domain.RetentionActionAnonymize {
+		details["action"] = "action must be one of: delete, anonymize"
+	}
+
+	if len(details) > 0 {
+		return domain.NewValidationError("validation failed", details)
+	}
+	return nil
+}
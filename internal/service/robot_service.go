@@ -0,0 +1,108 @@
+// internal/service/robot_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/internal/validator"
+)
+
+// Compile-time interface check.
+var _ RobotService = (*robotService)(nil)
+
+type robotService struct {
+	robotRepo    repository.RobotRepository
+	residentRepo repository.ResidentRepository
+	auditRepo    repository.AuditRepository
+	tx           repository.Transactor
+	logger       *slog.Logger
+}
+
+// NewRobotService creates a new RobotService.
+func NewRobotService(
+	robotRepo repository.RobotRepository,
+	residentRepo repository.ResidentRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
+	logger *slog.Logger,
+) RobotService {
+	return &robotService{
+		robotRepo:    robotRepo,
+		residentRepo: residentRepo,
+		auditRepo:    auditRepo,
+		tx:           tx,
+		logger:       logger,
+	}
+}
+
+func (s *robotService) ProvisionRobot(ctx context.Context, robot *domain.Robot, actorID int64) error {
+	robot.SerialNumber = strings.TrimSpace(robot.SerialNumber)
+	robot.Status = domain.RobotProvisioned
+	if err := validator.Robot(robot); err != nil {
+		return err
+	}
+
+	if robot.AssignedResidentID != nil {
+		enterpriseID, err := s.residentRepo.GetEnterpriseID(ctx, *robot.AssignedResidentID)
+		if err != nil {
+			return err
+		}
+		if enterpriseID != robot.EnterpriseID {
+			return domain.NewValidationError("validation failed", map[string]string{
+				"assigned_resident_id": "resident belongs to another enterprise",
+			})
+		}
+	}
+
+	// The quota is checked and the robot created under the enterprise's
+	// lock, so concurrent provisioning cannot overshoot it.
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		used, limit, err := s.robotRepo.LockQuota(ctx, robot.EnterpriseID)
+		if err != nil {
+			return err
+		}
+		if used >= limit {
+			return domain.NewAppError(domain.ErrForbidden,
+				fmt.Sprintf("enterprise has reached its limit of %d robots", limit)).
+				WithCode(domain.CodeEnterpriseRobotLimit)
+		}
+		return s.robotRepo.Create(ctx, robot)
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+		EnterpriseID: &robot.EnterpriseID,
+		ActorID:      &actorID,
+		Action:       "robot.provisioned",
+		EntityType:   "robot",
+		EntityID:     &robot.ID,
+		Metadata: map[string]interface{}{
+			"serial_number":        robot.SerialNumber,
+			"assigned_resident_id": robot.AssignedResidentID,
+		},
+	})
+
+	s.logger.Info("robot provisioned",
+		slog.Int64("robot_id", robot.ID),
+		slog.Int64("enterprise_id", robot.EnterpriseID),
+	)
+	return nil
+}
+
+func (s *robotService) RecordHeartbeat(ctx context.Context, robotID int64, status string, firmwareVersion *string) (*domain.Robot, error) {
+	if robotID <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "robot ID must be positive")
+	}
+	if err := validator.Heartbeat(status, firmwareVersion); err != nil {
+		return nil, err
+	}
+	return s.robotRepo.RecordHeartbeat(ctx, robotID, status, firmwareVersion)
+}
@@ -0,0 +1,183 @@
+// internal/service/table_event_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+)
+
+// Compile-time interface check.
+var _ TableEventService = (*tableEventService)(nil)
+
+type tableEventService struct {
+	residentRepo repository.ResidentRepository
+	auditRepo    repository.AuditRepository
+	outboxRepo   repository.OutboxRepository
+	logger       *slog.Logger
+}
+
+// NewTableEventService creates a new TableEventService.
+func NewTableEventService(
+	residentRepo repository.ResidentRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	logger *slog.Logger,
+) TableEventService {
+	return &tableEventService{
+		residentRepo: residentRepo,
+		auditRepo:    auditRepo,
+		outboxRepo:   outboxRepo,
+		logger:       logger,
+	}
+}
+
+// change is one audited, published fact derived from a row change.
+type change struct {
+	action   string // audit action and domain event type
+	entity   string
+	entityID int64
+	metadata map[string]interface{}
+	event    func(enterpriseID *int64) (*domain.DomainEvent, error)
+}
+
+func (s *tableEventService) IncidentChanged(ctx context.Context, c *domain.TableChange[domain.Incident]) error {
+	if isDirectChange(c.ActorRole) {
+		return nil
+	}
+
+	var changes []change
+	incident := func(eventType string, i *domain.Incident, previous string) change {
+		p := domain.IncidentEventPayload{
+			IncidentID:       i.ID,
+			ResidentID:       i.ResidentID,
+			Severity:         i.Severity,
+			PreviousSeverity: previous,
+			ActorID:          c.ActorID,
+		}
+		metadata := map[string]interface{}{"resident_id": i.ResidentID, "severity": i.Severity}
+		if previous != "" {
+			metadata["previous_severity"] = previous
+		}
+		return change{
+			action:   eventType,
+			entity:   domain.AggregateIncident,
+			entityID: i.ID,
+			metadata: metadata,
+			event: func(enterpriseID *int64) (*domain.DomainEvent, error) {
+				return domain.NewIncidentEvent(eventType, enterpriseID, p)
+			},
+		}
+	}
+
+	switch c.Op {
+	case domain.ChangeInsert:
+		changes = append(changes, incident(domain.EventIncidentCreated, c.New, ""))
+	case domain.ChangeUpdate:
+		if c.Old.Severity != c.New.Severity {
+			changes = append(changes, incident(domain.EventIncidentSeverityChanged, c.New, c.Old.Severity))
+		}
+		if c.Old.ResolvedAt == nil && c.New.ResolvedAt != nil {
+			changes = append(changes, incident(domain.EventIncidentResolved, c.New, ""))
+		}
+	case domain.ChangeDelete:
+		changes = append(changes, incident(domain.EventIncidentDeleted, c.Old, ""))
+	}
+	return s.record(ctx, c.ActorID, rowResident(c.Old, c.New, func(i *domain.Incident) int64 { return i.ResidentID }), changes)
+}
+
+func (s *tableEventService) StoryChanged(ctx context.Context, c *domain.TableChange[domain.Story]) error {
+	if isDirectChange(c.ActorRole) {
+		return nil
+	}
+
+	story := func(eventType string, st *domain.Story) change {
+		p := domain.StoryEventPayload{
+			StoryID:    st.ID,
+			ResidentID: st.ResidentID,
+			AuthorID:   st.AuthorID,
+			StoryType:  st.StoryType,
+			ActorID:    c.ActorID,
+		}
+		return change{
+			action:   eventType,
+			entity:   domain.AggregateStory,
+			entityID: st.ID,
+			metadata: map[string]interface{}{"resident_id": st.ResidentID, "story_type": st.StoryType},
+			event: func(enterpriseID *int64) (*domain.DomainEvent, error) {
+				return domain.NewStoryEvent(eventType, enterpriseID, p)
+			},
+		}
+	}
+
+	var changes []change
+	switch c.Op {
+	case domain.ChangeInsert:
+		changes = append(changes, story(domain.EventStoryCreated, c.New))
+	case domain.ChangeDelete:
+		changes = append(changes, story(domain.EventStoryDeleted, c.Old))
+	}
+	return s.record(ctx, c.ActorID, rowResident(c.Old, c.New, func(st *domain.Story) int64 { return st.ResidentID }), changes)
+}
+
+// record writes an audit entry and appends a domain event for each change,
+// scoped to the enterprise of the resident the row belongs to.
+func (s *tableEventService) record(ctx context.Context, actorID *int64, residentID int64, changes []change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var enterpriseID *int64
+	id, err := s.residentRepo.GetEnterpriseID(ctx, residentID)
+	switch {
+	case err == nil:
+		enterpriseID = &id
+	case errors.Is(err, domain.ErrNotFound):
+		// The resident is gone and took the row with it.
+	default:
+		return err
+	}
+
+	events := make([]*domain.DomainEvent, 0, len(changes))
+	for _, ch := range changes {
+		err := s.auditRepo.Record(ctx, &domain.AuditEvent{
+			EnterpriseID: enterpriseID,
+			ActorID:      actorID,
+			Action:       ch.action,
+			EntityType:   ch.entity,
+			EntityID:     &ch.entityID,
+			Metadata:     ch.metadata,
+		})
+		if err != nil {
+			return err
+		}
+		e, err := ch.event(enterpriseID)
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+	return s.outboxRepo.Append(ctx, events...)
+}
+
+// isDirectChange reports whether a change was made in the database rather
+// than through Hasura, which leaves the session empty. Such changes come
+// from the API or the worker (e.g. retention purges), which record their
+// own audit entries and events.
+func isDirectChange(actorRole string) bool {
+	return actorRole == ""
+}
+
+// rowResident returns the resident of whichever row the change carries.
+func rowResident[T any](before, after *T, residentID func(*T) int64) int64 {
+	if after != nil {
+		return residentID(after)
+	}
+	if before != nil {
+		return residentID(before)
+	}
+	return 0
+}
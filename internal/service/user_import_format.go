@@ -0,0 +1,165 @@
+// internal/service/user_import_format.go
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"my-application/internal/domain"
+)
+
+// maxImportLine is the longest line of a JSON lines import file.
+const maxImportLine = 64 << 10
+
+// importColumns are the columns of a CSV import file; the first three are
+// required.
+var importColumns = []string{"username", "email", "full_name", "role", "locale"}
+
+// ParseUserImport reads the users of an import file in format, one of
+// domain.ImportFormatCSV and domain.ImportFormatJSONL. A file that can't be
+// read as such, has no users or more than maxRows (when positive) is
+// rejected with a domain.ErrInvalidInput error naming the line at fault;
+// the rows' fields are validated when they are imported.
+func ParseUserImport(src io.Reader, format string, maxRows int) ([]domain.UserImportRow, error) {
+	var (
+		rows []domain.UserImportRow
+		err  error
+	)
+	switch format {
+	case domain.ImportFormatCSV:
+		rows, err = parseImportCSV(src, maxRows)
+	case domain.ImportFormatJSONL:
+		rows, err = parseImportJSONL(src, maxRows)
+	default:
+		return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("unsupported import format %q", format))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "the import file has no users")
+	}
+	return rows, nil
+}
+
+func parseImportCSV(src io.Reader, maxRows int) ([]domain.UserImportRow, error) {
+	r := csv.NewReader(src)
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, invalidImportFile(err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(importColumns, name) {
+			return nil, domain.NewAppError(domain.ErrInvalidInput,
+				fmt.Sprintf("line 1: unknown column %q; columns are %s", name, strings.Join(importColumns, ", ")))
+		}
+		if _, dup := index[name]; dup {
+			return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("line 1: column %q appears twice", name))
+		}
+		index[name] = i
+	}
+	for _, name := range importColumns[:3] {
+		if _, ok := index[name]; !ok {
+			return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("line 1: missing column %q", name))
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := index[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []domain.UserImportRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, invalidImportFile(err)
+		}
+		line, _ := r.FieldPos(0)
+		if maxRows > 0 && len(rows) == maxRows {
+			return nil, tooManyImportRows(line, maxRows)
+		}
+		row := domain.UserImportRow{
+			Line:     line,
+			Username: field(record, "username"),
+			Email:    field(record, "email"),
+			FullName: field(record, "full_name"),
+			Role:     field(record, "role"),
+		}
+		if locale := field(record, "locale"); locale != "" {
+			row.Locale = &locale
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseImportJSONL(src io.Reader, maxRows int) ([]domain.UserImportRow, error) {
+	sc := bufio.NewScanner(src)
+	sc.Buffer(make([]byte, 0, 4096), maxImportLine)
+
+	var (
+		rows []domain.UserImportRow
+		line int
+	)
+	for sc.Scan() {
+		line++
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		if maxRows > 0 && len(rows) == maxRows {
+			return nil, tooManyImportRows(line, maxRows)
+		}
+		var row domain.UserImportRow
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&row); err != nil {
+			return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("line %d: %v", line, err))
+		}
+		if dec.More() {
+			return nil, domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("line %d: more than one JSON value", line))
+		}
+		row.Line = line
+		if row.Locale != nil && *row.Locale == "" {
+			row.Locale = nil
+		}
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, domain.NewAppError(domain.ErrInvalidInput,
+				fmt.Sprintf("line %d: longer than %d bytes", line+1, maxImportLine))
+		}
+		return nil, invalidImportFile(err)
+	}
+	return rows, nil
+}
+
+// invalidImportFile reports a file that can't be read, such as malformed
+// CSV; csv errors name the line.
+func invalidImportFile(err error) error {
+	return domain.NewAppError(domain.ErrInvalidInput, err.Error())
+}
+
+func tooManyImportRows(line, maxRows int) error {
+	return domain.NewAppError(domain.ErrInvalidInput,
+		fmt.Sprintf("line %d: an import file may hold at most %d users", line, maxRows))
+}
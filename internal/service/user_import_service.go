@@ -0,0 +1,388 @@
+// internal/service/user_import_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/internal/validator"
+)
+
+// Compile-time interface check.
+var _ UserImportService = (*userImportService)(nil)
+
+// errImportRolledBack rolls back an atomic import in which a row failed.
+var errImportRolledBack = errors.New("atomic user import rolled back")
+
+// UserImportConfig holds settings for bulk user imports.
+type UserImportConfig struct {
+	// InlineRows is the most rows imported within the request; larger
+	// files are run by the worker.
+	InlineRows int
+	// MaxRows is the most rows an import file may hold.
+	MaxRows int
+}
+
+type userImportService struct {
+	userService       UserService
+	invitationService InvitationService
+	userRepo          repository.UserRepository
+	importRepo        repository.UserImportRepository
+	auditRepo         repository.AuditRepository
+	enqueuer          jobs.Enqueuer
+	tx                repository.Transactor
+	config            UserImportConfig
+	logger            *slog.Logger
+}
+
+// NewUserImportService creates a new UserImportService. Users are created
+// through userService, so each import row is validated and published like
+// a user created through the API, and invited through invitationService.
+func NewUserImportService(
+	userService UserService,
+	invitationService InvitationService,
+	userRepo repository.UserRepository,
+	importRepo repository.UserImportRepository,
+	auditRepo repository.AuditRepository,
+	enqueuer jobs.Enqueuer,
+	tx repository.Transactor,
+	config UserImportConfig,
+	logger *slog.Logger,
+) UserImportService {
+	return &userImportService{
+		userService:       userService,
+		invitationService: invitationService,
+		userRepo:          userRepo,
+		importRepo:        importRepo,
+		auditRepo:         auditRepo,
+		enqueuer:          enqueuer,
+		tx:                tx,
+		config:            config,
+		logger:            logger,
+	}
+}
+
+func (s *userImportService) ImportUsers(ctx context.Context, imp *domain.UserImport, src io.Reader, format string) error {
+	if !domain.IsValidImportMode(imp.Mode) {
+		return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("unknown import mode %q", imp.Mode))
+	}
+	rows, err := ParseUserImport(src, format, s.config.MaxRows)
+	if err != nil {
+		return err
+	}
+	imp.Rows = rows
+	if len(rows) <= s.config.InlineRows {
+		return s.RunImport(ctx, imp)
+	}
+
+	// The job commits with the import, so no import is left pending
+	// without one.
+	imp.Status = domain.ImportStatusPending
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.importRepo.Create(ctx, imp); err != nil {
+			return err
+		}
+		_, err := s.enqueuer.Enqueue(ctx, jobs.UserImportArgs{ImportID: imp.ID}, jobs.UserImportOptions(imp.ID)...)
+		return err
+	})
+}
+
+func (s *userImportService) GetImport(ctx context.Context, id int64) (*domain.UserImport, error) {
+	if id <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "import ID must be positive")
+	}
+	return s.importRepo.GetByID(ctx, id)
+}
+
+func (s *userImportService) RunImport(ctx context.Context, imp *domain.UserImport) error {
+	if !domain.IsValidImportMode(imp.Mode) {
+		return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("unknown import mode %q", imp.Mode))
+	}
+	users, results, err := s.check(ctx, imp)
+	if err != nil {
+		return err
+	}
+
+	if !imp.DryRun {
+		if imp.Mode == domain.ImportModeAtomic {
+			results, err = s.createAll(ctx, imp, users, results)
+		} else {
+			err = s.createEach(ctx, imp, users, results)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	imp.Results = results
+	imp.Status = domain.ImportStatusCompleted
+
+	if !imp.DryRun {
+		audit.Record(ctx, s.auditRepo, s.logger, &domain.AuditEvent{
+			EnterpriseID: imp.EnterpriseID,
+			ActorID:      imp.RequestedBy,
+			Action:       "users.imported",
+			EntityType:   "user_import",
+			EntityID:     nonZero(imp.ID),
+			Metadata: map[string]interface{}{
+				"mode":    imp.Mode,
+				"rows":    len(results),
+				"created": imp.Count(domain.ImportRowCreated),
+				"failed":  imp.Count(domain.ImportRowFailed),
+			},
+		})
+	}
+	s.logger.Info("user import completed",
+		slog.Int64("import_id", imp.ID),
+		slog.String("mode", imp.Mode),
+		slog.Bool("dry_run", imp.DryRun),
+		slog.Int("rows", len(results)),
+		slog.Int("failed", imp.Count(domain.ImportRowFailed)),
+	)
+	return nil
+}
+
+// check validates every row as CreateUser would and against the other rows
+// and existing users. It returns the users to create, indexed like the
+// rows, and results marking each row valid or failed.
+//
+// Within a request scoped to an enterprise only that enterprise's users are
+// visible, so a clash with another enterprise's user is only found when
+// the user is created.
+func (s *userImportService) check(ctx context.Context, imp *domain.UserImport) ([]domain.User, []domain.UserImportResult, error) {
+	users := make([]domain.User, len(imp.Rows))
+	violations := make([]map[string]domain.Violation, len(imp.Rows))
+	seenUsernames := make(map[string]bool, len(imp.Rows))
+	seenEmails := make(map[string]bool, len(imp.Rows))
+	var usernames, emails []string
+	roles, err := s.importableRoles(ctx, imp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, row := range imp.Rows {
+		users[i] = domain.User{
+			Username:     strings.TrimSpace(row.Username),
+			Email:        strings.ToLower(strings.TrimSpace(row.Email)),
+			FullName:     row.FullName,
+			Role:         row.Role,
+			Locale:       row.Locale,
+			EnterpriseID: imp.EnterpriseID,
+		}
+		u := &users[i]
+		violations[i] = map[string]domain.Violation{}
+		var appErr *domain.AppError
+		if err := validator.User(u); errors.As(err, &appErr) {
+			for field, v := range appErr.Violations {
+				violations[i][field] = v
+			}
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if _, bad := violations[i]["role"]; !bad && u.Role != "" && !slices.Contains(roles, u.Role) {
+			violations[i]["role"] = domain.Violation{Rule: "one_of", Params: map[string]string{
+				"values": strings.Join(roles, ", "),
+			}}
+		}
+
+		// A later row repeating a username or email fails; the first one
+		// can still be imported.
+		if _, bad := violations[i]["username"]; !bad {
+			if seenUsernames[u.Username] {
+				violations[i]["username"] = domain.Violation{Rule: "taken"}
+			} else {
+				seenUsernames[u.Username] = true
+				usernames = append(usernames, u.Username)
+			}
+		}
+		if _, bad := violations[i]["email"]; !bad {
+			if seenEmails[u.Email] {
+				violations[i]["email"] = domain.Violation{Rule: "taken"}
+			} else {
+				seenEmails[u.Email] = true
+				emails = append(emails, u.Email)
+			}
+		}
+	}
+
+	takenUsernames, takenEmails, err := s.userRepo.Taken(ctx, usernames, emails)
+	if err != nil {
+		return nil, nil, err
+	}
+	taken := make(map[string]bool, len(takenUsernames)+len(takenEmails))
+	for _, u := range takenUsernames {
+		taken["username:"+u] = true
+	}
+	for _, e := range takenEmails {
+		taken["email:"+e] = true
+	}
+	for i, u := range users {
+		for field, value := range map[string]string{"username": u.Username, "email": u.Email} {
+			if _, bad := violations[i][field]; !bad && taken[field+":"+value] {
+				violations[i][field] = domain.Violation{Rule: "taken"}
+			}
+		}
+	}
+
+	results := make([]domain.UserImportResult, len(imp.Rows))
+	for i, row := range imp.Rows {
+		results[i] = domain.UserImportResult{Line: row.Line, Status: domain.ImportRowValid}
+		if len(violations[i]) > 0 {
+			results[i].Status = domain.ImportRowFailed
+			results[i].Code = domain.CodeValidationFailed
+			results[i].Violations = violations[i]
+		}
+	}
+	return users, results, nil
+}
+
+// importableRoles returns the roles imp may create users with. Only mta,
+// or ops from the command line (no requester), may create mta and robot
+// accounts; an import that invites its users is limited to the roles that
+// can be invited.
+func (s *userImportService) importableRoles(ctx context.Context, imp *domain.UserImport) ([]string, error) {
+	roles := domain.Roles
+	if imp.RequestedBy != nil {
+		requester, err := s.userRepo.GetByID(ctx, *imp.RequestedBy)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		assigner := ""
+		if requester != nil {
+			assigner = requester.Role
+		}
+		roles = slices.DeleteFunc(slices.Clone(roles), func(role string) bool {
+			return !domain.CanAssignRole(assigner, role)
+		})
+	}
+	if imp.Invite {
+		roles = slices.DeleteFunc(slices.Clone(roles), func(role string) bool {
+			return !slices.Contains(domain.InvitableRoles, role)
+		})
+	}
+	return roles, nil
+}
+
+// createAll creates the users of the rows in one transaction, which is
+// rolled back if any row fails, and returns the updated results. Nothing is
+// created when a row failed the checks. Each user is created in a savepoint
+// so that every row failing is reported.
+func (s *userImportService) createAll(ctx context.Context, imp *domain.UserImport, users []domain.User, checked []domain.UserImportResult) ([]domain.UserImportResult, error) {
+	results := append([]domain.UserImportResult(nil), checked...)
+	err := errImportRolledBack
+	if !hasFailed(checked) {
+		err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			// The transaction may be run again; start over each time.
+			results = append(results[:0], checked...)
+			failed := false
+			for i := range results {
+				user := users[i]
+				ok, err := s.create(ctx, imp, &user, &results[i])
+				if err != nil {
+					return err
+				}
+				failed = failed || !ok
+			}
+			if failed {
+				return errImportRolledBack
+			}
+			return nil
+		})
+	}
+	if errors.Is(err, errImportRolledBack) {
+		for i := range results {
+			if results[i].Status != domain.ImportRowFailed {
+				results[i] = domain.UserImportResult{Line: results[i].Line, Status: domain.ImportRowSkipped}
+			}
+		}
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// createEach creates the user of each valid row in a transaction of its
+// own, updating results.
+func (s *userImportService) createEach(ctx context.Context, imp *domain.UserImport, users []domain.User, results []domain.UserImportResult) error {
+	for i := range results {
+		if results[i].Status != domain.ImportRowValid {
+			continue
+		}
+		if _, err := s.create(ctx, imp, &users[i], &results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// create creates user and invites it, recording the outcome in
+// result. It reports false when the row fails, and returns errors that
+// are not the row's fault.
+func (s *userImportService) create(ctx context.Context, imp *domain.UserImport, user *domain.User, result *domain.UserImportResult) (bool, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userService.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		if imp.Invite {
+			_, err := s.invitationService.InviteUser(ctx, user, imp.RequestedBy)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidInput) && !errors.Is(err, domain.ErrAlreadyExists) {
+			return false, err
+		}
+		result.Status = domain.ImportRowFailed
+		result.Code = domain.ErrorCodeOf(err)
+		result.Violations = rowViolations(err)
+		return false, nil
+	}
+	result.Status = domain.ImportRowCreated
+	result.UserID = &user.ID
+	return true, nil
+}
+
+// rowViolations returns the violations of a row that failed with err. A
+// taken username or email becomes a violation of that field.
+func rowViolations(err error) map[string]domain.Violation {
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) {
+		return nil
+	}
+	switch appErr.Code {
+	case domain.CodeUserUsernameTaken:
+		return map[string]domain.Violation{"username": {Rule: "taken"}}
+	case domain.CodeUserEmailTaken:
+		return map[string]domain.Violation{"email": {Rule: "taken"}}
+	}
+	return appErr.Violations
+}
+
+// nonZero returns a pointer to id, or nil for an ID not yet assigned.
+func nonZero(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// hasFailed reports whether any row failed.
+func hasFailed(results []domain.UserImportResult) bool {
+	for _, r := range results {
+		if r.Status == domain.ImportRowFailed {
+			return true
+		}
+	}
+	return false
+}
@@ -8,21 +8,32 @@ import (
 
 	"my-application/internal/domain"
 	"my-application/internal/repository"
+	"my-application/internal/validator"
 )
 
 // Compile-time interface check.
 var _ UserService = (*userService)(nil)
 
 type userService struct {
-	userRepo repository.UserRepository
-	logger   *slog.Logger
+	userRepo   repository.UserRepository
+	outboxRepo repository.OutboxRepository
+	tx         repository.Transactor
+	logger     *slog.Logger
 }
 
-// NewUserService creates a new UserService.
-func NewUserService(userRepo repository.UserRepository, logger *slog.Logger) UserService {
+// NewUserService creates a new UserService. Every change is recorded as a
+// domain event in the outbox, in the same transaction.
+func NewUserService(
+	userRepo repository.UserRepository,
+	outboxRepo repository.OutboxRepository,
+	tx repository.Transactor,
+	logger *slog.Logger,
+) UserService {
 	return &userService{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:   userRepo,
+		outboxRepo: outboxRepo,
+		tx:         tx,
+		logger:     logger,
 	}
 }
 
@@ -33,74 +44,175 @@ func (s *userService) GetUser(ctx context.Context, id int64) (*domain.User, erro
 	return s.userRepo.GetByID(ctx, id)
 }
 
-func (s *userService) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, int64, error) {
+func (s *userService) ListUsers(ctx context.Context, filter domain.UserFilter) (*domain.UserPage, error) {
 	filter.Normalize()
+	filter.Search = strings.TrimSpace(filter.Search)
+	if err := validator.UserFilter(filter); err != nil {
+		return nil, err
+	}
 	return s.userRepo.List(ctx, filter)
 }
 
 func (s *userService) CreateUser(ctx context.Context, user *domain.User) error {
-	if err := s.validateUser(user); err != nil {
+	if err := validator.User(user); err != nil {
 		return err
 	}
 
 	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
 	user.Username = strings.TrimSpace(user.Username)
+	if user.Locale != nil && *user.Locale == "" {
+		user.Locale = nil
+	}
 	user.IsActive = true
 
 	if user.Role == "" {
 		user.Role = "caregiver"
 	}
 
-	return s.userRepo.Create(ctx, user)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		return s.publish(ctx, user, domain.EventUserCreated)
+	})
 }
 
 func (s *userService) UpdateUser(ctx context.Context, user *domain.User) error {
 	if user.ID <= 0 {
 		return domain.NewAppError(domain.ErrInvalidInput, "user ID must be positive")
 	}
-	if err := s.validateUser(user); err != nil {
+	if err := validator.User(user); err != nil {
 		return err
 	}
 
 	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
 	user.Username = strings.TrimSpace(user.Username)
+	if user.Locale != nil && *user.Locale == "" {
+		user.Locale = nil
+	}
 
-	return s.userRepo.Update(ctx, user)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.userRepo.GetByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		// The enterprise is not editable here; keep it rather than clearing it.
+		if user.EnterpriseID == nil {
+			user.EnterpriseID = before.EnterpriseID
+		}
+		return s.update(ctx, before, user)
+	})
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id int64) error {
+func (s *userService) PatchUser(ctx context.Context, id int64, patch domain.UserPatch, version int64) (*domain.User, error) {
 	if id <= 0 {
-		return domain.NewAppError(domain.ErrInvalidInput, "user ID must be positive")
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "user ID must be positive")
+	}
+	var user domain.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		user = *before
+		patch.Apply(&user)
+		if err := validator.User(&user); err != nil {
+			return err
+		}
+
+		user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+		user.Username = strings.TrimSpace(user.Username)
+		if user.Locale != nil && *user.Locale == "" {
+			user.Locale = nil
+		}
+		user.Version = version
+		return s.update(ctx, before, &user)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return s.userRepo.Delete(ctx, id)
+	return &user, nil
 }
 
-func (s *userService) validateUser(user *domain.User) error {
-	details := make(map[string]string)
-
-	if strings.TrimSpace(user.Username) == "" {
-		details["username"] = "username is required"
-	} else if len(user.Username) < 3 || len(user.Username) > 50 {
-		details["username"] = "username must be between 3 and 50 characters"
+// update writes user, which was before, and publishes the change.
+func (s *userService) update(ctx context.Context, before, user *domain.User) error {
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
 	}
+	// Fields the update doesn't write come from the stored user.
+	user.FirebaseUID = before.FirebaseUID
+	user.CreatedAt = before.CreatedAt
 
-	if strings.TrimSpace(user.Email) == "" {
-		details["email"] = "email is required"
-	} else if !strings.Contains(user.Email, "@") {
-		details["email"] = "email must be a valid email address"
+	types := []string{domain.EventUserUpdated}
+	if before.IsActive && !user.IsActive {
+		types = append(types, domain.EventUserDeactivated)
 	}
+	return s.publish(ctx, user, types...)
+}
 
-	if strings.TrimSpace(user.FullName) == "" {
-		details["full_name"] = "full name is required"
+func (s *userService) DeleteUser(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return domain.NewAppError(domain.ErrInvalidInput, "user ID must be positive")
 	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.userRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.publish(ctx, user, domain.EventUserDeleted)
+	})
+}
 
-	validRoles := map[string]bool{"mta": true, "eta": true, "caregiver": true, "family": true, "robot": true}
-	if user.Role != "" && !validRoles[user.Role] {
-		details["role"] = "role must be one of: mta, eta, caregiver, family, robot"
+func (s *userService) RestoreUser(ctx context.Context, id int64) (*domain.User, error) {
+	if id <= 0 {
+		return nil, domain.NewAppError(domain.ErrInvalidInput, "user ID must be positive")
 	}
+	var user *domain.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.userRepo.Restore(ctx, id); err != nil {
+			return err
+		}
+		return s.publish(ctx, user, domain.EventUserRestored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
 
-	if len(details) > 0 {
-		return domain.NewValidationError("validation failed", details)
+// PurgeUser irreversibly anonymizes a soft-deleted user. The user must be
+// deleted first so that purging is always a deliberate second step.
+func (s *userService) PurgeUser(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return domain.NewAppError(domain.ErrInvalidInput, "user ID must be positive")
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Purge(ctx, id); err != nil {
+			return err
+		}
+		// The purged row holds no personal data any more; the ID is enough.
+		return s.publish(ctx, &domain.User{ID: id}, domain.EventUserPurged)
+	})
+	if err != nil {
+		return err
 	}
+	s.logger.Info("user purged", slog.Int64("user_id", id))
 	return nil
 }
+
+// publish appends one event per type for user to the outbox.
+func (s *userService) publish(ctx context.Context, user *domain.User, types ...string) error {
+	events := make([]*domain.DomainEvent, 0, len(types))
+	for _, t := range types {
+		e, err := domain.NewUserEvent(t, user)
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+	return s.outboxRepo.Append(ctx, events...)
+}
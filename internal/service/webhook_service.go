@@ -0,0 +1,240 @@
+// internal/service/webhook_service.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"my-application/internal/audit"
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/internal/webhook"
+	"my-application/pkg/httputil"
+)
+
+// Compile-time interface check.
+var _ WebhookService = (*webhookService)(nil)
+
+// Limits on webhook subscription fields.
+const (
+	maxWebhookURLLength         = 2048
+	maxWebhookDescriptionLength = 200
+)
+
+// WebhookConfig holds settings for webhook subscriptions.
+type WebhookConfig struct {
+	// AllowHTTP accepts plain http:// URLs; otherwise only https:// is.
+	AllowHTTP bool
+	// AllowPrivateNetworks accepts URLs on loopback and private addresses.
+	// Otherwise they are refused here when the host is an address, and by
+	// the sender's dialer after resolving host names.
+	AllowPrivateNetworks bool
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	auditRepo   repository.AuditRepository
+	sender      *webhook.Sender
+	config      WebhookConfig
+	logger      *slog.Logger
+}
+
+// NewWebhookService creates a new WebhookService.
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	auditRepo repository.AuditRepository,
+	sender *webhook.Sender,
+	config WebhookConfig,
+	logger *slog.Logger,
+) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		auditRepo:   auditRepo,
+		sender:      sender,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context, enterpriseID int64) ([]domain.WebhookSubscription, error) {
+	return s.webhookRepo.List(ctx, enterpriseID)
+}
+
+func (s *webhookService) GetSubscription(ctx context.Context, enterpriseID, id int64) (*domain.WebhookSubscription, error) {
+	return s.webhookRepo.Get(ctx, enterpriseID, id)
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription, actorID int64) error {
+	if err := s.validate(sub); err != nil {
+		return err
+	}
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return err
+	}
+	sub.Secret = secret
+	sub.IsActive = true
+
+	if err := s.webhookRepo.Create(ctx, sub); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, webhookAuditEvent(sub, actorID, "webhook.created"))
+	return nil
+}
+
+func (s *webhookService) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription, actorID int64) error {
+	if err := s.validate(sub); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Update(ctx, sub); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, webhookAuditEvent(sub, actorID, "webhook.updated"))
+	return nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, enterpriseID, id int64, actorID int64) error {
+	if err := s.webhookRepo.Delete(ctx, enterpriseID, id); err != nil {
+		return err
+	}
+
+	audit.Record(ctx, s.auditRepo, s.logger, webhookAuditEvent(&domain.WebhookSubscription{ID: id, EnterpriseID: enterpriseID}, actorID, "webhook.deleted"))
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(
+	ctx context.Context, enterpriseID, id int64, filter domain.WebhookDeliveryFilter,
+) ([]domain.WebhookDelivery, int64, error) {
+	if _, err := s.webhookRepo.Get(ctx, enterpriseID, id); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, id, filter)
+}
+
+func (s *webhookService) SendTest(ctx context.Context, enterpriseID, id int64) (*domain.WebhookDelivery, error) {
+	sub, err := s.webhookRepo.Get(ctx, enterpriseID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	event := domain.WebhookPayload{
+		ID:           uuid.NewString(),
+		Type:         domain.WebhookEventTest,
+		EnterpriseID: enterpriseID,
+		CreatedAt:    now,
+		Data:         json.RawMessage(`{"message":"This is a test event."}`),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("encoding test event: %w", err)
+	}
+	d := &domain.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Attempts:       1,
+		LastAttemptAt:  &now,
+	}
+
+	// Test events are sent synchronously so the caller sees the outcome, and
+	// they never count towards the subscription's failure streak.
+	result, sendErr := s.sender.Send(ctx, webhook.Request{
+		URL:       sub.URL,
+		Secret:    sub.Secret,
+		EventID:   d.EventID,
+		EventType: d.EventType,
+		Body:      payload,
+	})
+	d.ApplyResult(result.StatusCode, result.Duration, sendErr, true)
+
+	if err := s.webhookRepo.CreateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *webhookService) validate(sub *domain.WebhookSubscription) error {
+	details := make(map[string]string)
+
+	if sub.EnterpriseID <= 0 {
+		details["enterprise_id"] = "enterprise ID must be positive"
+	}
+
+	sub.URL = strings.TrimSpace(sub.URL)
+	u, err := url.Parse(sub.URL)
+	switch {
+	case sub.URL == "":
+		details["url"] = "url is required"
+	case len(sub.URL) > maxWebhookURLLength:
+		details["url"] = fmt.Sprintf("url must be at most %d characters", maxWebhookURLLength)
+	case err != nil || u.Host == "" || u.User != nil:
+		details["url"] = "url must be an absolute URL without credentials"
+	case u.Scheme != "https" && !(s.config.AllowHTTP && u.Scheme == "http"):
+		details["url"] = "url must use https"
+	case !s.config.AllowPrivateNetworks && !publicHost(u.Hostname()):
+		details["url"] = "url must not point to a local or private network address"
+	}
+
+	if len(sub.Description) > maxWebhookDescriptionLength {
+		details["description"] = fmt.Sprintf("description must be at most %d characters", maxWebhookDescriptionLength)
+	}
+
+	if len(sub.EventTypes) == 0 {
+		details["event_types"] = "at least one event type is required"
+	}
+	for _, t := range sub.EventTypes {
+		if !slices.Contains(domain.WebhookEventTypes, t) {
+			details["event_types"] = "event_types must be among: " + strings.Join(domain.WebhookEventTypes, ", ")
+			break
+		}
+	}
+	slices.Sort(sub.EventTypes)
+	sub.EventTypes = slices.Compact(sub.EventTypes)
+
+	if len(details) > 0 {
+		return domain.NewValidationError("validation failed", details)
+	}
+	return nil
+}
+
+// publicHost reports whether host may be public: a name other than
+// localhost, or a public address. Names are checked once resolved, when
+// connecting.
+func publicHost(host string) bool {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return httputil.IsPublicAddr(ip)
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+// webhookAuditEvent describes action on sub by actorID.
+func webhookAuditEvent(sub *domain.WebhookSubscription, actorID int64, action string) *domain.AuditEvent {
+	metadata := map[string]interface{}{}
+	if sub.URL != "" {
+		metadata["url"] = sub.URL
+		metadata["event_types"] = sub.EventTypes
+		metadata["is_active"] = sub.IsActive
+	}
+	return &domain.AuditEvent{
+		EnterpriseID: &sub.EnterpriseID,
+		ActorID:      &actorID,
+		Action:       action,
+		EntityType:   "webhook",
+		EntityID:     &sub.ID,
+		Metadata:     metadata,
+	}
+}
@@ -0,0 +1,32 @@
+// internal/validator/robot_validator.go
+package validator
+
+import "my-application/internal/domain"
+
+// robotRules are the rules a provisioned robot must satisfy. They match the
+// columns of the robots table.
+type robotRules struct {
+	EnterpriseID    int64   `json:"enterprise_id" binding:"gt=0"`
+	SerialNumber    string  `json:"serial_number" binding:"required,max=100"`
+	FirmwareVersion *string `json:"firmware_version" binding:"omitempty,max=50"`
+}
+
+// heartbeatRules are the rules of the state a robot reports in a heartbeat.
+type heartbeatRules struct {
+	Status          string  `json:"status" binding:"oneof=active idle maintenance"`
+	FirmwareVersion *string `json:"firmware_version" binding:"omitempty,max=50"`
+}
+
+// Robot validates a robot being provisioned.
+func Robot(robot *domain.Robot) error {
+	return Struct(robotRules{
+		EnterpriseID:    robot.EnterpriseID,
+		SerialNumber:    robot.SerialNumber,
+		FirmwareVersion: robot.FirmwareVersion,
+	})
+}
+
+// Heartbeat validates the status and firmware version of a heartbeat.
+func Heartbeat(status string, firmwareVersion *string) error {
+	return Struct(heartbeatRules{Status: status, FirmwareVersion: firmwareVersion})
+}
@@ -1,2 +1,68 @@
 // internal/validator/user_validator.go
 package validator
+
+import (
+	"strings"
+
+	"my-application/internal/domain"
+	"my-application/pkg/pagination"
+)
+
+// userRules are the rules a user must satisfy however it was created or
+// changed. They match the columns of the users table.
+type userRules struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	FullName string `json:"full_name" binding:"required,max=100"`
+	Role     string `json:"role" binding:"omitempty,sona_role"`
+	Locale   string `json:"locale" binding:"omitempty,locale"`
+}
+
+// User validates user. Surrounding whitespace doesn't count towards a
+// field, and an empty role or locale means the default.
+func User(user *domain.User) error {
+	rules := userRules{
+		Username: strings.TrimSpace(user.Username),
+		Email:    strings.TrimSpace(user.Email),
+		FullName: strings.TrimSpace(user.FullName),
+		Role:     user.Role,
+	}
+	if user.Locale != nil {
+		rules.Locale = *user.Locale
+	}
+	return Struct(rules)
+}
+
+// userFilterRules are the rules of a user listing's filter that fit tags.
+type userFilterRules struct {
+	Role         string `json:"role" binding:"omitempty,sona_role"`
+	EnterpriseID *int64 `json:"enterprise_id" binding:"omitempty,gt=0"`
+	Search       string `json:"search" binding:"max=100"`
+}
+
+// UserFilter validates a normalized user listing filter. A cursor must come
+// from a listing in the same sort order.
+func UserFilter(filter domain.UserFilter) error {
+	violations := structViolations(userFilterRules{
+		Role:         filter.Role,
+		EnterpriseID: filter.EnterpriseID,
+		Search:       filter.Search,
+	})
+
+	sort, err := pagination.ParseSort(filter.Sort, domain.UserSortFields)
+	if err != nil {
+		violations["sort"] = domain.Violation{Rule: "one_of", Params: map[string]string{
+			"values": strings.Join(pagination.SortValues(domain.UserSortFields), ", "),
+		}}
+	} else if filter.Cursor != "" {
+		if _, err := pagination.Decode(filter.Cursor, sort); err != nil {
+			violations["cursor"] = domain.Violation{Rule: "invalid"}
+		}
+	}
+
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedBefore.After(*filter.CreatedAfter) {
+		violations["created_before"] = domain.Violation{Rule: "after", Params: map[string]string{"other": "created_after"}}
+	}
+
+	return validationError(violations)
+}
@@ -0,0 +1,117 @@
+// internal/validator/validator.go
+//
+// Package validator is the application's validation layer. Request types
+// and the rule sets of domain entities declare their rules in "binding"
+// tags; failures become a domain validation error with one translatable
+// violation per JSON field, whichever API the input came through. Besides
+// the rules of pkg/validator it knows:
+//
+//	sona_role  one of domain.Roles
+//	locale     a locale with a message catalog (see the i18n package)
+package validator
+
+import (
+	"reflect"
+	"strings"
+
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+	pkgvalidator "my-application/pkg/validator"
+)
+
+var std = newValidator()
+
+func newValidator() *pkgvalidator.Validator {
+	v := pkgvalidator.New()
+	v.RegisterRule("sona_role", domain.IsValidRole)
+	v.RegisterRule("locale", i18n.IsSupported)
+	return v
+}
+
+// Default returns the Validator with the application's rules. The request
+// package installs it as gin's binding validator, so binding tags on request
+// types are checked by the same rules.
+func Default() *pkgvalidator.Validator {
+	return std
+}
+
+// Struct validates s, a struct or pointer to one, and returns nil or a
+// validation error from Error.
+func Struct(s any) error {
+	return Error(std.Struct(s))
+}
+
+// Error converts failed rules in err into a validation error. Other errors,
+// including nil, are returned unchanged.
+func Error(err error) error {
+	fieldErrs, ok := pkgvalidator.Fields(err)
+	if !ok {
+		return err
+	}
+	return i18n.ValidationError(violations(fieldErrs))
+}
+
+// violations returns one violation per failed field, keyed by JSON path.
+// A field failing several rules reports the first.
+func violations(fieldErrs []pkgvalidator.FieldError) map[string]domain.Violation {
+	violations := make(map[string]domain.Violation, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		path := pkgvalidator.Path(fe)
+		if _, seen := violations[path]; !seen {
+			violations[path] = violation(fe)
+		}
+	}
+	return violations
+}
+
+// structViolations validates s and returns its violations, which rule sets
+// with checks that don't fit a tag add to.
+func structViolations(s any) map[string]domain.Violation {
+	fieldErrs, _ := pkgvalidator.Fields(std.Struct(s))
+	return violations(fieldErrs)
+}
+
+// validationError returns a validation error for violations, or nil if
+// there are none.
+func validationError(violations map[string]domain.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return i18n.ValidationError(violations)
+}
+
+// violation maps a failed rule to the catalog's validation rule of the same
+// meaning. Rules without one are reported as "invalid".
+func violation(fe pkgvalidator.FieldError) domain.Violation {
+	isString := fe.Kind() == reflect.String
+	isNumber := fe.Kind() >= reflect.Int && fe.Kind() <= reflect.Float64
+
+	switch tag := fe.Tag(); {
+	case tag == "required":
+		return domain.Violation{Rule: "required"}
+	case tag == "email":
+		return domain.Violation{Rule: "email"}
+	case tag == "phone":
+		return domain.Violation{Rule: "phone"}
+	case tag == "timezone":
+		return domain.Violation{Rule: "timezone"}
+	case tag == "sona_role":
+		return domain.Violation{Rule: "one_of", Params: map[string]string{"values": strings.Join(domain.Roles, ", ")}}
+	case tag == "locale":
+		return i18n.LocaleViolation()
+	case tag == "min" && isString:
+		return domain.Violation{Rule: "min_length", Params: map[string]string{"min": fe.Param()}}
+	case tag == "max" && isString:
+		return domain.Violation{Rule: "max_length", Params: map[string]string{"max": fe.Param()}}
+	case (tag == "min" || tag == "gte") && isNumber:
+		return domain.Violation{Rule: "min", Params: map[string]string{"min": fe.Param()}}
+	case (tag == "max" || tag == "lte") && isNumber:
+		return domain.Violation{Rule: "max", Params: map[string]string{"max": fe.Param()}}
+	case tag == "gt" && isNumber:
+		return domain.Violation{Rule: "greater_than", Params: map[string]string{"min": fe.Param()}}
+	case tag == "oneof":
+		return domain.Violation{Rule: "one_of", Params: map[string]string{"values": strings.Join(strings.Fields(fe.Param()), ", ")}}
+	default:
+		return domain.Violation{Rule: "invalid"}
+	}
+}
@@ -0,0 +1,100 @@
+// internal/webhook/sender.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"my-application/pkg/httputil"
+)
+
+// maxDrainBody caps how much of a response is read to reuse the connection.
+const maxDrainBody = 4096
+
+// Request is one signed POST to a subscription.
+type Request struct {
+	URL       string
+	Secret    string
+	EventID   string
+	EventType string
+	Body      []byte
+}
+
+// Result is the outcome of one delivery attempt. StatusCode is 0 when no
+// response was received.
+type Result struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+// Sender POSTs signed payloads.
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender creates a Sender whose requests time out after timeout.
+// Redirects are not followed: a receiver must answer at its configured URL.
+// Unless allowPrivate (for local development), receivers must be on public
+// addresses, so a subscription cannot reach the internal network.
+func NewSender(timeout time.Duration, allowPrivate bool) *Sender {
+	client := httputil.NewClient(timeout, allowPrivate)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &Sender{client: client, now: time.Now}
+}
+
+// Send delivers req. Any response other than 2xx is an error; the result
+// is returned either way so the caller can log the status code. Errors are
+// shown to the subscription's owner, so they name the kind of failure only,
+// never the response body or the network details of a failed connection.
+func (s *Sender) Send(ctx context.Context, req Request) (Result, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return Result{}, fmt.Errorf("building request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "SONA-Webhooks/1")
+	httpReq.Header.Set(HeaderEventID, req.EventID)
+	httpReq.Header.Set(HeaderEventType, req.EventType)
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, s.now(), req.Body))
+
+	start := time.Now()
+	resp, err := s.client.Do(httpReq)
+	result := Result{Duration: time.Since(start)}
+	if err != nil {
+		return result, requestError(err)
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBody)) //nolint:errcheck // drained for connection reuse only
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// requestError reduces an error of http.Client.Do to the kind of failure.
+func requestError(err error) error {
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	switch {
+	case errors.Is(err, httputil.ErrForbiddenAddress):
+		return httputil.ErrForbiddenAddress
+	case errors.As(err, &certErr):
+		return errors.New("receiver's TLS certificate is not valid")
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errors.New("receiver did not respond in time")
+	default:
+		return errors.New("could not connect to receiver")
+	}
+}
@@ -1,2 +1,66 @@
 // internal/worker/email_worker.go
 package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"my-application/internal/email"
+	"my-application/internal/jobs"
+)
+
+// EmailWorker renders and delivers queued emails.
+type EmailWorker struct {
+	renderer *email.Renderer
+	sender   email.Sender
+	logger   *slog.Logger
+}
+
+// NewEmailWorker creates an EmailWorker.
+func NewEmailWorker(renderer *email.Renderer, sender email.Sender, logger *slog.Logger) *EmailWorker {
+	return &EmailWorker{renderer: renderer, sender: sender, logger: logger}
+}
+
+// Send is the handler for jobs.EmailSendArgs. Rendering errors and hard
+// bounces are permanent; anything else is retried by the job queue. Only
+// metadata is logged, never recipients' addresses or message bodies, and
+// addresses are redacted from the error the queue stores.
+func (w *EmailWorker) Send(ctx context.Context, job *jobs.Job, args jobs.EmailSendArgs) error {
+	log := w.logger.With(
+		slog.Int64("job_id", job.ID),
+		slog.String("template", args.Template),
+		slog.String("locale", args.Locale),
+		slog.Int("recipients", len(args.To)),
+		slog.Int("attempt", job.Attempts),
+	)
+
+	msg, err := w.renderer.Render(args.Template, args.Locale, args.Data)
+	if err != nil {
+		log.Error("email rendering failed", slog.String("error", err.Error()))
+		return jobs.Permanent(err)
+	}
+	msg.To = args.To
+	// A stable ID lets receivers drop duplicates if a retry follows a
+	// delivery whose acknowledgement was lost.
+	msg.ID = fmt.Sprintf("job-%d", job.ID)
+
+	start := time.Now()
+	if err := w.sender.Send(ctx, msg); err != nil {
+		err = email.Redact(err)
+		if email.IsPermanent(err) {
+			log.Error("email rejected", slog.String("error", err.Error()))
+			return jobs.Permanent(err)
+		}
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		log.Warn("email delivery failed", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Info("email delivered", slog.Duration("duration", time.Since(start)))
+	return nil
+}
@@ -0,0 +1,269 @@
+// internal/worker/export_worker.go
+package worker
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/pkg/httputil"
+)
+
+// orphanedExportAge is how long an export may stay pending before
+// maintenance assumes its build job was never enqueued.
+const orphanedExportAge = 10 * time.Minute
+
+// ExportConfig holds settings for building personal data export archives.
+type ExportConfig struct {
+	Dir           string        // where archives are written; must be readable by the API
+	LinkTTL       time.Duration // how long a download link stays valid
+	MaxMediaBytes int64         // attached media larger than this is skipped
+	MediaHosts    []string      // media is only fetched from these hosts
+}
+
+// ExportWorker assembles personal data export archives (data.json + media).
+type ExportWorker struct {
+	exportRepo repository.DataExportRepository
+	auditRepo  repository.AuditRepository
+	enqueuer   jobs.Enqueuer
+	httpClient *http.Client
+	config     ExportConfig
+	logger     *slog.Logger
+}
+
+// NewExportWorker creates an ExportWorker.
+func NewExportWorker(
+	exportRepo repository.DataExportRepository,
+	auditRepo repository.AuditRepository,
+	enqueuer jobs.Enqueuer,
+	config ExportConfig,
+	logger *slog.Logger,
+) *ExportWorker {
+	return &ExportWorker{
+		exportRepo: exportRepo,
+		auditRepo:  auditRepo,
+		enqueuer:   enqueuer,
+		httpClient: mediaClient(),
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// Build is the handler for jobs.ExportBuildArgs. A failed build is retried
+// by the job queue; the export is only marked failed on the last attempt.
+func (w *ExportWorker) Build(ctx context.Context, job *jobs.Job, args jobs.ExportBuildArgs) error {
+	export, err := w.exportRepo.Claim(ctx, args.ExportID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil // already built, failed or expired
+		}
+		return err
+	}
+
+	log := w.logger.With(slog.Int64("export_id", export.ID), slog.Int64("user_id", export.UserID))
+	log.Info("building data export", slog.Int("attempt", job.Attempts))
+
+	filePath, err := w.build(ctx, export)
+	if err != nil {
+		if job.Attempts < job.MaxAttempts {
+			return err
+		}
+		log.Error("data export failed", slog.String("error", err.Error()))
+		if markErr := w.exportRepo.MarkFailed(ctx, export.ID, "failed to assemble export archive"); markErr != nil {
+			return markErr
+		}
+		return jobs.Permanent(err)
+	}
+
+	// The API issues download links on request; see DataExportService.
+	expiresAt := time.Now().Add(w.config.LinkTTL)
+	if err := w.exportRepo.MarkCompleted(ctx, export.ID, filePath, expiresAt); err != nil {
+		return err
+	}
+
+	log.Info("data export completed", slog.Time("expires_at", expiresAt))
+	return nil
+}
+
+// Maintain is the handler for jobs.ExportMaintenanceArgs. It deletes archives
+// whose download link has expired and re-enqueues builds for exports left
+// pending, e.g. because the API could not enqueue their job.
+func (w *ExportWorker) Maintain(ctx context.Context, _ *jobs.Job, _ jobs.ExportMaintenanceArgs) error {
+	expired, err := w.exportRepo.ListExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, e := range expired {
+		if err := os.Remove(e.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			w.logger.Warn("failed to remove expired export archive",
+				slog.Int64("export_id", e.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if err := w.exportRepo.MarkExpired(ctx, e.ID); err != nil {
+			return err
+		}
+	}
+
+	pending, err := w.exportRepo.ListPendingIDs(ctx, time.Now().Add(-orphanedExportAge))
+	if err != nil {
+		return err
+	}
+	for _, id := range pending {
+		if _, err := w.enqueuer.Enqueue(ctx, jobs.ExportBuildArgs{ExportID: id}, jobs.ExportBuildOptions(id)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build writes the archive for export and returns its path.
+func (w *ExportWorker) build(ctx context.Context, export *domain.DataExport) (string, error) {
+	data, err := w.exportRepo.CollectPersonalData(ctx, export.UserID)
+	if err != nil {
+		return "", fmt.Errorf("collecting personal data: %w", err)
+	}
+	data.AuditTrail, err = w.auditRepo.ListByActor(ctx, export.UserID)
+	if err != nil {
+		return "", fmt.Errorf("collecting audit trail: %w", err)
+	}
+
+	if err := os.MkdirAll(w.config.Dir, 0o750); err != nil {
+		return "", fmt.Errorf("creating export directory: %w", err)
+	}
+
+	// Write to a temp file first so a half-written archive is never served.
+	tmp, err := os.CreateTemp(w.config.Dir, fmt.Sprintf("export-%d-*.tmp", export.ID))
+	if err != nil {
+		return "", fmt.Errorf("creating archive: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // temp file is gone after a successful rename
+
+	zw := zip.NewWriter(tmp)
+	for i := range data.Stories {
+		story := &data.Stories[i]
+		if story.MediaURL == nil || *story.MediaURL == "" {
+			continue
+		}
+		name, mediaErr := w.addMedia(ctx, zw, story.ID, *story.MediaURL)
+		if mediaErr != nil {
+			w.logger.Warn("skipping story media in data export",
+				slog.Int64("export_id", export.ID),
+				slog.Int64("story_id", story.ID),
+				slog.String("error", mediaErr.Error()),
+			)
+			continue
+		}
+		story.MediaFile = name
+	}
+
+	f, err := zw.Create("data.json")
+	if err != nil {
+		return "", fmt.Errorf("adding data.json: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return "", fmt.Errorf("encoding data.json: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("finalizing archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("closing archive: %w", err)
+	}
+
+	final := filepath.Join(w.config.Dir, fmt.Sprintf("export-%d.zip", export.ID))
+	if err := os.Rename(tmp.Name(), final); err != nil {
+		return "", fmt.Errorf("moving archive into place: %w", err)
+	}
+	return final, nil
+}
+
+// mediaClient returns the client media is downloaded with: public
+// addresses only, and no redirects, which could lead off the media hosts.
+func mediaClient() *http.Client {
+	client := httputil.NewClient(30*time.Second, false)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// addMedia downloads one media file into the archive and returns its name.
+// Only https URLs on the configured media hosts are fetched; a story's
+// media_url is written through Hasura and must not make the worker request
+// anything else.
+func (w *ExportWorker) addMedia(ctx context.Context, zw *zip.Writer, storyID int64, mediaURL string) (string, error) {
+	u, err := url.Parse(mediaURL)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return "", fmt.Errorf("unsupported media URL")
+	}
+	if !slices.ContainsFunc(w.config.MediaHosts, func(host string) bool { return strings.EqualFold(host, u.Host) }) {
+		return "", fmt.Errorf("media host %q is not a configured media host", u.Host)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > w.config.MaxMediaBytes {
+		return "", fmt.Errorf("media exceeds %d bytes", w.config.MaxMediaBytes)
+	}
+
+	// Spool to disk first so an oversized file never lands in the archive.
+	spool, err := os.CreateTemp(w.config.Dir, "media-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(spool.Name()) //nolint:errcheck // best-effort cleanup of the spool file
+	defer spool.Close()
+
+	n, err := io.Copy(spool, io.LimitReader(resp.Body, w.config.MaxMediaBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if n > w.config.MaxMediaBytes {
+		return "", fmt.Errorf("media exceeds %d bytes", w.config.MaxMediaBytes)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("media/story-%d%s", storyID, path.Ext(u.Path))
+	f, err := zw.Create(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, spool); err != nil {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,73 @@
+// internal/worker/invitation_worker.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/email"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+)
+
+// InvitationWorker emails invitations their links.
+type InvitationWorker struct {
+	invitationRepo repository.InvitationRepository
+	email          *EmailWorker
+	appURL         string
+	logger         *slog.Logger
+}
+
+// NewInvitationWorker creates an InvitationWorker. appURL is the base URL of
+// the web app, which accepts invitations at /invitations/accept?token=<token>.
+func NewInvitationWorker(invitationRepo repository.InvitationRepository, email *EmailWorker, appURL string, logger *slog.Logger) *InvitationWorker {
+	return &InvitationWorker{invitationRepo: invitationRepo, email: email, appURL: appURL, logger: logger}
+}
+
+// Send is the handler for jobs.InvitationSendArgs. It gives the invitation a
+// new token, keeping its expiry, and emails the link straight away, so the
+// token only ever exists in memory and in the email. Invitations that are no
+// longer pending are skipped.
+func (w *InvitationWorker) Send(ctx context.Context, job *jobs.Job, args jobs.InvitationSendArgs) error {
+	inv, err := w.invitationRepo.GetByID(ctx, args.InvitationID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if status := inv.Status(time.Now()); status != domain.InvitationPending {
+		w.logger.Info("invitation not sent", slog.Int64("invitation_id", inv.ID), slog.String("status", status))
+		return nil
+	}
+
+	if err := inv.RotateToken(); err != nil {
+		return err
+	}
+	if err := w.invitationRepo.Renew(ctx, inv); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil // accepted or revoked meanwhile
+		}
+		return err
+	}
+
+	locale := ""
+	if inv.Locale != nil {
+		locale = *inv.Locale
+	}
+	return w.email.Send(ctx, job, jobs.EmailSendArgs{
+		To:       []string{inv.Email},
+		Template: email.TemplateInvitation,
+		Locale:   locale,
+		Data: map[string]interface{}{
+			"Name":      inv.FullName,
+			"Email":     inv.Email,
+			"URL":       strings.TrimRight(w.appURL, "/") + "/invitations/accept?token=" + inv.Token,
+			"ExpiresAt": inv.ExpiresAt.UTC().Format("2006-01-02 15:04 UTC"),
+		},
+	})
+}
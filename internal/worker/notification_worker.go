@@ -1,2 +1,284 @@
 // internal/worker/notification_worker.go
 package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/email"
+	"my-application/internal/jobs"
+	"my-application/internal/push"
+	"my-application/internal/repository"
+)
+
+// NotificationConfig holds settings for notification fan-out.
+type NotificationConfig struct {
+	AppURL            string        // base URL of the web app, used for links
+	RobotOfflineAfter time.Duration // heartbeat age after which a robot is offline
+}
+
+// emailTemplates maps notification types to their email template.
+var emailTemplates = map[string]string{
+	domain.NotificationTypeIncidentEscalated: email.TemplateIncidentEscalation,
+	domain.NotificationTypeFamilyStory:       email.TemplateFamilyStory,
+	domain.NotificationTypeRobotOffline:      email.TemplateRobotOffline,
+}
+
+// NotificationWorker fans events out to the in-app inbox, push and email,
+// honoring each recipient's preferences and quiet hours.
+type NotificationWorker struct {
+	notificationRepo repository.NotificationRepository
+	enqueuer         jobs.Enqueuer
+	pushSender       push.Sender
+	config           NotificationConfig
+	logger           *slog.Logger
+}
+
+// NewNotificationWorker creates a NotificationWorker.
+func NewNotificationWorker(
+	notificationRepo repository.NotificationRepository,
+	enqueuer jobs.Enqueuer,
+	pushSender push.Sender,
+	config NotificationConfig,
+	logger *slog.Logger,
+) *NotificationWorker {
+	return &NotificationWorker{
+		notificationRepo: notificationRepo,
+		enqueuer:         enqueuer,
+		pushSender:       pushSender,
+		config:           config,
+		logger:           logger,
+	}
+}
+
+// Fanout is the handler for jobs.NotificationFanoutArgs. Every step is
+// idempotent so a retried fan-out never notifies anyone twice.
+func (w *NotificationWorker) Fanout(ctx context.Context, _ *jobs.Job, args jobs.NotificationFanoutArgs) error {
+	event, err := w.notificationRepo.ResolveEvent(ctx, args.Type, args.EntityID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil // deleted, resolved or back online in the meantime
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	title, body := describe(event)
+	pushTitle, pushBody := describePush(event)
+	url := w.link(event)
+	dedupeKey := fmt.Sprintf("%s:%d", event.Type, event.EntityID)
+	now := time.Now()
+
+	for _, rc := range event.Recipients {
+		if rc.Preference.InApp {
+			_, err := w.notificationRepo.Create(ctx, &domain.Notification{
+				UserID:    rc.UserID,
+				Type:      event.Type,
+				Title:     title,
+				Body:      body,
+				Data:      map[string]interface{}{"entity_id": event.EntityID, "url": url},
+				DedupeKey: dedupeKey,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		loc, err := time.LoadLocation(rc.TimeZone)
+		if err != nil {
+			loc = time.UTC
+		}
+
+		// Push and email wait for quiet hours to end unless the event is urgent.
+		var opts []jobs.Option
+		if rc.QuietHours != nil && !event.Urgent {
+			if until, quiet := rc.QuietHours.Until(now, loc); quiet {
+				opts = append(opts, jobs.WithRunAt(until))
+			}
+		}
+		recipientKey := fmt.Sprintf("%s:%d", dedupeKey, rc.UserID)
+
+		if rc.Preference.Push {
+			_, err := w.enqueuer.Enqueue(ctx, jobs.PushSendArgs{
+				UserID: rc.UserID,
+				Title:  pushTitle,
+				Body:   pushBody,
+				Data: map[string]string{
+					"type":      event.Type,
+					"entity_id": strconv.FormatInt(event.EntityID, 10),
+					"url":       url,
+				},
+			}, append(opts, jobs.WithUniqueKey("push:"+recipientKey))...)
+			if err != nil {
+				return err
+			}
+		}
+
+		if rc.Preference.Email && rc.Email != "" {
+			_, err := w.enqueuer.Enqueue(ctx, jobs.EmailSendArgs{
+				To:       []string{rc.Email},
+				Template: emailTemplates[event.Type],
+				Locale:   rc.Locale,
+				Data:     emailData(event, url, loc),
+			}, append(append(opts, jobs.EmailSendOptions()...), jobs.WithUniqueKey("email:"+recipientKey))...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	w.logger.Info("notification fanned out",
+		slog.String("type", event.Type),
+		slog.Int64("entity_id", event.EntityID),
+		slog.Int("recipients", len(event.Recipients)),
+	)
+	return nil
+}
+
+// CheckRobots is the handler for jobs.RobotOfflineCheckArgs.
+func (w *NotificationWorker) CheckRobots(ctx context.Context, _ *jobs.Job, _ jobs.RobotOfflineCheckArgs) error {
+	ids, err := w.notificationRepo.EnqueueRobotOffline(ctx, time.Now().Add(-w.config.RobotOfflineAfter))
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		w.logger.Warn("robots went offline", slog.Any("robot_ids", ids))
+	}
+	return nil
+}
+
+// Push is the handler for jobs.PushSendArgs. Invalid tokens are removed.
+// The job is retried only if no device could be reached, so a partial
+// success does not notify the reachable devices twice.
+func (w *NotificationWorker) Push(ctx context.Context, job *jobs.Job, args jobs.PushSendArgs) error {
+	devices, err := w.notificationRepo.ListDevices(ctx, args.UserID)
+	if err != nil {
+		return err
+	}
+
+	delivered := 0
+	var lastErr error
+	for _, d := range devices {
+		err := w.pushSender.Send(ctx, &push.Message{Token: d.Token, Title: args.Title, Body: args.Body, Data: args.Data})
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, push.ErrInvalidToken):
+			if delErr := w.notificationRepo.DeleteDeviceByToken(ctx, d.Token); delErr != nil {
+				return delErr
+			}
+			w.logger.Info("removed invalid push token", slog.Int64("device_id", d.ID), slog.Int64("user_id", args.UserID))
+		default:
+			lastErr = err
+			w.logger.Warn("push delivery failed",
+				slog.Int64("job_id", job.ID),
+				slog.Int64("device_id", d.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if delivered == 0 && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
+
+// RevokeDevices is the outbox subscriber that stops push notifications to
+// users who can no longer sign in.
+func (w *NotificationWorker) RevokeDevices(ctx context.Context, event *domain.DomainEvent) error {
+	var payload domain.UserEventPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("decoding %s payload: %w", event.Type, err))
+	}
+
+	n, err := w.notificationRepo.DeleteDevicesByUser(ctx, payload.UserID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		w.logger.Info("revoked push devices",
+			slog.Int64("user_id", payload.UserID),
+			slog.String("event_type", event.Type),
+			slog.Int64("devices", n),
+		)
+	}
+	return nil
+}
+
+// link returns the web app URL for the event's subject.
+func (w *NotificationWorker) link(event *domain.NotificationEvent) string {
+	base := strings.TrimRight(w.config.AppURL, "/")
+	switch event.Type {
+	case domain.NotificationTypeIncidentEscalated:
+		return fmt.Sprintf("%s/incidents/%d", base, event.EntityID)
+	case domain.NotificationTypeFamilyStory:
+		return fmt.Sprintf("%s/residents/%v/stories/%d", base, event.Fields["ResidentID"], event.EntityID)
+	case domain.NotificationTypeRobotOffline:
+		return fmt.Sprintf("%s/robots/%d", base, event.EntityID)
+	}
+	return base
+}
+
+// describe returns the inbox and push title and body for an event.
+func describe(event *domain.NotificationEvent) (string, string) {
+	f := event.Fields
+	switch event.Type {
+	case domain.NotificationTypeIncidentEscalated:
+		return fmt.Sprintf("%s incident: %s", strings.ToUpper(fmt.Sprint(f["Severity"])), f["ResidentName"]),
+			fmt.Sprint(f["Description"])
+	case domain.NotificationTypeFamilyStory:
+		return fmt.Sprintf("New story for %s", f["ResidentName"]),
+			fmt.Sprintf("%s shared a new %s story.", f["AuthorName"], f["StoryType"])
+	case domain.NotificationTypeRobotOffline:
+		return fmt.Sprintf("Robot %s is offline", f["SerialNumber"]),
+			"The robot has stopped sending heartbeats."
+	}
+	return event.Type, ""
+}
+
+// describePush returns the push title and body for an event. Push messages
+// pass through third-party services and show on lock screens, so an
+// incident's only identify it; its details are in the app.
+func describePush(event *domain.NotificationEvent) (string, string) {
+	if event.Type == domain.NotificationTypeIncidentEscalated {
+		return "Incident escalated", fmt.Sprintf("Incident %d needs attention.", event.EntityID)
+	}
+	return describe(event)
+}
+
+// emailData prepares template data, formatting times in the recipient's zone.
+func emailData(event *domain.NotificationEvent, url string, loc *time.Location) map[string]interface{} {
+	data := make(map[string]interface{}, len(event.Fields)+1)
+	for k, v := range event.Fields {
+		switch t := v.(type) {
+		case time.Time:
+			data[k] = t.In(loc).Format("2006-01-02 15:04 MST")
+		case *time.Time:
+			if t != nil {
+				data[k] = t.In(loc).Format("2006-01-02 15:04 MST")
+			} else {
+				data[k] = nil
+			}
+		case *string:
+			if t != nil {
+				data[k] = *t
+			} else {
+				data[k] = nil
+			}
+		default:
+			data[k] = v
+		}
+	}
+	data["URL"] = url
+	return data
+}
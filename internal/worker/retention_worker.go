@@ -0,0 +1,119 @@
+// internal/worker/retention_worker.go
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+)
+
+// RetentionWorker enforces data retention policies by purging expired rows
+// in batches and recording every batch in the audit log. A batch and its
+// audit event commit together, so no purge goes unrecorded.
+type RetentionWorker struct {
+	retentionRepo repository.RetentionRepository
+	auditRepo     repository.AuditRepository
+	tx            repository.Transactor
+	batchSize     int
+	logger        *slog.Logger
+}
+
+// NewRetentionWorker creates a RetentionWorker.
+func NewRetentionWorker(
+	retentionRepo repository.RetentionRepository,
+	auditRepo repository.AuditRepository,
+	tx repository.Transactor,
+	batchSize int,
+	logger *slog.Logger,
+) *RetentionWorker {
+	return &RetentionWorker{
+		retentionRepo: retentionRepo,
+		auditRepo:     auditRepo,
+		tx:            tx,
+		batchSize:     batchSize,
+		logger:        logger,
+	}
+}
+
+// Enforce is the handler for jobs.RetentionEnforceArgs. It applies every
+// active policy once; a failing policy is logged and skipped so one
+// enterprise cannot block purging for the others.
+func (w *RetentionWorker) Enforce(ctx context.Context, _ *jobs.Job, _ jobs.RetentionEnforceArgs) error {
+	policies, err := w.retentionRepo.ListActivePolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, policy := range policies {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := w.apply(ctx, policy, policy.Cutoff(now)); err != nil {
+			w.logger.Error("retention policy failed",
+				slog.Int64("policy_id", policy.ID),
+				slog.Int64("enterprise_id", policy.EnterpriseID),
+				slog.String("table", policy.TableName),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return nil
+}
+
+// apply purges batches until a short batch signals nothing is left.
+func (w *RetentionWorker) apply(ctx context.Context, policy domain.RetentionPolicy, cutoff time.Time) error {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := w.purgeBatch(ctx, policy, cutoff)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < w.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		w.logger.Info("retention policy applied",
+			slog.Int64("policy_id", policy.ID),
+			slog.Int64("enterprise_id", policy.EnterpriseID),
+			slog.String("table", policy.TableName),
+			slog.String("action", policy.Action),
+			slog.Int("rows", total),
+		)
+	}
+	return nil
+}
+
+// purgeBatch purges one batch and records it in the audit log, in one
+// transaction, and returns the number of rows purged.
+func (w *RetentionWorker) purgeBatch(ctx context.Context, policy domain.RetentionPolicy, cutoff time.Time) (int, error) {
+	var ids []int64
+	err := w.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		ids, err = w.retentionRepo.PurgeBatch(ctx, policy, cutoff, w.batchSize)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return w.auditRepo.Record(ctx, &domain.AuditEvent{
+			EnterpriseID: &policy.EnterpriseID,
+			Action:       "retention." + policy.Action,
+			EntityType:   policy.TableName,
+			Metadata: map[string]interface{}{
+				"policy_id": policy.ID,
+				"cutoff":    cutoff.UTC().Format(time.RFC3339),
+				"row_ids":   ids,
+			},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
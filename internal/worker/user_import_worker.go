@@ -0,0 +1,56 @@
+// internal/worker/user_import_worker.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/internal/service"
+)
+
+// UserImportWorker runs user imports too large to run within a request.
+type UserImportWorker struct {
+	importRepo    repository.UserImportRepository
+	importService service.UserImportService
+	logger        *slog.Logger
+}
+
+// NewUserImportWorker creates a UserImportWorker.
+func NewUserImportWorker(importRepo repository.UserImportRepository, importService service.UserImportService, logger *slog.Logger) *UserImportWorker {
+	return &UserImportWorker{importRepo: importRepo, importService: importService, logger: logger}
+}
+
+// Run is the handler for jobs.UserImportArgs. An import that could not run
+// is retried by the job queue and only marked failed on the last attempt;
+// rows failing don't fail it.
+func (w *UserImportWorker) Run(ctx context.Context, job *jobs.Job, args jobs.UserImportArgs) error {
+	imp, err := w.importRepo.Claim(ctx, args.ImportID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil // already run
+		}
+		return err
+	}
+
+	log := w.logger.With(slog.Int64("import_id", imp.ID))
+	log.Info("running user import", slog.Int("rows", len(imp.Rows)), slog.Int("attempt", job.Attempts))
+
+	if err := w.importService.RunImport(ctx, imp); err != nil {
+		if job.Attempts < job.MaxAttempts {
+			return err
+		}
+		log.Error("user import failed", slog.String("error", err.Error()))
+		imp.Status = domain.ImportStatusFailed
+		imp.Results = []domain.UserImportResult{}
+		imp.Error = "failed to run the import"
+		if finishErr := w.importRepo.Finish(ctx, imp); finishErr != nil {
+			return finishErr
+		}
+		return jobs.Permanent(err)
+	}
+	return w.importRepo.Finish(ctx, imp)
+}
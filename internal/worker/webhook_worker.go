@@ -0,0 +1,172 @@
+// internal/worker/webhook_worker.go
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/internal/webhook"
+)
+
+// WebhookWorker turns domain events into webhook deliveries and sends them
+// to enterprise endpoints. Retries use the job queue's exponential backoff;
+// receivers deduplicate on the event ID, since a delivery may arrive more
+// than once.
+type WebhookWorker struct {
+	webhookRepo repository.WebhookRepository
+	auditRepo   repository.AuditRepository
+	enqueuer    jobs.Enqueuer
+	tx          repository.Transactor
+	sender      *webhook.Sender
+	disable     domain.WebhookDisablePolicy
+	logger      *slog.Logger
+}
+
+// NewWebhookWorker creates a WebhookWorker that disables subscriptions
+// according to disable.
+func NewWebhookWorker(
+	webhookRepo repository.WebhookRepository,
+	auditRepo repository.AuditRepository,
+	enqueuer jobs.Enqueuer,
+	tx repository.Transactor,
+	sender *webhook.Sender,
+	disable domain.WebhookDisablePolicy,
+	logger *slog.Logger,
+) *WebhookWorker {
+	return &WebhookWorker{
+		webhookRepo: webhookRepo,
+		auditRepo:   auditRepo,
+		enqueuer:    enqueuer,
+		tx:          tx,
+		sender:      sender,
+		disable:     disable,
+		logger:      logger,
+	}
+}
+
+// Fanout is the outbox subscriber for domain.WebhookEventTypes. It creates
+// a delivery and a webhook.deliver job for every active subscription of the
+// event's enterprise that wants it, in the outbox delivery's transaction, so
+// each event is queued for a subscription exactly once.
+func (w *WebhookWorker) Fanout(ctx context.Context, event *domain.DomainEvent) error {
+	if event.EnterpriseID == nil {
+		return nil
+	}
+	subs, err := w.webhookRepo.ListSubscribed(ctx, *event.EnterpriseID, event.Type)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	body := domain.WebhookPayload{
+		ID:           uuid.NewString(),
+		Type:         event.Type,
+		EnterpriseID: *event.EnterpriseID,
+		CreatedAt:    event.OccurredAt,
+		Data:         event.Payload,
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("encoding %s webhook payload: %w", event.Type, err))
+	}
+
+	for _, sub := range subs {
+		d := &domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        body.ID,
+			EventType:      body.Type,
+			Payload:        payload,
+			Status:         domain.WebhookDeliveryPending,
+		}
+		if err := w.webhookRepo.CreateDelivery(ctx, d); err != nil {
+			return err
+		}
+		if _, err := w.enqueuer.Enqueue(ctx, jobs.WebhookDeliverArgs{DeliveryID: d.ID}, jobs.WebhookDeliverOptions(d.ID)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver is the handler for jobs.WebhookDeliverArgs.
+func (w *WebhookWorker) Deliver(ctx context.Context, job *jobs.Job, args jobs.WebhookDeliverArgs) error {
+	d, sub, err := w.webhookRepo.GetDelivery(ctx, args.DeliveryID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil // the subscription was deleted
+		}
+		return err
+	}
+	if d.Status != domain.WebhookDeliveryPending {
+		return nil
+	}
+
+	now := time.Now()
+	d.Attempts = job.Attempts
+	d.LastAttemptAt = &now
+
+	if !sub.IsActive {
+		msg := "subscription is disabled"
+		d.Status = domain.WebhookDeliveryFailed
+		d.LastError = &msg
+		return w.webhookRepo.SaveAttempt(ctx, d)
+	}
+
+	result, sendErr := w.sender.Send(ctx, webhook.Request{
+		URL:       sub.URL,
+		Secret:    sub.Secret,
+		EventID:   d.EventID,
+		EventType: d.EventType,
+		Body:      d.Payload,
+	})
+	d.ApplyResult(result.StatusCode, result.Duration, sendErr, job.Attempts >= job.MaxAttempts)
+
+	err = w.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := w.webhookRepo.SaveAttempt(ctx, d); err != nil {
+			return err
+		}
+		disabled, err := w.webhookRepo.RecordResult(ctx, sub.ID, sendErr == nil, w.disable)
+		if err != nil || !disabled {
+			return err
+		}
+
+		w.logger.Warn("webhook subscription disabled after repeated failures",
+			slog.Int64("subscription_id", sub.ID),
+			slog.Int64("enterprise_id", sub.EnterpriseID),
+		)
+		return w.auditRepo.Record(ctx, &domain.AuditEvent{
+			EnterpriseID: &sub.EnterpriseID,
+			Action:       "webhook.disabled",
+			EntityType:   "webhook",
+			EntityID:     &sub.ID,
+			Metadata: map[string]interface{}{
+				"url":              sub.URL,
+				"delivery_id":      d.ID,
+				"minimum_failures": w.disable.Failures,
+			},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if sendErr != nil {
+		w.logger.Warn("webhook delivery failed",
+			slog.Int64("delivery_id", d.ID),
+			slog.Int64("subscription_id", sub.ID),
+			slog.Int("status_code", result.StatusCode),
+			slog.Int("attempt", job.Attempts),
+			slog.String("error", sendErr.Error()),
+		)
+		return sendErr
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+-- migrations/000011_soft_delete_users.down.sql
+
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_reporter_id_fkey;
+ALTER TABLE incidents ADD CONSTRAINT incidents_reporter_id_fkey
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE stories DROP CONSTRAINT IF EXISTS stories_author_id_fkey;
+ALTER TABLE stories ADD CONSTRAINT stories_author_id_fkey
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS purged_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
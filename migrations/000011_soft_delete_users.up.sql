@@ -0,0 +1,21 @@
+-- migrations/000011_soft_delete_users.up.sql
+
+-- Soft delete: deleted users are hidden from the API but keep their rows so
+-- authored clinical history (stories, incidents) stays attributable.
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Purge: personal fields have been anonymized; the row is kept as a tombstone.
+ALTER TABLE users
+    ADD COLUMN purged_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+-- Hard-deleting an author or reporter must never cascade into clinical history.
+ALTER TABLE stories DROP CONSTRAINT IF EXISTS stories_author_id_fkey;
+ALTER TABLE stories ADD CONSTRAINT stories_author_id_fkey
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_reporter_id_fkey;
+ALTER TABLE incidents ADD CONSTRAINT incidents_reporter_id_fkey
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE RESTRICT;
@@ -0,0 +1,5 @@
+-- migrations/000012_create_audit_log_and_data_exports.down.sql
+
+DROP TRIGGER IF EXISTS set_data_exports_updated_at ON data_exports;
+DROP TABLE IF EXISTS data_exports;
+DROP TABLE IF EXISTS audit_log;
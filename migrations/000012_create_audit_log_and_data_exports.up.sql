@@ -0,0 +1,39 @@
+-- migrations/000012_create_audit_log_and_data_exports.up.sql
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id              BIGSERIAL       PRIMARY KEY,
+    enterprise_id   BIGINT          REFERENCES enterprises(id) ON DELETE SET NULL,
+    actor_id        BIGINT          REFERENCES users(id) ON DELETE SET NULL,
+    action          VARCHAR(100)    NOT NULL,
+    entity_type     VARCHAR(50)     NOT NULL,
+    entity_id       BIGINT,
+    metadata        JSONB           NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+CREATE TABLE IF NOT EXISTS data_exports (
+    id              BIGSERIAL       PRIMARY KEY,
+    user_id         BIGINT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_by    BIGINT          REFERENCES users(id) ON DELETE SET NULL,
+    status          VARCHAR(20)     NOT NULL DEFAULT 'pending'
+                                    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'expired')),
+    file_path       TEXT,
+    download_token  VARCHAR(64)     UNIQUE,
+    expires_at      TIMESTAMPTZ,
+    error           TEXT,
+    completed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports (user_id);
+CREATE INDEX IF NOT EXISTS idx_data_exports_status ON data_exports (status);
+
+CREATE TRIGGER set_data_exports_updated_at
+    BEFORE UPDATE ON data_exports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
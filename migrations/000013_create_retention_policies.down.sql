@@ -0,0 +1,9 @@
+-- migrations/000013_create_retention_policies.down.sql
+
+DROP INDEX IF EXISTS idx_stories_created_at;
+DROP INDEX IF EXISTS idx_incidents_resolved_at;
+
+ALTER TABLE residents DROP COLUMN IF EXISTS legal_hold;
+
+DROP TRIGGER IF EXISTS set_retention_policies_updated_at ON retention_policies;
+DROP TABLE IF EXISTS retention_policies;
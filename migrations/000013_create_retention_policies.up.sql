@@ -0,0 +1,28 @@
+-- migrations/000013_create_retention_policies.up.sql
+
+CREATE TABLE IF NOT EXISTS retention_policies (
+    id              BIGSERIAL       PRIMARY KEY,
+    enterprise_id   BIGINT          NOT NULL REFERENCES enterprises(id) ON DELETE CASCADE,
+    table_name      VARCHAR(50)     NOT NULL
+                                    CHECK (table_name IN ('robot_sessions', 'incidents', 'stories')),
+    retention_days  INT             NOT NULL CHECK (retention_days > 0),
+    action          VARCHAR(20)     NOT NULL DEFAULT 'delete'
+                                    CHECK (action IN ('delete', 'anonymize')),
+    is_active       BOOLEAN         NOT NULL DEFAULT true,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    UNIQUE (enterprise_id, table_name)
+);
+
+CREATE TRIGGER set_retention_policies_updated_at
+    BEFORE UPDATE ON retention_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Legal hold suspends retention purging for everything linked to a resident.
+ALTER TABLE residents
+    ADD COLUMN legal_hold BOOLEAN NOT NULL DEFAULT false;
+
+-- Supports the retention scans on incidents and stories.
+CREATE INDEX IF NOT EXISTS idx_incidents_resolved_at ON incidents (resolved_at);
+CREATE INDEX IF NOT EXISTS idx_stories_created_at ON stories (created_at);
@@ -0,0 +1,4 @@
+-- migrations/000014_create_jobs_table.down.sql
+
+DROP TRIGGER IF EXISTS set_jobs_updated_at ON jobs;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,36 @@
+-- migrations/000014_create_jobs_table.up.sql
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id              BIGSERIAL       PRIMARY KEY,
+    queue           VARCHAR(50)     NOT NULL DEFAULT 'default',
+    kind            VARCHAR(100)    NOT NULL,
+    payload         JSONB           NOT NULL DEFAULT '{}',
+    status          VARCHAR(20)     NOT NULL DEFAULT 'pending'
+                                    CHECK (status IN ('pending', 'running', 'completed', 'dead')),
+    attempts        INT             NOT NULL DEFAULT 0,
+    max_attempts    INT             NOT NULL DEFAULT 10 CHECK (max_attempts > 0),
+    run_at          TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    unique_key      VARCHAR(200),
+    locked_at       TIMESTAMPTZ,
+    locked_by       VARCHAR(100),
+    last_error      TEXT,
+    completed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+-- Fetching only ever looks at due, pending jobs of one queue.
+CREATE INDEX IF NOT EXISTS idx_jobs_fetch ON jobs (queue, run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs (locked_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_completed_at ON jobs (completed_at) WHERE status = 'completed';
+
+-- At most one live job per unique key (used for periodic and deduplicated jobs).
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('pending', 'running');
+-- Periodic jobs also check finished jobs for their interval's key.
+CREATE INDEX IF NOT EXISTS idx_jobs_unique_key_all ON jobs (unique_key) WHERE unique_key IS NOT NULL;
+
+CREATE TRIGGER set_jobs_updated_at
+    BEFORE UPDATE ON jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
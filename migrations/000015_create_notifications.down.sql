@@ -0,0 +1,24 @@
+-- migrations/000015_create_notifications.down.sql
+
+DROP TRIGGER IF EXISTS stories_notify_family_story ON stories;
+DROP FUNCTION IF EXISTS notify_family_story();
+DROP TRIGGER IF EXISTS incidents_notify_escalated ON incidents;
+DROP FUNCTION IF EXISTS notify_incident_escalated();
+DROP FUNCTION IF EXISTS enqueue_notification_fanout(TEXT, BIGINT);
+
+DROP TRIGGER IF EXISTS reset_robots_offline_notified ON robots;
+DROP FUNCTION IF EXISTS reset_robot_offline_notified();
+ALTER TABLE robots DROP COLUMN IF EXISTS offline_notified_at;
+
+DROP TABLE IF EXISTS push_devices;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS chk_users_quiet_hours,
+    DROP COLUMN IF EXISTS quiet_hours_end,
+    DROP COLUMN IF EXISTS quiet_hours_start;
+
+DROP TRIGGER IF EXISTS set_notification_preferences_updated_at ON notification_preferences;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+
+ALTER TABLE enterprises DROP COLUMN IF EXISTS time_zone;
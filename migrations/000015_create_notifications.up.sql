@@ -0,0 +1,122 @@
+-- migrations/000015_create_notifications.up.sql
+
+-- Quiet hours are evaluated in the enterprise's local time.
+ALTER TABLE enterprises
+    ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id              BIGSERIAL       PRIMARY KEY,
+    user_id         BIGINT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type            VARCHAR(50)     NOT NULL
+                                    CHECK (type IN ('incident_escalated', 'family_story', 'robot_offline')),
+    title           VARCHAR(200)    NOT NULL,
+    body            TEXT            NOT NULL,
+    data            JSONB           NOT NULL DEFAULT '{}',
+    -- One notification per user and source event, so fan-out retries are idempotent.
+    dedupe_key      VARCHAR(100)    NOT NULL,
+    read_at         TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, dedupe_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+-- Channel opt-ins per notification type. Missing rows fall back to defaults.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id         BIGINT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type            VARCHAR(50)     NOT NULL
+                                    CHECK (type IN ('incident_escalated', 'family_story', 'robot_offline')),
+    in_app          BOOLEAN         NOT NULL DEFAULT true,
+    push            BOOLEAN         NOT NULL DEFAULT true,
+    email           BOOLEAN         NOT NULL DEFAULT false,
+    updated_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, type)
+);
+
+CREATE TRIGGER set_notification_preferences_updated_at
+    BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Quiet hours hold back push and email (in-app is always delivered).
+ALTER TABLE users
+    ADD COLUMN quiet_hours_start TIME,
+    ADD COLUMN quiet_hours_end   TIME,
+    ADD CONSTRAINT chk_users_quiet_hours
+        CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL));
+
+CREATE TABLE IF NOT EXISTS push_devices (
+    id              BIGSERIAL       PRIMARY KEY,
+    user_id         BIGINT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token           VARCHAR(512)    NOT NULL UNIQUE,
+    platform        VARCHAR(20)     NOT NULL CHECK (platform IN ('ios', 'android', 'web')),
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    last_seen_at    TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices (user_id);
+
+-- Set when a robot-offline notification has gone out; cleared by the next heartbeat.
+ALTER TABLE robots
+    ADD COLUMN offline_notified_at TIMESTAMPTZ;
+
+CREATE OR REPLACE FUNCTION reset_robot_offline_notified()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.last_heartbeat IS DISTINCT FROM OLD.last_heartbeat THEN
+        NEW.offline_notified_at = NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reset_robots_offline_notified
+    BEFORE UPDATE OF last_heartbeat ON robots
+    FOR EACH ROW
+    EXECUTE FUNCTION reset_robot_offline_notified();
+
+-- Incidents and stories are mostly written through Hasura, so the fan-out
+-- job is enqueued by the database itself (see internal/jobs/args.go,
+-- NotificationFanoutArgs).
+CREATE OR REPLACE FUNCTION enqueue_notification_fanout(event_type TEXT, entity_id BIGINT)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO jobs (queue, kind, payload, unique_key)
+    VALUES ('default', 'notification.fanout',
+            jsonb_build_object('type', event_type, 'entity_id', entity_id),
+            'notify:' || event_type || ':' || entity_id)
+    ON CONFLICT (unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'running') DO NOTHING;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_incident_escalated()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.resolved_at IS NULL AND NEW.severity IN ('high', 'critical')
+       AND (TG_OP = 'INSERT' OR OLD.severity NOT IN ('high', 'critical')) THEN
+        PERFORM enqueue_notification_fanout('incident_escalated', NEW.id);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER incidents_notify_escalated
+    AFTER INSERT OR UPDATE OF severity ON incidents
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_incident_escalated();
+
+CREATE OR REPLACE FUNCTION notify_family_story()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE id = NEW.author_id AND role = 'family') THEN
+        PERFORM enqueue_notification_fanout('family_story', NEW.id);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stories_notify_family_story
+    AFTER INSERT ON stories
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_family_story();
@@ -0,0 +1,10 @@
+-- migrations/000016_create_realtime_events.down.sql
+
+DROP TRIGGER IF EXISTS robot_sessions_publish_realtime ON robot_sessions;
+DROP FUNCTION IF EXISTS publish_session_started_event();
+DROP TRIGGER IF EXISTS robots_publish_realtime ON robots;
+DROP FUNCTION IF EXISTS publish_robot_status_event();
+DROP TRIGGER IF EXISTS incidents_publish_realtime ON incidents;
+DROP FUNCTION IF EXISTS publish_incident_event();
+DROP FUNCTION IF EXISTS publish_realtime_event(TEXT, BIGINT, BIGINT, JSONB);
+DROP SEQUENCE IF EXISTS realtime_event_id_seq;
@@ -0,0 +1,106 @@
+-- migrations/000016_create_realtime_events.up.sql
+
+-- Dashboard events are broadcast to every API replica over LISTEN/NOTIFY on
+-- the 'realtime_events' channel (see internal/realtime). Notifications are
+-- sent on commit only, so rolled-back changes are never streamed. Event IDs
+-- come from a sequence so every replica numbers events the same way, which
+-- lets clients resume on any replica with Last-Event-ID.
+CREATE SEQUENCE IF NOT EXISTS realtime_event_id_seq;
+
+-- Payloads carry identifiers and status only; clients fetch details through
+-- the regular, access-checked API. NOTIFY payloads are limited to 8000 bytes.
+CREATE OR REPLACE FUNCTION publish_realtime_event(
+    event_type      TEXT,
+    p_enterprise_id BIGINT,
+    p_resident_id   BIGINT,
+    event_data      JSONB
+)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM pg_notify('realtime_events', json_build_object(
+        'id',            nextval('realtime_event_id_seq'),
+        'type',          event_type,
+        'enterprise_id', p_enterprise_id,
+        'resident_id',   p_resident_id,
+        'data',          event_data,
+        'created_at',    NOW()
+    )::TEXT);
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION publish_incident_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_enterprise_id BIGINT;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NOT (OLD.resolved_at IS NULL AND NEW.resolved_at IS NOT NULL) THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT enterprise_id INTO v_enterprise_id FROM residents WHERE id = NEW.resident_id;
+
+    PERFORM publish_realtime_event(
+        CASE WHEN TG_OP = 'INSERT' THEN 'incident.created' ELSE 'incident.resolved' END,
+        v_enterprise_id,
+        NEW.resident_id,
+        jsonb_build_object('incident_id', NEW.id, 'severity', NEW.severity)
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER incidents_publish_realtime
+    AFTER INSERT OR UPDATE OF resolved_at ON incidents
+    FOR EACH ROW
+    EXECUTE FUNCTION publish_incident_event();
+
+CREATE OR REPLACE FUNCTION publish_robot_status_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status IS DISTINCT FROM OLD.status THEN
+        PERFORM publish_realtime_event(
+            'robot.status_changed',
+            NEW.enterprise_id,
+            NEW.assigned_resident_id,
+            jsonb_build_object(
+                'robot_id',        NEW.id,
+                'serial_number',   NEW.serial_number,
+                'status',          NEW.status,
+                'previous_status', OLD.status
+            )
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER robots_publish_realtime
+    AFTER UPDATE OF status ON robots
+    FOR EACH ROW
+    EXECUTE FUNCTION publish_robot_status_event();
+
+CREATE OR REPLACE FUNCTION publish_session_started_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_enterprise_id BIGINT;
+BEGIN
+    SELECT enterprise_id INTO v_enterprise_id FROM residents WHERE id = NEW.resident_id;
+
+    PERFORM publish_realtime_event(
+        'session.started',
+        v_enterprise_id,
+        NEW.resident_id,
+        jsonb_build_object(
+            'session_id',   NEW.id,
+            'robot_id',     NEW.robot_id,
+            'session_type', NEW.session_type
+        )
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER robot_sessions_publish_realtime
+    AFTER INSERT ON robot_sessions
+    FOR EACH ROW
+    EXECUTE FUNCTION publish_session_started_event();
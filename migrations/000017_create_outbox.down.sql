@@ -0,0 +1,4 @@
+-- migrations/000017_create_outbox.down.sql
+
+DROP TABLE IF EXISTS outbox_deliveries;
+DROP TABLE IF EXISTS outbox_events;
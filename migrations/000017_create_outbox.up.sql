@@ -0,0 +1,29 @@
+-- migrations/000017_create_outbox.up.sql
+
+-- Domain events are written here in the same transaction as the change they
+-- describe. The worker's relay turns each event into one delivery job per
+-- subscriber and stamps relayed_at (see internal/outbox).
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              BIGSERIAL       PRIMARY KEY,
+    event_type      VARCHAR(100)    NOT NULL,
+    aggregate_type  VARCHAR(50)     NOT NULL,
+    aggregate_id    BIGINT          NOT NULL,
+    -- No foreign key: events outlive the rows they describe.
+    enterprise_id   BIGINT,
+    payload         JSONB           NOT NULL DEFAULT '{}',
+    occurred_at     TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    relayed_at      TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unrelayed ON outbox_events (id) WHERE relayed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_relayed_at ON outbox_events (relayed_at) WHERE relayed_at IS NOT NULL;
+
+-- One row per event and subscriber that has handled it, written in the
+-- subscriber's transaction so a retried delivery is skipped.
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    event_id        BIGINT          NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    subscriber      VARCHAR(100)    NOT NULL,
+    delivered_at    TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (event_id, subscriber)
+);
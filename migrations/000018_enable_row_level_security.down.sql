@@ -0,0 +1,37 @@
+-- migrations/000018_enable_row_level_security.down.sql
+
+DROP POLICY IF EXISTS tenant_isolation ON push_devices;
+ALTER TABLE push_devices DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON notification_preferences;
+ALTER TABLE notification_preferences DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON notifications;
+ALTER TABLE notifications DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON data_exports;
+ALTER TABLE data_exports DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON robot_sessions;
+ALTER TABLE robot_sessions DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON incidents;
+ALTER TABLE incidents DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON stories;
+ALTER TABLE stories DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON caregiver_residents;
+ALTER TABLE caregiver_residents DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON audit_log;
+ALTER TABLE audit_log DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON retention_policies;
+ALTER TABLE retention_policies DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON robots;
+ALTER TABLE robots DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON residents;
+ALTER TABLE residents DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON enterprises;
+ALTER TABLE enterprises DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_user_id();
+DROP FUNCTION IF EXISTS app_enterprise_id();
+
+-- Revokes the grants and default privileges in this database. The roles
+-- themselves are cluster-wide and are left for other databases using them.
+DROP OWNED BY app_tenant, app_bypass;
@@ -0,0 +1,107 @@
+-- migrations/000018_enable_row_level_security.up.sql
+
+-- Row-level security is a second tenant-isolation layer under the checks in
+-- the Go services. Transactions made on behalf of an authenticated user
+-- switch to one of two roles (see pkg/database/tenant.go):
+--
+--   app_tenant  policies below apply, keyed on the app.* session settings
+--   app_bypass  BYPASSRLS, used for mta
+--
+-- Connections that never switch role (migrations, the worker, Hasura, login
+-- before a user is known) keep the owner's privileges: RLS is enabled but
+-- not forced, so the table owner is not subject to it.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_tenant') THEN
+        CREATE ROLE app_tenant NOLOGIN;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_bypass') THEN
+        CREATE ROLE app_bypass NOLOGIN BYPASSRLS;
+    END IF;
+END
+$$;
+
+-- The application's login role must be able to SET ROLE to both.
+GRANT app_tenant, app_bypass TO CURRENT_USER;
+
+GRANT USAGE ON SCHEMA public TO app_tenant, app_bypass;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO app_tenant, app_bypass;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO app_tenant, app_bypass;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO app_tenant, app_bypass;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    GRANT USAGE, SELECT ON SEQUENCES TO app_tenant, app_bypass;
+
+-- Session settings are transaction-local (set_config(..., true)). A missing
+-- or empty setting yields NULL, which matches nothing.
+CREATE OR REPLACE FUNCTION app_enterprise_id()
+RETURNS BIGINT AS $$
+    SELECT NULLIF(current_setting('app.enterprise_id', true), '')::BIGINT
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION app_user_id()
+RETURNS BIGINT AS $$
+    SELECT NULLIF(current_setting('app.user_id', true), '')::BIGINT
+$$ LANGUAGE sql STABLE;
+
+-- Tables keyed directly on enterprise_id.
+ALTER TABLE enterprises ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON enterprises TO app_tenant
+    USING (id = app_enterprise_id());
+
+ALTER TABLE residents ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON residents TO app_tenant
+    USING (enterprise_id = app_enterprise_id());
+
+ALTER TABLE robots ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON robots TO app_tenant
+    USING (enterprise_id = app_enterprise_id());
+
+ALTER TABLE retention_policies ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON retention_policies TO app_tenant
+    USING (enterprise_id = app_enterprise_id());
+
+-- Users see their enterprise's users and always themselves.
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users TO app_tenant
+    USING (enterprise_id = app_enterprise_id() OR id = app_user_id());
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_log TO app_tenant
+    USING (enterprise_id = app_enterprise_id() OR actor_id = app_user_id());
+
+-- Tables owned by a resident follow the residents policy.
+ALTER TABLE caregiver_residents ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON caregiver_residents TO app_tenant
+    USING (resident_id IN (SELECT id FROM residents));
+
+ALTER TABLE stories ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON stories TO app_tenant
+    USING (resident_id IN (SELECT id FROM residents));
+
+ALTER TABLE incidents ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON incidents TO app_tenant
+    USING (resident_id IN (SELECT id FROM residents));
+
+ALTER TABLE robot_sessions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON robot_sessions TO app_tenant
+    USING (resident_id IN (SELECT id FROM residents));
+
+-- Exports follow the users policy: one's own and those of one's enterprise.
+ALTER TABLE data_exports ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON data_exports TO app_tenant
+    USING (user_id IN (SELECT id FROM users));
+
+-- Personal notification data is visible to its owner only.
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notifications TO app_tenant
+    USING (user_id = app_user_id());
+
+ALTER TABLE notification_preferences ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notification_preferences TO app_tenant
+    USING (user_id = app_user_id());
+
+ALTER TABLE push_devices ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON push_devices TO app_tenant
+    USING (user_id = app_user_id());
@@ -0,0 +1,26 @@
+-- migrations/000019_create_webhooks.down.sql
+
+-- Restore the 000016 version, which only notifies dashboards.
+CREATE OR REPLACE FUNCTION publish_realtime_event(
+    event_type      TEXT,
+    p_enterprise_id BIGINT,
+    p_resident_id   BIGINT,
+    event_data      JSONB
+)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM pg_notify('realtime_events', json_build_object(
+        'id',            nextval('realtime_event_id_seq'),
+        'type',          event_type,
+        'enterprise_id', p_enterprise_id,
+        'resident_id',   p_resident_id,
+        'data',          event_data,
+        'created_at',    NOW()
+    )::TEXT);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS enqueue_webhook_event(TEXT, BIGINT, JSONB);
+DELETE FROM jobs WHERE kind = 'webhook.deliver';
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
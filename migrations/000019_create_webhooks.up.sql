@@ -0,0 +1,118 @@
+-- migrations/000019_create_webhooks.up.sql
+
+-- Outbound webhooks let an enterprise's own systems (EHR, paging) receive
+-- dashboard events. The secret signs every payload (see internal/webhook) and
+-- is kept in clear because the worker needs it to sign.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                      BIGSERIAL       PRIMARY KEY,
+    enterprise_id           BIGINT          NOT NULL REFERENCES enterprises(id) ON DELETE CASCADE,
+    url                     VARCHAR(2048)   NOT NULL,
+    description             VARCHAR(200)    NOT NULL DEFAULT '',
+    event_types             TEXT[]          NOT NULL CHECK (cardinality(event_types) > 0),
+    secret                  VARCHAR(100)    NOT NULL,
+    is_active               BOOLEAN         NOT NULL DEFAULT true,
+    -- Failed attempts in a row and when the streak began; a long enough
+    -- streak disables the subscription (disabled_at is set).
+    consecutive_failures    INT             NOT NULL DEFAULT 0,
+    failing_since           TIMESTAMPTZ,
+    disabled_at             TIMESTAMPTZ,
+    created_at              TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_enterprise ON webhook_subscriptions (enterprise_id);
+
+CREATE TRIGGER set_webhook_subscriptions_updated_at
+    BEFORE UPDATE ON webhook_subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- One row per event and subscription; doubles as the delivery log. The
+-- payload is stored as sent so retries are byte-for-byte identical.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id                  BIGSERIAL       PRIMARY KEY,
+    subscription_id     BIGINT          NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id            UUID            NOT NULL,
+    event_type          VARCHAR(50)     NOT NULL,
+    payload             JSONB           NOT NULL,
+    status              VARCHAR(20)     NOT NULL DEFAULT 'pending'
+                                        CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts            INT             NOT NULL DEFAULT 0,
+    response_code       INT,
+    last_error          TEXT,
+    last_attempt_at     TIMESTAMPTZ,
+    delivered_at        TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_created
+    ON webhook_deliveries (subscription_id, created_at DESC);
+
+-- Creates a delivery and a webhook.deliver job (see internal/jobs/args.go,
+-- WebhookDeliverArgs) for every active subscription of the enterprise that
+-- wants event_type. It runs as the table owner so the fan-out does not
+-- depend on the tenant scope of whoever made the change.
+CREATE OR REPLACE FUNCTION enqueue_webhook_event(
+    event_type      TEXT,
+    p_enterprise_id BIGINT,
+    event_data      JSONB
+)
+RETURNS VOID AS $$
+DECLARE
+    v_event_id UUID := gen_random_uuid();
+BEGIN
+    IF p_enterprise_id IS NULL THEN
+        RETURN;
+    END IF;
+
+    WITH deliveries AS (
+        INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+        SELECT s.id, v_event_id, event_type, jsonb_build_object(
+                   'id',            v_event_id,
+                   'type',          event_type,
+                   'enterprise_id', p_enterprise_id,
+                   'created_at',    NOW(),
+                   'data',          event_data)
+        FROM webhook_subscriptions s
+        WHERE s.enterprise_id = p_enterprise_id
+          AND s.is_active
+          AND event_type = ANY (s.event_types)
+        RETURNING id
+    )
+    INSERT INTO jobs (queue, kind, payload, max_attempts, unique_key)
+    SELECT 'webhooks', 'webhook.deliver', jsonb_build_object('delivery_id', id), 16, 'webhook:' || id
+    FROM deliveries;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Every dashboard event (migration 000016) is also offered to webhooks.
+CREATE OR REPLACE FUNCTION publish_realtime_event(
+    event_type      TEXT,
+    p_enterprise_id BIGINT,
+    p_resident_id   BIGINT,
+    event_data      JSONB
+)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM pg_notify('realtime_events', json_build_object(
+        'id',            nextval('realtime_event_id_seq'),
+        'type',          event_type,
+        'enterprise_id', p_enterprise_id,
+        'resident_id',   p_resident_id,
+        'data',          event_data,
+        'created_at',    NOW()
+    )::TEXT);
+
+    PERFORM enqueue_webhook_event(event_type, p_enterprise_id,
+        jsonb_build_object('resident_id', p_resident_id) || event_data);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE webhook_subscriptions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhook_subscriptions TO app_tenant
+    USING (enterprise_id = app_enterprise_id());
+
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhook_deliveries TO app_tenant
+    USING (subscription_id IN (SELECT id FROM webhook_subscriptions));
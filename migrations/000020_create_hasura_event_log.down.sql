@@ -0,0 +1,3 @@
+-- migrations/000020_create_hasura_event_log.down.sql
+
+DROP TABLE IF EXISTS hasura_processed_events;
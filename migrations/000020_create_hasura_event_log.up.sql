@@ -0,0 +1,13 @@
+-- migrations/000020_create_hasura_event_log.up.sql
+
+-- Hasura event triggers deliver at least once. The receiver records each
+-- event ID in the same transaction as the work it does for the event, so a
+-- redelivered event is recognized and skipped (see internal/hasura).
+CREATE TABLE IF NOT EXISTS hasura_processed_events (
+    event_id        UUID            PRIMARY KEY,
+    trigger_name    VARCHAR(100)    NOT NULL,
+    table_name      VARCHAR(100)    NOT NULL,
+    processed_at    TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hasura_processed_events_processed_at ON hasura_processed_events (processed_at);
@@ -0,0 +1,3 @@
+-- migrations/000021_fix_users_role_default.down.sql
+
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'viewer';
@@ -0,0 +1,6 @@
+-- migrations/000021_fix_users_role_default.up.sql
+
+-- 000004 replaced the role CHECK with the SONA roles but kept the original
+-- 'viewer' default, so inserts without a role violated the constraint.
+-- 'viewer' was mapped to 'caregiver', which is also the API's default.
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'caregiver';
@@ -0,0 +1,3 @@
+-- migrations/000022_add_users_locale.down.sql
+
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
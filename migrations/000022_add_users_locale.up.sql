@@ -0,0 +1,8 @@
+-- migrations/000022_add_users_locale.up.sql
+
+-- Preferred language of API messages, a BCP 47 tag such as 'es'. NULL lets
+-- the client's Accept-Language header decide. Hasura lets users set their
+-- own, so the format is checked here as well as in the API.
+ALTER TABLE users
+    ADD COLUMN locale VARCHAR(35)
+    CONSTRAINT users_locale_check CHECK (locale ~ '^[a-z]{2,3}(-[A-Za-z0-9]{1,8})*$');
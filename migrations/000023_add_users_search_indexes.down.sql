@@ -0,0 +1,8 @@
+-- migrations/000023_add_users_search_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_users_created_at_id;
+DROP INDEX IF EXISTS idx_users_full_name_trgm;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_users_username_trgm;
+
+-- pg_trgm is left installed; other objects may have come to depend on it.
@@ -0,0 +1,12 @@
+-- migrations/000023_add_users_search_indexes.up.sql
+
+-- User search matches a case-insensitive substring of the username, email
+-- or full name (ILIKE '%term%'), which only a trigram index can serve.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING gin (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_full_name_trgm ON users USING gin (full_name gin_trgm_ops);
+
+-- Keyset pages in the default order seek on (created_at, id).
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at, id);
@@ -0,0 +1,5 @@
+-- migrations/000024_add_users_version.down.sql
+
+DROP TRIGGER IF EXISTS users_bump_version ON users;
+DROP FUNCTION IF EXISTS bump_user_version();
+ALTER TABLE users DROP COLUMN IF EXISTS version;
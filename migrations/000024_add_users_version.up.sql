@@ -0,0 +1,22 @@
+-- migrations/000024_add_users_version.up.sql
+
+-- Version of a user's profile, sent as the ETag of /api/v1/users/:id and
+-- checked against If-Match to refuse updates based on a stale copy. The
+-- trigger bumps it on every profile change, including those made through
+-- Hasura, but not on changes to columns the profile doesn't show, such as
+-- the password or quiet hours.
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_user_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_bump_version
+    BEFORE UPDATE OF username, email, full_name, role, enterprise_id, locale, is_active, deleted_at, purged_at
+    ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_user_version();
@@ -0,0 +1,18 @@
+-- migrations/000025_create_idempotency_keys.up.sql
+
+-- Responses to requests sent with an Idempotency-Key header, replayed when
+-- the client retries (see internal/idempotency). A row without status_code
+-- is a request still in flight; its expires_at is when the claim times out
+-- and the key may be claimed again. The worker prunes expired rows.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope           VARCHAR(100)    NOT NULL,
+    idempotency_key VARCHAR(255)    NOT NULL,
+    fingerprint     VARCHAR(64)     NOT NULL,
+    status_code     INTEGER,
+    response        JSONB,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ     NOT NULL,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
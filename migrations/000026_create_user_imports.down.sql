@@ -0,0 +1,3 @@
+-- migrations/000026_create_user_imports.down.sql
+
+DROP TABLE IF EXISTS user_imports;
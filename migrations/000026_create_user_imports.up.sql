@@ -0,0 +1,28 @@
+-- migrations/000026_create_user_imports.up.sql
+
+-- Bulk user imports too large to run within a request are stored here and
+-- run by the worker (jobs.UserImportArgs). rows holds the parsed file until
+-- the import has run; results keep each row's outcome without personal data.
+CREATE TABLE IF NOT EXISTS user_imports (
+    id              BIGSERIAL       PRIMARY KEY,
+    enterprise_id   BIGINT          REFERENCES enterprises(id) ON DELETE CASCADE,
+    requested_by    BIGINT          REFERENCES users(id) ON DELETE SET NULL,
+    mode            VARCHAR(20)     NOT NULL CHECK (mode IN ('atomic', 'best_effort')),
+    dry_run         BOOLEAN         NOT NULL DEFAULT false,
+    invite          BOOLEAN         NOT NULL DEFAULT true,
+    status          VARCHAR(20)     NOT NULL DEFAULT 'pending'
+                                    CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    rows            JSONB,
+    results         JSONB           NOT NULL DEFAULT '[]',
+    error           TEXT,
+    completed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_imports_enterprise_id ON user_imports (enterprise_id);
+
+CREATE TRIGGER set_user_imports_updated_at
+    BEFORE UPDATE ON user_imports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- migrations/000027_create_invitations.down.sql
+
+DROP TABLE IF EXISTS invitations;
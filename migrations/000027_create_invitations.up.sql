@@ -0,0 +1,39 @@
+-- migrations/000027_create_invitations.up.sql
+
+-- Invitations to join by email with a pre-assigned role and enterprise. Only
+-- the SHA-256 of the token is kept; the token itself is only in the emailed
+-- link. user_id is set from the start when the invitation gives credentials
+-- to an existing user (an imported one), otherwise when it is accepted.
+CREATE TABLE IF NOT EXISTS invitations (
+    id              BIGSERIAL       PRIMARY KEY,
+    enterprise_id   BIGINT          REFERENCES enterprises(id) ON DELETE CASCADE,
+    email           VARCHAR(255)    NOT NULL,
+    role            VARCHAR(50)     NOT NULL CHECK (role IN ('mta', 'eta', 'caregiver', 'family', 'robot')),
+    full_name       VARCHAR(100)    NOT NULL DEFAULT '',
+    locale          VARCHAR(10),
+    user_id         BIGINT          REFERENCES users(id) ON DELETE CASCADE,
+    invited_by      BIGINT          REFERENCES users(id) ON DELETE SET NULL,
+    token_hash      BYTEA           NOT NULL UNIQUE,
+    expires_at      TIMESTAMPTZ     NOT NULL,
+    sent_at         TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    accepted_at     TIMESTAMPTZ,
+    revoked_at      TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+-- At most one open (neither accepted nor revoked) invitation per address.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_open_email
+    ON invitations (lower(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_invitations_enterprise_id ON invitations (enterprise_id);
+
+CREATE TRIGGER set_invitations_updated_at
+    BEFORE UPDATE ON invitations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE invitations ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON invitations TO app_tenant
+    USING (enterprise_id = app_enterprise_id());
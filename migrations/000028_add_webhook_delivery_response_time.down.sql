@@ -0,0 +1,3 @@
+-- migrations/000028_add_webhook_delivery_response_time.down.sql
+
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_time_ms;
@@ -0,0 +1,14 @@
+-- migrations/000028_add_webhook_delivery_response_time.up.sql
+
+-- Deliveries keep the receiver's status code and response time only; the
+-- response body and connection details are not stored, since the
+-- subscription's owner reads them back.
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS response_time_ms INT;
+
+UPDATE webhook_deliveries
+SET last_error = 'receiver responded ' || response_code
+WHERE response_code IS NOT NULL AND last_error IS NOT NULL;
+
+UPDATE webhook_deliveries
+SET last_error = 'could not connect to receiver'
+WHERE response_code IS NULL AND last_error IS NOT NULL AND last_error <> 'subscription is disabled';
@@ -0,0 +1,100 @@
+-- migrations/000029_deliver_webhooks_from_outbox.down.sql
+
+-- Restore the 000016 triggers and the 000019 fan-out from the database.
+CREATE OR REPLACE FUNCTION publish_robot_status_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status IS DISTINCT FROM OLD.status THEN
+        PERFORM publish_realtime_event(
+            'robot.status_changed',
+            NEW.enterprise_id,
+            NEW.assigned_resident_id,
+            jsonb_build_object(
+                'robot_id',        NEW.id,
+                'serial_number',   NEW.serial_number,
+                'status',          NEW.status,
+                'previous_status', OLD.status
+            )
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION publish_session_started_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_enterprise_id BIGINT;
+BEGIN
+    SELECT enterprise_id INTO v_enterprise_id FROM residents WHERE id = NEW.resident_id;
+
+    PERFORM publish_realtime_event(
+        'session.started',
+        v_enterprise_id,
+        NEW.resident_id,
+        jsonb_build_object(
+            'session_id',   NEW.id,
+            'robot_id',     NEW.robot_id,
+            'session_type', NEW.session_type
+        )
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS append_outbox_event(TEXT, TEXT, BIGINT, BIGINT, JSONB);
+
+CREATE OR REPLACE FUNCTION enqueue_webhook_event(
+    event_type      TEXT,
+    p_enterprise_id BIGINT,
+    event_data      JSONB
+)
+RETURNS VOID AS $$
+DECLARE
+    v_event_id UUID := gen_random_uuid();
+BEGIN
+    IF p_enterprise_id IS NULL THEN
+        RETURN;
+    END IF;
+
+    WITH deliveries AS (
+        INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+        SELECT s.id, v_event_id, event_type, jsonb_build_object(
+                   'id',            v_event_id,
+                   'type',          event_type,
+                   'enterprise_id', p_enterprise_id,
+                   'created_at',    NOW(),
+                   'data',          event_data)
+        FROM webhook_subscriptions s
+        WHERE s.enterprise_id = p_enterprise_id
+          AND s.is_active
+          AND event_type = ANY (s.event_types)
+        RETURNING id
+    )
+    INSERT INTO jobs (queue, kind, payload, max_attempts, unique_key)
+    SELECT 'webhooks', 'webhook.deliver', jsonb_build_object('delivery_id', id), 16, 'webhook:' || id
+    FROM deliveries;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+CREATE OR REPLACE FUNCTION publish_realtime_event(
+    event_type      TEXT,
+    p_enterprise_id BIGINT,
+    p_resident_id   BIGINT,
+    event_data      JSONB
+)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM pg_notify('realtime_events', json_build_object(
+        'id',            nextval('realtime_event_id_seq'),
+        'type',          event_type,
+        'enterprise_id', p_enterprise_id,
+        'resident_id',   p_resident_id,
+        'data',          event_data,
+        'created_at',    NOW()
+    )::TEXT);
+
+    PERFORM enqueue_webhook_event(event_type, p_enterprise_id,
+        jsonb_build_object('resident_id', p_resident_id) || event_data);
+END;
+$$ LANGUAGE plpgsql;
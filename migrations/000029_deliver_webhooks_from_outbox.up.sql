@@ -0,0 +1,83 @@
+-- migrations/000029_deliver_webhooks_from_outbox.up.sql
+
+-- Webhooks are delivered by an outbox subscriber (see internal/worker,
+-- WebhookWorker.Fanout) instead of enqueue_webhook_event(), so integrators
+-- get each domain event exactly once. Incident events already reach the
+-- outbox from the services; robot status changes and session starts come
+-- from devices and Hasura as well as the API, so their triggers record them.
+CREATE OR REPLACE FUNCTION append_outbox_event(
+    event_type      TEXT,
+    aggregate_type  TEXT,
+    aggregate_id    BIGINT,
+    p_enterprise_id BIGINT,
+    payload         JSONB
+)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, enterprise_id, payload)
+    VALUES (event_type, aggregate_type, aggregate_id, p_enterprise_id, payload);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Back to the 000016 version, which only notifies dashboards.
+CREATE OR REPLACE FUNCTION publish_realtime_event(
+    event_type      TEXT,
+    p_enterprise_id BIGINT,
+    p_resident_id   BIGINT,
+    event_data      JSONB
+)
+RETURNS VOID AS $$
+BEGIN
+    PERFORM pg_notify('realtime_events', json_build_object(
+        'id',            nextval('realtime_event_id_seq'),
+        'type',          event_type,
+        'enterprise_id', p_enterprise_id,
+        'resident_id',   p_resident_id,
+        'data',          event_data,
+        'created_at',    NOW()
+    )::TEXT);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS enqueue_webhook_event(TEXT, BIGINT, JSONB);
+
+-- The payloads match domain.RobotEventPayload and domain.SessionEventPayload.
+CREATE OR REPLACE FUNCTION publish_robot_status_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_data JSONB;
+BEGIN
+    IF NEW.status IS DISTINCT FROM OLD.status THEN
+        v_data := jsonb_build_object(
+            'robot_id',        NEW.id,
+            'serial_number',   NEW.serial_number,
+            'status',          NEW.status,
+            'previous_status', OLD.status
+        );
+        PERFORM publish_realtime_event('robot.status_changed', NEW.enterprise_id, NEW.assigned_resident_id, v_data);
+        PERFORM append_outbox_event('robot.status_changed', 'robot', NEW.id, NEW.enterprise_id,
+            v_data || jsonb_build_object('resident_id', NEW.assigned_resident_id));
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION publish_session_started_event()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_enterprise_id BIGINT;
+    v_data          JSONB;
+BEGIN
+    SELECT enterprise_id INTO v_enterprise_id FROM residents WHERE id = NEW.resident_id;
+
+    v_data := jsonb_build_object(
+        'session_id',   NEW.id,
+        'robot_id',     NEW.robot_id,
+        'session_type', NEW.session_type
+    );
+    PERFORM publish_realtime_event('session.started', v_enterprise_id, NEW.resident_id, v_data);
+    PERFORM append_outbox_event('session.started', 'session', NEW.id, v_enterprise_id,
+        v_data || jsonb_build_object('resident_id', NEW.resident_id));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
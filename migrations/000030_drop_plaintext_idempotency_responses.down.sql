@@ -0,0 +1,4 @@
+-- migrations/000030_drop_plaintext_idempotency_responses.down.sql
+
+-- Encrypted responses can't be read without the API's key.
+DELETE FROM idempotency_keys WHERE response IS NOT NULL;
@@ -0,0 +1,7 @@
+-- migrations/000030_drop_plaintext_idempotency_responses.up.sql
+
+-- Stored response bodies are now encrypted by the API (see
+-- idempotency.SealedStore). Responses stored before were kept in clear,
+-- including the tokens returned by /auth/register, and can't be read any
+-- more; drop them. Retries of those requests run again.
+DELETE FROM idempotency_keys WHERE response IS NOT NULL;
@@ -0,0 +1,4 @@
+-- migrations/000031_enable_user_imports_row_level_security.down.sql
+
+DROP POLICY IF EXISTS tenant_isolation ON user_imports;
+ALTER TABLE user_imports DISABLE ROW LEVEL SECURITY;
@@ -0,0 +1,8 @@
+-- migrations/000031_enable_user_imports_row_level_security.up.sql
+
+-- user_imports holds the parsed rows of an import, personal data included,
+-- and was created without the tenant policy of the other enterprise tables
+-- (see migration 000018). Imports without an enterprise are mta's only.
+ALTER TABLE user_imports ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON user_imports TO app_tenant
+    USING (enterprise_id = app_enterprise_id());
@@ -0,0 +1,27 @@
+-- migrations/000032_restrict_tenant_system_tables.down.sql
+
+DROP FUNCTION IF EXISTS append_outbox_event(TEXT, TEXT, BIGINT, BIGINT, JSONB, TIMESTAMPTZ);
+
+CREATE OR REPLACE FUNCTION append_outbox_event(
+    event_type      TEXT,
+    aggregate_type  TEXT,
+    aggregate_id    BIGINT,
+    p_enterprise_id BIGINT,
+    payload         JSONB
+)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, enterprise_id, payload)
+    VALUES (event_type, aggregate_type, aggregate_id, p_enterprise_id, payload);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS enqueue_job(TEXT, TEXT, JSONB, INT, TIMESTAMPTZ, TEXT);
+
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO app_tenant;
+
+GRANT SELECT, INSERT, UPDATE, DELETE
+    ON jobs, outbox_events, outbox_deliveries, idempotency_keys, hasura_processed_events
+    TO app_tenant;
+GRANT USAGE, SELECT ON SEQUENCE jobs_id_seq, outbox_events_id_seq TO app_tenant;
@@ -0,0 +1,62 @@
+-- migrations/000032_restrict_tenant_system_tables.up.sql
+
+-- Migration 000018 granted app_tenant every table, present and future,
+-- including the queue, outbox and deduplication tables, which have no
+-- tenant to isolate. Tenant transactions only ever append jobs and outbox
+-- events; they now do so through the functions below, which run as the
+-- owner. Everything else about these tables is the worker's and is reached
+-- through the owner's connections only.
+REVOKE ALL ON jobs, outbox_events, outbox_deliveries, idempotency_keys, hasura_processed_events
+    FROM app_tenant;
+REVOKE ALL ON SEQUENCE jobs_id_seq, outbox_events_id_seq FROM app_tenant;
+
+-- New tables are no longer granted to app_tenant by default: a migration
+-- grants each one together with its row-level security policy, and
+-- cmd/schemacheck reports a table granted without one.
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM app_tenant;
+
+-- Returns the new job's ID, or NULL when a live job with the same unique
+-- key is already queued (see internal/jobs, Client.EnqueueTx).
+CREATE OR REPLACE FUNCTION enqueue_job(
+    p_queue         TEXT,
+    p_kind          TEXT,
+    p_payload       JSONB,
+    p_max_attempts  INT,
+    p_run_at        TIMESTAMPTZ,
+    p_unique_key    TEXT
+)
+RETURNS BIGINT AS $$
+DECLARE
+    v_id BIGINT;
+BEGIN
+    INSERT INTO jobs (queue, kind, payload, max_attempts, run_at, unique_key)
+    VALUES (p_queue, p_kind, p_payload, p_max_attempts, p_run_at, p_unique_key)
+    ON CONFLICT (unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'running') DO NOTHING
+    RETURNING id INTO v_id;
+    RETURN v_id;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+-- Replaces the 000029 version, which returned nothing and ran as the
+-- caller, so that the services can append through it too.
+DROP FUNCTION IF EXISTS append_outbox_event(TEXT, TEXT, BIGINT, BIGINT, JSONB);
+
+CREATE OR REPLACE FUNCTION append_outbox_event(
+    event_type      TEXT,
+    aggregate_type  TEXT,
+    aggregate_id    BIGINT,
+    p_enterprise_id BIGINT,
+    payload         JSONB,
+    p_occurred_at   TIMESTAMPTZ DEFAULT NOW()
+)
+RETURNS BIGINT AS $$
+DECLARE
+    v_id BIGINT;
+BEGIN
+    INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, enterprise_id, payload, occurred_at)
+    VALUES (event_type, aggregate_type, aggregate_id, p_enterprise_id, payload, p_occurred_at)
+    RETURNING id INTO v_id;
+    RETURN v_id;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
@@ -0,0 +1,9 @@
+-- migrations/000033_users_unique_among_live.down.sql
+
+-- Fails while a deleted user shares a username or email with another user;
+-- purge or rename one of them first.
+DROP INDEX IF EXISTS users_email_key;
+DROP INDEX IF EXISTS users_username_key;
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
@@ -0,0 +1,11 @@
+-- migrations/000033_users_unique_among_live.up.sql
+
+-- A soft-deleted user keeps their username and email until purged, so only
+-- live users need unique ones: the address can be reused for a new account,
+-- and restoring the old one then fails with a conflict. The indexes keep the
+-- constraint names the API maps to error codes.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_key ON users (username) WHERE deleted_at IS NULL;
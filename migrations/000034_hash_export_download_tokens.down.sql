@@ -0,0 +1,6 @@
+-- migrations/000034_hash_export_download_tokens.down.sql
+
+-- Hashes cannot be turned back into tokens: outstanding links stop working
+-- and are issued again on the next status request.
+ALTER TABLE data_exports ADD COLUMN IF NOT EXISTS download_token VARCHAR(64) UNIQUE;
+ALTER TABLE data_exports DROP COLUMN IF EXISTS download_token_hash;
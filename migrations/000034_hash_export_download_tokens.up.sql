@@ -0,0 +1,11 @@
+-- migrations/000034_hash_export_download_tokens.up.sql
+
+-- Download tokens are stored as their SHA-256 hash, like invitation tokens,
+-- so a leaked data_exports row does not leak a working link. Outstanding
+-- links keep working.
+ALTER TABLE data_exports ADD COLUMN IF NOT EXISTS download_token_hash BYTEA UNIQUE;
+
+UPDATE data_exports SET download_token_hash = sha256(convert_to(download_token, 'UTF8'))
+WHERE download_token IS NOT NULL;
+
+ALTER TABLE data_exports DROP COLUMN IF EXISTS download_token;
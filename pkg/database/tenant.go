@@ -0,0 +1,184 @@
+// pkg/database/tenant.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Database roles assumed by tenant-scoped transactions (migration 000018).
+const (
+	RoleTenant = "app_tenant" // subject to row-level security
+	RoleBypass = "app_bypass" // BYPASSRLS
+)
+
+// Tenant identifies the user a transaction runs on behalf of. Row-level
+// security policies read it from the app.user_id, app.enterprise_id and
+// app.role settings.
+type Tenant struct {
+	UserID       int64
+	EnterpriseID int64 // 0 when the user has no enterprise
+	Role         string
+	// Bypass runs the transaction as RoleBypass, e.g. for platform admins.
+	Bypass bool
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose transactions are scoped to t.
+func WithTenant(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// TenantFromContext returns the tenant carried by ctx, if any.
+func TenantFromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(Tenant)
+	return t, ok
+}
+
+// applyTenant switches tx to the tenant's role and settings. Everything is
+// transaction-local, so the connection is clean again when it returns to
+// the pool.
+func applyTenant(ctx context.Context, tx pgx.Tx, t Tenant) error {
+	role := RoleTenant
+	if t.Bypass {
+		role = RoleBypass
+	}
+	enterpriseID := ""
+	if t.EnterpriseID != 0 {
+		enterpriseID = strconv.FormatInt(t.EnterpriseID, 10)
+	}
+
+	_, err := tx.Exec(ctx,
+		`SELECT set_config('role', $1, true),
+		        set_config('app.user_id', $2, true),
+		        set_config('app.enterprise_id', $3, true),
+		        set_config('app.role', $4, true)`,
+		role, strconv.FormatInt(t.UserID, 10), enterpriseID, t.Role)
+	if err != nil {
+		return fmt.Errorf("applying tenant scope: %w", err)
+	}
+	return nil
+}
+
+// tenantPool is the DBTX returned by Conn for a tenant-scoped context with no
+// open transaction. Each statement runs in its own short transaction that
+// carries the tenant's scope.
+type tenantPool struct {
+	pool   *pgxpool.Pool
+	tenant Tenant
+}
+
+// Compile-time interface check.
+var _ DBTX = (*tenantPool)(nil)
+
+func (p *tenantPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTenant(ctx, tx, p.tenant); err != nil {
+		tx.Rollback(ctx) //nolint:errcheck // the scope error is returned
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (p *tenantPool) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	tx, err := p.Begin(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return tag, err
+	}
+	return tag, tx.Commit(ctx)
+}
+
+func (p *tenantPool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	tx, err := p.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		tx.Rollback(ctx) //nolint:errcheck // the query error is returned
+		return nil, err
+	}
+	return &tenantRows{Rows: rows, ctx: ctx, tx: tx}, nil
+}
+
+func (p *tenantPool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return &tenantRow{pool: p, ctx: ctx, sql: sql, args: args}
+}
+
+// tenantRows ends its transaction once the rows are exhausted or closed. A
+// failed commit is reported by Err.
+type tenantRows struct {
+	pgx.Rows
+	ctx       context.Context
+	tx        pgx.Tx
+	done      bool
+	commitErr error
+}
+
+func (r *tenantRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.finish()
+	return false
+}
+
+func (r *tenantRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+	return r.commitErr
+}
+
+func (r *tenantRows) Close() {
+	r.finish()
+}
+
+func (r *tenantRows) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.Rows.Close()
+	if r.Rows.Err() != nil {
+		r.tx.Rollback(r.ctx) //nolint:errcheck // the rows error is reported by Err
+		return
+	}
+	r.commitErr = r.tx.Commit(r.ctx)
+}
+
+// tenantRow runs its query when scanned, like the row from pgx.
+type tenantRow struct {
+	pool *tenantPool
+	ctx  context.Context
+	sql  string
+	args []interface{}
+}
+
+func (r *tenantRow) Scan(dest ...interface{}) error {
+	tx, err := r.pool.Begin(r.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(r.ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	if err := tx.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...); err != nil {
+		return err
+	}
+	return tx.Commit(r.ctx)
+}
@@ -0,0 +1,153 @@
+// pkg/database/tx.go
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is the query interface shared by *pgxpool.Pool and pgx.Tx.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Compile-time interface checks.
+var (
+	_ DBTX = (*pgxpool.Pool)(nil)
+	_ DBTX = (pgx.Tx)(nil)
+)
+
+type txKey struct{}
+
+// TxFromContext returns the transaction carried by ctx, if any.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// Conn returns the transaction carried by ctx, or pool when there is none.
+// Repositories call it for every query so they join a transaction started
+// by a TxManager without knowing about it. Without a transaction but with a
+// Tenant in ctx, each statement runs in its own tenant-scoped transaction.
+func Conn(ctx context.Context, pool *pgxpool.Pool) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	if t, ok := TenantFromContext(ctx); ok {
+		return &tenantPool{pool: pool, tenant: t}
+	}
+	return pool
+}
+
+// Retry policy for transactions aborted by a serialization failure or a
+// deadlock, which Postgres expects the client to simply run again.
+const (
+	defaultTxMaxRetries = 3
+	txRetryBaseDelay    = 20 * time.Millisecond
+)
+
+// SQLSTATE codes of errors that are resolved by retrying the transaction.
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// IsRetryable reports whether err is a serialization failure or deadlock.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+}
+
+// TxManager runs units of work in a transaction carried by the context.
+type TxManager struct {
+	pool       *pgxpool.Pool
+	maxRetries int
+}
+
+// NewTxManager creates a TxManager.
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{pool: pool, maxRetries: defaultTxMaxRetries}
+}
+
+// WithinTx runs fn in a read-committed transaction; see WithinTxOptions.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithinTxOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// WithinTxOptions runs fn in a transaction and commits it if fn returns nil.
+// The transaction is scoped to the Tenant carried by ctx, if any.
+//
+// When ctx already carries a transaction, fn runs in a savepoint of it
+// instead: an error rolls back only fn's work, opts are ignored and the
+// outermost caller decides whether to commit.
+//
+// The outermost transaction is run again from the start, up to a few times,
+// when it fails with a serialization failure or deadlock. fn must therefore
+// not have side effects outside the transaction.
+func (m *TxManager) WithinTxOptions(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if tx, ok := TxFromContext(ctx); ok {
+		return runTx(ctx, tx.Begin, fn)
+	}
+
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		tx, err := m.pool.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := TenantFromContext(ctx); ok {
+			if err := applyTenant(ctx, tx, t); err != nil {
+				tx.Rollback(ctx) //nolint:errcheck // the scope error is returned
+				return nil, err
+			}
+		}
+		return tx, nil
+	}
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, begin, fn)
+		if err == nil || !IsRetryable(err) || attempt >= m.maxRetries {
+			return err
+		}
+
+		// Jittered exponential backoff spreads out the retrying transactions.
+		delay := txRetryBaseDelay << attempt
+		delay += time.Duration(rand.Int64N(int64(delay)))
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// runTx begins a transaction (or savepoint), runs fn with it in the context
+// and commits (or releases) it.
+func runTx(ctx context.Context, begin func(context.Context) (pgx.Tx, error), fn func(ctx context.Context) error) error {
+	tx, err := begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // rollback after commit is a no-op
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
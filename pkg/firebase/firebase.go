@@ -3,18 +3,22 @@ package firebase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	firebase "firebase.google.com/go/v4"
 	fbauth "firebase.google.com/go/v4/auth"
+	"firebase.google.com/go/v4/messaging"
 	"google.golang.org/api/option"
 )
 
-// Client wraps the Firebase Auth client for token verification.
+// Client wraps the Firebase Auth client for token verification and the
+// Cloud Messaging client for push notifications.
 type Client struct {
-	auth   *fbauth.Client
-	logger *slog.Logger
+	auth      *fbauth.Client
+	messaging *messaging.Client
+	logger    *slog.Logger
 }
 
 // Config holds Firebase initialization settings.
@@ -42,9 +46,14 @@ func NewClient(ctx context.Context, cfg Config, logger *slog.Logger) (*Client, e
 		return nil, fmt.Errorf("initializing firebase auth client: %w", err)
 	}
 
+	messagingClient, err := app.Messaging(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initializing firebase messaging client: %w", err)
+	}
+
 	logger.Info("Firebase Auth client initialized", slog.String("project_id", cfg.ProjectID))
 
-	return &Client{auth: authClient, logger: logger}, nil
+	return &Client{auth: authClient, messaging: messagingClient, logger: logger}, nil
 }
 
 // VerifiedUser holds the user info extracted from a verified Firebase ID token.
@@ -70,3 +79,32 @@ func (c *Client) VerifyIDToken(ctx context.Context, idToken string) (*VerifiedUs
 		DisplayName: name,
 	}, nil
 }
+
+// ErrTokenUnregistered is returned by SendPush when the device token is no
+// longer valid and should be forgotten.
+var ErrTokenUnregistered = errors.New("firebase: registration token is not registered")
+
+// PushMessage is a notification addressed to one device token.
+type PushMessage struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// SendPush delivers msg through Firebase Cloud Messaging and returns the
+// FCM message ID.
+func (c *Client) SendPush(ctx context.Context, msg PushMessage) (string, error) {
+	id, err := c.messaging.Send(ctx, &messaging.Message{
+		Token:        msg.Token,
+		Notification: &messaging.Notification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+	})
+	if err != nil {
+		if messaging.IsUnregistered(err) || messaging.IsSenderIDMismatch(err) {
+			return "", fmt.Errorf("%w: %v", ErrTokenUnregistered, err)
+		}
+		return "", fmt.Errorf("sending push message: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,184 @@
+// pkg/pagination/pagination.go
+//
+// Package pagination implements keyset pagination for list endpoints. A
+// page is requested with a sort order and an opaque cursor naming the last
+// row of the previous page; the next page is the rows after it in that
+// order, found by comparing the sort column and a unique tiebreaker rather
+// than skipping an OFFSET. Pages stay stable while rows are inserted, and a
+// deep page costs no more than the first.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSort   = errors.New("pagination: invalid sort")
+	ErrInvalidCursor = errors.New("pagination: invalid cursor")
+)
+
+// Sort is an order on one field. Its text form is the field name, prefixed
+// with "-" when descending: "-created_at", "username".
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses s as a Sort on one of fields.
+func ParseSort(s string, fields []string) (Sort, error) {
+	sort := Sort{Field: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+	if !slices.Contains(fields, sort.Field) {
+		return Sort{}, fmt.Errorf("%w: %q", ErrInvalidSort, s)
+	}
+	return sort, nil
+}
+
+// SortValues returns the text forms of every Sort on fields, ascending then
+// descending for each field.
+func SortValues(fields []string) []string {
+	values := make([]string, 0, 2*len(fields))
+	for _, f := range fields {
+		values = append(values, f, "-"+f)
+	}
+	return values
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Cursor is the position a page starts after: the sort value and the ID of
+// the last row of the previous page, and the sort they were taken in.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// NewCursor returns the cursor after a row with the given sort value and ID.
+// Times are kept to the nanosecond in UTC; other values in their default
+// format.
+func NewCursor(sort Sort, value any, id int64) Cursor {
+	var v string
+	switch value := value.(type) {
+	case time.Time:
+		v = value.UTC().Format(time.RFC3339Nano)
+	case string:
+		v = value
+	case int64:
+		v = strconv.FormatInt(value, 10)
+	default:
+		v = fmt.Sprint(value)
+	}
+	return Cursor{Sort: sort.String(), Value: v, ID: id}
+}
+
+// Encode returns c as an opaque, URL-safe token.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c) //nolint:errcheck // strings and an int always marshal
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a token from Encode. A cursor taken in another sort order
+// than sort is invalid: its value would compare against the wrong column.
+func Decode(token string, sort Sort) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.Sort != sort.String() {
+		return Cursor{}, fmt.Errorf("%w: taken in order %q, not %q", ErrInvalidCursor, c.Sort, sort)
+	}
+	return c, nil
+}
+
+// Column is the SQL of a sort field: an expression and the Postgres type
+// a cursor value is cast to for comparing with it. The expression must not
+// be NULL.
+type Column struct {
+	Expr string
+	Type string
+}
+
+// accepts reports whether value casts to the column's type. Types other than
+// timestamps and integers are taken to be text.
+func (c Column) accepts(value string) bool {
+	if strings.ContainsRune(value, 0) {
+		return false // Postgres text cannot hold NUL
+	}
+	switch c.Type {
+	case "timestamptz":
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	case "bigint", "integer":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// Keyset builds the SQL of a keyset page on Column in Sort, with ID, a
+// unique column, breaking ties.
+type Keyset struct {
+	Sort   Sort
+	Column Column
+	ID     string
+}
+
+// Decode parses a token from Encode for a page of k. Besides the checks of
+// the package's Decode, the cursor's value must be of the column's type: a
+// tampered value would otherwise fail the query rather than the request.
+func (k Keyset) Decode(token string) (Cursor, error) {
+	c, err := Decode(token, k.Sort)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if !k.Column.accepts(c.Value) {
+		return Cursor{}, fmt.Errorf("%w: %q is not a %s", ErrInvalidCursor, c.Value, k.Column.Type)
+	}
+	return c, nil
+}
+
+// After returns the condition selecting rows after c, with arguments
+// numbered from $argIdx. c must come from Decode.
+func (k Keyset) After(c Cursor, argIdx int) (string, []any) {
+	op := ">"
+	if k.Sort.Desc {
+		op = "<"
+	}
+	cond := fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d)", k.Column.Expr, k.ID, op, argIdx, k.Column.Type, argIdx+1)
+	return cond, []any{c.Value, c.ID}
+}
+
+// OrderBy returns the ORDER BY list matching After.
+func (k Keyset) OrderBy() string {
+	dir := "ASC"
+	if k.Sort.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, %s %s", k.Column.Expr, dir, k.ID, dir)
+}
+
+// Trim returns the page of rows fetched with a LIMIT of limit+1, and
+// whether there are more rows after it.
+func Trim[T any](rows []T, limit int) ([]T, bool) {
+	if len(rows) > limit {
+		return rows[:limit], true
+	}
+	return rows, false
+}
@@ -1,2 +1,143 @@
 // pkg/validator/validator.go
+//
+// Package validator checks structs against the rules in their "binding" tags
+// and names failed fields by their JSON path, the names clients know. It
+// wraps go-playground's validator with rules the standard set lacks and can
+// stand in for gin's default binding validator.
 package validator
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	playground "github.com/go-playground/validator/v10"
+)
+
+// TagName is the struct tag holding the rules, the one gin binds with.
+const TagName = "binding"
+
+// FieldError is one failed rule of one field.
+type FieldError = playground.FieldError
+
+// Validator validates structs. Rules must be registered before the first
+// validation; after that a Validator is safe for concurrent use.
+type Validator struct {
+	validate *playground.Validate
+}
+
+// New returns a Validator with the standard rules plus:
+//
+//	phone     an international phone number: "+" and 8 to 15 digits, which
+//	          may be grouped by spaces, dots, dashes or parentheses
+//	timezone  an IANA time zone name such as "Europe/Madrid"
+func New() *Validator {
+	v := &Validator{validate: playground.New()}
+	v.validate.SetTagName(TagName)
+	v.validate.RegisterTagNameFunc(jsonName)
+	v.RegisterRule("phone", IsPhone)
+	v.RegisterRule("timezone", IsTimeZone)
+	return v
+}
+
+// RegisterRule adds a rule for string fields under tag, replacing any rule of
+// that name. Fields of other kinds fail it. It panics if tag is reserved by
+// the tag syntax, which is a programming error.
+func (v *Validator) RegisterRule(tag string, rule func(string) bool) {
+	err := v.validate.RegisterValidation(tag, func(fl playground.FieldLevel) bool {
+		return fl.Field().Kind() == reflect.String && rule(fl.Field().String())
+	})
+	if err != nil {
+		panic("validator: registering " + tag + ": " + err.Error())
+	}
+}
+
+// Struct validates s, a struct or pointer to one. It returns nil or the
+// failed rules as a playground.ValidationErrors; see Fields.
+func (v *Validator) Struct(s any) error {
+	return v.validate.Struct(s)
+}
+
+// ValidateStruct validates obj like gin's default validator does: structs
+// and pointers to them are validated, slices and arrays element by element,
+// and anything else is accepted. With Engine it makes a Validator a gin
+// binding.StructValidator.
+func (v *Validator) ValidateStruct(obj any) error {
+	if obj == nil {
+		return nil
+	}
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return v.ValidateStruct(value.Elem().Interface())
+	case reflect.Struct:
+		return v.Struct(obj)
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Engine returns the underlying go-playground validator.
+func (v *Validator) Engine() any {
+	return v.validate
+}
+
+// Fields returns the failed rules in err, or false if err did not come from
+// a failed rule.
+func Fields(err error) ([]FieldError, bool) {
+	var errs playground.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+	return errs, true
+}
+
+// Path returns the JSON path of fe's field without the name of the
+// validated struct, such as "quiet_hours.start" or "preferences[0].type".
+func Path(fe FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+var phoneDigits = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// IsPhone reports whether s is an international phone number.
+func IsPhone(s string) bool {
+	return phoneDigits.MatchString(strings.NewReplacer(" ", "", ".", "", "-", "", "(", "", ")", "").Replace(s))
+}
+
+// IsTimeZone reports whether s names an IANA time zone. The names
+// time.LoadLocation accepts for UTC and the host's zone ("" and "Local") are
+// not names of a zone.
+func IsTimeZone(s string) bool {
+	if s == "" || s == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(s)
+	return err == nil
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	default:
+		return name
+	}
+}
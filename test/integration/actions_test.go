@@ -0,0 +1,227 @@
+// test/integration/actions_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/handler"
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/middleware"
+	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/internal/hasura"
+)
+
+// stubAuth fails every call with err.
+type stubAuth struct{ err error }
+
+func (s stubAuth) Login(context.Context, auth.LoginRequest) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+func (s stubAuth) Register(context.Context, auth.RegisterRequest) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+func (s stubAuth) RefreshToken(context.Context, auth.RefreshRequest) (*auth.TokenPair, error) {
+	return nil, s.err
+}
+func (s stubAuth) SyncUser(context.Context, auth.SyncUserRequest) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+func (s stubAuth) FirebaseLogin(context.Context, auth.FirebaseLoginRequest) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+func (s stubAuth) AcceptInvitation(context.Context, auth.AcceptInvitationRequest) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+
+func actionsServer(authErr error) (*gin.Engine, *handler.ActionsHandler) {
+	gin.SetMode(gin.TestMode)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := handler.NewActionsHandler(stubAuth{err: authErr}, nil, nil, nil, log)
+	r := gin.New()
+	r.POST("/actions", middleware.HasuraActionAuth("action-secret", log), h.Dispatch)
+	return r, h
+}
+
+func callAction(t *testing.T, r http.Handler, secret, body string) (int, interceptor.ActionError, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/actions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set("X-Hasura-Action-Secret", secret)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var actionErr interceptor.ActionError
+	json.Unmarshal(rec.Body.Bytes(), &actionErr) //nolint:errcheck // success bodies are checked by the caller
+	return rec.Code, actionErr, rec.Body.Bytes()
+}
+
+const loginCall = `{"action": {"name": "login"}, "input": {"email": "a@example.com", "password": "hunter2hunter2"}, "session_variables": {"x-hasura-role": "anonymous"}}`
+
+func TestActionsRequireSecret(t *testing.T) {
+	r, _ := actionsServer(nil)
+	for _, secret := range []string{"", "wrong-secret"} {
+		status, body, _ := callAction(t, r, secret, loginCall)
+		if status != http.StatusUnauthorized || body.Extensions.Code != "access-denied" ||
+			body.Extensions.ErrorCode != string(domain.CodeAuthSecretInvalid) {
+			t.Errorf("secret %q: %d %+v, want 401 access-denied", secret, status, body)
+		}
+	}
+}
+
+func TestHasuraRoutesRejectWithoutSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := handler.NewActionsHandler(stubAuth{}, nil, nil, nil, log)
+	r := gin.New()
+	r.POST("/actions", middleware.HasuraActionAuth("", log), h.Dispatch)
+	r.POST("/events/hasura", middleware.HasuraEventAuth("", log), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, secret := range []string{"", "any-secret"} {
+		status, body, _ := callAction(t, r, secret, `{"action": {"name": "provisionRobot"}, "input": {},
+			"session_variables": {"x-hasura-role": "mta", "x-hasura-user-id": "1"}}`)
+		if status != http.StatusUnauthorized || body.Extensions.ErrorCode != string(domain.CodeAuthSecretInvalid) {
+			t.Errorf("action, secret %q: %d %+v, want 401", secret, status, body)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/events/hasura", strings.NewReader(`{}`))
+		if secret != "" {
+			req.Header.Set("X-Hasura-Event-Secret", secret)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("event, secret %q: %d, want 401", secret, rec.Code)
+		}
+	}
+}
+
+func TestActionErrorContract(t *testing.T) {
+	tests := []struct {
+		name       string
+		authErr    error
+		body       string
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:       "domain error keeps its status and message",
+			authErr:    domain.NewAppError(domain.ErrUnauthorized, "invalid email or password"),
+			body:       loginCall,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "access-denied",
+			wantMsg:    "invalid email or password",
+		},
+		{
+			name:       "database error does not leak",
+			authErr:    domain.NewDatabaseError(errors.New(`relation "users" does not exist`)),
+			body:       loginCall,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "unexpected",
+			wantMsg:    "internal server error",
+		},
+		{
+			name:       "input is validated",
+			body:       `{"action": {"name": "login"}, "input": {"email": "not-an-email"}, "session_variables": {"x-hasura-role": "anonymous"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation-failed",
+		},
+		{
+			name:       "role outside the action's permissions",
+			body:       `{"action": {"name": "provisionRobot"}, "input": {}, "session_variables": {"x-hasura-role": "caregiver", "x-hasura-user-id": "5"}}`,
+			wantStatus: http.StatusForbidden,
+			wantCode:   "permission-denied",
+		},
+		{
+			name:       "business action needs a signed-in user",
+			body:       `{"action": {"name": "assignCaregiver"}, "input": {"caregiver_id": 5, "resident_id": 3}, "session_variables": {"x-hasura-role": "admin"}}`,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "access-denied",
+		},
+		{
+			name:       "unknown action",
+			body:       `{"action": {"name": "launchRobot"}, "input": {}}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "not-found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := actionsServer(tt.authErr)
+			status, body, _ := callAction(t, r, "action-secret", tt.body)
+			if status != tt.wantStatus || body.Extensions.Code != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", status, body.Extensions.Code, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantMsg != "" && body.Message != tt.wantMsg {
+				t.Errorf("message = %q, want %q", body.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestActionSessionSetsIdentity(t *testing.T) {
+	r, h := actionsServer(nil)
+	h.Register("whoami", func(c *gin.Context, _ json.RawMessage) (interface{}, error) {
+		return gin.H{
+			"user_id":       c.GetInt64(middleware.ContextKeyUserID),
+			"role":          c.GetString(middleware.ContextKeyUserRole),
+			"enterprise_id": c.GetInt64(middleware.ContextKeyEnterpriseID),
+		}, nil
+	})
+
+	status, _, raw := callAction(t, r, "action-secret", `{"action": {"name": "whoami"}, "input": {},
+		"session_variables": {"X-Hasura-Role": "eta", "X-Hasura-User-Id": "42", "X-Hasura-Enterprise-Id": "7"}}`)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d: %s", status, raw)
+	}
+	var got struct {
+		UserID       int64  `json:"user_id"`
+		Role         string `json:"role"`
+		EnterpriseID int64  `json:"enterprise_id"`
+	}
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("decoding output: %v", err)
+	}
+	if got.UserID != 42 || got.Role != "eta" || got.EnterpriseID != 7 {
+		t.Errorf("identity = %+v, want 42/eta/7", got)
+	}
+}
+
+func TestActionMetadataIsGenerated(t *testing.T) {
+	_, h := actionsServer(nil)
+	defs := h.Definitions()
+
+	sdl, err := hasura.RenderActionsGraphQL(defs)
+	if err != nil {
+		t.Fatalf("rendering actions.graphql: %v", err)
+	}
+	meta, err := hasura.RenderActionsYAML(defs)
+	if err != nil {
+		t.Fatalf("rendering actions.yaml: %v", err)
+	}
+	for name, want := range map[string][]byte{"actions.graphql": sdl, "actions.yaml": meta} {
+		got, err := os.ReadFile(filepath.Join("..", "..", "hasura", "metadata", name))
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("hasura/metadata/%s is out of date; run make hasura-actions", name)
+		}
+	}
+}
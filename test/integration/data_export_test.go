@@ -0,0 +1,160 @@
+// test/integration/data_export_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/repository"
+	"my-application/internal/service"
+)
+
+// exportUsers has user 1 in enterprise 3, user 2 in enterprise 4 and user
+// 3 without an enterprise.
+type exportUsers struct{ repository.UserRepository }
+
+func (exportUsers) GetByID(_ context.Context, id int64) (*domain.User, error) {
+	enterprises := map[int64]int64{1: 3, 2: 4, 3: 0}
+	enterprise, ok := enterprises[id]
+	if !ok {
+		return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
+	}
+	user := &domain.User{ID: id}
+	if enterprise != 0 {
+		user.EnterpriseID = &enterprise
+	}
+	return user, nil
+}
+
+// memExports stores exports in memory.
+type memExports struct {
+	repository.DataExportRepository
+	exports []domain.DataExport
+}
+
+func (r *memExports) Create(_ context.Context, export *domain.DataExport) error {
+	export.ID = int64(len(r.exports) + 1)
+	r.exports = append(r.exports, *export)
+	return nil
+}
+
+func (r *memExports) GetByID(_ context.Context, id int64) (*domain.DataExport, error) {
+	if id < 1 || id > int64(len(r.exports)) {
+		return nil, domain.NewAppError(domain.ErrNotFound, "data export not found")
+	}
+	export := r.exports[id-1]
+	return &export, nil
+}
+
+func (r *memExports) SetTokenHash(_ context.Context, id int64, hash []byte) error {
+	r.exports[id-1].DownloadTokenHash = hash
+	return nil
+}
+
+func TestDataExportDownloadLinks(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	exports, repos := &memExports{}, &importRepos{}
+	svc := service.NewDataExportService(exportUsers{}, exports, repos.audit, repos, repos, log)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+	exports.exports = []domain.DataExport{
+		{ID: 1, UserID: 1, Status: domain.ExportStatusCompleted, ExpiresAt: &expiresAt},
+		{ID: 2, UserID: 1, Status: domain.ExportStatusPending},
+	}
+	issue := func(id int64) string {
+		t.Helper()
+		export, err := svc.GetExport(ctx, id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.IssueDownloadLink(ctx, export); err != nil {
+			t.Fatal(err)
+		}
+		return export.DownloadToken
+	}
+
+	// Pending exports have no link to download.
+	if token := issue(2); token != "" || exports.exports[1].DownloadTokenHash != nil {
+		t.Errorf("pending export issued token %q", token)
+	}
+	if _, err := svc.OpenDownload(ctx, 2, ""); domain.ErrorCodeOf(err) != domain.CodeExportLinkInvalid {
+		t.Errorf("download of a pending export: %v", err)
+	}
+
+	// Only the hash of the token is stored, and it opens the download.
+	first := issue(1)
+	if first == "" || !bytes.Equal(exports.exports[0].DownloadTokenHash, domain.ExportTokenHash(first)) {
+		t.Fatalf("token %q stored as %x", first, exports.exports[0].DownloadTokenHash)
+	}
+	if export, err := svc.OpenDownload(ctx, 1, first); err != nil || export.ID != 1 {
+		t.Errorf("download with the issued token: %v", err)
+	}
+	for _, token := range []string{"", "guess", fmt.Sprintf("%x", exports.exports[0].DownloadTokenHash)} {
+		if _, err := svc.OpenDownload(ctx, 1, token); domain.ErrorCodeOf(err) != domain.CodeExportLinkInvalid {
+			t.Errorf("download with token %q: %v", token, err)
+		}
+	}
+
+	// A new link replaces the previous one.
+	second := issue(1)
+	if _, err := svc.OpenDownload(ctx, 1, first); domain.ErrorCodeOf(err) != domain.CodeExportLinkInvalid {
+		t.Errorf("download with a replaced token: %v", err)
+	}
+	if _, err := svc.OpenDownload(ctx, 1, second); err != nil {
+		t.Errorf("download with the new token: %v", err)
+	}
+
+	// Expired exports get no new link, and old ones stop working.
+	expiresAt = time.Now().Add(-time.Minute)
+	if token := issue(1); token != "" {
+		t.Errorf("expired export issued token %q", token)
+	}
+	if _, err := svc.OpenDownload(ctx, 1, second); domain.ErrorCodeOf(err) != domain.CodeExportLinkInvalid {
+		t.Errorf("download of an expired export: %v", err)
+	}
+}
+
+func TestDataExportEnterpriseScope(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	exports, repos := &memExports{}, &importRepos{}
+	svc := service.NewDataExportService(exportUsers{}, exports, repos.audit, repos, repos, log)
+	ctx := context.Background()
+	three, none := int64(3), int64(0)
+
+	// An eta of enterprise 3 exports its users only.
+	if _, err := svc.RequestExport(ctx, 2, 9, &three); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("export of another enterprise's user: err = %v, want NOT_FOUND", err)
+	}
+	if _, err := svc.RequestExport(ctx, 3, 9, &three); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("export of a user without enterprise: err = %v, want NOT_FOUND", err)
+	}
+	if len(exports.exports) != 0 || len(repos.jobs) != 0 {
+		t.Fatalf("out of scope requests created %d exports and %d jobs", len(exports.exports), len(repos.jobs))
+	}
+	own, err := svc.RequestExport(ctx, 1, 9, &three)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := svc.RequestExport(ctx, 2, 8, nil) // mta
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.GetExport(ctx, own.ID, &three); err != nil {
+		t.Errorf("GetExport in scope: %v", err)
+	}
+	for _, scope := range []*int64{&three, &none} {
+		if _, err := svc.GetExport(ctx, other.ID, scope); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+			t.Errorf("GetExport of enterprise 4's export in scope %d: err = %v, want NOT_FOUND", *scope, err)
+		}
+	}
+	if _, err := svc.GetExport(ctx, other.ID, nil); err != nil {
+		t.Errorf("GetExport unscoped: %v", err)
+	}
+}
@@ -1,2 +1,205 @@
 // test/integration/database_test.go
 package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository/postgres"
+	"my-application/pkg/database"
+)
+
+// testPool connects to the database named by TEST_DATABASE_URL, which must
+// have every migration applied. The test is skipped when it is not set.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// seedEnterprise creates an enterprise with one resident and one robot as
+// the table owner, and removes them when the test ends.
+func seedEnterprise(t *testing.T, pool *pgxpool.Pool, name string) (enterpriseID, residentID int64) {
+	t.Helper()
+	ctx := context.Background()
+	suffix := fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+
+	err := pool.QueryRow(ctx,
+		`INSERT INTO enterprises (name, contact_email) VALUES ($1, $2) RETURNING id`,
+		"rls-"+suffix, suffix+"@example.com").Scan(&enterpriseID)
+	if err != nil {
+		t.Fatalf("seeding enterprise: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), `DELETE FROM enterprises WHERE id = $1`, enterpriseID); err != nil {
+			t.Errorf("removing enterprise %d: %v", enterpriseID, err)
+		}
+	})
+
+	err = pool.QueryRow(ctx,
+		`INSERT INTO residents (enterprise_id, full_name) VALUES ($1, $2) RETURNING id`,
+		enterpriseID, "Resident "+name).Scan(&residentID)
+	if err != nil {
+		t.Fatalf("seeding resident: %v", err)
+	}
+	if _, err := pool.Exec(ctx,
+		`INSERT INTO robots (serial_number, enterprise_id, assigned_resident_id) VALUES ($1, $2, $3)`,
+		"RLS-"+suffix, enterpriseID, residentID); err != nil {
+		t.Fatalf("seeding robot: %v", err)
+	}
+	return enterpriseID, residentID
+}
+
+func countVisible(t *testing.T, ctx context.Context, pool *pgxpool.Pool, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := database.Conn(ctx, pool).QueryRow(ctx, query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestRowLevelSecurityIsolatesTenants(t *testing.T) {
+	pool := testPool(t)
+	entA, resA := seedEnterprise(t, pool, "a")
+	entB, resB := seedEnterprise(t, pool, "b")
+
+	tenantA := database.WithTenant(context.Background(), database.Tenant{
+		EnterpriseID: entA, Role: "eta",
+	})
+	residents := `SELECT count(*) FROM residents WHERE id = ANY($1)`
+	robots := `SELECT count(*) FROM robots WHERE enterprise_id = ANY($1)`
+	both := []int64{resA, resB}
+	bothEnt := []int64{entA, entB}
+
+	t.Run("statement without transaction", func(t *testing.T) {
+		if n := countVisible(t, tenantA, pool, residents, both); n != 1 {
+			t.Errorf("tenant A sees %d residents, want 1", n)
+		}
+		if n := countVisible(t, tenantA, pool, `SELECT count(*) FROM residents WHERE id = $1`, resB); n != 0 {
+			t.Errorf("tenant A sees tenant B's resident")
+		}
+		if n := countVisible(t, tenantA, pool, robots, bothEnt); n != 1 {
+			t.Errorf("tenant A sees %d robots, want 1", n)
+		}
+	})
+
+	t.Run("within transaction", func(t *testing.T) {
+		tm := database.NewTxManager(pool)
+		err := tm.WithinTx(tenantA, func(ctx context.Context) error {
+			if n := countVisible(t, ctx, pool, residents, both); n != 1 {
+				t.Errorf("tenant A sees %d residents, want 1", n)
+			}
+			if n := countVisible(t, ctx, pool, `SELECT count(*) FROM enterprises WHERE id = $1`, entB); n != 0 {
+				t.Errorf("tenant A sees tenant B's enterprise")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WithinTx: %v", err)
+		}
+	})
+
+	t.Run("writes into another tenant are rejected", func(t *testing.T) {
+		_, err := database.Conn(tenantA, pool).Exec(tenantA,
+			`INSERT INTO residents (enterprise_id, full_name) VALUES ($1, 'Intruder')`, entB)
+		if err == nil {
+			t.Fatal("inserting a resident into tenant B succeeded")
+		}
+		tag, err := database.Conn(tenantA, pool).Exec(tenantA,
+			`UPDATE residents SET full_name = 'Changed' WHERE id = $1`, resB)
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if tag.RowsAffected() != 0 {
+			t.Errorf("tenant A updated %d of tenant B's residents", tag.RowsAffected())
+		}
+	})
+
+	t.Run("user imports", func(t *testing.T) {
+		for _, ent := range bothEnt {
+			if _, err := pool.Exec(context.Background(),
+				`INSERT INTO user_imports (enterprise_id, mode) VALUES ($1, 'atomic')`, ent); err != nil {
+				t.Fatalf("seeding import: %v", err)
+			}
+		}
+		if n := countVisible(t, tenantA, pool, `SELECT count(*) FROM user_imports WHERE enterprise_id = ANY($1)`, bothEnt); n != 1 {
+			t.Errorf("tenant A sees %d imports, want 1", n)
+		}
+	})
+
+	t.Run("system tables", func(t *testing.T) {
+		for _, table := range []string{"jobs", "outbox_events", "outbox_deliveries", "idempotency_keys", "hasura_processed_events"} {
+			var n int
+			var pgErr *pgconn.PgError
+			err := database.Conn(tenantA, pool).QueryRow(tenantA, `SELECT count(*) FROM `+table).Scan(&n)
+			if !errors.As(err, &pgErr) || pgErr.Code != "42501" {
+				t.Errorf("tenant reads %s: %v", table, err)
+			}
+		}
+
+		// Jobs and outbox events are still appended from tenant transactions.
+		client := jobs.NewClient(pool)
+		args := jobs.ExportBuildArgs{ExportID: -entA}
+		id, err := client.Enqueue(tenantA, args, jobs.ExportBuildOptions(args.ExportID)...)
+		if err != nil || id == 0 {
+			t.Fatalf("tenant enqueue: %d, %v", id, err)
+		}
+		t.Cleanup(func() {
+			if _, err := pool.Exec(context.Background(), `DELETE FROM jobs WHERE id = $1`, id); err != nil {
+				t.Errorf("removing job: %v", err)
+			}
+		})
+		if again, err := client.Enqueue(tenantA, args, jobs.ExportBuildOptions(args.ExportID)...); err != nil || again != 0 {
+			t.Errorf("duplicate enqueue: %d, %v", again, err)
+		}
+
+		outbox := postgres.NewOutboxPostgres(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		event := &domain.DomainEvent{Type: domain.EventUserUpdated, AggregateType: "user", AggregateID: 1,
+			EnterpriseID: &entA, Payload: []byte(`{}`), OccurredAt: time.Now()}
+		errRollback := errors.New("rollback")
+		err = database.NewTxManager(pool).WithinTx(tenantA, func(ctx context.Context) error {
+			if err := outbox.Append(ctx, event); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) || event.ID == 0 {
+			t.Errorf("tenant append: ID %d, %v", event.ID, err)
+		}
+	})
+
+	t.Run("no enterprise sees nothing", func(t *testing.T) {
+		ctx := database.WithTenant(context.Background(), database.Tenant{Role: "caregiver"})
+		if n := countVisible(t, ctx, pool, residents, both); n != 0 {
+			t.Errorf("tenant without enterprise sees %d residents, want 0", n)
+		}
+	})
+
+	t.Run("bypass sees every tenant", func(t *testing.T) {
+		ctx := database.WithTenant(context.Background(), database.Tenant{Role: "mta", Bypass: true})
+		if n := countVisible(t, ctx, pool, residents, both); n != 2 {
+			t.Errorf("bypass sees %d residents, want 2", n)
+		}
+	})
+}
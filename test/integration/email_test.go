@@ -0,0 +1,317 @@
+// test/integration/email_test.go
+package integration
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"my-application/internal/email"
+	"my-application/internal/jobs"
+	"my-application/internal/worker"
+)
+
+func resetData(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"Name":      name,
+		"URL":       "https://app.example.com/reset?token=abc",
+		"ExpiresIn": "1 hour",
+	}
+}
+
+func TestEmailRendering(t *testing.T) {
+	r, err := email.NewRenderer("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locales := []struct {
+		locale, subject string
+	}{
+		{"es", "Restablezca su contraseña de SONA"},
+		{"es-MX", "Restablezca su contraseña de SONA"},
+		{"ES_mx", "Restablezca su contraseña de SONA"},
+		{"fr", "Reset your SONA password"},
+		{"", "Reset your SONA password"},
+	}
+	for _, tt := range locales {
+		msg, err := r.Render(email.TemplatePasswordReset, tt.locale, resetData("Ada"))
+		if err != nil {
+			t.Fatalf("%q: %v", tt.locale, err)
+		}
+		if msg.Subject != tt.subject {
+			t.Errorf("%q: subject %q, want %q", tt.locale, msg.Subject, tt.subject)
+		}
+	}
+
+	// The layout adds the localized footer to both bodies, and only the
+	// HTML body escapes data.
+	msg, err := r.Render(email.TemplatePasswordReset, "es", resetData("<Ada & Co>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg.Text, "Hola <Ada & Co>:") || !strings.Contains(msg.Text, "no responda") {
+		t.Errorf("text body:\n%s", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "&lt;Ada &amp; Co&gt;") || strings.Contains(msg.HTML, "<Ada") ||
+		!strings.Contains(msg.HTML, "no responda") {
+		t.Errorf("html body:\n%s", msg.HTML)
+	}
+
+	if _, err := r.Render("nope", "en", nil); !errors.Is(err, email.ErrUnknownTemplate) {
+		t.Errorf("unknown template: %v", err)
+	}
+	data := resetData("Ada")
+	delete(data, "URL")
+	if _, err := r.Render(email.TemplatePasswordReset, "en", data); err == nil {
+		t.Error("rendering without URL succeeded")
+	}
+	if _, err := email.NewRenderer("fr"); err == nil {
+		t.Error("renderer without templates for its default locale")
+	}
+}
+
+// mimeParts returns the decoded parts of a multipart message by content type,
+// in order.
+func mimeParts(t *testing.T, msg *mail.Message) (types []string, bodies map[string]string) {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type %q: %v", msg.Header.Get("Content-Type"), err)
+	}
+	bodies = map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc := part.Header.Get("Content-Transfer-Encoding"); enc != "quoted-printable" {
+			t.Errorf("transfer encoding %q", enc)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct := part.Header.Get("Content-Type")
+		types = append(types, ct)
+		bodies[ct] = string(body)
+	}
+	return types, bodies
+}
+
+func TestEmailMIME(t *testing.T) {
+	ctx := context.Background()
+	sender := email.NewMemorySender("SONA <sona@example.com>")
+
+	long := strings.Repeat("é", 100)
+	err := sender.Send(ctx, &email.Message{
+		ID:      "job-7",
+		To:      []string{"Ada Lovelace <ada@example.com>", "alan@example.com"},
+		Subject: "Contraseña de SONA",
+		Text:    "Hola " + long,
+		HTML:    "<p>Hola " + long + "</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := sender.Sent()
+	if len(sent) != 1 || sent[0].MessageID != "<job-7@example.com>" {
+		t.Fatalf("sent %+v", sent)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(sent[0].Raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Contraseña de SONA" {
+		t.Errorf("subject %q: %v", subject, err)
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 2 || to[0].Name != "Ada Lovelace" || to[1].Address != "alan@example.com" {
+		t.Errorf("to %v: %v", to, err)
+	}
+	if from := msg.Header.Get("From"); from != `"SONA" <sona@example.com>` {
+		t.Errorf("from %q", from)
+	}
+	if msg.Header.Get("Message-ID") != "<job-7@example.com>" || msg.Header.Get("MIME-Version") != "1.0" {
+		t.Errorf("headers %v", msg.Header)
+	}
+	for _, line := range strings.Split(string(sent[0].Raw), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("line of %d bytes", len(line))
+		}
+	}
+
+	// Plain text comes first so clients prefer the HTML part.
+	types, bodies := mimeParts(t, msg)
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Fatalf("parts %v", types)
+	}
+	if bodies[types[0]] != "Hola "+long || bodies[types[1]] != "<p>Hola "+long+"</p>" {
+		t.Errorf("bodies %v", bodies)
+	}
+
+	rejected := []struct {
+		name string
+		msg  email.Message
+	}{
+		{"no recipients", email.Message{Subject: "s", Text: "t"}},
+		{"no body", email.Message{To: []string{"ada@example.com"}, Subject: "s"}},
+		{"bad recipient", email.Message{To: []string{"not an address"}, Text: "t"}},
+		{"header injection", email.Message{To: []string{"ada@example.com\r\nBcc: eve@example.com"}, Text: "t"}},
+		{"bad sender", email.Message{From: "nobody", To: []string{"ada@example.com"}, Text: "t"}},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := sender.Send(ctx, &tt.msg); !email.IsPermanent(err) {
+				t.Errorf("err = %v, want a permanent error", err)
+			}
+		})
+	}
+	if n := len(sender.Sent()); n != 1 {
+		t.Errorf("%d messages recorded", n)
+	}
+}
+
+// fakeSMTP serves one SMTP session on a local port, answering each command
+// verb with replies[verb], or a success reply when there is none. The DATA
+// verb answers the end of the message. It returns the port.
+func fakeSMTP(t *testing.T, replies map[string]string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() }) //nolint:errcheck // the test is over
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+		reply := func(verb, ok string) bool {
+			line := ok
+			if custom, found := replies[verb]; found {
+				line = custom
+			}
+			_, err := w.WriteString(line + "\r\n")
+			return err == nil && w.Flush() == nil
+		}
+		reply("", "220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.Fields(line + " x")[0])
+			switch verb {
+			case "EHLO":
+				reply("", "250-localhost\r\n250 AUTH PLAIN")
+			case "AUTH":
+				reply("AUTH", "235 2.7.0 Authenticated")
+			case "MAIL":
+				reply("MAIL", "250 2.1.0 OK")
+			case "RCPT":
+				reply("RCPT", "250 2.1.5 OK")
+			case "DATA":
+				reply("", "354 Go ahead")
+				for {
+					if line, err = r.ReadString('\n'); err != nil || line == ".\r\n" {
+						break
+					}
+				}
+				reply("DATA", "250 2.0.0 Queued")
+			case "QUIT":
+				reply("", "221 Bye")
+				return
+			default:
+				reply("", "250 OK")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestSMTPBounces(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	msg := func() *email.Message {
+		return &email.Message{To: []string{"ada@example.com"}, Subject: "Hello", Text: "Hello"}
+	}
+
+	// Only a 5xx reply about the message itself is a bounce; rejected
+	// credentials or sender are the relay's problem and are retried.
+	tests := []struct {
+		name      string
+		replies   map[string]string
+		permanent bool
+	}{
+		{"delivered", nil, false},
+		{"auth rejected", map[string]string{"AUTH": "535 5.7.8 Authentication credentials invalid"}, false},
+		{"sender rejected", map[string]string{"MAIL": "553 5.7.1 Sender not allowed"}, false},
+		{"recipient unknown", map[string]string{"RCPT": "550 5.1.1 <ada@example.com>: Recipient address rejected"}, true},
+		{"recipient deferred", map[string]string{"RCPT": "450 4.2.0 <ada@example.com>: Greylisted"}, false},
+		{"message rejected", map[string]string{"DATA": "554 5.7.1 Message rejected as spam"}, true},
+		{"message deferred", map[string]string{"DATA": "451 4.3.0 Try again later"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := email.NewSMTPSender(email.SMTPConfig{
+				Host: "127.0.0.1", Port: fakeSMTP(t, tt.replies), Username: "sona", Password: "secret",
+				TLSMode: email.TLSModeNone, Timeout: 5 * time.Second,
+			}, "sona@example.com", log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = sender.Send(context.Background(), msg())
+			if (err == nil) != (tt.replies == nil) || email.IsPermanent(err) != tt.permanent {
+				t.Errorf("err = %v, permanent %v; want permanent %v", err, email.IsPermanent(err), tt.permanent)
+			}
+		})
+	}
+}
+
+// rejectingSender fails every message with err.
+type rejectingSender struct{ err error }
+
+func (s rejectingSender) Send(context.Context, *email.Message) error { return s.err }
+
+func TestEmailErrorsAreRedacted(t *testing.T) {
+	reply := errors.New("smtp RCPT TO: 550 5.1.1 <ada.lovelace+sona@mail.example.com>: user unknown")
+	redacted := email.Redact(reply)
+	if got := redacted.Error(); got != "smtp RCPT TO: 550 5.1.1 <[redacted]@mail.example.com>: user unknown" {
+		t.Errorf("redacted: %q", got)
+	}
+	if !errors.Is(redacted, reply) || email.Redact(nil) != nil {
+		t.Error("redaction changed the error chain")
+	}
+
+	// The error the worker returns is what the queue stores as last_error.
+	r, err := email.NewRenderer("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := worker.NewEmailWorker(r, rejectingSender{reply}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	err = w.Send(context.Background(), &jobs.Job{ID: 1}, jobs.EmailSendArgs{
+		To: []string{"ada.lovelace+sona@mail.example.com"}, Template: email.TemplatePasswordReset, Locale: "en", Data: resetData("Ada"),
+	})
+	if err == nil || strings.Contains(err.Error(), "lovelace") {
+		t.Errorf("worker error %v", err)
+	}
+}
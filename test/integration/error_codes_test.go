@@ -0,0 +1,161 @@
+// test/integration/error_codes_test.go
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/api/openapi"
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/router"
+	"my-application/internal/auth"
+	"my-application/internal/domain"
+)
+
+var errorCodeFormat = regexp.MustCompile(`^[A-Z]+(_[A-Z]+)*$`)
+
+func TestErrorCodeRegistry(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, info := range domain.ErrorCodes {
+		code := string(info.Code)
+		if !errorCodeFormat.MatchString(code) {
+			t.Errorf("code %q is not UPPER_SNAKE_CASE", code)
+		}
+		if registered[code] {
+			t.Errorf("code %s is registered twice", code)
+		}
+		if info.Status < 400 || info.Description == "" {
+			t.Errorf("code %s: status %d, description %q", code, info.Status, info.Description)
+		}
+		registered[code] = true
+	}
+
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	documented := make(map[string]bool)
+	for _, v := range doc.Components.Schemas["ErrorCode"].Value.Enum {
+		documented[v.(string)] = true //nolint:forcetypeassert // enum of a string schema
+	}
+	for code := range registered {
+		if !documented[code] {
+			t.Errorf("code %s is missing from the ErrorCode enum in api/openapi/openapi.yaml", code)
+		}
+	}
+	for code := range documented {
+		if !registered[code] {
+			t.Errorf("api/openapi/openapi.yaml lists %s, which is not in domain.ErrorCodes", code)
+		}
+	}
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want domain.ErrorCode
+	}{
+		{"specific code", domain.NewAppError(domain.ErrAlreadyExists, "taken").WithCode(domain.CodeUserEmailTaken), domain.CodeUserEmailTaken},
+		{"wrapped", errors.Join(errors.New("ctx"), domain.NewAppError(domain.ErrNotFound, "gone")), domain.CodeNotFound},
+		{"validation details", domain.NewValidationError("invalid", map[string]string{"email": "required"}), domain.CodeValidationFailed},
+		{"invalid input", domain.NewAppError(domain.ErrInvalidInput, "invalid user ID"), domain.CodeInvalidInput},
+		{"forbidden", domain.NewAppError(domain.ErrForbidden, "no"), domain.CodeForbidden},
+		{"database", domain.NewDatabaseError(io.ErrUnexpectedEOF), domain.CodeInternal},
+		{"plain error", io.EOF, domain.CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := domain.ErrorCodeOf(tt.err); got != tt.want {
+			t.Errorf("%s: ErrorCodeOf = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestErrorCodeInEnvelope(t *testing.T) {
+	call := func(r http.Handler, method, target, body string) (int, interceptor.APIResponse) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp interceptor.APIResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp) //nolint:errcheck // asserted through the fields
+		return w.Code, resp
+	}
+
+	r := newRouter(t, router.Config{})
+	if code, resp := call(r, http.MethodGet, "/api/v1/users/1", ""); code != http.StatusUnauthorized ||
+		resp.Code != string(domain.CodeAuthTokenMissing) {
+		t.Errorf("no token: %d %q", code, resp.Code)
+	}
+	if code, resp := call(r, http.MethodGet, "/nope", ""); code != http.StatusNotFound ||
+		resp.Code != string(domain.CodeRouteNotFound) {
+		t.Errorf("unknown route: %d %q", code, resp.Code)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	login := gin.New()
+	login.POST("/login", auth.NewHandler(stubAuth{err: domain.NewAppError(domain.ErrUnauthorized, "invalid email or password").
+		WithCode(domain.CodeAuthInvalidCredentials)}, log).Login)
+	code, resp := call(login, http.MethodPost, "/login", `{"email":"a@example.com","password":"hunter2hunter2"}`)
+	if code != http.StatusUnauthorized || resp.Code != string(domain.CodeAuthInvalidCredentials) || resp.Success {
+		t.Errorf("wrong password: %d %+v", code, resp)
+	}
+
+	login = gin.New()
+	login.POST("/login", auth.NewHandler(stubAuth{err: domain.NewAppError(domain.ErrUnauthorized, "nope")}, log).Login)
+	if code, resp := call(login, http.MethodPost, "/login", `{"email":"a@example.com","password":"hunter2hunter2"}`); code != http.StatusUnauthorized ||
+		resp.Code != string(domain.CodeUnauthorized) {
+		t.Errorf("uncoded error: %d %q, want the generic code", code, resp.Code)
+	}
+}
+
+func TestProblemDetailsNegotiation(t *testing.T) {
+	r := newRouter(t, router.Config{})
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+		req.Header.Set("X-Request-ID", "req-7807")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, accept := range []string{"", "*/*", "application/json", "application/json, application/problem+json"} {
+		if ct := get(accept).Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("Accept %q: Content-Type %q, want the JSON envelope", accept, ct)
+		}
+	}
+
+	w := get("application/problem+json, application/json")
+	if ct := w.Header().Get("Content-Type"); ct != interceptor.MIMEProblemJSON {
+		t.Fatalf("Content-Type = %q, want %s", ct, interceptor.MIMEProblemJSON)
+	}
+	var problem interceptor.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	want := interceptor.Problem{
+		Type:      "about:blank",
+		Title:     "Unauthorized",
+		Status:    http.StatusUnauthorized,
+		Detail:    "missing authorization header",
+		Instance:  "/api/v1/users/1",
+		Code:      string(domain.CodeAuthTokenMissing),
+		RequestID: "req-7807",
+	}
+	if w.Code != http.StatusUnauthorized || problem != want {
+		t.Errorf("got %d %+v, want %+v", w.Code, problem, want)
+	}
+}
@@ -0,0 +1,305 @@
+// test/integration/grpc_test.go
+package integration
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"my-application/api/proto/sonav1"
+	"my-application/internal/api/rpc"
+	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+)
+
+// grpcAuth logs in a single known user and fails everything else.
+type grpcAuth struct{ stubAuth }
+
+func (grpcAuth) Login(_ context.Context, req auth.LoginRequest) (*auth.AuthResponse, error) {
+	if req.Email != "ada@example.com" || req.Password != "correct-horse" {
+		return nil, domain.NewAppError(domain.ErrUnauthorized, "invalid email or password")
+	}
+	return &auth.AuthResponse{
+		User:   auth.UserInfo{ID: 7, Email: req.Email, Role: "caregiver"},
+		Tokens: auth.TokenPair{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Unix(1700000000, 0)},
+	}, nil
+}
+
+// grpcUsers knows user 1; Create fails validation, List fails in the
+// database and Patch on a version conflict.
+type grpcUsers struct{ service.UserService }
+
+func (grpcUsers) GetUser(_ context.Context, id int64) (*domain.User, error) {
+	if id != 1 {
+		return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
+	}
+	return &domain.User{ID: 1, Username: "ada", Role: "eta", IsActive: true}, nil
+}
+
+func (grpcUsers) CreateUser(context.Context, *domain.User) error {
+	return domain.NewValidationError("validation failed", map[string]string{
+		"username": "username is required",
+		"email":    "email is required",
+	})
+}
+
+func (grpcUsers) ListUsers(context.Context, domain.UserFilter) (*domain.UserPage, error) {
+	return nil, domain.NewDatabaseError(io.ErrUnexpectedEOF)
+}
+
+func (grpcUsers) PatchUser(context.Context, int64, domain.UserPatch, int64) (*domain.User, error) {
+	return nil, domain.NewAppError(domain.ErrPreconditionFailed, "user with id 1 has changed since version 1")
+}
+
+// grpcRobots records which robot sent a heartbeat.
+type grpcRobots struct {
+	service.RobotService
+	robotID *int64
+}
+
+func (r grpcRobots) RecordHeartbeat(_ context.Context, robotID int64, status string, _ *string) (*domain.Robot, error) {
+	*r.robotID = robotID
+	return &domain.Robot{ID: robotID, Status: status}, nil
+}
+
+type grpcEnv struct {
+	conn       *grpc.ClientConn
+	jwtManager *auth.JWTManager
+	heartbeat  int64
+}
+
+func newGRPCEnv(t *testing.T) *grpcEnv {
+	t.Helper()
+	env := &grpcEnv{jwtManager: auth.NewJWTManager(auth.JWTConfig{
+		Secret:             "grpc-test-secret",
+		AccessTokenExpiry:  time.Minute,
+		RefreshTokenExpiry: time.Hour,
+		Issuer:             "test",
+	})}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := rpc.NewServer(rpc.Services{
+		Auth:  grpcAuth{},
+		User:  grpcUsers{},
+		Robot: grpcRobots{robotID: &env.heartbeat},
+	}, env.jwtManager, log)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis) //nolint:errcheck // returns when the server stops
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	env.conn = conn
+	return env
+}
+
+// as returns a context carrying an access token for the given identity.
+func (e *grpcEnv) as(t *testing.T, role string, enterpriseID, robotID *int64) context.Context {
+	t.Helper()
+	token, err := e.jwtManager.GenerateAccessToken(auth.TokenInput{
+		UserID: 42, Role: role, EnterpriseID: enterpriseID, RobotID: robotID,
+	})
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func wantCode(t *testing.T, err error, code codes.Code) *status.Status {
+	t.Helper()
+	st, _ := status.FromError(err)
+	if st.Code() != code {
+		t.Fatalf("code = %v (%q), want %v", st.Code(), st.Message(), code)
+	}
+	return st
+}
+
+func TestGRPCAuthentication(t *testing.T) {
+	env := newGRPCEnv(t)
+	users := sonav1.NewUserServiceClient(env.conn)
+
+	_, err := users.GetUser(context.Background(), &sonav1.GetUserRequest{Id: 1})
+	wantCode(t, err, codes.Unauthenticated)
+
+	bad := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not-a-token")
+	_, err = users.GetUser(bad, &sonav1.GetUserRequest{Id: 1})
+	wantCode(t, err, codes.Unauthenticated)
+
+	refresh, err := env.jwtManager.GenerateRefreshToken(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withRefresh := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+refresh)
+	_, err = users.GetUser(withRefresh, &sonav1.GetUserRequest{Id: 1})
+	wantCode(t, err, codes.Unauthenticated)
+
+	user, err := users.GetUser(env.as(t, "family", nil, nil), &sonav1.GetUserRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.GetUsername() != "ada" || !user.GetIsActive() {
+		t.Errorf("user = %v", user)
+	}
+}
+
+func TestGRPCRoles(t *testing.T) {
+	env := newGRPCEnv(t)
+	users := sonav1.NewUserServiceClient(env.conn)
+	robots := sonav1.NewRobotServiceClient(env.conn)
+
+	_, err := users.DeleteUser(env.as(t, "eta", nil, nil), &sonav1.DeleteUserRequest{Id: 1})
+	wantCode(t, err, codes.PermissionDenied)
+
+	_, err = users.ListUsers(env.as(t, "caregiver", nil, nil), &sonav1.ListUsersRequest{})
+	wantCode(t, err, codes.PermissionDenied)
+
+	// Only mta sees deleted users.
+	_, err = users.ListUsers(env.as(t, "eta", nil, nil), &sonav1.ListUsersRequest{Deleted: true})
+	if reason := errorReason(wantCode(t, err, codes.PermissionDenied)); reason != string(domain.CodeRoleNotAllowed) {
+		t.Errorf("reason = %q, want %s", reason, domain.CodeRoleNotAllowed)
+	}
+
+	_, err = robots.Heartbeat(env.as(t, "mta", nil, nil), &sonav1.HeartbeatRequest{Status: "active"})
+	wantCode(t, err, codes.PermissionDenied)
+
+	enterprise := int64(3)
+	_, err = robots.ProvisionRobot(env.as(t, "eta", &enterprise, nil), &sonav1.ProvisionRobotRequest{
+		EnterpriseId: 4, SerialNumber: "SN-1",
+	})
+	wantCode(t, err, codes.PermissionDenied)
+
+	// A robot token identifies the robot; the request cannot name another.
+	robotID := int64(11)
+	robot, err := robots.Heartbeat(env.as(t, "robot", &enterprise, &robotID), &sonav1.HeartbeatRequest{Status: "idle"})
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if env.heartbeat != 11 || robot.GetId() != 11 || robot.GetStatus() != "idle" {
+		t.Errorf("heartbeat for robot %d, response %v", env.heartbeat, robot)
+	}
+}
+
+func TestGRPCErrorMapping(t *testing.T) {
+	env := newGRPCEnv(t)
+	users := sonav1.NewUserServiceClient(env.conn)
+	ctx := env.as(t, "mta", nil, nil)
+
+	st := wantCode(t, mustFail(users.GetUser(ctx, &sonav1.GetUserRequest{Id: 2})), codes.NotFound)
+	if st.Message() != "user not found" {
+		t.Errorf("message = %q", st.Message())
+	}
+	if reason := errorReason(st); reason != string(domain.CodeNotFound) {
+		t.Errorf("reason = %q, want %s", reason, domain.CodeNotFound)
+	}
+
+	wantCode(t, mustFail(users.GetUser(ctx, &sonav1.GetUserRequest{Id: 0})), codes.InvalidArgument)
+
+	st = wantCode(t, mustFail(users.CreateUser(ctx, &sonav1.CreateUserRequest{})), codes.InvalidArgument)
+	var violations []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				violations = append(violations, v.GetField()+": "+v.GetDescription())
+			}
+		}
+	}
+	want := []string{"email: email is required", "username: username is required"}
+	if len(violations) != len(want) || violations[0] != want[0] || violations[1] != want[1] {
+		t.Errorf("violations = %v, want %v", violations, want)
+	}
+
+	st = wantCode(t, mustFail(users.UpdateUser(ctx, &sonav1.UpdateUserRequest{Id: 1})), codes.Aborted)
+	if reason := errorReason(st); reason != string(domain.CodePreconditionFailed) {
+		t.Errorf("reason = %q, want %s", reason, domain.CodePreconditionFailed)
+	}
+
+	// Database failures are not described to the caller.
+	st = wantCode(t, mustFail(users.ListUsers(ctx, &sonav1.ListUsersRequest{})), codes.Internal)
+	if st.Message() != "internal server error" || errorReason(st) != string(domain.CodeInternal) {
+		t.Errorf("message = %q, reason = %q", st.Message(), errorReason(st))
+	}
+
+	st = wantCode(t, mustFail(users.GetUser(context.Background(), &sonav1.GetUserRequest{Id: 1})), codes.Unauthenticated)
+	if reason := errorReason(st); reason != string(domain.CodeAuthTokenMissing) {
+		t.Errorf("reason = %q, want %s", reason, domain.CodeAuthTokenMissing)
+	}
+}
+
+// errorReason returns the reason of st's google.rpc.ErrorInfo.
+func errorReason(st *status.Status) string {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func TestGRPCRequestID(t *testing.T) {
+	env := newGRPCEnv(t)
+	users := sonav1.NewUserServiceClient(env.conn)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(env.as(t, "mta", nil, nil), "x-request-id", "req-123")
+	if _, err := users.GetUser(ctx, &sonav1.GetUserRequest{Id: 1}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-123" {
+		t.Errorf("x-request-id = %v, want [req-123]", got)
+	}
+
+	// Generated when absent, also for failed and unauthenticated calls.
+	header = nil
+	_, err := users.GetUser(context.Background(), &sonav1.GetUserRequest{Id: 1}, grpc.Header(&header))
+	wantCode(t, err, codes.Unauthenticated)
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] == "" {
+		t.Errorf("x-request-id = %v, want a generated ID", got)
+	}
+}
+
+func TestGRPCPublicLogin(t *testing.T) {
+	env := newGRPCEnv(t)
+	authClient := sonav1.NewAuthServiceClient(env.conn)
+
+	resp, err := authClient.Login(context.Background(), &sonav1.LoginRequest{
+		Email: "ada@example.com", Password: "correct-horse",
+	})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if resp.GetUser().GetId() != 7 || resp.GetTokens().GetAccessToken() != "access" ||
+		!resp.GetTokens().GetExpiresAt().AsTime().Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("response = %v", resp)
+	}
+
+	_, err = authClient.Login(context.Background(), &sonav1.LoginRequest{
+		Email: "ada@example.com", Password: "wrong-horse",
+	})
+	wantCode(t, err, codes.Unauthenticated)
+
+	_, err = authClient.Login(context.Background(), &sonav1.LoginRequest{Email: "nope", Password: "x"})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func mustFail[T any](_ T, err error) error { return err }
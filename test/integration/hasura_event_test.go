@@ -0,0 +1,121 @@
+// test/integration/hasura_event_test.go
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/hasura"
+)
+
+// incidentEvent builds an event trigger delivery for an update of an
+// incident's severity, as Hasura sends it.
+func incidentEvent(t *testing.T, id string) *hasura.Event {
+	t.Helper()
+	body := `{
+		"id": "` + id + `",
+		"created_at": "2026-10-18T09:30:00.123456Z",
+		"trigger": {"name": "incidents_changed"},
+		"table": {"schema": "public", "name": "incidents"},
+		"delivery_info": {"max_retries": 5, "current_retry": 0},
+		"event": {
+			"op": "UPDATE",
+			"session_variables": {"x-hasura-role": "eta", "x-hasura-user-id": "42", "x-hasura-enterprise-id": "7"},
+			"data": {
+				"old": {"id": 9, "resident_id": 3, "reporter_id": 42, "severity": "low", "description": "fall",
+				        "resolution": null, "resolved_at": null,
+				        "created_at": "2026-10-18T09:00:00+00:00", "updated_at": "2026-10-18T09:00:00+00:00"},
+				"new": {"id": 9, "resident_id": 3, "reporter_id": 42, "severity": "high", "description": "fall",
+				        "resolution": null, "resolved_at": null,
+				        "created_at": "2026-10-18T09:00:00+00:00", "updated_at": "2026-10-18T09:30:00.123456+00:00"}
+			}
+		}
+	}`
+	var e hasura.Event
+	if err := json.Unmarshal([]byte(body), &e); err != nil {
+		t.Fatalf("decoding event: %v", err)
+	}
+	return &e
+}
+
+func TestHasuraEventSession(t *testing.T) {
+	e := incidentEvent(t, "7a0c9d6e-1f0b-4c5e-9d3a-2b8f4e6a0001")
+	s := e.Session()
+	if s.Role != "eta" || s.UserID != 42 || s.EnterpriseID != 7 {
+		t.Errorf("session = %+v, want eta/42/7", s)
+	}
+
+	e.Event.SessionVariables = map[string]string{"X-Hasura-Role": "admin"}
+	if s := e.Session(); s.Role != "admin" || s.UserID != 0 {
+		t.Errorf("admin session = %+v", s)
+	}
+}
+
+func TestHasuraDispatchRejectsBadEvents(t *testing.T) {
+	d := hasura.NewDispatcher(nil, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	hasura.Register(d, "public.incidents", func(context.Context, *domain.TableChange[domain.Incident]) error {
+		t.Error("handler called for a rejected event")
+		return nil
+	})
+
+	bad := incidentEvent(t, "not-a-uuid")
+	if _, err := d.Dispatch(context.Background(), bad); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("non-UUID event ID: %v", err)
+	}
+
+	other := incidentEvent(t, "7a0c9d6e-1f0b-4c5e-9d3a-2b8f4e6a0002")
+	other.Table.Name = "robots"
+	if _, err := d.Dispatch(context.Background(), other); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("table without a handler: %v", err)
+	}
+}
+
+func TestHasuraDispatchIsIdempotent(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	eventID := "7a0c9d6e-1f0b-4c5e-9d3a-2b8f4e6a0003"
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM hasura_processed_events WHERE event_id = $1`, eventID) //nolint:errcheck // best-effort cleanup
+	})
+
+	var calls int
+	failNext := true
+	d := hasura.NewDispatcher(pool, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	hasura.Register(d, "public.incidents", func(_ context.Context, c *domain.TableChange[domain.Incident]) error {
+		calls++
+		if c.Old.Severity != "low" || c.New.Severity != "high" || c.ActorID == nil || *c.ActorID != 42 {
+			t.Errorf("change = %+v", c)
+		}
+		if failNext {
+			failNext = false
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	e := incidentEvent(t, eventID)
+
+	// A failed attempt leaves no trace, so Hasura's retry is processed.
+	if _, err := d.Dispatch(ctx, e); err == nil {
+		t.Fatal("handler error was not returned")
+	}
+	processed, err := d.Dispatch(ctx, e)
+	if err != nil || !processed {
+		t.Fatalf("retry = %v, %v; want processed", processed, err)
+	}
+
+	// A redelivery of the processed event is skipped.
+	processed, err = d.Dispatch(ctx, e)
+	if err != nil || processed {
+		t.Fatalf("redelivery = %v, %v; want skipped", processed, err)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
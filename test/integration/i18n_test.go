@@ -0,0 +1,234 @@
+// test/integration/i18n_test.go
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/api/openapi"
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/middleware"
+	"my-application/internal/api/request"
+	"my-application/internal/api/router"
+	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/internal/i18n"
+)
+
+var placeholder = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// validationRules are the rules the validator package reports; each needs
+// a message.
+var validationRules = []string{
+	"required", "email", "min_length", "max_length", "min", "max",
+	"greater_than", "after", "one_of", "phone", "timezone", "locale", "type", "unknown", "invalid", "taken", "exclusive",
+}
+
+// boundRequests are the request types validated by binding tags; each of
+// their fields needs a label.
+var boundRequests = []interface{}{
+	auth.LoginRequest{}, auth.RegisterRequest{}, auth.RefreshRequest{},
+	auth.SyncUserRequest{}, auth.FirebaseLoginRequest{}, auth.AcceptInvitationRequest{},
+	request.ProvisionRobotInput{}, request.ResolveIncidentInput{}, request.AssignCaregiverInput{},
+	request.CreateUserRequest{}, request.UpdateUserRequest{}, request.PatchUserRequest{},
+	request.ListUsersQuery{}, request.ImportUsersQuery{}, request.CreateInvitationRequest{},
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	source := i18n.Catalog(i18n.SourceLocale)
+
+	var required []string
+	for _, info := range domain.ErrorCodes {
+		required = append(required, "error."+string(info.Code))
+	}
+	for _, rule := range validationRules {
+		required = append(required, "validation."+rule)
+	}
+	for _, req := range boundRequests {
+		typ := reflect.TypeOf(req)
+		for i := range typ.NumField() {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			required = append(required, "field."+name)
+		}
+	}
+	for _, key := range required {
+		if source[key] == "" {
+			t.Errorf("%s catalog has no message for %s", i18n.SourceLocale, key)
+		}
+	}
+
+	for _, locale := range i18n.Locales()[1:] {
+		catalog := i18n.Catalog(locale)
+		for key, msg := range source {
+			translated, ok := catalog[key]
+			if !ok || translated == "" {
+				t.Errorf("%s catalog is missing %s", locale, key)
+				continue
+			}
+			want, got := placeholder.FindAllString(msg, -1), placeholder.FindAllString(translated, -1)
+			slices.Sort(want)
+			slices.Sort(got)
+			if !slices.Equal(want, got) {
+				t.Errorf("%s %s uses %v, want %v", locale, key, got, want)
+			}
+		}
+		for key := range catalog {
+			if _, ok := source[key]; !ok {
+				t.Errorf("%s catalog has %s, which %s doesn't", locale, key, i18n.SourceLocale)
+			}
+		}
+	}
+}
+
+func TestOpenAPILocalesMatchCatalogs(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var documented []string
+	for _, v := range doc.Components.Schemas["Locale"].Value.Enum {
+		documented = append(documented, v.(string)) //nolint:forcetypeassert // enum of a string schema
+	}
+	if !slices.Equal(documented, i18n.Locales()) {
+		t.Errorf("Locale enum = %v, catalogs = %v", documented, i18n.Locales())
+	}
+}
+
+func TestLocaleMatch(t *testing.T) {
+	tests := []struct {
+		preference string
+		want       string
+		wantOK     bool
+	}{
+		{"es", "es", true},
+		{"es-MX", "es", true},
+		{"fr-FR, es;q=0.8, en;q=0.5", "es", true},
+		{"en-GB", "en", true},
+		{"fr", "", false},
+		{"", "", false},
+		{"not a tag!", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := i18n.Match(tt.preference)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Match(%q) = %q, %v; want %q, %v", tt.preference, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// localized sends a request and returns the status, Content-Language and
+// envelope of the response.
+func localized(r http.Handler, req *http.Request) (int, string, interceptor.APIResponse) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp interceptor.APIResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp) //nolint:errcheck // asserted through the fields
+	return w.Code, w.Header().Get("Content-Language"), resp
+}
+
+func TestLocalizedErrorMessages(t *testing.T) {
+	r := newRouter(t, router.Config{DefaultLocale: "en"})
+	get := func(target, acceptLanguage, token string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+
+	_, lang, resp := localized(r, get("/api/v1/users/1", "es-MX,es;q=0.9", ""))
+	if lang != "es" || resp.Message != i18n.Catalog("es")["error.AUTH_TOKEN_MISSING"] ||
+		resp.Code != string(domain.CodeAuthTokenMissing) {
+		t.Errorf("Accept-Language es-MX: %s %q %s", lang, resp.Message, resp.Code)
+	}
+
+	// Unsupported languages get the default locale, in which the message is
+	// the server's own.
+	_, lang, resp = localized(r, get("/api/v1/users/1", "fr", ""))
+	if lang != "en" || resp.Message != "missing authorization header" {
+		t.Errorf("Accept-Language fr: %s %q", lang, resp.Message)
+	}
+
+	// The profile locale in the token wins over Accept-Language.
+	jwtManager := auth.NewJWTManager(auth.JWTConfig{Secret: "openapi-test", AccessTokenExpiry: time.Minute})
+	es := "es"
+	token, err := jwtManager.GenerateAccessToken(auth.TokenInput{UserID: 1, Role: "mta", Locale: &es})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, lang, resp := localized(r, get("/api/v1/users/abc", "en", token))
+	if status != http.StatusBadRequest || lang != "es" || resp.Message != i18n.Catalog("es")["error.INVALID_INPUT"] {
+		t.Errorf("profile locale es: %d %s %q", status, lang, resp.Message)
+	}
+}
+
+func TestLocalizedValidationDetails(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newServer := func(defaultLocale string) *gin.Engine {
+		r := gin.New()
+		r.Use(middleware.Locale(defaultLocale))
+		r.POST("/login", auth.NewHandler(stubAuth{}, log).Login)
+		return r
+	}
+	login := func(acceptLanguage string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"not-an-email"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		return req
+	}
+	details := func(resp interceptor.APIResponse) map[string]string {
+		out := make(map[string]string)
+		errs, _ := resp.Errors.(map[string]interface{}) //nolint:errcheck // nil map fails the comparison
+		for k, v := range errs {
+			out[k], _ = v.(string) //nolint:errcheck // non-strings fail the comparison
+		}
+		return out
+	}
+
+	tests := []struct {
+		name           string
+		defaultLocale  string
+		acceptLanguage string
+		want           map[string]string
+	}{
+		{"source locale", "en", "", map[string]string{
+			"email":    "email must be a valid email address",
+			"password": "password is required",
+		}},
+		{"requested locale", "en", "es", map[string]string{
+			"email":    "El campo correo electrónico debe ser un correo electrónico válido",
+			"password": "El campo contraseña es obligatorio",
+		}},
+		{"default locale", "es", "", map[string]string{
+			"email":    "El campo correo electrónico debe ser un correo electrónico válido",
+			"password": "El campo contraseña es obligatorio",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, resp := localized(newServer(tt.defaultLocale), login(tt.acceptLanguage))
+			if status != http.StatusBadRequest || resp.Code != string(domain.CodeValidationFailed) {
+				t.Fatalf("got %d %s, want 400 %s", status, resp.Code, domain.CodeValidationFailed)
+			}
+			if got := details(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+// test/integration/idempotency_test.go
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/middleware"
+	"my-application/internal/domain"
+	"my-application/internal/idempotency"
+)
+
+// idempotentRouter serves POST /things, counting the requests that reach
+// the handler. The X-User header stands in for authentication; a "fail"
+// body answers 500 and a "block" body waits on release.
+func idempotentRouter(store idempotency.Store) (*gin.Engine, *atomic.Int32, chan struct{}) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := gin.New()
+	r.POST("/things",
+		func(c *gin.Context) {
+			if id, err := strconv.ParseInt(c.GetHeader("X-User"), 10, 64); err == nil {
+				c.Set(middleware.ContextKeyUserID, id)
+			}
+		},
+		middleware.Idempotency(store, middleware.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute}, log),
+		func(c *gin.Context) {
+			n := calls.Add(1)
+			body, _ := io.ReadAll(c.Request.Body) //nolint:errcheck // an empty body is a fine echo
+			switch string(body) {
+			case "fail":
+				c.JSON(http.StatusInternalServerError, gin.H{"call": n})
+				return
+			case "block":
+				<-release
+			}
+			c.Header("Location", fmt.Sprintf("/things/%d", n))
+			c.JSON(http.StatusCreated, gin.H{"call": n, "body": string(body)})
+		})
+	return r, &calls, release
+}
+
+func postThing(r http.Handler, key, user, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	}
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	r, calls, _ := idempotentRouter(idempotency.NewMemoryStore())
+
+	first := postThing(r, "k1", "1", "a")
+	if first.Code != http.StatusCreated || first.Header().Get(middleware.HeaderIdempotentReplayed) != "" {
+		t.Fatalf("first: %d %v", first.Code, first.Header())
+	}
+	again := postThing(r, "k1", "1", "a")
+	if again.Code != http.StatusCreated || again.Body.String() != first.Body.String() ||
+		again.Header().Get("Location") != "/things/1" || again.Header().Get(middleware.HeaderIdempotentReplayed) != "true" {
+		t.Errorf("retry: %d %v %s", again.Code, again.Header(), again.Body)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler ran %d times", n)
+	}
+
+	// Without a key, or under another user, the request runs.
+	postThing(r, "", "1", "a")
+	postThing(r, "k1", "2", "a")
+	if n := calls.Load(); n != 3 {
+		t.Errorf("handler ran %d times, want 3", n)
+	}
+}
+
+func TestIdempotencyRejectsReuse(t *testing.T) {
+	r, _, _ := idempotentRouter(idempotency.NewMemoryStore())
+
+	postThing(r, "k1", "1", "a")
+	status, _, resp := localized(r, newThingRequest("k1", "1", "b"))
+	if status != http.StatusUnprocessableEntity || resp.Code != string(domain.CodeIdempotencyKeyReused) {
+		t.Errorf("different body: %d %s", status, resp.Code)
+	}
+
+	for _, key := range []string{strings.Repeat("k", 256), "k\x01"} {
+		if status, _, _ := localized(r, newThingRequest(key, "1", "a")); status != http.StatusBadRequest {
+			t.Errorf("key %q: %d, want 400", key, status)
+		}
+	}
+}
+
+func TestIdempotencyAnonymousKeys(t *testing.T) {
+	r, calls, _ := idempotentRouter(idempotency.NewMemoryStore())
+
+	// Anonymous clients that pick the same key do not see each other's
+	// responses: each request with another body runs.
+	alice := postThing(r, "k1", "", "alice:secret")
+	mallory := postThing(r, "k1", "", "mallory:guess")
+	if mallory.Code != http.StatusCreated || mallory.Header().Get(middleware.HeaderIdempotentReplayed) != "" ||
+		strings.Contains(mallory.Body.String(), "alice") {
+		t.Errorf("other anonymous body: %d %v %s", mallory.Code, mallory.Header(), mallory.Body)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want 2", n)
+	}
+
+	// A retry of the same request is still replayed.
+	again := postThing(r, "k1", "", "alice:secret")
+	if again.Header().Get(middleware.HeaderIdempotentReplayed) != "true" || again.Body.String() != alice.Body.String() {
+		t.Errorf("anonymous retry: %d %v %s", again.Code, again.Header(), again.Body)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times after the retry, want 2", n)
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	r, calls, release := idempotentRouter(idempotency.NewMemoryStore())
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postThing(r, "k1", "1", "block") }()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	status, _, resp := localized(r, newThingRequest("k1", "1", "block"))
+	if status != http.StatusConflict || resp.Code != string(domain.CodeIdempotencyKeyInFlight) {
+		t.Errorf("duplicate in flight: %d %s", status, resp.Code)
+	}
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("first: %d", w.Code)
+	}
+	if w := postThing(r, "k1", "1", "block"); w.Header().Get(middleware.HeaderIdempotentReplayed) != "true" {
+		t.Errorf("retry after completion: %d %v", w.Code, w.Header())
+	}
+}
+
+func TestIdempotencyForgetsServerErrors(t *testing.T) {
+	r, calls, _ := idempotentRouter(idempotency.NewMemoryStore())
+
+	postThing(r, "k1", "1", "fail")
+	if w := postThing(r, "k1", "1", "fail"); w.Code != http.StatusInternalServerError ||
+		w.Header().Get(middleware.HeaderIdempotentReplayed) != "" {
+		t.Errorf("retry of a failure: %d %v", w.Code, w.Header())
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want 2", n)
+	}
+}
+
+func TestIdempotencyLimitsBodies(t *testing.T) {
+	r, calls, _ := idempotentRouter(idempotency.NewMemoryStore())
+
+	status, _, resp := localized(r, newThingRequest("k1", "1", strings.Repeat("a", 1<<20+1)))
+	if status != http.StatusRequestEntityTooLarge || resp.Code != string(domain.CodeRequestTooLarge) {
+		t.Errorf("oversized body: %d %s", status, resp.Code)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("handler ran %d times", n)
+	}
+	if w := postThing(r, "k2", "1", strings.Repeat("a", 1<<20)); w.Code != http.StatusCreated {
+		t.Errorf("body at the limit: %d", w.Code)
+	}
+}
+
+func TestSealedIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	inner := idempotency.NewMemoryStore()
+	store, err := idempotency.NewSealedStore(inner, "jwt-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := idempotency.Key{Scope: "anonymous", Value: "k1"}
+	body := `{"tokens":{"access_token":"secret-token"}}`
+
+	if _, err := store.Claim(ctx, key, "fp", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	resp := &idempotency.Response{Status: 201, Header: http.Header{}, Body: []byte(body)}
+	if err := store.Complete(ctx, key, resp, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := inner.Claim(ctx, key, "fp", time.Now().Add(time.Minute))
+	if err != nil || raw == nil || raw.Response == nil {
+		t.Fatalf("stored record: %+v, %v", raw, err)
+	}
+	if strings.Contains(string(raw.Response.Body), "secret-token") {
+		t.Error("response body stored in clear")
+	}
+	rec, err := store.Claim(ctx, key, "fp", time.Now().Add(time.Minute))
+	if err != nil || rec == nil || rec.Response == nil || string(rec.Response.Body) != body || rec.Response.Status != 201 {
+		t.Errorf("replayed record: %+v, %v", rec, err)
+	}
+
+	other, err := idempotency.NewSealedStore(inner, "another-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Claim(ctx, key, "fp", time.Now().Add(time.Minute)); err == nil {
+		t.Error("opened a response sealed under another secret")
+	}
+	if _, err := idempotency.NewSealedStore(inner, ""); err == nil {
+		t.Error("NewSealedStore accepted an empty secret")
+	}
+}
+
+func newThingRequest(key, user, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+	req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	req.Header.Set("X-User", user)
+	return req
+}
+
+func TestPostgresIdempotencyStore(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := idempotency.NewPostgresStore(pool, log)
+
+	key := idempotency.Key{Scope: "test", Value: fmt.Sprintf("k%d", time.Now().UnixNano())}
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(),
+			`DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2`, key.Scope, key.Value); err != nil {
+			t.Errorf("removing key: %v", err)
+		}
+	})
+
+	if rec, err := store.Claim(ctx, key, "fp", time.Now().Add(time.Minute)); err != nil || rec != nil {
+		t.Fatalf("first claim: %+v, %v", rec, err)
+	}
+	if rec, err := store.Claim(ctx, key, "fp", time.Now().Add(time.Minute)); err != nil || rec == nil || rec.Response != nil {
+		t.Fatalf("claim in flight: %+v, %v", rec, err)
+	}
+	if err := store.Release(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := store.Claim(ctx, key, "fp", time.Now().Add(time.Minute)); err != nil || rec != nil {
+		t.Fatalf("claim after release: %+v, %v", rec, err)
+	}
+
+	resp := &idempotency.Response{Status: 201, Header: http.Header{"Location": {"/x"}}, Body: []byte(`{"ok":true}`)}
+	if err := store.Complete(ctx, key, resp, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := store.Claim(ctx, key, "other", time.Now().Add(time.Minute))
+	if err != nil || rec == nil || rec.Fingerprint != "fp" || rec.Response == nil ||
+		rec.Response.Status != 201 || string(rec.Response.Body) != `{"ok":true}` || rec.Response.Header.Get("Location") != "/x" {
+		t.Errorf("claim of a completed key: %+v, %v", rec, err)
+	}
+}
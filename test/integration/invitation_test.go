@@ -0,0 +1,497 @@
+// test/integration/invitation_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/handler"
+	"my-application/internal/api/middleware"
+	"my-application/internal/api/response"
+	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/internal/email"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+	"my-application/internal/worker"
+)
+
+// memInvitations keeps invitations in memory with the rules of the
+// invitations table.
+type memInvitations struct {
+	repository.InvitationRepository
+	invs []*domain.Invitation
+}
+
+func isOpen(inv *domain.Invitation) bool { return inv.AcceptedAt == nil && inv.RevokedAt == nil }
+
+func (r *memInvitations) ListOpen(_ context.Context, enterpriseID int64) ([]domain.Invitation, error) {
+	open := make([]domain.Invitation, 0)
+	for _, inv := range r.invs {
+		if inv.EnterpriseID != nil && *inv.EnterpriseID == enterpriseID && isOpen(inv) {
+			open = append(open, *inv)
+		}
+	}
+	return open, nil
+}
+
+func (r *memInvitations) find(match func(*domain.Invitation) bool) (*domain.Invitation, error) {
+	for _, inv := range r.invs {
+		if match(inv) {
+			found := *inv
+			return &found, nil
+		}
+	}
+	return nil, domain.NewAppError(domain.ErrNotFound, "invitation not found")
+}
+
+func (r *memInvitations) Get(_ context.Context, enterpriseID, id int64) (*domain.Invitation, error) {
+	return r.find(func(inv *domain.Invitation) bool {
+		return inv.ID == id && inv.EnterpriseID != nil && *inv.EnterpriseID == enterpriseID
+	})
+}
+
+func (r *memInvitations) GetByID(_ context.Context, id int64) (*domain.Invitation, error) {
+	return r.find(func(inv *domain.Invitation) bool { return inv.ID == id })
+}
+
+func (r *memInvitations) GetByTokenHash(_ context.Context, tokenHash []byte) (*domain.Invitation, error) {
+	return r.find(func(inv *domain.Invitation) bool { return bytes.Equal(inv.TokenHash, tokenHash) })
+}
+
+func (r *memInvitations) GetOpenByEmail(_ context.Context, email string) (*domain.Invitation, error) {
+	return r.find(func(inv *domain.Invitation) bool { return isOpen(inv) && strings.EqualFold(inv.Email, email) })
+}
+
+func (r *memInvitations) Create(ctx context.Context, inv *domain.Invitation) error {
+	if _, err := r.GetOpenByEmail(ctx, inv.Email); err == nil {
+		return domain.NewAppError(domain.ErrAlreadyExists, "email already has a pending invitation").
+			WithCode(domain.CodeInvitationPending)
+	}
+	inv.ID = int64(len(r.invs) + 1)
+	inv.SentAt, inv.CreatedAt = time.Now(), time.Now()
+	stored := *inv
+	r.invs = append(r.invs, &stored)
+	return nil
+}
+
+// update applies fn to the stored invitation and copies it to inv, or
+// fails when the stored one does not pass check.
+func (r *memInvitations) update(inv *domain.Invitation, check func(*domain.Invitation) bool, fn func(*domain.Invitation)) error {
+	for _, stored := range r.invs {
+		if stored.ID == inv.ID {
+			if !check(stored) {
+				return domain.NewAppError(domain.ErrAlreadyExists, "invitation is not pending").
+					WithCode(domain.CodeInvitationNotPending)
+			}
+			fn(stored)
+			token := inv.Token
+			*inv = *stored
+			inv.Token = token
+			return nil
+		}
+	}
+	return domain.NewAppError(domain.ErrNotFound, "invitation not found")
+}
+
+func (r *memInvitations) Renew(_ context.Context, inv *domain.Invitation) error {
+	hash, expires := inv.TokenHash, inv.ExpiresAt
+	return r.update(inv, isOpen, func(stored *domain.Invitation) {
+		stored.TokenHash, stored.ExpiresAt, stored.SentAt = hash, expires, time.Now()
+	})
+}
+
+func (r *memInvitations) Revoke(_ context.Context, inv *domain.Invitation) error {
+	now := time.Now()
+	return r.update(inv, isOpen, func(stored *domain.Invitation) { stored.RevokedAt = &now })
+}
+
+func (r *memInvitations) Accept(_ context.Context, inv *domain.Invitation, userID int64) error {
+	now := time.Now()
+	return r.update(inv, func(stored *domain.Invitation) bool {
+		return stored.Status(now) == domain.InvitationPending
+	}, func(stored *domain.Invitation) {
+		stored.AcceptedAt, stored.UserID = &now, &userID
+	})
+}
+
+func newInvitationService(invs *memInvitations, repos *importRepos) service.InvitationService {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return service.NewInvitationService(invs, takenUsers{}, repos.audit, repos, repos, service.InvitationConfig{
+		TTL: time.Hour,
+	}, log)
+}
+
+// invitationLink runs the invitation email queued as job and returns the
+// link it sent.
+func invitationLink(t *testing.T, invs *memInvitations, job jobs.Args) (url, token string) {
+	t.Helper()
+	args, ok := job.(jobs.InvitationSendArgs)
+	if !ok {
+		t.Fatalf("job = %+v, want an invitation email", job)
+	}
+	if raw, _ := json.Marshal(args); strings.Contains(string(raw), "token") {
+		t.Errorf("job args %s carry a token", raw)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	renderer, err := email.NewRenderer("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := email.NewMemorySender("sona@example.com")
+	w := worker.NewInvitationWorker(invs, worker.NewEmailWorker(renderer, sender, log), "https://app.example.com/", log)
+	if err := w.Send(context.Background(), &jobs.Job{ID: 1}, args); err != nil {
+		t.Fatalf("sending invitation: %v", err)
+	}
+	sent := sender.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d emails", len(sent))
+	}
+	url = regexp.MustCompile(`https://\S+`).FindString(sent[0].Text)
+	_, token, found := strings.Cut(url, "/invitations/accept?token=")
+	if !found || token == "" {
+		t.Fatalf("invitation link %q", url)
+	}
+	return url, token
+}
+
+func TestInvitationService(t *testing.T) {
+	ctx := context.Background()
+	invs, repos := &memInvitations{}, &importRepos{}
+	svc := newInvitationService(invs, repos)
+	enterpriseID := int64(3)
+
+	inv := &domain.Invitation{EnterpriseID: &enterpriseID, Email: " Ada@Example.com ", Role: domain.RoleCaregiver}
+	if err := svc.Invite(ctx, inv, 5); err != nil {
+		t.Fatal(err)
+	}
+	url, token := invitationLink(t, invs, repos.jobs[0])
+	if inv.Email != "ada@example.com" || *inv.InvitedBy != 5 || inv.Status(time.Now()) != domain.InvitationPending ||
+		!strings.HasPrefix(url, "https://app.example.com/invitations/") ||
+		!bytes.Equal(invs.invs[0].TokenHash, domain.InvitationTokenHash(token)) {
+		t.Errorf("invitation = %+v, link %s", inv, url)
+	}
+
+	rejected := []struct {
+		name string
+		inv  domain.Invitation
+		code domain.ErrorCode
+	}{
+		{"pending", domain.Invitation{Email: "ADA@example.com", Role: domain.RoleFamily}, domain.CodeInvitationPending},
+		{"existing user", domain.Invitation{Email: "old@example.com", Role: domain.RoleFamily}, domain.CodeUserEmailTaken},
+		{"role", domain.Invitation{Email: "root@example.com", Role: domain.RoleMTA}, domain.CodeValidationFailed},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.inv.EnterpriseID = &enterpriseID
+			if err := svc.Invite(ctx, &tt.inv, 5); domain.ErrorCodeOf(err) != tt.code {
+				t.Errorf("err = %v, want %s", err, tt.code)
+			}
+		})
+	}
+
+	// Resending replaces the link.
+	if _, err := svc.Resend(ctx, enterpriseID, inv.ID, 5); err != nil {
+		t.Fatal(err)
+	}
+	_, newToken := invitationLink(t, invs, repos.jobs[1])
+	if newToken == token || !bytes.Equal(invs.invs[0].TokenHash, domain.InvitationTokenHash(newToken)) {
+		t.Errorf("resent invitation kept its token")
+	}
+	if _, err := svc.Resend(ctx, 4, inv.ID, 5); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("resending another enterprise's invitation: %v", err)
+	}
+
+	// An expired invitation is replaced by a new one.
+	invs.invs[0].ExpiresAt = time.Now().Add(-time.Minute)
+	list, _ := svc.ListInvitations(ctx, enterpriseID)
+	if len(list) != 1 || list[0].Status(time.Now()) != domain.InvitationExpired {
+		t.Errorf("listed %+v", list)
+	}
+	again := &domain.Invitation{EnterpriseID: &enterpriseID, Email: "ada@example.com", Role: domain.RoleETA}
+	if err := svc.Invite(ctx, again, 5); err != nil {
+		t.Fatal(err)
+	}
+	if invs.invs[0].RevokedAt == nil || again.ID != 2 {
+		t.Errorf("invitations = %+v", invs.invs)
+	}
+
+	revoked, err := svc.Revoke(ctx, enterpriseID, again.ID, 5)
+	if err != nil || revoked.Status(time.Now()) != domain.InvitationRevoked {
+		t.Fatalf("revoke: %v %+v", err, revoked)
+	}
+	for _, op := range []func(context.Context, int64, int64, int64) (*domain.Invitation, error){svc.Resend, svc.Revoke} {
+		if _, err := op(ctx, enterpriseID, again.ID, 5); domain.ErrorCodeOf(err) != domain.CodeInvitationNotPending {
+			t.Errorf("revoked invitation: %v", err)
+		}
+	}
+	if list, _ := svc.ListInvitations(ctx, enterpriseID); len(list) != 0 {
+		t.Errorf("listed %+v", list)
+	}
+}
+
+// acceptUsers stands in for the users and outbox of an acceptance. User 9
+// was imported without credentials.
+type acceptUsers struct {
+	repository.UserRepository
+	users       map[int64]*domain.User
+	credentials map[int64]string
+}
+
+func newAcceptUsers() *acceptUsers {
+	return &acceptUsers{
+		users: map[int64]*domain.User{
+			9: {ID: 9, Username: "imported", Email: "imported@example.com", Role: domain.RoleFamily, IsActive: true},
+		},
+		credentials: map[int64]string{},
+	}
+}
+
+func (r *acceptUsers) Create(_ context.Context, user *domain.User) error {
+	user.ID = int64(len(r.users) + 100)
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *acceptUsers) GetByID(_ context.Context, id int64) (*domain.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
+}
+
+func (r *acceptUsers) SetCredentials(_ context.Context, id int64, passwordHash string, firebaseUID *string) error {
+	r.credentials[id] = passwordHash
+	if firebaseUID != nil {
+		r.credentials[id] = "firebase:" + *firebaseUID
+	}
+	return nil
+}
+
+func (r *acceptUsers) Append(context.Context, ...*domain.DomainEvent) error { return nil }
+
+// fakeFirebase accepts ID tokens of the form "<uid>:<email>".
+type fakeFirebase struct{}
+
+func (fakeFirebase) VerifyIDToken(_ context.Context, idToken string) (*auth.VerifiedFirebaseUser, error) {
+	uid, email, ok := strings.Cut(idToken, ":")
+	if !ok {
+		return nil, errors.New("malformed token")
+	}
+	return &auth.VerifiedFirebaseUser{UID: uid, Email: email}, nil
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	ctx := context.Background()
+	invs, repos, users := &memInvitations{}, &importRepos{}, newAcceptUsers()
+	invitations := newInvitationService(invs, repos)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	jwtManager := auth.NewJWTManager(auth.JWTConfig{Secret: "invitation-test", AccessTokenExpiry: time.Minute})
+	svc := auth.NewService(users, users, invs, repos.audit, repos, jwtManager, fakeFirebase{}, log)
+
+	enterpriseID := int64(3)
+	locale := "es"
+	if err := invitations.Invite(ctx, &domain.Invitation{EnterpriseID: &enterpriseID, Email: "ada@example.com",
+		Role: domain.RoleETA, FullName: "Ada", Locale: &locale}, 5); err != nil {
+		t.Fatal(err)
+	}
+	_, token := invitationLink(t, invs, repos.jobs[0])
+
+	rejected := []struct {
+		name string
+		req  auth.AcceptInvitationRequest
+		code domain.ErrorCode
+	}{
+		{"no credentials", auth.AcceptInvitationRequest{Token: token, Username: "ada"}, domain.CodeValidationFailed},
+		{"both credentials", auth.AcceptInvitationRequest{Token: token, Username: "ada", Password: "secret-password",
+			IDToken: "uid:ada@example.com"}, domain.CodeValidationFailed},
+		{"unknown token", auth.AcceptInvitationRequest{Token: "nope", Password: "secret-password"}, domain.CodeInvitationInvalid},
+		{"other email", auth.AcceptInvitationRequest{Token: token, Username: "ada", IDToken: "uid:eve@example.com"},
+			domain.CodeInvitationEmailMismatch},
+		{"no username", auth.AcceptInvitationRequest{Token: token, Password: "secret-password"}, domain.CodeValidationFailed},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.AcceptInvitation(ctx, tt.req); domain.ErrorCodeOf(err) != tt.code {
+				t.Errorf("err = %v, want %s", err, tt.code)
+			}
+		})
+	}
+
+	resp, err := svc.AcceptInvitation(ctx, auth.AcceptInvitationRequest{Token: token, Username: "ada", Password: "secret-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := users.users[resp.User.ID]
+	if resp.Tokens.AccessToken == "" || user.Role != domain.RoleETA || *user.EnterpriseID != enterpriseID ||
+		user.FullName != "Ada" || *user.Locale != "es" || auth.CheckPassword("secret-password", user.PasswordHash) != nil {
+		t.Errorf("accepted as %+v", user)
+	}
+	if stored := invs.invs[0]; stored.AcceptedAt == nil || *stored.UserID != user.ID {
+		t.Errorf("invitation = %+v", stored)
+	}
+	if _, err := svc.AcceptInvitation(ctx, auth.AcceptInvitationRequest{Token: token, Username: "ada2",
+		Password: "secret-password"}); domain.ErrorCodeOf(err) != domain.CodeInvitationInvalid {
+		t.Errorf("accepting twice: %v", err)
+	}
+
+	// An imported user links their Firebase account.
+	if _, err := invitations.InviteUser(ctx, users.users[9], nil); err != nil {
+		t.Fatal(err)
+	}
+	_, token = invitationLink(t, invs, repos.jobs[1])
+	resp, err = svc.AcceptInvitation(ctx, auth.AcceptInvitationRequest{Token: token, IDToken: "fb-9:Imported@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.User.ID != 9 || users.credentials[9] != "firebase:fb-9" {
+		t.Errorf("accepted as %+v with %q", resp.User, users.credentials[9])
+	}
+}
+
+// invitationRouter serves the invitation routes; the X-Role and
+// X-Enterprise headers stand in for authentication.
+func invitationRouter() (*gin.Engine, *importRepos) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := &importRepos{}
+	h := handler.NewInvitationHandler(newInvitationService(&memInvitations{}, repos), log)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyUserID, int64(5))
+		c.Set(middleware.ContextKeyUserRole, c.GetHeader("X-Role"))
+		var enterpriseID int64
+		fmt.Sscan(c.GetHeader("X-Enterprise"), &enterpriseID) //nolint:errcheck // no header is enterprise 0
+		c.Set(middleware.ContextKeyEnterpriseID, enterpriseID)
+	})
+	invitations := r.Group("/enterprises/:id/invitations")
+	invitations.GET("", h.List)
+	invitations.POST("", h.Create)
+	invitations.POST("/:invitation_id/resend", h.Resend)
+	invitations.DELETE("/:invitation_id", h.Revoke)
+	return r, repos
+}
+
+func TestInvitationHandler(t *testing.T) {
+	r, repos := invitationRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest(http.MethodPost, "/enterprises/3/invitations", "eta", "3", "application/json",
+		`{"email":"ada@example.com","role":"caregiver","full_name":"Ada"}`))
+	var created struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body)
+	}
+	if created.Data["status"] != domain.InvitationPending || created.Data["enterprise_id"] != float64(3) ||
+		created.Data["token"] != nil || created.Data["token_hash"] != nil || len(repos.jobs) != 1 {
+		t.Errorf("created %v", created.Data)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest(http.MethodGet, "/enterprises/3/invitations", "eta", "3", "", ""))
+	var list struct {
+		Data []response.InvitationResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Data) != 1 || list.Data[0].Email != "ada@example.com" {
+		t.Errorf("list: %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest(http.MethodPost, "/enterprises/3/invitations/1/resend", "eta", "3", "", ""))
+	if w.Code != http.StatusOK || len(repos.jobs) != 2 {
+		t.Errorf("resend: %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest(http.MethodDelete, "/enterprises/3/invitations/1", "eta", "3", "", ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"revoked"`) {
+		t.Errorf("revoke: %d %s", w.Code, w.Body)
+	}
+
+	rejected := []struct {
+		name   string
+		req    *http.Request
+		status int
+		code   domain.ErrorCode
+	}{
+		{"role", importRequest(http.MethodPost, "/enterprises/3/invitations", "mta", "", "application/json",
+			`{"email":"root@example.com","role":"mta"}`), http.StatusBadRequest, domain.CodeValidationFailed},
+		{"other enterprise", importRequest(http.MethodGet, "/enterprises/4/invitations", "eta", "3", "", ""),
+			http.StatusForbidden, domain.CodeEnterpriseOutOfScope},
+		{"revoked", importRequest(http.MethodPost, "/enterprises/3/invitations/1/resend", "eta", "3", "", ""),
+			http.StatusConflict, domain.CodeInvitationNotPending},
+		{"missing", importRequest(http.MethodDelete, "/enterprises/3/invitations/8", "mta", "", "", ""),
+			http.StatusNotFound, domain.CodeNotFound},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, resp := localized(r, tt.req)
+			if status != tt.status || resp.Code != string(tt.code) {
+				t.Errorf("got %d %s, want %d %s", status, resp.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestInvitationPostgres(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	enterpriseID, _ := seedEnterprise(t, pool, "invitation")
+	repo := postgres.NewInvitationPostgres(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	email := "invitee-" + time.Now().Format("150405.000000000") + "@example.com"
+	inv := &domain.Invitation{EnterpriseID: &enterpriseID, Email: email, Role: domain.RoleCaregiver}
+	if err := inv.NewToken(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, inv); err != nil {
+		t.Fatal(err)
+	}
+
+	dup := &domain.Invitation{EnterpriseID: &enterpriseID, Email: strings.ToUpper(email), Role: domain.RoleFamily}
+	if err := dup.NewToken(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, dup); domain.ErrorCodeOf(err) != domain.CodeInvitationPending {
+		t.Errorf("second open invitation: %v", err)
+	}
+
+	got, err := repo.GetByTokenHash(ctx, domain.InvitationTokenHash(inv.Token))
+	if err != nil || got.ID != inv.ID || got.Status(time.Now()) != domain.InvitationPending {
+		t.Fatalf("by token: %v %+v", err, got)
+	}
+	if list, err := repo.ListOpen(ctx, enterpriseID); err != nil || len(list) != 1 {
+		t.Errorf("open: %v %+v", err, list)
+	}
+
+	if err := repo.Revoke(ctx, inv); err != nil || inv.RevokedAt == nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if err := repo.Renew(ctx, inv); domain.ErrorCodeOf(err) != domain.CodeInvitationNotPending {
+		t.Errorf("renewing a revoked invitation: %v", err)
+	}
+	if err := repo.Accept(ctx, inv, 1); domain.ErrorCodeOf(err) != domain.CodeInvitationInvalid {
+		t.Errorf("accepting a revoked invitation: %v", err)
+	}
+	// Revoking frees the address for a new invitation.
+	if err := repo.Create(ctx, dup); err != nil {
+		t.Errorf("invitation after revoking: %v", err)
+	}
+}
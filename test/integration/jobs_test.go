@@ -0,0 +1,340 @@
+// test/integration/jobs_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/jobs"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{12, 2048 * time.Second},
+		{13, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		for range 50 {
+			got := jobs.Backoff(tt.attempt)
+			if got < tt.want*9/10 || got > tt.want*11/10 {
+				t.Fatalf("Backoff(%d) = %s, want %s ±10%%", tt.attempt, got, tt.want)
+			}
+		}
+	}
+}
+
+// testJobArgs is the payload of the jobs run by these tests.
+type testJobArgs struct {
+	N int `json:"n"`
+}
+
+func (testJobArgs) Kind() string { return "test.job" }
+
+// jobQueue returns a queue of its own for the test, whose jobs are deleted
+// when it ends.
+func jobQueue(t *testing.T, pool *pgxpool.Pool) string {
+	t.Helper()
+	queue := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM jobs WHERE queue = $1`, queue) //nolint:errcheck // best-effort cleanup
+	})
+	return queue
+}
+
+// startRunner runs the jobs of queue with fn, one at a time, until the test
+// ends. config may override the test's short intervals.
+func startRunner(t *testing.T, pool *pgxpool.Pool, queue string, config jobs.RunnerConfig, fn jobs.HandlerFunc[testJobArgs]) {
+	t.Helper()
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, fn)
+
+	config.Queues = map[string]int{queue: 1}
+	config.PollInterval = 10 * time.Millisecond
+	if config.StaleAfter == 0 {
+		config.StaleAfter = time.Minute
+	}
+	if config.MaintenanceInterval == 0 {
+		config.MaintenanceInterval = time.Hour
+	}
+	config.KeepCompleted = time.Hour
+
+	runner := jobs.NewRunner(pool, registry, config, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	runner.Start(context.Background())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := runner.Stop(ctx); err != nil {
+			t.Errorf("Stop: %v", err)
+		}
+	})
+}
+
+// jobState is a job's row as the tests check it.
+type jobState struct {
+	Status    string
+	Attempts  int
+	LockedBy  *string
+	LastError *string
+}
+
+func getJob(t *testing.T, pool *pgxpool.Pool, id int64) jobState {
+	t.Helper()
+	var s jobState
+	err := pool.QueryRow(context.Background(), `SELECT status, attempts, locked_by, last_error FROM jobs WHERE id = $1`, id).
+		Scan(&s.Status, &s.Attempts, &s.LockedBy, &s.LastError)
+	if err != nil {
+		t.Fatalf("reading job %d: %v", id, err)
+	}
+	return s
+}
+
+// waitForJob waits until job id has status, failing the test after timeout.
+func waitForJob(t *testing.T, pool *pgxpool.Pool, id int64, status string, timeout time.Duration) jobState {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		s := getJob(t, pool, id)
+		if s.Status == status {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %d is %s after %s, want %s", id, s.Status, timeout, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobUniqueKey(t *testing.T) {
+	pool := testPool(t)
+	queue := jobQueue(t, pool)
+	client := jobs.NewClient(pool)
+	ctx := context.Background()
+	key := jobs.WithUniqueKey(queue + ":unique")
+
+	first, err := client.Enqueue(ctx, testJobArgs{N: 1}, jobs.WithQueue(queue), key)
+	if err != nil || first == 0 {
+		t.Fatalf("first Enqueue = %d, %v", first, err)
+	}
+	if again, err := client.Enqueue(ctx, testJobArgs{N: 2}, jobs.WithQueue(queue), key); err != nil || again != 0 {
+		t.Errorf("Enqueue while pending = %d, %v; want 0, nil", again, err)
+	}
+
+	// Once the job is finished the key is free again.
+	if _, err := pool.Exec(ctx, `UPDATE jobs SET status = 'completed', completed_at = NOW() WHERE id = $1`, first); err != nil {
+		t.Fatal(err)
+	}
+	if next, err := client.Enqueue(ctx, testJobArgs{N: 3}, jobs.WithQueue(queue), key); err != nil || next == 0 || next == first {
+		t.Errorf("Enqueue after completion = %d, %v; want a new job", next, err)
+	}
+}
+
+func TestJobClaimSkipsLockedRows(t *testing.T) {
+	pool := testPool(t)
+	queue := jobQueue(t, pool)
+	client := jobs.NewClient(pool)
+	ctx := context.Background()
+
+	locked, err := client.Enqueue(ctx, testJobArgs{N: 1}, jobs.WithQueue(queue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	free, err := client.Enqueue(ctx, testJobArgs{N: 2}, jobs.WithQueue(queue))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another transaction holds the first job's row, as a concurrent claim
+	// would.
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // committed below
+	if _, err := tx.Exec(ctx, `SELECT id FROM jobs WHERE id = $1 FOR UPDATE`, locked); err != nil {
+		t.Fatal(err)
+	}
+
+	ran := make(chan int64, 2)
+	startRunner(t, pool, queue, jobs.RunnerConfig{}, func(_ context.Context, job *jobs.Job, _ testJobArgs) error {
+		ran <- job.ID
+		return nil
+	})
+
+	select {
+	case id := <-ran:
+		if id != free {
+			t.Fatalf("ran job %d while its row was locked", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the unlocked job did not run")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if s := getJob(t, pool, locked); s.Status != jobs.StatusPending || s.Attempts != 0 {
+		t.Errorf("locked job = %+v, want pending and untried", s)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitForJob(t, pool, locked, jobs.StatusCompleted, 5*time.Second)
+}
+
+func TestJobRetriesUntilDead(t *testing.T) {
+	pool := testPool(t)
+	queue := jobQueue(t, pool)
+	client := jobs.NewClient(pool)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	attempts := map[int]int{}
+	startRunner(t, pool, queue, jobs.RunnerConfig{}, func(_ context.Context, job *jobs.Job, args testJobArgs) error {
+		mu.Lock()
+		attempts[args.N]++
+		mu.Unlock()
+		if args.N == 2 {
+			return jobs.Permanent(errors.New("bad payload"))
+		}
+		return fmt.Errorf("attempt %d failed", job.Attempts)
+	})
+
+	retried, err := client.Enqueue(ctx, testJobArgs{N: 1}, jobs.WithQueue(queue), jobs.WithMaxAttempts(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	permanent, err := client.Enqueue(ctx, testJobArgs{N: 2}, jobs.WithQueue(queue), jobs.WithMaxAttempts(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The retry waits Backoff(1), about a second.
+	s := waitForJob(t, pool, retried, jobs.StatusDead, 10*time.Second)
+	if s.Attempts != 2 || s.LastError == nil || *s.LastError != "attempt 2 failed" {
+		t.Errorf("retried job = %+v, want dead after 2 attempts", s)
+	}
+	s = waitForJob(t, pool, permanent, jobs.StatusDead, 5*time.Second)
+	if s.Attempts != 1 || s.LastError == nil || *s.LastError != "bad payload" {
+		t.Errorf("permanently failed job = %+v, want dead after 1 attempt", s)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts[1] != 2 || attempts[2] != 1 {
+		t.Errorf("handler runs = %v, want 2 and 1", attempts)
+	}
+}
+
+func TestJobRescue(t *testing.T) {
+	pool := testPool(t)
+	queue := jobQueue(t, pool)
+	ctx := context.Background()
+
+	// Jobs left running by a worker that died an hour ago.
+	insert := `INSERT INTO jobs (queue, kind, payload, status, attempts, max_attempts, locked_at, locked_by)
+			   VALUES ($1, 'test.job', '{"n": 1}', 'running', $2, 3, NOW() - INTERVAL '1 hour', 'dead-worker')
+			   RETURNING id`
+	var stale, exhausted int64
+	if err := pool.QueryRow(ctx, insert, queue, 1).Scan(&stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.QueryRow(ctx, insert, queue, 3).Scan(&exhausted); err != nil {
+		t.Fatal(err)
+	}
+
+	startRunner(t, pool, queue, jobs.RunnerConfig{StaleAfter: time.Second, MaintenanceInterval: 20 * time.Millisecond},
+		func(context.Context, *jobs.Job, testJobArgs) error { return nil })
+
+	if s := waitForJob(t, pool, stale, jobs.StatusCompleted, 5*time.Second); s.Attempts != 2 {
+		t.Errorf("rescued job = %+v, want completed on its second attempt", s)
+	}
+	if s := waitForJob(t, pool, exhausted, jobs.StatusDead, 5*time.Second); s.LastError == nil {
+		t.Errorf("exhausted job = %+v, want dead with the rescue noted", s)
+	}
+}
+
+func TestJobHeartbeatKeepsLongJobsClaimed(t *testing.T) {
+	pool := testPool(t)
+	queue := jobQueue(t, pool)
+
+	var mu sync.Mutex
+	runs := 0
+	startRunner(t, pool, queue, jobs.RunnerConfig{StaleAfter: 300 * time.Millisecond, MaintenanceInterval: 20 * time.Millisecond},
+		func(ctx context.Context, _ *jobs.Job, _ testJobArgs) error {
+			mu.Lock()
+			runs++
+			mu.Unlock()
+			select {
+			case <-time.After(time.Second): // well past StaleAfter
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
+	id, err := jobs.NewClient(pool).Enqueue(context.Background(), testJobArgs{}, jobs.WithQueue(queue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := waitForJob(t, pool, id, jobs.StatusCompleted, 5*time.Second); s.Attempts != 1 {
+		t.Errorf("job = %+v, want completed on its first attempt", s)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 1 {
+		t.Errorf("handler ran %d times, want once", runs)
+	}
+}
+
+func TestJobLostLockDiscardsOutcome(t *testing.T) {
+	pool := testPool(t)
+	queue := jobQueue(t, pool)
+	ctx := context.Background()
+
+	started := make(chan int64, 1)
+	cancelled := make(chan error, 1)
+	startRunner(t, pool, queue, jobs.RunnerConfig{StaleAfter: 150 * time.Millisecond},
+		func(ctx context.Context, job *jobs.Job, _ testJobArgs) error {
+			started <- job.ID
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return nil // reports success, but no longer holds the job
+		})
+
+	id, err := jobs.NewClient(pool).Enqueue(ctx, testJobArgs{}, jobs.WithQueue(queue))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// Another worker rescues and claims the job meanwhile.
+	if _, err := pool.Exec(ctx, `UPDATE jobs SET attempts = attempts + 1, locked_by = 'other-worker' WHERE id = $1`, id); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("handler context ended with %v, want cancellation", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler was not cancelled after losing its lock")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if s := getJob(t, pool, id); s.Status != jobs.StatusRunning || s.LockedBy == nil || *s.LockedBy != "other-worker" || s.Attempts != 2 {
+		t.Errorf("job = %+v, want still running for other-worker", s)
+	}
+}
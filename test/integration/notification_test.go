@@ -0,0 +1,170 @@
+// test/integration/notification_test.go
+package integration
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/email"
+	"my-application/internal/jobs"
+	"my-application/internal/push"
+	"my-application/internal/repository"
+	"my-application/internal/worker"
+)
+
+func TestQuietHoursUntil(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, cet) }
+
+	tests := []struct {
+		name       string
+		start, end string
+		now        time.Time
+		want       time.Time // zero when not quiet
+	}{
+		{"before midnight", "22:00", "07:00", at(10, 23, 30), at(11, 7, 0)},
+		{"after midnight", "22:00", "07:00", at(10, 3, 0), at(10, 7, 0)},
+		{"start is quiet", "22:00", "07:00", at(10, 22, 0), at(11, 7, 0)},
+		{"end is not", "22:00", "07:00", at(10, 7, 0), time.Time{}},
+		{"daytime", "22:00", "07:00", at(10, 12, 0), time.Time{}},
+		{"same day window", "12:00", "14:00", at(10, 13, 15), at(10, 14, 0)},
+		{"after same day window", "12:00", "14:00", at(10, 14, 30), time.Time{}},
+		{"empty window", "09:00", "09:00", at(10, 9, 0), time.Time{}},
+		{"invalid", "9am", "5pm", at(10, 12, 0), time.Time{}},
+		// Evaluated in the enterprise's zone: 21:30 UTC is 22:30 CET.
+		{"other zone", "22:00", "07:00", time.Date(2026, 3, 10, 21, 30, 0, 0, time.UTC), at(11, 7, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &domain.QuietHours{Start: tt.start, End: tt.end}
+			until, quiet := q.Until(tt.now, cet)
+			if quiet != !tt.want.IsZero() || !until.Equal(tt.want) {
+				t.Errorf("Until = %v, %v; want %v", until, quiet, tt.want)
+			}
+		})
+	}
+}
+
+// fanoutEvents resolves every fan-out to event and records inbox
+// notifications.
+type fanoutEvents struct {
+	repository.NotificationRepository
+	event   *domain.NotificationEvent
+	created []domain.Notification
+}
+
+func (r *fanoutEvents) ResolveEvent(context.Context, string, int64) (*domain.NotificationEvent, error) {
+	return r.event, nil
+}
+
+func (r *fanoutEvents) Create(_ context.Context, n *domain.Notification) (bool, error) {
+	r.created = append(r.created, *n)
+	return true, nil
+}
+
+// enqueuedJob is a job recorded by recordedJobs with its options applied.
+type enqueuedJob struct {
+	args jobs.Args
+	opts jobs.Options
+}
+
+type recordedJobs struct{ jobs []enqueuedJob }
+
+func (r *recordedJobs) Enqueue(_ context.Context, args jobs.Args, opts ...jobs.Option) (int64, error) {
+	var o jobs.Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r.jobs = append(r.jobs, enqueuedJob{args: args, opts: o})
+	return int64(len(r.jobs)), nil
+}
+
+func TestNotificationFanout(t *testing.T) {
+	now := time.Now().UTC()
+	quiet := &domain.QuietHours{Start: now.Add(-time.Hour).Format("15:04"), End: now.Add(time.Hour).Format("15:04")}
+	incident := domain.NotificationTypeIncidentEscalated
+	events := &fanoutEvents{event: &domain.NotificationEvent{
+		Type:     incident,
+		EntityID: 42,
+		Fields: map[string]interface{}{
+			"IncidentID":   int64(42),
+			"ResidentID":   int64(7),
+			"ResidentName": "Ada",
+			"Severity":     "high",
+			"Description":  "Fell in the bathroom",
+			"ReportedAt":   now,
+		},
+		Recipients: []domain.NotificationRecipient{
+			{UserID: 1, Email: "one@example.com", Locale: "es", TimeZone: "UTC",
+				Preference: domain.DefaultNotificationPreference(incident)},
+			{UserID: 2, Email: "two@example.com", TimeZone: "UTC",
+				Preference: domain.NotificationPreference{Type: incident, InApp: true}},
+			{UserID: 3, TimeZone: "UTC", QuietHours: quiet,
+				Preference: domain.NotificationPreference{Type: incident, Push: true, Email: true}},
+		},
+	}}
+	enqueued := &recordedJobs{}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	w := worker.NewNotificationWorker(events, enqueued, push.NewFakeSender(log), worker.NotificationConfig{
+		AppURL: "https://app.example.com/",
+	}, log)
+
+	if err := w.Fanout(context.Background(), &jobs.Job{}, jobs.NotificationFanoutArgs{Type: incident, EntityID: 42}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events.created) != 2 || events.created[0].UserID != 1 || events.created[1].UserID != 2 ||
+		events.created[0].Body != "Fell in the bathroom" {
+		t.Errorf("inbox = %+v", events.created)
+	}
+
+	var pushed []int64
+	var emailed []jobs.EmailSendArgs
+	for _, job := range enqueued.jobs {
+		switch args := job.args.(type) {
+		case jobs.PushSendArgs:
+			pushed = append(pushed, args.UserID)
+			if strings.Contains(args.Title+args.Body, "Fell") || strings.Contains(args.Title+args.Body, "Ada") ||
+				args.Data["entity_id"] != "42" || args.Data["type"] != incident {
+				t.Errorf("push to %d: %+v", args.UserID, args)
+			}
+			// Quiet hours hold back user 3's push until they end.
+			held := !job.opts.RunAt.IsZero()
+			if held != (args.UserID == 3) || held && (job.opts.RunAt.Format("15:04") != quiet.End || !job.opts.RunAt.After(now)) {
+				t.Errorf("push to %d runs at %v", args.UserID, job.opts.RunAt)
+			}
+		case jobs.EmailSendArgs:
+			emailed = append(emailed, args)
+			if job.opts.Queue != jobs.QueueEmail || job.opts.UniqueKey == "" {
+				t.Errorf("email options %+v", job.opts)
+			}
+		default:
+			t.Errorf("enqueued %T", args)
+		}
+	}
+	if len(pushed) != 2 || pushed[0] != 1 || pushed[1] != 3 {
+		t.Errorf("pushed to %v, want [1 3]", pushed)
+	}
+	// User 3 has no email address.
+	if len(emailed) != 1 || emailed[0].To[0] != "one@example.com" || emailed[0].Locale != "es" ||
+		emailed[0].Template != email.TemplateIncidentEscalation || emailed[0].Data["URL"] != "https://app.example.com/incidents/42" {
+		t.Errorf("emailed %+v", emailed)
+	}
+
+	// Urgent events ignore quiet hours.
+	events.event.Urgent = true
+	enqueued.jobs = nil
+	if err := w.Fanout(context.Background(), &jobs.Job{}, jobs.NotificationFanoutArgs{Type: incident, EntityID: 42}); err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range enqueued.jobs {
+		if !job.opts.RunAt.IsZero() {
+			t.Errorf("urgent %T held until %v", job.args, job.opts.RunAt)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// test/integration/outbox_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/outbox"
+	"my-application/pkg/database"
+)
+
+// outboxTest is a bus with one subscriber to an event type of its own. The
+// events and delivery jobs of the test are deleted when it ends.
+type outboxTest struct {
+	pool       *pgxpool.Pool
+	bus        *outbox.Bus
+	relay      *outbox.Relay
+	eventType  string
+	subscriber string
+}
+
+func newOutboxTest(t *testing.T, pool *pgxpool.Pool, handle outbox.Handler) *outboxTest {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	suffix := time.Now().UnixNano()
+	o := &outboxTest{
+		pool:       pool,
+		bus:        outbox.NewBus(pool, log),
+		eventType:  fmt.Sprintf("test.outbox.%d", suffix),
+		subscriber: fmt.Sprintf("test-%d", suffix),
+	}
+	o.bus.Subscribe(o.subscriber, handle, o.eventType)
+	o.relay = outbox.NewRelay(pool, o.bus, jobs.NewClient(pool),
+		outbox.RelayConfig{PollInterval: 10 * time.Millisecond, BatchSize: 5, KeepRelayed: time.Hour}, log)
+	t.Cleanup(func() {
+		ctx := context.Background()
+		pool.Exec(ctx, `DELETE FROM jobs WHERE kind = 'outbox.deliver' AND payload->>'subscriber' = $1`, o.subscriber) //nolint:errcheck // best-effort cleanup
+		pool.Exec(ctx, `DELETE FROM outbox_events WHERE event_type = $1`, o.eventType)                                 //nolint:errcheck // best-effort cleanup
+	})
+	return o
+}
+
+// append records an event of the test's type and returns its ID.
+func (o *outboxTest) append(t *testing.T) int64 {
+	t.Helper()
+	var id int64
+	if err := o.pool.QueryRow(context.Background(),
+		`INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id) VALUES ($1, 'test', 1) RETURNING id`,
+		o.eventType).Scan(&id); err != nil {
+		t.Fatalf("appending event: %v", err)
+	}
+	return id
+}
+
+// relayAll relays batches until the outbox is empty and returns the number
+// of events claimed.
+func (o *outboxTest) relayAll(t *testing.T) int {
+	t.Helper()
+	total := 0
+	for {
+		n, err := o.relay.RelayBatch(context.Background())
+		if err != nil {
+			t.Fatalf("relaying: %v", err)
+		}
+		if n == 0 {
+			return total
+		}
+		total += n
+	}
+}
+
+// state reports whether event id is relayed, how many delivery jobs the
+// subscriber has for it, and whether the subscriber has handled it.
+func (o *outboxTest) state(t *testing.T, id int64) (relayed bool, jobCount int, delivered bool) {
+	t.Helper()
+	err := o.pool.QueryRow(context.Background(),
+		`SELECT e.relayed_at IS NOT NULL,
+		        (SELECT count(*) FROM jobs j WHERE j.kind = 'outbox.deliver'
+		           AND j.payload->>'subscriber' = $2 AND (j.payload->>'event_id')::bigint = e.id),
+		        EXISTS (SELECT 1 FROM outbox_deliveries d WHERE d.event_id = e.id AND d.subscriber = $2)
+		 FROM outbox_events e WHERE e.id = $1`, id, o.subscriber).Scan(&relayed, &jobCount, &delivered)
+	if err != nil {
+		t.Fatalf("event %d: %v", id, err)
+	}
+	return relayed, jobCount, delivered
+}
+
+func (o *outboxTest) deliver(id int64) error {
+	return o.bus.Deliver(context.Background(), &jobs.Job{Attempts: 1},
+		jobs.OutboxDeliverArgs{EventID: id, Subscriber: o.subscriber})
+}
+
+func TestOutboxRelay(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	o := newOutboxTest(t, pool, func(context.Context, *domain.DomainEvent) error { return nil })
+	ids := []int64{o.append(t), o.append(t), o.append(t)}
+
+	// A row locked by another transaction is skipped, not waited for.
+	lock, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Rollback(ctx) //nolint:errcheck // rollback after rollback is a no-op
+	if _, err := lock.Exec(ctx, `SELECT id FROM outbox_events WHERE id = $1 FOR UPDATE`, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	o.relayAll(t)
+	for _, id := range ids[:2] {
+		if relayed, n, _ := o.state(t, id); !relayed || n != 1 {
+			t.Errorf("event %d: relayed %v with %d jobs, want one", id, relayed, n)
+		}
+	}
+	if relayed, n, _ := o.state(t, ids[2]); relayed || n != 0 {
+		t.Errorf("locked event: relayed %v with %d jobs", relayed, n)
+	}
+
+	if err := lock.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+	o.relayAll(t)
+	if n := o.relayAll(t); n != 0 {
+		t.Errorf("relayed %d events again", n)
+	}
+	for _, id := range ids {
+		if relayed, n, _ := o.state(t, id); !relayed || n != 1 {
+			t.Errorf("event %d: relayed %v with %d jobs, want one", id, relayed, n)
+		}
+	}
+}
+
+func TestOutboxConcurrentRelays(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	o := newOutboxTest(t, pool, func(context.Context, *domain.DomainEvent) error { return nil })
+	ids := make([]int64, 30)
+	for i := range ids {
+		ids[i] = o.append(t)
+	}
+	var pending int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM outbox_events WHERE relayed_at IS NULL`).Scan(&pending); err != nil {
+		t.Fatal(err)
+	}
+
+	// The relays together claim every pending event exactly once.
+	var (
+		mu      sync.Mutex
+		claimed int
+		wg      sync.WaitGroup
+	)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n, err := o.relay.RelayBatch(ctx)
+				if err != nil {
+					t.Errorf("relaying: %v", err)
+					return
+				}
+				if n == 0 {
+					return
+				}
+				mu.Lock()
+				claimed += n
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if claimed != pending {
+		t.Errorf("claimed %d events, %d were pending", claimed, pending)
+	}
+	for _, id := range ids {
+		if relayed, n, _ := o.state(t, id); !relayed || n != 1 {
+			t.Errorf("event %d: relayed %v with %d jobs, want one", id, relayed, n)
+		}
+	}
+}
+
+func TestOutboxDeliver(t *testing.T) {
+	pool := testPool(t)
+	var (
+		calls int
+		fail  = true
+	)
+	o := newOutboxTest(t, pool, func(ctx context.Context, event *domain.DomainEvent) error {
+		calls++
+		if _, ok := database.TxFromContext(ctx); !ok {
+			t.Error("subscriber called outside the delivery transaction")
+		}
+		if fail {
+			return errors.New("subscriber down")
+		}
+		return nil
+	})
+	id := o.append(t)
+	o.relayAll(t)
+
+	// A failing subscriber records no delivery, so the retry runs it again.
+	if err := o.deliver(id); err == nil || jobs.IsPermanent(err) {
+		t.Fatalf("failing delivery: %v", err)
+	}
+	if _, _, delivered := o.state(t, id); delivered {
+		t.Error("failed delivery recorded")
+	}
+
+	fail = false
+	if err := o.deliver(id); err != nil || calls != 2 {
+		t.Fatalf("retried delivery: %v after %d calls", err, calls)
+	}
+	if _, _, delivered := o.state(t, id); !delivered {
+		t.Error("delivery not recorded")
+	}
+
+	// Redelivering the same event is a no-op.
+	if err := o.deliver(id); err != nil || calls != 2 {
+		t.Errorf("redelivery: %v after %d calls", err, calls)
+	}
+
+	// So is delivering an event that was pruned meanwhile.
+	pruned := o.append(t)
+	if _, err := pool.Exec(context.Background(), `DELETE FROM outbox_events WHERE id = $1`, pruned); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.deliver(pruned); err != nil || calls != 2 {
+		t.Errorf("pruned event: %v after %d calls", err, calls)
+	}
+
+	err := o.bus.Deliver(context.Background(), &jobs.Job{}, jobs.OutboxDeliverArgs{EventID: id, Subscriber: "nobody"})
+	if !jobs.IsPermanent(err) {
+		t.Errorf("unknown subscriber: %v", err)
+	}
+}
+
+func TestOutboxPrune(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	o := newOutboxTest(t, pool, func(context.Context, *domain.DomainEvent) error { return nil })
+	old, recent, unrelayed := o.append(t), o.append(t), o.append(t)
+	if _, err := pool.Exec(ctx, `UPDATE outbox_events SET relayed_at = NOW() - INTERVAL '2 hours', created_at = NOW() - INTERVAL '2 hours'
+		WHERE id = $1`, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `UPDATE outbox_events SET relayed_at = NOW() - INTERVAL '30 minutes' WHERE id = $1`, recent); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `UPDATE outbox_events SET created_at = NOW() - INTERVAL '2 hours' WHERE id = $1`, unrelayed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.relay.Prune(ctx, &jobs.Job{}, jobs.OutboxPruneArgs{}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := pool.Query(ctx, `SELECT id FROM outbox_events WHERE id = ANY($1) ORDER BY id`, []int64{old, recent, unrelayed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(left, []int64{recent, unrelayed}) {
+		t.Errorf("events left %v, want %d and %d", left, recent, unrelayed)
+	}
+}
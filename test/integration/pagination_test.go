@@ -0,0 +1,234 @@
+// test/integration/pagination_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/handler"
+	"my-application/internal/domain"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+	"my-application/pkg/pagination"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort, err := pagination.ParseSort("-created_at", domain.UserSortFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.FixedZone("CET", 3600))
+	token := pagination.NewCursor(sort, at, 42).Encode()
+
+	c, err := pagination.Decode(token, sort)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if c.Value != "2026-03-01T11:30:00.123456Z" || c.ID != 42 {
+		t.Errorf("cursor = %+v", c)
+	}
+
+	// A cursor only continues the order it was taken in.
+	if _, err := pagination.Decode(token, pagination.Sort{Field: "created_at"}); !errors.Is(err, pagination.ErrInvalidCursor) {
+		t.Errorf("other sort: %v", err)
+	}
+	for _, bad := range []string{"not base64!", "e30", token[:len(token)-4]} {
+		if _, err := pagination.Decode(bad, sort); !errors.Is(err, pagination.ErrInvalidCursor) {
+			t.Errorf("Decode(%q) = %v", bad, err)
+		}
+	}
+	if _, err := pagination.ParseSort("password_hash", domain.UserSortFields); !errors.Is(err, pagination.ErrInvalidSort) {
+		t.Errorf("ParseSort(password_hash) = %v", err)
+	}
+}
+
+func TestKeysetSQL(t *testing.T) {
+	keyset := pagination.Keyset{
+		Sort:   pagination.Sort{Field: "created_at", Desc: true},
+		Column: pagination.Column{Expr: "created_at", Type: "timestamptz"},
+		ID:     "id",
+	}
+	cond, args := keyset.After(pagination.Cursor{Value: "2026-03-01T11:30:00Z", ID: 7}, 3)
+	if cond != "(created_at, id) < ($3::timestamptz, $4)" || !reflect.DeepEqual(args, []any{"2026-03-01T11:30:00Z", int64(7)}) {
+		t.Errorf("After = %q %v", cond, args)
+	}
+	if got := keyset.OrderBy(); got != "created_at DESC, id DESC" {
+		t.Errorf("OrderBy = %q", got)
+	}
+
+	keyset.Sort.Desc = false
+	if cond, _ := keyset.After(pagination.Cursor{ID: 7}, 1); cond != "(created_at, id) > ($1::timestamptz, $2)" {
+		t.Errorf("ascending After = %q", cond)
+	}
+}
+
+func TestTamperedCursor(t *testing.T) {
+	keyset := pagination.Keyset{
+		Sort:   pagination.Sort{Field: "created_at", Desc: true},
+		Column: pagination.Column{Expr: "created_at", Type: "timestamptz"},
+		ID:     "id",
+	}
+	tampered := func(value string) string {
+		return pagination.Cursor{Sort: keyset.Sort.String(), Value: value, ID: 7}.Encode()
+	}
+	if c, err := keyset.Decode(tampered("2026-03-01T11:30:00.5Z")); err != nil || c.ID != 7 {
+		t.Errorf("untampered: %+v, %v", c, err)
+	}
+	for _, tt := range []struct {
+		column pagination.Column
+		value  string
+	}{
+		{keyset.Column, "yesterday"},
+		{keyset.Column, "2026-03-01"},
+		{keyset.Column, "1; DROP TABLE users"},
+		{pagination.Column{Expr: "id", Type: "bigint"}, "1e3"},
+		{pagination.Column{Expr: "username", Type: "text"}, "ada\x00"},
+	} {
+		k := keyset
+		k.Column = tt.column
+		if _, err := k.Decode(tampered(tt.value)); !errors.Is(err, pagination.ErrInvalidCursor) {
+			t.Errorf("%s cursor %q: %v", tt.column.Type, tt.value, err)
+		}
+	}
+
+	// The listing rejects it before querying the database.
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := gin.New()
+	r.GET("/users", handler.NewUserHandler(service.NewUserService(postgres.NewUserPostgres(nil, log), nil, nil, log), log).List)
+	status, _, resp := localized(r, httptest.NewRequest(http.MethodGet, "/users?sort=-created_at&cursor="+tampered("yesterday"), nil))
+	if status != http.StatusBadRequest || resp.Code != string(domain.CodeInvalidInput) {
+		t.Errorf("got %d %s, want 400 %s", status, resp.Code, domain.CodeInvalidInput)
+	}
+}
+
+func TestListUsersRejectsBadQueries(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := gin.New()
+	// Every query here fails validation before the repository is used.
+	r.GET("/users", handler.NewUserHandler(service.NewUserService(nil, nil, nil, log), log).List)
+
+	byName := pagination.NewCursor(pagination.Sort{Field: "username"}, "ada", 1).Encode()
+	tests := []struct {
+		query string
+		field string
+		want  string
+	}{
+		{"limit=abc", "limit", "page size has the wrong type"},
+		{"limit=500", "limit", "page size must be at most 100"},
+		{"limit=0", "limit", "page size must be at least 1"},
+		{"is_active=yes", "is_active", "active has the wrong type"},
+		{"created_after=yesterday", "created_after", "created after has the wrong type"},
+		{"sort=password_hash", "sort", "sort order must be one of: " +
+			strings.Join(pagination.SortValues(domain.UserSortFields), ", ")},
+		{"cursor=garbage", "cursor", "cursor is invalid"},
+		{"sort=-created_at&cursor=" + byName, "cursor", "cursor is invalid"},
+		{"role=root", "role", "role must be one of: " + strings.Join(domain.Roles, ", ")},
+		{"search=" + strings.Repeat("a", 101), "search", "search must be at most 100 characters"},
+		{"created_after=2026-03-02T00:00:00Z&created_before=2026-03-01T00:00:00Z", "created_before",
+			"created before must be after created_after"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			status, _, resp := localized(r, httptest.NewRequest(http.MethodGet, "/users?"+tt.query, nil))
+			errs, _ := resp.Errors.(map[string]interface{}) //nolint:errcheck // nil map fails the check
+			if status != http.StatusBadRequest || errs[tt.field] != tt.want {
+				t.Errorf("got %d %v, want 400 with %s: %q", status, resp.Errors, tt.field, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserListingPages(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := postgres.NewUserPostgres(pool, log)
+	enterpriseID, _ := seedEnterprise(t, pool, "pages")
+
+	// Five users whose names share a prefix no other user has; the last
+	// belongs to another enterprise.
+	prefix := fmt.Sprintf("pg%d", time.Now().UnixNano()%1e9)
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), `DELETE FROM users WHERE username LIKE $1`, prefix+"%"); err != nil {
+			t.Errorf("removing users: %v", err)
+		}
+	})
+	for i, name := range []string{"erin", "alice", "dave", "bob", "carol"} {
+		user := &domain.User{
+			Username: prefix + "_" + name, Email: name + "@" + prefix + ".example.com",
+			FullName: strings.ToUpper(name[:1]) + name[1:] + " Tester", Role: "caregiver", IsActive: true,
+		}
+		if i < 4 {
+			user.EnterpriseID = &enterpriseID
+		}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("creating %s: %v", name, err)
+		}
+	}
+
+	users := service.NewUserService(repo, nil, nil, log)
+	list := func(filter domain.UserFilter) []string {
+		t.Helper()
+		var names []string
+		for {
+			page, err := users.ListUsers(ctx, filter)
+			if err != nil {
+				t.Fatalf("ListUsers(%+v): %v", filter, err)
+			}
+			for _, u := range page.Users {
+				names = append(names, strings.TrimPrefix(u.Username, prefix+"_"))
+			}
+			if page.NextCursor == "" {
+				return names
+			}
+			filter.Cursor = page.NextCursor
+		}
+	}
+
+	// Search is case-insensitive across username, email and full name, and
+	// "_" in the term is not a wildcard.
+	got := list(domain.UserFilter{Search: strings.ToUpper(prefix) + "_", Sort: "username", Limit: 2})
+	if want := []string{"alice", "bob", "carol", "dave", "erin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("by username = %v, want %v", got, want)
+	}
+	got = list(domain.UserFilter{Search: prefix, Sort: "-full_name", Limit: 3})
+	if want := []string{"erin", "dave", "carol", "bob", "alice"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("by full name descending = %v, want %v", got, want)
+	}
+	if got := list(domain.UserFilter{Search: "carol tester", Limit: 1}); len(got) != 1 || got[0] != "carol" {
+		t.Errorf("search by full name = %v", got)
+	}
+
+	got = list(domain.UserFilter{Search: prefix, EnterpriseID: &enterpriseID, Limit: 2})
+	if want := []string{"bob", "dave", "alice", "erin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("newest first in enterprise = %v, want %v", got, want)
+	}
+
+	page, err := users.ListUsers(ctx, domain.UserFilter{Search: prefix, Limit: 2, WithTotal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total == nil || *page.Total != 5 || len(page.Users) != 2 || page.NextCursor == "" {
+		t.Errorf("page with total = %d users, total %v, next %q", len(page.Users), page.Total, page.NextCursor)
+	}
+
+	future := time.Now().Add(time.Hour)
+	if got := list(domain.UserFilter{Search: prefix, CreatedAfter: &future}); len(got) != 0 {
+		t.Errorf("created after now = %v", got)
+	}
+
+	if _, err := users.ListUsers(ctx, domain.UserFilter{Cursor: "x"}); err == nil {
+		t.Error("bad cursor was accepted")
+	}
+}
@@ -0,0 +1,189 @@
+// test/integration/realtime_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"my-application/internal/domain"
+	"my-application/internal/realtime"
+	"my-application/internal/repository"
+	"my-application/internal/service"
+)
+
+// caregiverResidents assigns caregiver 5 to resident 10.
+type caregiverResidents struct {
+	repository.ResidentRepository
+}
+
+func (caregiverResidents) ListIDsByCaregiver(_ context.Context, caregiverID int64) ([]int64, error) {
+	if caregiverID == 5 {
+		return []int64{10}, nil
+	}
+	return nil, nil
+}
+
+func realtimeEvent(id, enterpriseID, residentID int64) *domain.RealtimeEvent {
+	return &domain.RealtimeEvent{ID: id, Type: domain.RealtimeIncidentCreated, EnterpriseID: &enterpriseID, ResidentID: &residentID}
+}
+
+// receivedIDs drains the events already queued on sub and reports whether
+// the subscription was closed.
+func receivedIDs(sub *realtime.Subscription) (ids []int64, closed bool) {
+	for {
+		select {
+		case m, ok := <-sub.Events:
+			if !ok {
+				return ids, true
+			}
+			ids = append(ids, m.Event.ID)
+		default:
+			return ids, false
+		}
+	}
+}
+
+func eventIDs(messages []realtime.Message) []int64 {
+	ids := make([]int64, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.Event.ID)
+	}
+	return ids
+}
+
+// publish publishes an event for each ID and returns the cursors the hub
+// assigned them, as received by a subscriber.
+func publish(t *testing.T, hub *realtime.Hub, ids ...int64) []string {
+	t.Helper()
+	sub := hub.Subscribe(domain.RealtimeAudience{All: true}, "")
+	defer sub.Close()
+	for _, id := range ids {
+		hub.Publish(realtimeEvent(id, 1, 10))
+	}
+	cursors := make([]string, 0, len(ids))
+	for range ids {
+		cursors = append(cursors, (<-sub.Events).Cursor)
+	}
+	return cursors
+}
+
+func TestRealtimeAudiences(t *testing.T) {
+	ctx := context.Background()
+	hub := realtime.NewHub(realtime.Config{BufferSize: 10, ClientBuffer: 10})
+	defer hub.Close()
+	svc := service.NewRealtimeService(hub, caregiverResidents{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	subscribe := func(userID int64, role string, enterpriseID int64) *realtime.Subscription {
+		t.Helper()
+		sub, err := svc.Subscribe(ctx, userID, role, enterpriseID, "")
+		if err != nil {
+			t.Fatalf("%s: %v", role, err)
+		}
+		return sub
+	}
+	mta := subscribe(1, "mta", 0)
+	eta := subscribe(2, "eta", 1)
+	caregiver := subscribe(5, "caregiver", 1)
+
+	if _, err := svc.Subscribe(ctx, 3, "eta", 0, ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("eta without enterprise: %v", err)
+	}
+	if _, err := svc.Subscribe(ctx, 4, "family", 1, ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("family: %v", err)
+	}
+
+	hub.Publish(realtimeEvent(1, 1, 10))
+	hub.Publish(realtimeEvent(2, 2, 20))
+	hub.Publish(realtimeEvent(3, 1, 11))
+	first := (<-mta.Events).Cursor
+
+	for _, tt := range []struct {
+		name string
+		sub  *realtime.Subscription
+		want []int64
+	}{
+		{"mta", mta, []int64{2, 3}},
+		{"eta", eta, []int64{1, 3}},
+		{"caregiver", caregiver, []int64{1}},
+	} {
+		if got, _ := receivedIDs(tt.sub); !slices.Equal(got, tt.want) {
+			t.Errorf("%s received %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Replay is filtered the same way.
+	resumed, err := svc.Subscribe(ctx, 2, "eta", 1, first)
+	if err != nil || resumed.Reset || !slices.Equal(eventIDs(resumed.Replay), []int64{3}) {
+		t.Errorf("eta replay %v, reset %v: %v", eventIDs(resumed.Replay), resumed.Reset, err)
+	}
+}
+
+func TestRealtimeReplayBuffer(t *testing.T) {
+	hub := realtime.NewHub(realtime.Config{BufferSize: 3, ClientBuffer: 10})
+	all := domain.RealtimeAudience{All: true}
+	// Events arrive in commit order, not ID order.
+	cursors := publish(t, hub, 2, 1, 4, 3, 5)
+
+	// The buffer keeps the last three events: resuming after the second or
+	// later is complete, resuming after the first may have missed the second.
+	for _, tt := range []struct {
+		lastEventID string
+		replay      []int64
+		reset       bool
+	}{
+		{"", []int64{}, false},
+		{cursors[0], []int64{}, true},
+		{cursors[1], []int64{4, 3, 5}, false},
+		{cursors[3], []int64{5}, false},
+		{cursors[4], []int64{}, false},
+		{"5", []int64{}, true},
+		{"other-4", []int64{}, true},
+		{strings.Replace(cursors[4], "-5", "-6", 1), []int64{}, true},
+	} {
+		sub := hub.Subscribe(all, tt.lastEventID)
+		if got := eventIDs(sub.Replay); !slices.Equal(got, tt.replay) || sub.Reset != tt.reset {
+			t.Errorf("after %q: replay %v, reset %v; want %v, %v", tt.lastEventID, got, sub.Reset, tt.replay, tt.reset)
+		}
+		sub.Close()
+		sub.Close()
+	}
+
+	// Events lost while the listener reconnected are a gap too.
+	hub.MarkGap()
+	after := publish(t, hub, 7)
+	if sub := hub.Subscribe(all, cursors[4]); !sub.Reset {
+		t.Error("resuming across a gap did not reset")
+	}
+	if sub := hub.Subscribe(all, after[0]); sub.Reset || len(sub.Replay) != 0 {
+		t.Errorf("resuming after the gap: replay %v, reset %v", eventIDs(sub.Replay), sub.Reset)
+	}
+
+	// Cursors are only meaningful to the hub that issued them.
+	if sub := realtime.NewHub(realtime.Config{BufferSize: 3}).Subscribe(all, after[0]); !sub.Reset {
+		t.Error("resuming from another hub's cursor did not reset")
+	}
+
+	// A subscriber that falls behind is dropped, not waited for.
+	slow := realtime.NewHub(realtime.Config{BufferSize: 3, ClientBuffer: 1})
+	sub := slow.Subscribe(all, "")
+	slow.Publish(realtimeEvent(1, 1, 10))
+	slow.Publish(realtimeEvent(2, 1, 10))
+	if got, closed := receivedIDs(sub); !slices.Equal(got, []int64{1}) || !closed {
+		t.Errorf("slow subscriber received %v, closed %v", got, closed)
+	}
+
+	// Closing the hub ends every stream, current and future.
+	open := hub.Subscribe(all, "")
+	hub.Close()
+	if _, closed := receivedIDs(open); !closed {
+		t.Error("subscription open after the hub closed")
+	}
+	if _, closed := receivedIDs(hub.Subscribe(all, "")); !closed {
+		t.Error("subscribed to a closed hub")
+	}
+}
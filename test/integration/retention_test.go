@@ -0,0 +1,264 @@
+// test/integration/retention_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+	"my-application/internal/worker"
+	"my-application/pkg/database"
+)
+
+// upsertedPolicies records the policies written through it.
+type upsertedPolicies struct {
+	repository.RetentionRepository
+	policies []domain.RetentionPolicy
+}
+
+func (r *upsertedPolicies) UpsertPolicy(_ context.Context, policy *domain.RetentionPolicy) error {
+	policy.ID = int64(len(r.policies) + 1)
+	r.policies = append(r.policies, *policy)
+	return nil
+}
+
+// failingAudit fails every audit event.
+type failingAudit struct{ repository.AuditRepository }
+
+func (failingAudit) Record(context.Context, *domain.AuditEvent) error {
+	return errors.New("audit log unavailable")
+}
+
+func TestRetentionPolicyValidation(t *testing.T) {
+	ctx := context.Background()
+	repo := &upsertedPolicies{}
+	svc := service.NewRetentionService(repo, audit{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	valid := domain.RetentionPolicy{EnterpriseID: 1, TableName: domain.RetentionTableIncidents, RetentionDays: 30,
+		Action: domain.RetentionActionAnonymize, IsActive: true}
+	if err := svc.SetPolicy(ctx, &valid, 9); err != nil || len(repo.policies) != 1 {
+		t.Fatalf("valid policy: %v, %d stored", err, len(repo.policies))
+	}
+
+	tests := []struct {
+		field  string
+		policy func(p *domain.RetentionPolicy)
+	}{
+		{"enterprise_id", func(p *domain.RetentionPolicy) { p.EnterpriseID = 0 }},
+		{"table_name", func(p *domain.RetentionPolicy) { p.TableName = "users" }},
+		{"retention_days", func(p *domain.RetentionPolicy) { p.RetentionDays = 0 }},
+		{"retention_days", func(p *domain.RetentionPolicy) { p.RetentionDays = 36501 }},
+		{"action", func(p *domain.RetentionPolicy) { p.Action = "archive" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			policy := valid
+			tt.policy(&policy)
+			err := svc.SetPolicy(ctx, &policy, 9)
+			var appErr *domain.AppError
+			if !errors.As(err, &appErr) || !errors.Is(err, domain.ErrInvalidInput) || appErr.Details[tt.field] == "" {
+				t.Errorf("err = %v, want a violation of %s", err, tt.field)
+			}
+		})
+	}
+	if len(repo.policies) != 1 {
+		t.Errorf("%d policies stored, want 1", len(repo.policies))
+	}
+
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	if got := valid.Cutoff(now); !got.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("cutoff = %v", got)
+	}
+}
+
+// retentionFixture holds an enterprise with one resident on legal hold and
+// one not, each with an old and a recent resolved incident, an unresolved
+// incident and an old story.
+type retentionFixture struct {
+	enterpriseID    int64
+	free, held      int64 // residents
+	oldIncidents    map[int64]int64
+	recentIncidents map[int64]int64
+	openIncidents   map[int64]int64
+	oldStories      map[int64]int64
+	cutoff          time.Time
+}
+
+func seedRetention(t *testing.T, pool *pgxpool.Pool) *retentionFixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &retentionFixture{
+		oldIncidents:    map[int64]int64{},
+		recentIncidents: map[int64]int64{},
+		openIncidents:   map[int64]int64{},
+		oldStories:      map[int64]int64{},
+		cutoff:          time.Now().AddDate(0, 0, -30),
+	}
+	f.enterpriseID, f.free = seedEnterprise(t, pool, "retention")
+	name := fmt.Sprintf("ret%d", time.Now().UnixNano()%1e9)
+	var reporterID int64
+	err := pool.QueryRow(ctx,
+		`INSERT INTO users (username, email, full_name, role, enterprise_id) VALUES ($1, $2, 'Reporter', 'eta', $3) RETURNING id`,
+		name, name+"@example.com", f.enterpriseID).Scan(&reporterID)
+	if err != nil {
+		t.Fatalf("seeding reporter: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, reporterID); err != nil {
+			t.Errorf("removing reporter: %v", err)
+		}
+	})
+	if err := pool.QueryRow(ctx,
+		`INSERT INTO residents (enterprise_id, full_name, legal_hold) VALUES ($1, 'Held', true) RETURNING id`,
+		f.enterpriseID).Scan(&f.held); err != nil {
+		t.Fatalf("seeding resident: %v", err)
+	}
+
+	insert := func(query string, args ...interface{}) int64 {
+		t.Helper()
+		var id int64
+		if err := pool.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return id
+	}
+	const incident = `INSERT INTO incidents (resident_id, reporter_id, description, resolution, resolved_at)
+		VALUES ($1, $2, 'Fell in the garden', $3, $4) RETURNING id`
+	old, recent := f.cutoff.Add(-24*time.Hour), f.cutoff.Add(24*time.Hour)
+	for _, resident := range []int64{f.free, f.held} {
+		f.oldIncidents[resident] = insert(incident, resident, reporterID, "Checked by nurse", old)
+		f.recentIncidents[resident] = insert(incident, resident, reporterID, "Checked by nurse", recent)
+		f.openIncidents[resident] = insert(incident, resident, reporterID, nil, nil)
+		f.oldStories[resident] = insert(`INSERT INTO stories (resident_id, author_id, content_encrypted, media_url, created_at)
+			VALUES ($1, $2, 'A story', 'https://media.example.com/1.jpg', $3) RETURNING id`, resident, reporterID, old)
+	}
+	return f
+}
+
+func (f *retentionFixture) policy(table, action string) domain.RetentionPolicy {
+	return domain.RetentionPolicy{ID: 1, EnterpriseID: f.enterpriseID, TableName: table, RetentionDays: 30, Action: action, IsActive: true}
+}
+
+func TestRetentionPurgeBatch(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := postgres.NewRetentionPostgres(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	f := seedRetention(t, pool)
+
+	// Only the old incident of the resident without a legal hold is expired;
+	// anonymizing it twice finds nothing the second time.
+	anonymize := f.policy(domain.RetentionTableIncidents, domain.RetentionActionAnonymize)
+	ids, err := repo.PurgeBatch(ctx, anonymize, f.cutoff, 10)
+	if err != nil || !slices.Equal(ids, []int64{f.oldIncidents[f.free]}) {
+		t.Fatalf("anonymized %v: %v", ids, err)
+	}
+	if ids, err := repo.PurgeBatch(ctx, anonymize, f.cutoff, 10); err != nil || len(ids) != 0 {
+		t.Errorf("anonymized again %v: %v", ids, err)
+	}
+	var description string
+	var resolution *string
+	if err := pool.QueryRow(ctx, `SELECT description, resolution FROM incidents WHERE id = $1`,
+		f.oldIncidents[f.free]).Scan(&description, &resolution); err != nil || description != "[redacted]" || resolution != nil {
+		t.Errorf("anonymized incident: %q, %v: %v", description, resolution, err)
+	}
+	for _, id := range []int64{f.oldIncidents[f.held], f.recentIncidents[f.free], f.openIncidents[f.free]} {
+		if err := pool.QueryRow(ctx, `SELECT description FROM incidents WHERE id = $1`, id).Scan(&description); err != nil ||
+			description == "[redacted]" {
+			t.Errorf("incident %d: %q, %v", id, description, err)
+		}
+	}
+
+	// Deleting honours the limit and the legal hold too.
+	remove := f.policy(domain.RetentionTableStories, domain.RetentionActionDelete)
+	if ids, err := repo.PurgeBatch(ctx, remove, f.cutoff, 0); err != nil || len(ids) != 0 {
+		t.Errorf("empty batch %v: %v", ids, err)
+	}
+	if ids, err := repo.PurgeBatch(ctx, remove, f.cutoff, 10); err != nil || !slices.Equal(ids, []int64{f.oldStories[f.free]}) {
+		t.Errorf("deleted %v: %v", ids, err)
+	}
+	var n int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM stories WHERE id = ANY($1)`,
+		[]int64{f.oldStories[f.free], f.oldStories[f.held]}).Scan(&n); err != nil || n != 1 {
+		t.Errorf("%d stories left, want the held one: %v", n, err)
+	}
+
+	// Lifting the hold makes the held rows eligible.
+	if err := repo.SetLegalHold(ctx, f.held, &f.enterpriseID, false); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := repo.PurgeBatch(ctx, anonymize, f.cutoff, 10); err != nil || !slices.Equal(ids, []int64{f.oldIncidents[f.held]}) {
+		t.Errorf("after lifting the hold: %v: %v", ids, err)
+	}
+
+	if _, err := repo.PurgeBatch(ctx, f.policy("users", domain.RetentionActionDelete), f.cutoff, 10); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("unsupported table: %v", err)
+	}
+}
+
+func TestRetentionWorkerAuditsEachBatch(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	retentionRepo := postgres.NewRetentionPostgres(pool, log)
+	tm := database.NewTxManager(pool)
+	f := seedRetention(t, pool)
+	if err := retentionRepo.SetLegalHold(ctx, f.held, &f.enterpriseID, false); err != nil {
+		t.Fatal(err)
+	}
+	policy := f.policy(domain.RetentionTableStories, domain.RetentionActionDelete)
+	remaining := func() int {
+		t.Helper()
+		var n int
+		if err := pool.QueryRow(ctx, `SELECT count(*) FROM stories s JOIN residents r ON r.id = s.resident_id
+			WHERE r.enterprise_id = $1`, f.enterpriseID).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if err := retentionRepo.UpsertPolicy(ctx, &policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// A batch whose audit event fails is rolled back.
+	failing := worker.NewRetentionWorker(retentionRepo, failingAudit{}, tm, 1, log)
+	if err := failing.Enforce(ctx, &jobs.Job{}, jobs.RetentionEnforceArgs{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := remaining(); n != 2 {
+		t.Fatalf("%d stories left after a failed audit, want 2", n)
+	}
+
+	w := worker.NewRetentionWorker(retentionRepo, postgres.NewAuditPostgres(pool, log), tm, 1, log)
+	if err := w.Enforce(ctx, &jobs.Job{}, jobs.RetentionEnforceArgs{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := remaining(); n != 0 {
+		t.Errorf("%d stories left", n)
+	}
+	rows, err := pool.Query(ctx, `SELECT metadata->'row_ids' FROM audit_log
+		WHERE enterprise_id = $1 AND action = 'retention.delete' ORDER BY id`, f.enterpriseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches, err := pgx.CollectRows(rows, pgx.RowTo[[]int64])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int64{{f.oldStories[f.free]}, {f.oldStories[f.held]}}
+	if !slices.EqualFunc(batches, want, slices.Equal[[]int64]) {
+		t.Errorf("audited batches %v, want %v", batches, want)
+	}
+}
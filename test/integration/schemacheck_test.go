@@ -0,0 +1,116 @@
+// test/integration/schemacheck_test.go
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"my-application/internal/schemacheck"
+)
+
+func TestRolesMigrationsAndHasuraAgree(t *testing.T) {
+	schema, err := schemacheck.LoadSchema(filepath.Join("..", "..", "migrations"))
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+	md, err := schemacheck.LoadMetadata(filepath.Join("..", "..", "hasura", "metadata"))
+	if err != nil {
+		t.Fatalf("loading hasura metadata: %v", err)
+	}
+	for _, issue := range schemacheck.Check(schemacheck.ProjectSpec(), schema, md) {
+		t.Error(issue)
+	}
+}
+
+func TestSchemaCheckReportsDrift(t *testing.T) {
+	schema := &schemacheck.Schema{Tables: make(map[string]*schemacheck.Table)}
+	err := schema.Apply(`
+		CREATE TABLE users (
+			id BIGSERIAL PRIMARY KEY,
+			password_hash TEXT,
+			role VARCHAR(20) NOT NULL DEFAULT 'viewer' CHECK (role IN ('admin', 'viewer'))
+		);
+		CREATE TABLE jobs (id BIGSERIAL PRIMARY KEY);
+		ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+		ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('mta', 'eta', 'auditor')),
+			ADD COLUMN nickname TEXT;
+		GRANT app_tenant TO CURRENT_USER;
+		GRANT USAGE ON SCHEMA public TO app_tenant;
+		GRANT SELECT, INSERT ON ALL TABLES IN SCHEMA public TO app_tenant, app_bypass;
+		ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT ALL ON TABLES TO app_tenant;
+		CREATE TABLE notes (id BIGSERIAL PRIMARY KEY);
+		CREATE TABLE events (id BIGSERIAL PRIMARY KEY);
+		ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL ON TABLES FROM app_tenant;
+		CREATE TABLE locks (id BIGSERIAL PRIMARY KEY);
+		ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+		REVOKE INSERT ON jobs FROM app_tenant;
+		REVOKE ALL ON TABLE events FROM app_tenant;
+		REVOKE ALL ON SEQUENCE notes_id_seq FROM app_tenant;
+	`)
+	if err != nil {
+		t.Fatalf("applying DDL: %v", err)
+	}
+
+	dir := t.TempDir()
+	tables := filepath.Join(dir, "databases", "default", "tables")
+	writeFile(t, filepath.Join(tables, "tables.yaml"), `- "!include public_users.yaml"`)
+	writeFile(t, filepath.Join(tables, "public_users.yaml"), `
+table:
+  name: users
+  schema: public
+select_permissions:
+  - role: mta
+    permission:
+      columns: '*'
+      filter: {}
+  - role: guest
+    permission:
+      columns: [id, email]
+      filter: {}
+`)
+	writeFile(t, filepath.Join(dir, "actions.yaml"), "actions: []\n")
+	md, err := schemacheck.LoadMetadata(dir)
+	if err != nil {
+		t.Fatalf("loading metadata: %v", err)
+	}
+
+	spec := schemacheck.Spec{
+		Roles:      []string{"mta", "eta", "caregiver"},
+		RoleColumn: "users.role",
+		Hidden:     map[string][]string{"users": {"password_hash"}},
+		Untracked:  []string{"notes", "events", "locks"},
+		TenantRole: "app_tenant",
+	}
+	var got []string
+	for _, issue := range schemacheck.Check(spec, schema, md) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`hidden-column-exposed: hidden column users.password_hash is selectable in Hasura`,
+		`invalid-default: default "viewer" of users.role is not allowed by its CHECK (mta, eta, auditor)`,
+		`no-row-security: table jobs is granted to app_tenant (SELECT) without row-level security`,
+		`no-row-security: table notes is granted to app_tenant (SELECT, INSERT, UPDATE, DELETE, TRUNCATE, REFERENCES, TRIGGER) without row-level security`,
+		`role-mismatch: role "auditor" is allowed by the CHECK on users.role but not defined in Go`,
+		`role-mismatch: role "caregiver" is defined in Go but not allowed by the CHECK on users.role`,
+		`unknown-column: select permission of guest on users names unknown column email`,
+		`unknown-role: select permission on users is granted to unknown role "guest"`,
+		`untracked-table: table jobs is not tracked by Hasura nor listed as internal`,
+		`unused-role: role "caregiver" has no permission in the Hasura metadata`,
+		`unused-role: role "eta" has no permission in the Hasura metadata`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("issues:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
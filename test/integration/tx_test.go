@@ -0,0 +1,193 @@
+// test/integration/tx_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"my-application/pkg/database"
+)
+
+func TestIsRetryable(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.PgError{Code: "40001"}, true},
+		{&pgconn.PgError{Code: "40P01"}, true},
+		{fmt.Errorf("updating: %w", &pgconn.PgError{Code: "40001"}), true},
+		{&pgconn.PgError{Code: "23505"}, false},
+		{errors.New("40001"), false},
+		{nil, false},
+	} {
+		if got := database.IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v", tt.err, got)
+		}
+	}
+}
+
+func TestTxManagerSavepoints(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	tm := database.NewTxManager(pool)
+	errInner := errors.New("inner failed")
+
+	var rows []int
+	err := tm.WithinTx(ctx, func(ctx context.Context) error {
+		outer, _ := database.TxFromContext(ctx)
+		conn := database.Conn(ctx, pool)
+		if _, err := conn.Exec(ctx, `CREATE TEMP TABLE tx_test (n INT) ON COMMIT DROP`); err != nil {
+			return err
+		}
+		insert := func(ctx context.Context, n int) error {
+			_, err := database.Conn(ctx, pool).Exec(ctx, `INSERT INTO tx_test VALUES ($1)`, n)
+			return err
+		}
+		if err := insert(ctx, 1); err != nil {
+			return err
+		}
+
+		// A failing savepoint only undoes its own work, nested ones included.
+		err := tm.WithinTx(ctx, func(ctx context.Context) error {
+			if inner, _ := database.TxFromContext(ctx); inner == outer {
+				t.Error("savepoint shares the outer transaction")
+			}
+			if err := insert(ctx, 2); err != nil {
+				return err
+			}
+			if err := tm.WithinTx(ctx, func(ctx context.Context) error { return insert(ctx, 3) }); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Errorf("savepoint error = %v", err)
+		}
+
+		// A failing nested savepoint leaves its parent's work.
+		err = tm.WithinTx(ctx, func(ctx context.Context) error {
+			if err := insert(ctx, 4); err != nil {
+				return err
+			}
+			if err := tm.WithinTx(ctx, func(ctx context.Context) error {
+				if err := insert(ctx, 5); err != nil {
+					return err
+				}
+				return errInner
+			}); !errors.Is(err, errInner) {
+				t.Errorf("nested savepoint error = %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		r, err := conn.Query(ctx, `SELECT n FROM tx_test ORDER BY n`)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		for r.Next() {
+			var n int
+			if err := r.Scan(&n); err != nil {
+				return err
+			}
+			rows = append(rows, n)
+		}
+		return r.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(rows) != "[1 4]" {
+		t.Errorf("rows = %v, want [1 4]", rows)
+	}
+
+	// The outermost error rolls everything back.
+	name := fmt.Sprintf("tx-rollback-%d", time.Now().UnixNano())
+	err = tm.WithinTx(ctx, func(ctx context.Context) error {
+		return tm.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := database.Conn(ctx, pool).Exec(ctx,
+				`INSERT INTO enterprises (name, contact_email) VALUES ($1, $2)`, name, name+"@example.com"); err != nil {
+				return err
+			}
+			return errInner
+		})
+	})
+	if !errors.Is(err, errInner) {
+		t.Errorf("outer error = %v", err)
+	}
+	var n int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM enterprises WHERE name = $1`, name).Scan(&n); err != nil || n != 0 {
+		t.Errorf("rolled back enterprise committed: %d, %v", n, err)
+	}
+}
+
+func TestTxManagerRetries(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	tm := database.NewTxManager(pool)
+	serialization := &pgconn.PgError{Code: "40001"}
+	deadlock := &pgconn.PgError{Code: "40P01"}
+
+	// failing returns a function failing with err on its first n calls, and
+	// a pointer to its call count.
+	failing := func(n int, err error) (func(context.Context) error, *int) {
+		calls := new(int)
+		return func(ctx context.Context) error {
+			*calls++
+			if _, ok := database.TxFromContext(ctx); !ok {
+				t.Error("no transaction in context")
+			}
+			if *calls <= n {
+				return err
+			}
+			return nil
+		}, calls
+	}
+
+	fn, calls := failing(2, serialization)
+	if err := tm.WithinTx(ctx, fn); err != nil || *calls != 3 {
+		t.Errorf("serialization failures: %v after %d calls", err, *calls)
+	}
+
+	fn, calls = failing(100, deadlock)
+	if err := tm.WithinTx(ctx, fn); !errors.Is(err, deadlock) || *calls != 4 {
+		t.Errorf("repeated deadlocks: %v after %d calls, want 4", err, *calls)
+	}
+
+	other := errors.New("not retryable")
+	fn, calls = failing(100, other)
+	if err := tm.WithinTx(ctx, fn); !errors.Is(err, other) || *calls != 1 {
+		t.Errorf("other error: %v after %d calls", err, *calls)
+	}
+
+	// A savepoint is not retried on its own: the whole transaction is.
+	inner, innerCalls := failing(1, serialization)
+	outerCalls := 0
+	err := tm.WithinTx(ctx, func(ctx context.Context) error {
+		outerCalls++
+		return tm.WithinTx(ctx, inner)
+	})
+	if err != nil || outerCalls != 2 || *innerCalls != 2 {
+		t.Errorf("savepoint failure: %v after %d outer, %d inner calls", err, outerCalls, *innerCalls)
+	}
+
+	// Cancelling stops retrying.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancelCalls := 0
+	err = tm.WithinTx(cancelCtx, func(context.Context) error {
+		cancelCalls++
+		cancel()
+		return serialization
+	})
+	if !errors.Is(err, serialization) || cancelCalls != 1 {
+		t.Errorf("cancelled: %v after %d calls", err, cancelCalls)
+	}
+}
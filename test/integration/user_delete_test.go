@@ -0,0 +1,233 @@
+// test/integration/user_delete_test.go
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/handler"
+	"my-application/internal/api/middleware"
+	"my-application/internal/domain"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+	"my-application/pkg/database"
+)
+
+// listedUsers records the filter of the last listing.
+type listedUsers struct {
+	service.UserService
+	filter *domain.UserFilter
+}
+
+func (s listedUsers) ListUsers(_ context.Context, filter domain.UserFilter) (*domain.UserPage, error) {
+	*s.filter = filter
+	return &domain.UserPage{}, nil
+}
+
+func TestListDeletedUsersRequiresMTA(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var filter domain.UserFilter
+	r := gin.New()
+	r.GET("/users", func(c *gin.Context) {
+		c.Set(middleware.ContextKeyUserRole, c.GetHeader("X-Role"))
+	}, handler.NewUserHandler(listedUsers{filter: &filter}, log).List)
+
+	list := func(role, query string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/users?"+query, nil)
+		req.Header.Set("X-Role", role)
+		status, _, resp := localized(r, req)
+		return status, resp.Code
+	}
+
+	if status, code := list("eta", "deleted=true"); status != http.StatusForbidden || code != string(domain.CodeRoleNotAllowed) {
+		t.Errorf("eta listing deleted users: %d %s", status, code)
+	}
+	if status, _ := list("eta", "deleted=false"); status != http.StatusOK || filter.Deleted {
+		t.Errorf("eta listing live users: %d, deleted = %v", status, filter.Deleted)
+	}
+	if status, _ := list("mta", "deleted=true"); status != http.StatusOK || !filter.Deleted {
+		t.Errorf("mta listing deleted users: %d, deleted = %v", status, filter.Deleted)
+	}
+}
+
+func TestUserRestoreAndPurge(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.NewUserService(postgres.NewUserPostgres(pool, log), postgres.NewOutboxPostgres(pool, log),
+		database.NewTxManager(pool), log)
+
+	enterpriseID, residentID := seedEnterprise(t, pool, "purge")
+	name := fmt.Sprintf("purge%d", time.Now().UnixNano())
+	user := &domain.User{
+		Username:     name,
+		Email:        name + "@example.com",
+		FullName:     "Grace Hopper",
+		Role:         "caregiver",
+		EnterpriseID: &enterpriseID,
+	}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		if _, err := pool.Exec(bg, `DELETE FROM outbox_events WHERE aggregate_type = 'user' AND aggregate_id = $1`, user.ID); err != nil {
+			t.Errorf("removing events: %v", err)
+		}
+		if _, err := pool.Exec(bg, `DELETE FROM users WHERE id = $1`, user.ID); err != nil {
+			t.Errorf("removing user: %v", err)
+		}
+	})
+	if _, err := pool.Exec(ctx,
+		`INSERT INTO caregiver_residents (caregiver_id, resident_id) VALUES ($1, $2)`, user.ID, residentID); err != nil {
+		t.Fatalf("assigning resident: %v", err)
+	}
+
+	// Only deleted users are restored or purged.
+	if _, err := svc.RestoreUser(ctx, user.ID); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("restore of a live user: %v", err)
+	}
+	if err := svc.PurgeUser(ctx, user.ID); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("purge of a live user: %v", err)
+	}
+
+	if err := svc.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := svc.GetUser(ctx, user.ID); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("deleted user still visible: %v", err)
+	}
+	restored, err := svc.RestoreUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("RestoreUser: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Email != user.Email {
+		t.Errorf("restored user = %+v", restored)
+	}
+	if _, err := svc.GetUser(ctx, user.ID); err != nil {
+		t.Errorf("restored user not visible: %v", err)
+	}
+
+	if err := svc.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("second DeleteUser: %v", err)
+	}
+	if err := svc.PurgeUser(ctx, user.ID); err != nil {
+		t.Fatalf("PurgeUser: %v", err)
+	}
+
+	var username, email, fullName, passwordHash string
+	var active bool
+	err = pool.QueryRow(ctx,
+		`SELECT username, email, full_name, password_hash, is_active FROM users WHERE id = $1 AND purged_at IS NOT NULL`,
+		user.ID).Scan(&username, &email, &fullName, &passwordHash, &active)
+	if err != nil {
+		t.Fatalf("loading purged user: %v", err)
+	}
+	want := fmt.Sprintf("purged_%d", user.ID)
+	if username != want || email != want+"@purged.invalid" || fullName != "Purged User" || passwordHash != "" || active {
+		t.Errorf("purged user = %s, %s, %s, active %v; want anonymized", username, email, fullName, active)
+	}
+	var links int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM caregiver_residents WHERE caregiver_id = $1`, user.ID).Scan(&links); err != nil {
+		t.Fatal(err)
+	}
+	if links != 0 {
+		t.Errorf("purged caregiver keeps %d resident links", links)
+	}
+
+	// Purging is final.
+	if _, err := svc.RestoreUser(ctx, user.ID); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("restore of a purged user: %v", err)
+	}
+	if err := svc.PurgeUser(ctx, user.ID); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("second purge: %v", err)
+	}
+
+	rows, err := pool.Query(ctx,
+		`SELECT event_type FROM outbox_events WHERE aggregate_type = 'user' AND aggregate_id = $1 ORDER BY id`, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	for rows.Next() {
+		var e string
+		if err := rows.Scan(&e); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+	wantEvents := fmt.Sprint([]string{domain.EventUserCreated, domain.EventUserDeleted, domain.EventUserRestored,
+		domain.EventUserDeleted, domain.EventUserPurged})
+	if fmt.Sprint(events) != wantEvents {
+		t.Errorf("events = %v, want %s", events, wantEvents)
+	}
+}
+
+func TestDeletedUserNamesAreReusable(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := postgres.NewUserPostgres(pool, log)
+	svc := service.NewUserService(repo, postgres.NewOutboxPostgres(pool, log), database.NewTxManager(pool), log)
+
+	enterpriseID, _ := seedEnterprise(t, pool, "reuse")
+	name := fmt.Sprintf("reuse%d", time.Now().UnixNano())
+	newUser := func() *domain.User {
+		return &domain.User{Username: name, Email: name + "@example.com", FullName: "Ada Lovelace", Role: "caregiver",
+			EnterpriseID: &enterpriseID}
+	}
+	var ids []int64
+	t.Cleanup(func() {
+		bg := context.Background()
+		if _, err := pool.Exec(bg, `DELETE FROM outbox_events WHERE aggregate_type = 'user' AND aggregate_id = ANY($1)`, ids); err != nil {
+			t.Errorf("removing events: %v", err)
+		}
+		if _, err := pool.Exec(bg, `DELETE FROM users WHERE id = ANY($1)`, ids); err != nil {
+			t.Errorf("removing users: %v", err)
+		}
+	})
+
+	old := newUser()
+	if err := svc.CreateUser(ctx, old); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	ids = append(ids, old.ID)
+	if err := svc.CreateUser(ctx, newUser()); domain.ErrorCodeOf(err) != domain.CodeUserEmailTaken &&
+		domain.ErrorCodeOf(err) != domain.CodeUserUsernameTaken {
+		t.Fatalf("duplicate of a live user: %v", err)
+	}
+	if err := svc.DeleteUser(ctx, old.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	// A deleted user's username and email are free again.
+	if usernames, emails, err := repo.Taken(ctx, []string{name}, []string{name + "@example.com"}); err != nil ||
+		len(usernames) != 0 || len(emails) != 0 {
+		t.Errorf("Taken = %v, %v, %v", usernames, emails, err)
+	}
+	reused := newUser()
+	if err := svc.CreateUser(ctx, reused); err != nil {
+		t.Fatalf("CreateUser after delete: %v", err)
+	}
+	ids = append(ids, reused.ID)
+
+	// Restoring the old user would duplicate them.
+	if _, err := svc.RestoreUser(ctx, old.ID); domain.ErrorCodeOf(err) != domain.CodeUserEmailTaken &&
+		domain.ErrorCodeOf(err) != domain.CodeUserUsernameTaken {
+		t.Errorf("restore over a reused email: %v", err)
+	}
+	if err := svc.DeleteUser(ctx, reused.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := svc.RestoreUser(ctx, old.ID); err != nil {
+		t.Errorf("restore once the email is free: %v", err)
+	}
+}
@@ -0,0 +1,460 @@
+// test/integration/user_import_test.go
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/internal/api/handler"
+	"my-application/internal/api/middleware"
+	"my-application/internal/api/response"
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+)
+
+func TestParseUserImport(t *testing.T) {
+	csv := "\ufeffUsername,email,full_name,locale\n" +
+		"ada,ADA@example.com,Ada Lovelace,en\n" +
+		"\n" +
+		`grace,grace@example.com,"Grace` + "\n" + `Hopper",` + "\n" +
+		"alan,alan@example.com,Alan Turing,\n"
+	rows, err := service.ParseUserImport(strings.NewReader(csv), domain.ImportFormatCSV, 0)
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(rows) != 3 || rows[0].Line != 2 || rows[0].Email != "ADA@example.com" || rows[0].Locale == nil ||
+		rows[1].Line != 4 || rows[1].FullName != "Grace\nHopper" || rows[1].Locale != nil || rows[2].Line != 6 {
+		t.Errorf("csv rows = %+v", rows)
+	}
+
+	jsonl := `{"username":"ada","email":"ada@example.com","full_name":"Ada","role":"eta"}` + "\n\n" +
+		`{"username":"alan","email":"alan@example.com","full_name":"Alan","locale":""}` + "\n"
+	rows, err = service.ParseUserImport(strings.NewReader(jsonl), domain.ImportFormatJSONL, 0)
+	if err != nil {
+		t.Fatalf("jsonl: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Line != 1 || rows[0].Role != "eta" || rows[1].Line != 3 || rows[1].Locale != nil {
+		t.Errorf("jsonl rows = %+v", rows)
+	}
+
+	tests := []struct {
+		name, format, body, want string
+	}{
+		{"empty", domain.ImportFormatCSV, "", "the import file has no users"},
+		{"header only", domain.ImportFormatCSV, "username,email,full_name\n", "the import file has no users"},
+		{"unknown column", domain.ImportFormatCSV, "username,email,full_name,age\n", `line 1: unknown column "age"`},
+		{"missing column", domain.ImportFormatCSV, "username,email\n", `line 1: missing column "full_name"`},
+		{"short record", domain.ImportFormatCSV, "username,email,full_name\nada,ada@example.com\n", "line 2"},
+		{"too many", domain.ImportFormatCSV, "username,email,full_name\na,a,a\nb,b,b\nc,c,c\n", "line 4: an import file may hold at most 2 users"},
+		{"unknown field", domain.ImportFormatJSONL, `{"username":"ada","age":3}`, "line 1: "},
+		{"not an object", domain.ImportFormatJSONL, "{}\n[1]", "line 2: "},
+		{"two values", domain.ImportFormatJSONL, `{} {}`, "line 1: more than one JSON value"},
+		{"format", "xlsx", "x", `unsupported import format "xlsx"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ParseUserImport(strings.NewReader(tt.body), tt.format, 2)
+			if domain.ErrorCodeOf(err) != domain.CodeInvalidInput || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want INVALID_INPUT containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// importUsers creates every user but "clash", whose username turns out to
+// be taken when it is created. Like UserService, it defaults the role to
+// caregiver.
+type importUsers struct {
+	service.UserService
+	created []string
+}
+
+func (s *importUsers) CreateUser(_ context.Context, user *domain.User) error {
+	if user.Username == "clash" {
+		return domain.NewAppError(domain.ErrAlreadyExists, "username already taken").WithCode(domain.CodeUserUsernameTaken)
+	}
+	if user.Role == "" {
+		user.Role = domain.RoleCaregiver
+	}
+	s.created = append(s.created, user.Username)
+	user.ID = int64(len(s.created))
+	return nil
+}
+
+// takenUsers has the users "old" and old@example.com, and the requesters
+// 1 (mta) and 2 (eta).
+type takenUsers struct{ repository.UserRepository }
+
+func (takenUsers) GetByID(_ context.Context, id int64) (*domain.User, error) {
+	switch id {
+	case 1:
+		return &domain.User{ID: 1, Role: domain.RoleMTA}, nil
+	case 2:
+		return &domain.User{ID: 2, Role: domain.RoleETA}, nil
+	}
+	return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
+}
+
+func (takenUsers) Taken(_ context.Context, usernames, emails []string) (takenUsernames, takenEmails []string, err error) {
+	for _, u := range usernames {
+		if u == "old" {
+			takenUsernames = append(takenUsernames, u)
+		}
+	}
+	for _, e := range emails {
+		if e == "old@example.com" {
+			takenEmails = append(takenEmails, e)
+		}
+	}
+	return takenUsernames, takenEmails, nil
+}
+
+// importRepos stands in for the import and audit repositories,
+// transactions and the job queue of an import.
+type importRepos struct {
+	repository.UserImportRepository
+	audit
+	jobs []jobs.Args
+}
+
+// audit discards audit events.
+type audit struct{ repository.AuditRepository }
+
+func (audit) Record(context.Context, *domain.AuditEvent) error { return nil }
+
+func (r *importRepos) Create(_ context.Context, imp *domain.UserImport) error {
+	imp.ID = 7
+	return nil
+}
+
+func (r *importRepos) Enqueue(_ context.Context, args jobs.Args, _ ...jobs.Option) (int64, error) {
+	r.jobs = append(r.jobs, args)
+	return int64(len(r.jobs)), nil
+}
+
+func (r *importRepos) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newImportService(inlineRows int) (service.UserImportService, *importUsers, *importRepos) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	users, repos := &importUsers{}, &importRepos{}
+	svc := service.NewUserImportService(users, newInvitationService(&memInvitations{}, repos), takenUsers{}, repos,
+		repos.audit, repos, repos, service.UserImportConfig{
+			InlineRows: inlineRows,
+		}, log)
+	return svc, users, repos
+}
+
+// importFile has a valid row, one repeating its email, one with an
+// existing username, one with an invalid email and one failing on
+// creation.
+const importFile = `username,email,full_name
+ada,ada@example.com,Ada Lovelace
+ada2,ADA@example.com,Ada Again
+old,new@example.com,Old Timer
+bad,not-an-email,Bad Email
+clash,clash@example.com,Clash
+`
+
+func rowStatuses(results []domain.UserImportResult) string {
+	statuses := make([]string, len(results))
+	for i, r := range results {
+		statuses[i] = fmt.Sprintf("%d:%s", r.Line, r.Status)
+		for field, v := range r.Violations {
+			statuses[i] += fmt.Sprintf(" %s=%s", field, v.Rule)
+		}
+	}
+	return strings.Join(statuses, ", ")
+}
+
+func TestUserImportModes(t *testing.T) {
+	tests := []struct {
+		mode    string
+		dryRun  bool
+		want    string
+		created int
+	}{
+		{domain.ImportModeBestEffort, true,
+			"2:valid, 3:failed email=taken, 4:failed username=taken, 5:failed email=email, 6:valid", 0},
+		{domain.ImportModeBestEffort, false,
+			"2:created, 3:failed email=taken, 4:failed username=taken, 5:failed email=email, 6:failed username=taken", 1},
+		{domain.ImportModeAtomic, false,
+			"2:skipped, 3:failed email=taken, 4:failed username=taken, 5:failed email=email, 6:skipped", 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s dry_run=%t", tt.mode, tt.dryRun), func(t *testing.T) {
+			svc, users, repos := newImportService(100)
+			imp := &domain.UserImport{Mode: tt.mode, DryRun: tt.dryRun, Invite: true}
+			if err := svc.ImportUsers(context.Background(), imp, strings.NewReader(importFile), domain.ImportFormatCSV); err != nil {
+				t.Fatal(err)
+			}
+			if got := rowStatuses(imp.Results); got != tt.want || imp.Status != domain.ImportStatusCompleted {
+				t.Errorf("%s: results %s, want %s", imp.Status, got, tt.want)
+			}
+			if len(users.created) != tt.created || len(repos.jobs) != tt.created {
+				t.Errorf("created %v with %d invitations, want %d", users.created, len(repos.jobs), tt.created)
+			}
+		})
+	}
+}
+
+func TestUserImportAtomicCreatesAll(t *testing.T) {
+	svc, users, repos := newImportService(100)
+	imp := &domain.UserImport{Mode: domain.ImportModeAtomic, Invite: true}
+	src := "username,email,full_name\nada,ada@example.com,Ada\nalan,alan@example.com,Alan\n"
+	if err := svc.ImportUsers(context.Background(), imp, strings.NewReader(src), domain.ImportFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	if got := rowStatuses(imp.Results); got != "2:created, 3:created" || len(users.created) != 2 {
+		t.Errorf("results %s, created %v", got, users.created)
+	}
+	send, ok := repos.jobs[0].(jobs.InvitationSendArgs)
+	if !ok || len(repos.jobs) != 2 || send.InvitationID != 1 {
+		t.Errorf("invitations = %+v", repos.jobs)
+	}
+
+	// Without invitations no email is queued.
+	svc, _, repos = newImportService(100)
+	imp = &domain.UserImport{Mode: domain.ImportModeAtomic}
+	if err := svc.ImportUsers(context.Background(), imp, strings.NewReader(src), domain.ImportFormatCSV); err != nil || len(repos.jobs) != 0 {
+		t.Errorf("invite=false: %v, jobs %+v", err, repos.jobs)
+	}
+}
+
+func TestUserImportRestrictsRoles(t *testing.T) {
+	const src = "username,email,full_name,role\n" +
+		"root,root@example.com,Root,mta\nbot,bot@example.com,Bot,robot\n" +
+		"eve,eve@example.com,Eve,eta\nfay,fay@example.com,Fay,family\n"
+	mta, eta := int64(1), int64(2)
+	tests := []struct {
+		name        string
+		requestedBy *int64
+		invite      bool
+		want        string
+	}{
+		{"eta", &eta, false, "2:failed role=one_of, 3:failed role=one_of, 4:valid, 5:valid"},
+		{"eta inviting", &eta, true, "2:failed role=one_of, 3:failed role=one_of, 4:valid, 5:valid"},
+		{"mta", &mta, false, "2:valid, 3:valid, 4:valid, 5:valid"},
+		{"mta inviting", &mta, true, "2:failed role=one_of, 3:failed role=one_of, 4:valid, 5:valid"},
+		{"command line", nil, false, "2:valid, 3:valid, 4:valid, 5:valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newImportService(100)
+			imp := &domain.UserImport{Mode: domain.ImportModeBestEffort, DryRun: true, Invite: tt.invite, RequestedBy: tt.requestedBy}
+			if err := svc.ImportUsers(context.Background(), imp, strings.NewReader(src), domain.ImportFormatCSV); err != nil {
+				t.Fatal(err)
+			}
+			if got := rowStatuses(imp.Results); got != tt.want {
+				t.Errorf("results %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Users with other roles are never invited, however they were created.
+	repos := &importRepos{}
+	invitations := newInvitationService(&memInvitations{}, repos)
+	for _, role := range []string{domain.RoleMTA, domain.RoleRobot} {
+		_, err := invitations.InviteUser(context.Background(), &domain.User{ID: 9, Email: "root@example.com", Role: role}, &eta)
+		if domain.ErrorCodeOf(err) != domain.CodeValidationFailed || len(repos.jobs) != 0 {
+			t.Errorf("InviteUser(%s) = %v with %d emails, want VALIDATION_FAILED and none", role, err, len(repos.jobs))
+		}
+	}
+}
+
+func TestUserImportQueuesLargeFiles(t *testing.T) {
+	svc, users, repos := newImportService(2)
+	imp := &domain.UserImport{Mode: domain.ImportModeBestEffort}
+	if err := svc.ImportUsers(context.Background(), imp, strings.NewReader(importFile), domain.ImportFormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	if imp.Status != domain.ImportStatusPending || imp.ID != 7 || len(imp.Rows) != 5 || len(users.created) != 0 {
+		t.Errorf("import = %+v, created %v", imp, users.created)
+	}
+	if args, ok := repos.jobs[0].(jobs.UserImportArgs); !ok || len(repos.jobs) != 1 || args.ImportID != 7 {
+		t.Errorf("jobs = %+v", repos.jobs)
+	}
+}
+
+// recordedImports records the import it is asked to run and answers it
+// with a failed row, or queues it when its file is "queue".
+type recordedImports struct {
+	service.UserImportService
+	imp    *domain.UserImport
+	format string
+}
+
+func (s *recordedImports) ImportUsers(_ context.Context, imp *domain.UserImport, src io.Reader, format string) error {
+	s.imp, s.format = imp, format
+	body, _ := io.ReadAll(src) //nolint:errcheck // an unread body fails the checks
+	if string(body) == "queue" {
+		imp.ID, imp.Status = 9, domain.ImportStatusPending
+		return nil
+	}
+	imp.Status = domain.ImportStatusCompleted
+	imp.Results = []domain.UserImportResult{
+		{Line: 2, Status: domain.ImportRowValid},
+		{Line: 3, Status: domain.ImportRowFailed, Code: domain.CodeValidationFailed,
+			Violations: map[string]domain.Violation{"email": {Rule: "taken"}}},
+	}
+	return nil
+}
+
+func (s *recordedImports) GetImport(_ context.Context, id int64) (*domain.UserImport, error) {
+	enterpriseID := int64(1)
+	return &domain.UserImport{ID: id, EnterpriseID: &enterpriseID, Mode: domain.ImportModeAtomic,
+		Status: domain.ImportStatusProcessing}, nil
+}
+
+// importRouter serves the import routes; the X-Role and X-Enterprise
+// headers stand in for authentication.
+func importRouter() (*gin.Engine, *recordedImports) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	imports := &recordedImports{}
+	h := handler.NewUserImportHandler(imports, log)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyUserID, int64(5))
+		c.Set(middleware.ContextKeyUserRole, c.GetHeader("X-Role"))
+		var enterpriseID int64
+		fmt.Sscan(c.GetHeader("X-Enterprise"), &enterpriseID) //nolint:errcheck // no header is enterprise 0
+		c.Set(middleware.ContextKeyEnterpriseID, enterpriseID)
+	})
+	r.POST("/users/import", h.Import)
+	r.GET("/users/imports/:id", h.GetByID)
+	return r, imports
+}
+
+func importRequest(method, target, role, enterprise, contentType, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("X-Role", role)
+	req.Header.Set("X-Enterprise", enterprise)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req
+}
+
+func TestImportUsersHandler(t *testing.T) {
+	r, imports := importRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest(http.MethodPost, "/users/import?dry_run=true", "eta", "3", "text/csv", "rows"))
+	var resp struct {
+		Data response.UserImportResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("import: %d %s", w.Code, w.Body)
+	}
+	imp := imports.imp
+	if imports.format != domain.ImportFormatCSV || imp.Mode != domain.ImportModeAtomic || !imp.DryRun || !imp.Invite ||
+		imp.EnterpriseID == nil || *imp.EnterpriseID != 3 || imp.RequestedBy == nil || *imp.RequestedBy != 5 {
+		t.Errorf("import = %+v", imp)
+	}
+	got := resp.Data
+	if got.ID != 0 || got.Valid != 1 || got.Failed != 1 || len(got.Results) != 2 ||
+		got.Results[1].Code != string(domain.CodeValidationFailed) || got.Results[1].Errors["email"] != "email is already in use" {
+		t.Errorf("response = %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest(http.MethodPost, "/users/import?mode=best_effort&invite=false&enterprise_id=4",
+		"mta", "", "application/x-ndjson", "queue"))
+	if w.Code != http.StatusAccepted || w.Header().Get("Location") != "/api/v1/users/imports/9" {
+		t.Errorf("queued: %d %v %s", w.Code, w.Header(), w.Body)
+	}
+	if imp := imports.imp; imports.format != domain.ImportFormatJSONL || imp.Mode != domain.ImportModeBestEffort ||
+		imp.Invite || imp.EnterpriseID == nil || *imp.EnterpriseID != 4 {
+		t.Errorf("import = %+v", imp)
+	}
+
+	rejected := []struct {
+		name   string
+		req    *http.Request
+		status int
+		code   domain.ErrorCode
+	}{
+		{"content type", importRequest(http.MethodPost, "/users/import", "mta", "", "application/json", "{}"),
+			http.StatusUnsupportedMediaType, domain.CodeUnsupportedMediaType},
+		{"mode", importRequest(http.MethodPost, "/users/import?mode=some", "mta", "", "text/csv", "rows"),
+			http.StatusBadRequest, domain.CodeValidationFailed},
+		{"other enterprise", importRequest(http.MethodPost, "/users/import?enterprise_id=4", "eta", "3", "text/csv", "rows"),
+			http.StatusForbidden, domain.CodeEnterpriseOutOfScope},
+		{"too large", importRequest(http.MethodPost, "/users/import", "mta", "", "text/csv", strings.Repeat("x", 10<<20+1)),
+			http.StatusBadRequest, domain.CodeInvalidInput},
+		{"import of other enterprise", importRequest(http.MethodGet, "/users/imports/9", "eta", "3", "", ""),
+			http.StatusNotFound, domain.CodeNotFound},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, resp := localized(r, tt.req)
+			if status != tt.status || resp.Code != string(tt.code) {
+				t.Errorf("got %d %s, want %d %s", status, resp.Code, tt.status, tt.code)
+			}
+		})
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest(http.MethodGet, "/users/imports/9", "eta", "1", "", ""))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"processing"`) {
+		t.Errorf("get: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestUserImportPostgres(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	enterpriseID, _ := seedEnterprise(t, pool, "import")
+	repo := postgres.NewUserImportPostgres(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	imp := &domain.UserImport{
+		EnterpriseID: &enterpriseID,
+		Mode:         domain.ImportModeBestEffort,
+		Invite:       true,
+		Status:       domain.ImportStatusPending,
+		Rows:         []domain.UserImportRow{{Line: 2, Username: "ada", Email: "ada@example.com", FullName: "Ada"}},
+	}
+	if err := repo.Create(ctx, imp); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := repo.Claim(ctx, imp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed.Status != domain.ImportStatusProcessing || len(claimed.Rows) != 1 || claimed.Rows[0].Username != "ada" ||
+		claimed.Mode != imp.Mode || !claimed.Invite || *claimed.EnterpriseID != enterpriseID {
+		t.Errorf("claimed = %+v", claimed)
+	}
+
+	claimed.Status = domain.ImportStatusCompleted
+	claimed.Results = []domain.UserImportResult{{Line: 2, Status: domain.ImportRowFailed, Code: domain.CodeValidationFailed,
+		Violations: map[string]domain.Violation{"email": {Rule: "taken"}}}}
+	if err := repo.Finish(ctx, claimed); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(ctx, imp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != domain.ImportStatusCompleted || got.CompletedAt == nil || len(got.Results) != 1 ||
+		got.Results[0].Violations["email"].Rule != "taken" {
+		t.Errorf("finished = %+v", got)
+	}
+	if _, err := repo.Claim(ctx, imp.ID); domain.ErrorCodeOf(err) != domain.CodeNotFound {
+		t.Errorf("claiming a finished import: %v", err)
+	}
+}
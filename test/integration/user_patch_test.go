@@ -0,0 +1,333 @@
+// test/integration/user_patch_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"my-application/api/openapi"
+	"my-application/internal/api/handler"
+	"my-application/internal/api/interceptor"
+	"my-application/internal/api/middleware"
+	"my-application/internal/api/router"
+	"my-application/internal/domain"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+)
+
+// versionedUsers holds user 1 at version 4 and records the last change.
+type versionedUsers struct {
+	service.UserService
+	patch   domain.UserPatch
+	update  *domain.User
+	version int64
+}
+
+func (s *versionedUsers) GetUser(_ context.Context, id int64) (*domain.User, error) {
+	if id != 1 {
+		return nil, domain.NewAppError(domain.ErrNotFound, "user not found")
+	}
+	return &domain.User{ID: 1, Username: "ada", Role: "eta", IsActive: true, Version: 4}, nil
+}
+
+func (s *versionedUsers) UpdateUser(_ context.Context, user *domain.User) error {
+	update := *user
+	s.update = &update
+	if user.Version != 0 && user.Version != 4 {
+		return domain.NewAppError(domain.ErrPreconditionFailed, "user with id 1 has changed since version 3")
+	}
+	user.Version = 5
+	return nil
+}
+
+func (s *versionedUsers) PatchUser(_ context.Context, id int64, patch domain.UserPatch, version int64) (*domain.User, error) {
+	s.patch, s.version = patch, version
+	if version != 0 && version != 4 {
+		return nil, domain.NewAppError(domain.ErrPreconditionFailed, "user with id 1 has changed since version 3")
+	}
+	user := &domain.User{ID: id, Username: "ada", Role: "eta", IsActive: true, Version: 5}
+	patch.Apply(user)
+	return user, nil
+}
+
+func versionedRouter() (*gin.Engine, *versionedUsers) {
+	return versionedRouterAs(0, "")
+}
+
+// versionedRouterAs is versionedRouter for requests authenticated as the
+// user with userID and role.
+func versionedRouterAs(userID int64, role string) (*gin.Engine, *versionedUsers) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	users := &versionedUsers{}
+	h := handler.NewUserHandler(users, log)
+	r := gin.New()
+	if userID != 0 {
+		r.Use(func(c *gin.Context) {
+			c.Set(middleware.ContextKeyUserID, userID)
+			c.Set(middleware.ContextKeyUserRole, role)
+		})
+	}
+	r.GET("/users/:id", h.GetByID)
+	r.PUT("/users/:id", h.Update)
+	r.PATCH("/users/:id", h.Patch)
+	return r, users
+}
+
+func userRequest(method, body, contentType, ifMatch string) *http.Request {
+	req := httptest.NewRequest(method, "/users/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return req
+}
+
+func TestPatchUserMergesFields(t *testing.T) {
+	r, users := versionedRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, userRequest(http.MethodPatch, `{"full_name":"Ada Lovelace","locale":null}`,
+		"application/merge-patch+json", `"4"`))
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"5"` {
+		t.Fatalf("patch: %d, ETag %q: %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+	p := users.patch
+	if p.FullName == nil || *p.FullName != "Ada Lovelace" || p.Locale == nil || *p.Locale != "" ||
+		p.Username != nil || p.Email != nil || p.Role != nil || p.IsActive != nil || users.version != 4 {
+		t.Errorf("patch = %+v, version %d", p, users.version)
+	}
+
+	// Plain JSON is accepted too, and If-Match is optional.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, userRequest(http.MethodPatch, `{"is_active":false}`, "application/json", ""))
+	if w.Code != http.StatusOK || users.patch.IsActive == nil || *users.patch.IsActive || users.version != 0 {
+		t.Errorf("plain JSON: %d, patch %+v, version %d", w.Code, users.patch, users.version)
+	}
+}
+
+func TestPatchUserRejectsBadPatches(t *testing.T) {
+	r, _ := versionedRouter()
+	tests := []struct {
+		body  string
+		field string
+		want  string
+	}{
+		{`{"username":null}`, "username", "username is required"},
+		{`{"is_active":"yes"}`, "is_active", "active has the wrong type"},
+		{`{"full_name":"Ada","created_at":"2026-01-01T00:00:00Z"}`, "created_at",
+			"created_at is not a field that can be changed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			status, _, resp := localized(r, userRequest(http.MethodPatch, tt.body, "application/merge-patch+json", ""))
+			errs, _ := resp.Errors.(map[string]interface{}) //nolint:errcheck // nil map fails the check
+			if status != http.StatusBadRequest || errs[tt.field] != tt.want {
+				t.Errorf("got %d %v, want 400 with %s: %q", status, resp.Errors, tt.field, tt.want)
+			}
+		})
+	}
+
+	if status, _, resp := localized(r, userRequest(http.MethodPatch, `[{"op":"replace"}]`, "application/merge-patch+json", "")); status != http.StatusBadRequest || resp.Code != string(domain.CodeInvalidInput) {
+		t.Errorf("non-object patch: %d %s", status, resp.Code)
+	}
+	if status, _, resp := localized(r, userRequest(http.MethodPatch, `{}`, "application/json-patch+json", "")); status != http.StatusUnsupportedMediaType || resp.Code != string(domain.CodeUnsupportedMediaType) {
+		t.Errorf("JSON Patch: %d %s", status, resp.Code)
+	}
+}
+
+func TestUserIfMatch(t *testing.T) {
+	r, _ := versionedRouter()
+	tests := []struct {
+		ifMatch string
+		status  int
+		code    domain.ErrorCode
+	}{
+		{`"4"`, http.StatusOK, ""},
+		{`*`, http.StatusOK, ""},
+		{`"3"`, http.StatusPreconditionFailed, domain.CodePreconditionFailed},
+		{`W/"4"`, http.StatusPreconditionFailed, domain.CodePreconditionFailed},
+		{`"v4"`, http.StatusPreconditionFailed, domain.CodePreconditionFailed},
+		{`"3", "4"`, http.StatusBadRequest, domain.CodeInvalidInput},
+		{`4`, http.StatusBadRequest, domain.CodeInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ifMatch, func(t *testing.T) {
+			status, _, resp := localized(r, userRequest(http.MethodPatch, `{"full_name":"Ada"}`, "application/json", tt.ifMatch))
+			if status != tt.status || resp.Code != string(tt.code) {
+				t.Errorf("PATCH: %d %s, want %d %s", status, resp.Code, tt.status, tt.code)
+			}
+		})
+	}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	if w := get(""); w.Code != http.StatusOK || w.Header().Get("ETag") != `"4"` {
+		t.Errorf("GET: %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+	for _, tag := range []string{`"4"`, `W/"4"`, `"2", "4"`} {
+		if w := get(tag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("GET If-None-Match %s: %d", tag, w.Code)
+		}
+	}
+	if w := get(`"3"`); w.Code != http.StatusOK {
+		t.Errorf("GET stale If-None-Match: %d", w.Code)
+	}
+}
+
+func TestPutReplacesUser(t *testing.T) {
+	r, users := versionedRouter()
+
+	body := `{"username":"ada","email":"ada@example.com","full_name":"Ada","role":"eta","is_active":true}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, userRequest(http.MethodPut, body, "application/json", `"4"`))
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"5"` {
+		t.Fatalf("PUT: %d, ETag %q: %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+	// An omitted locale is part of the replacement: it is cleared.
+	if u := users.update; u.Locale != nil || !u.IsActive || u.Version != 4 {
+		t.Errorf("update = %+v", u)
+	}
+
+	status, _, resp := localized(r, userRequest(http.MethodPut, `{"username":"ada","email":"ada@example.com","full_name":"Ada"}`,
+		"application/json", ""))
+	errs, _ := resp.Errors.(map[string]interface{}) //nolint:errcheck // nil map fails the check
+	if status != http.StatusBadRequest || errs["role"] != "role is required" || errs["is_active"] != "active is required" {
+		t.Errorf("partial PUT: %d %v", status, resp.Errors)
+	}
+
+	if status, _, resp := localized(r, userRequest(http.MethodPut, body, "application/json", `"3"`)); status != http.StatusPreconditionFailed || resp.Code != string(domain.CodePreconditionFailed) {
+		t.Errorf("stale PUT: %d %s", status, resp.Code)
+	}
+}
+
+func TestUserRoleChanges(t *testing.T) {
+	put := func(role string) string {
+		return `{"username":"ada","email":"ada@example.com","full_name":"Ada","role":"` + role + `","is_active":true}`
+	}
+	patch := func(role string) string { return `{"role":"` + role + `"}` }
+
+	tests := []struct {
+		name   string
+		userID int64
+		role   string
+		assign string
+		status int
+	}{
+		{"eta assigns caregiver", 2, "eta", "caregiver", http.StatusOK},
+		{"eta assigns mta", 2, "eta", "mta", http.StatusForbidden},
+		{"eta assigns robot", 2, "eta", "robot", http.StatusForbidden},
+		{"mta assigns robot", 2, "mta", "robot", http.StatusOK},
+		{"eta keeps own role", 1, "eta", "eta", http.StatusOK},
+		{"eta changes own role", 1, "eta", "caregiver", http.StatusForbidden},
+		{"eta promotes self", 1, "eta", "mta", http.StatusForbidden},
+		{"mta changes own role", 1, "mta", "eta", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for method, body := range map[string]string{http.MethodPut: put(tt.assign), http.MethodPatch: patch(tt.assign)} {
+				r, users := versionedRouterAs(tt.userID, tt.role)
+				status, _, resp := localized(r, userRequest(method, body, "application/json", ""))
+				if status != tt.status {
+					t.Errorf("%s: %d, want %d", method, status, tt.status)
+				}
+				if tt.status != http.StatusForbidden {
+					continue
+				}
+				if resp.Code != string(domain.CodeRoleNotAllowed) || users.update != nil || users.patch.Role != nil {
+					t.Errorf("%s: code %s, update %+v, patch %+v", method, resp.Code, users.update, users.patch)
+				}
+			}
+		})
+	}
+
+	// A patch leaving the role alone is not a role change.
+	r, _ := versionedRouterAs(1, "eta")
+	if status, _, _ := localized(r, userRequest(http.MethodPatch, `{"full_name":"Ada"}`, "application/json", "")); status != http.StatusOK {
+		t.Errorf("own profile: %d", status)
+	}
+}
+
+func TestOpenAPIAcceptsMergePatch(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(t, router.Config{ValidateRequests: true, OpenAPI: doc})
+
+	patch := func(body string) (int, interceptor.APIResponse) {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		status, _, resp := localized(r, req)
+		return status, resp
+	}
+	// The document's checks pass, so the request reaches authentication.
+	if status, resp := patch(`{"locale":null}`); status != http.StatusUnauthorized {
+		t.Errorf("null locale: %d %v", status, resp.Errors)
+	}
+	if status, _ := patch(`{"password":"secret"}`); status != http.StatusBadRequest {
+		t.Errorf("unknown member: %d, want 400", status)
+	}
+}
+
+func TestUserVersioning(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := postgres.NewUserPostgres(pool, log)
+
+	name := fmt.Sprintf("ver%d", time.Now().UnixNano()%1e9)
+	t.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), `DELETE FROM users WHERE username = $1`, name); err != nil {
+			t.Errorf("removing user: %v", err)
+		}
+	})
+	user := &domain.User{Username: name, Email: name + "@example.com", FullName: "Version Tester", Role: "caregiver", IsActive: true}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Version != 1 {
+		t.Fatalf("new user has version %d", user.Version)
+	}
+
+	user.FullName = "Version Tester II"
+	if err := repo.Update(ctx, user); err != nil || user.Version != 2 {
+		t.Fatalf("conditional update: %v, version %d", err, user.Version)
+	}
+
+	stale := *user
+	stale.Version = 1
+	if err := repo.Update(ctx, &stale); !errors.Is(err, domain.ErrPreconditionFailed) {
+		t.Errorf("stale update: %v", err)
+	}
+	missing := stale
+	missing.ID = -1
+	if err := repo.Update(ctx, &missing); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("update of a missing user: %v", err)
+	}
+
+	// Columns the profile doesn't show leave the version alone.
+	if _, err := pool.Exec(ctx, `UPDATE users SET password_hash = 'x' WHERE id = $1`, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil || got.Version != 2 {
+		t.Errorf("after a password change: %v, version %d", err, got.Version)
+	}
+}
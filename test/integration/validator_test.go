@@ -0,0 +1,210 @@
+// test/integration/validator_test.go
+package integration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	"my-application/api/proto/sonav1"
+	"my-application/internal/api/handler"
+	"my-application/internal/api/middleware"
+	"my-application/internal/auth"
+	"my-application/internal/domain"
+	"my-application/internal/service"
+	"my-application/internal/validator"
+	pkgvalidator "my-application/pkg/validator"
+)
+
+func TestCustomRules(t *testing.T) {
+	phones := map[string]bool{
+		"+34600123456":            true,
+		"+34 600 123 456":         true,
+		"+1 (555) 010-0199":       true,
+		"600123456":               false,
+		"+0 600 123 456":          false,
+		"+34 600":                 false,
+		"+34 600 123 456 7890123": false,
+	}
+	for phone, want := range phones {
+		if got := pkgvalidator.IsPhone(phone); got != want {
+			t.Errorf("IsPhone(%q) = %v, want %v", phone, got, want)
+		}
+	}
+
+	zones := map[string]bool{
+		"Europe/Madrid":    true,
+		"America/New_York": true,
+		"UTC":              true,
+		"":                 false,
+		"Local":            false,
+		"Mars/Olympus":     false,
+	}
+	for zone, want := range zones {
+		if got := pkgvalidator.IsTimeZone(zone); got != want {
+			t.Errorf("IsTimeZone(%q) = %v, want %v", zone, got, want)
+		}
+	}
+}
+
+// validationDetails returns the field details of a validation error, or
+// nil if err isn't one.
+func validationDetails(err error) map[string]string {
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || len(appErr.Details) == 0 {
+		return nil
+	}
+	return appErr.Details
+}
+
+func TestStructReportsJSONPaths(t *testing.T) {
+	type contact struct {
+		Phone    string `json:"phone" binding:"required,phone"`
+		TimeZone string `json:"time_zone" binding:"omitempty,timezone"`
+	}
+	type form struct {
+		Role     string    `json:"role" binding:"required,sona_role"`
+		Locale   string    `json:"locale" binding:"omitempty,locale"`
+		Primary  contact   `json:"primary"`
+		Contacts []contact `json:"contacts" binding:"dive"`
+	}
+
+	err := validator.Struct(form{
+		Role:     "root",
+		Locale:   "fr",
+		Primary:  contact{Phone: "+34 600 123 456", TimeZone: "Mars/Olympus"},
+		Contacts: []contact{{Phone: "+34 600 123 456"}, {Phone: "600"}},
+	})
+	want := map[string]string{
+		"role":              "role must be one of: " + strings.Join(domain.Roles, ", "),
+		"locale":            "language must be one of the supported languages: en, es",
+		"primary.time_zone": "primary.time_zone must be a time zone such as Europe/Madrid",
+		"contacts[1].phone": "contacts[1].phone must be an international phone number such as +34 600 123 456",
+	}
+	if got := validationDetails(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("details = %v, want %v", got, want)
+	}
+
+	if err := validator.Struct(form{Role: "eta", Primary: contact{Phone: "+34600123456"}}); err != nil {
+		t.Errorf("valid form: %v", err)
+	}
+}
+
+func TestUserRules(t *testing.T) {
+	locale := func(s string) *string { return &s }
+	valid := domain.User{Username: "ada", Email: "ada@example.com", FullName: "Ada Lovelace"}
+
+	tests := []struct {
+		name   string
+		change func(*domain.User)
+		want   map[string]string
+	}{
+		{"valid", func(*domain.User) {}, nil},
+		{"default role and locale", func(u *domain.User) { u.Role, u.Locale = "", locale("") }, nil},
+		{"blank username", func(u *domain.User) { u.Username = "   " }, map[string]string{
+			"username": "username is required",
+		}},
+		{"short username", func(u *domain.User) { u.Username = " ab " }, map[string]string{
+			"username": "username must be at least 3 characters",
+		}},
+		{"email", func(u *domain.User) { u.Email = "ada" }, map[string]string{
+			"email": "email must be a valid email address",
+		}},
+		{"role", func(u *domain.User) { u.Role = "root" }, map[string]string{
+			"role": "role must be one of: " + strings.Join(domain.Roles, ", "),
+		}},
+		{"locale", func(u *domain.User) { u.Locale = locale("fr") }, map[string]string{
+			"locale": "language must be one of the supported languages: en, es",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := valid
+			tt.change(&user)
+			err := validator.User(&user)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("User: %v", err)
+				}
+				return
+			}
+			if got := validationDetails(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("details = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRulesAreSharedAcrossAPIs sends the same invalid input through REST,
+// gRPC and the user service and expects the same field errors from each.
+func TestRulesAreSharedAcrossAPIs(t *testing.T) {
+	want := map[string]string{
+		"username": "username must be at least 3 characters",
+		"email":    "email must be a valid email address",
+		"password": "password must be at least 8 characters",
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := gin.New()
+	r.Use(middleware.Locale("en"))
+	r.POST("/register", auth.NewHandler(stubAuth{}, log).Register)
+	req := httptest.NewRequest(http.MethodPost, "/register",
+		strings.NewReader(`{"username":"ab","email":"ab","password":"short","full_name":"Ab"}`))
+	req.Header.Set("Content-Type", "application/json")
+	status, _, resp := localized(r, req)
+	if status != http.StatusBadRequest {
+		t.Fatalf("REST status = %d", status)
+	}
+	rest := make(map[string]string)
+	errs, _ := resp.Errors.(map[string]interface{}) //nolint:errcheck // nil map fails the comparison
+	for k, v := range errs {
+		rest[k], _ = v.(string) //nolint:errcheck // non-strings fail the comparison
+	}
+	if !reflect.DeepEqual(rest, want) {
+		t.Errorf("REST errors = %v, want %v", rest, want)
+	}
+
+	env := newGRPCEnv(t)
+	st := wantCode(t, mustFail(sonav1.NewAuthServiceClient(env.conn).Register(context.Background(),
+		&sonav1.RegisterRequest{Username: "ab", Email: "ab", Password: "short", FullName: "Ab"})), codes.InvalidArgument)
+	grpcErrs := make(map[string]string)
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				grpcErrs[v.GetField()] = v.GetDescription()
+			}
+		}
+	}
+	if !reflect.DeepEqual(grpcErrs, want) {
+		t.Errorf("gRPC violations = %v, want %v", grpcErrs, want)
+	}
+
+	users := service.NewUserService(nil, nil, nil, log)
+	err := users.CreateUser(context.Background(), &domain.User{Username: "ab", Email: "ab", FullName: "Ab"})
+	delete(want, "password")
+	if got := validationDetails(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("service details = %v, want %v", got, want)
+	}
+
+	// The binding tags of request types use the application's rules.
+	r = gin.New()
+	r.POST("/users", handler.NewUserHandler(service.NewUserService(nil, nil, nil, log), log).Create)
+	req = httptest.NewRequest(http.MethodPost, "/users",
+		strings.NewReader(`{"username":"ada","email":"ada@example.com","full_name":"Ada","role":"root","locale":"fr"}`))
+	req.Header.Set("Content-Type", "application/json")
+	status, _, resp = localized(r, req)
+	errs, _ = resp.Errors.(map[string]interface{}) //nolint:errcheck // nil map fails the check
+	if status != http.StatusBadRequest || errs["role"] == nil || errs["locale"] == nil {
+		t.Errorf("create user: %d %v", status, resp.Errors)
+	}
+}
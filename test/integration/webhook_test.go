@@ -0,0 +1,411 @@
+// test/integration/webhook_test.go
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"my-application/internal/domain"
+	"my-application/internal/jobs"
+	"my-application/internal/repository"
+	"my-application/internal/repository/postgres"
+	"my-application/internal/service"
+	"my-application/internal/webhook"
+	"my-application/internal/worker"
+	"my-application/pkg/database"
+	"my-application/pkg/httputil"
+)
+
+const testSecret = "whsec_test"
+
+// Test receivers listen on loopback, so senders allow private networks
+// unless a test says otherwise.
+
+// receiver records the last request it got and answers with status.
+type receiver struct {
+	status    int
+	header    http.Header
+	body      []byte
+	verifyErr error
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.header = r.Header.Clone()
+	rc.body, _ = io.ReadAll(r.Body)
+	rc.verifyErr = webhook.Verify(testSecret, r.Header.Get(webhook.HeaderSignature), rc.body, 5*time.Minute, time.Now())
+	w.WriteHeader(rc.status)
+	io.WriteString(w, "receiver says hi") //nolint:errcheck // test response body
+}
+
+func sendTo(t *testing.T, url string) (webhook.Result, error) {
+	t.Helper()
+	return webhook.NewSender(2*time.Second, true).Send(context.Background(), webhook.Request{
+		URL:       url,
+		Secret:    testSecret,
+		EventID:   "0b7e7f2c-2d1c-4d6c-9a55-0e8f0b0d7a11",
+		EventType: "incident.created",
+		Body:      []byte(`{"id":"0b7e7f2c-2d1c-4d6c-9a55-0e8f0b0d7a11","type":"incident.created"}`),
+	})
+}
+
+func TestWebhookSenderSignsDelivery(t *testing.T) {
+	rc := &receiver{status: http.StatusNoContent}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	result, err := sendTo(t, srv.URL)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", result.StatusCode, http.StatusNoContent)
+	}
+	if rc.verifyErr != nil {
+		t.Errorf("receiver could not verify signature: %v", rc.verifyErr)
+	}
+	if got := rc.header.Get(webhook.HeaderEventID); got != "0b7e7f2c-2d1c-4d6c-9a55-0e8f0b0d7a11" {
+		t.Errorf("%s = %q", webhook.HeaderEventID, got)
+	}
+	if got := rc.header.Get(webhook.HeaderEventType); got != "incident.created" {
+		t.Errorf("%s = %q", webhook.HeaderEventType, got)
+	}
+	if got := rc.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+}
+
+func TestWebhookSenderReportsFailures(t *testing.T) {
+	t.Run("error status", func(t *testing.T) {
+		srv := httptest.NewServer(&receiver{status: http.StatusServiceUnavailable})
+		defer srv.Close()
+
+		result, err := sendTo(t, srv.URL)
+		if err == nil {
+			t.Fatal("Send succeeded on a 503")
+		}
+		if result.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want 503", result.StatusCode)
+		}
+		if err.Error() != "receiver responded 503" {
+			t.Errorf("error = %q, want the status only, not the response body", err)
+		}
+	})
+
+	t.Run("redirect is not followed", func(t *testing.T) {
+		target := &receiver{status: http.StatusOK}
+		dst := httptest.NewServer(target)
+		defer dst.Close()
+		srv := httptest.NewServer(http.RedirectHandler(dst.URL, http.StatusFound))
+		defer srv.Close()
+
+		result, err := sendTo(t, srv.URL)
+		if err == nil || result.StatusCode != http.StatusFound {
+			t.Fatalf("Send = %d, %v; want a 302 failure", result.StatusCode, err)
+		}
+		if target.body != nil {
+			t.Error("redirect target received the delivery")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		sender := webhook.NewSender(50*time.Millisecond, true)
+		result, err := sender.Send(context.Background(), webhook.Request{URL: srv.URL, Secret: testSecret, Body: []byte(`{}`)})
+		if err == nil {
+			t.Fatal("Send succeeded against a hanging receiver")
+		}
+		if result.StatusCode != 0 {
+			t.Errorf("status = %d, want 0 for no response", result.StatusCode)
+		}
+	})
+}
+
+func TestWebhookSenderRefusesPrivateAddresses(t *testing.T) {
+	rc := &receiver{status: http.StatusOK}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	sender := webhook.NewSender(2*time.Second, false)
+	for _, url := range []string{srv.URL, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)} {
+		result, err := sender.Send(context.Background(), webhook.Request{URL: url, Secret: testSecret, Body: []byte(`{}`)})
+		if !errors.Is(err, httputil.ErrForbiddenAddress) || result.StatusCode != 0 {
+			t.Errorf("Send(%s) = %d, %v; want ErrForbiddenAddress", url, result.StatusCode, err)
+		}
+	}
+	if rc.body != nil {
+		t.Error("loopback receiver got the delivery")
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":      true,
+		"2606:2800:220:1::1": true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"100.64.0.1":         false,
+		"0.0.0.0":            false,
+		"::":                 false,
+		"fd00:ec2::254":      false,
+		"fe80::1":            false,
+		"::ffff:10.0.0.1":    false,
+		"64:ff9b::a00:1":     false,
+		"224.0.0.1":          false,
+		"255.255.255.255":    false,
+	}
+	for addr, want := range tests {
+		if got := httputil.IsPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestWebhookSubscriptionRefusesPrivateURLs(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.NewWebhookService(nil, nil, nil, service.WebhookConfig{}, log)
+	for _, url := range []string{
+		"https://127.0.0.1/hook",
+		"https://169.254.169.254/latest/meta-data/",
+		"https://[::1]:8443/hook",
+		"https://10.0.0.7/hook",
+		"https://localhost/hook",
+	} {
+		sub := &domain.WebhookSubscription{EnterpriseID: 1, URL: url, EventTypes: []string{domain.WebhookEventTypes[0]}}
+		err := svc.CreateSubscription(context.Background(), sub, 1)
+		var appErr *domain.AppError
+		if !errors.As(err, &appErr) || appErr.Details["url"] == "" {
+			t.Errorf("CreateSubscription(%s) = %v, want a url validation error", url, err)
+		}
+	}
+}
+
+func TestWebhookVerify(t *testing.T) {
+	body := []byte(`{"type":"robot.status_changed"}`)
+	now := time.Now()
+	header := webhook.Sign(testSecret, now, body)
+
+	if err := webhook.Verify(testSecret, header, body, time.Minute, now); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := webhook.Verify(testSecret, header, []byte(`{"type":"tampered"}`), time.Minute, now); !errors.Is(err, webhook.ErrSignatureMismatch) {
+		t.Errorf("tampered body: %v", err)
+	}
+	if err := webhook.Verify("whsec_other", header, body, time.Minute, now); !errors.Is(err, webhook.ErrSignatureMismatch) {
+		t.Errorf("wrong secret: %v", err)
+	}
+	if err := webhook.Verify(testSecret, header, body, time.Minute, now.Add(2*time.Minute)); !errors.Is(err, webhook.ErrTimestampExpired) {
+		t.Errorf("replayed signature: %v", err)
+	}
+	if err := webhook.Verify(testSecret, "v1=abc", body, time.Minute, now); !errors.Is(err, webhook.ErrMalformedSignature) {
+		t.Errorf("missing timestamp: %v", err)
+	}
+
+	// A receiver accepts any of several v1 signatures, which allows secret rotation.
+	rotated := header + ",v1=" + strings.Repeat("0", 64)
+	if err := webhook.Verify(testSecret, rotated, body, time.Minute, now); err != nil {
+		t.Errorf("signature among several rejected: %v", err)
+	}
+}
+
+func TestGenerateSecretIsUnique(t *testing.T) {
+	a, errA := webhook.GenerateSecret()
+	b, errB := webhook.GenerateSecret()
+	if errA != nil || errB != nil {
+		t.Fatalf("GenerateSecret: %v, %v", errA, errB)
+	}
+	if a == b || !strings.HasPrefix(a, "whsec_") {
+		t.Errorf("secrets %q and %q", a, b)
+	}
+}
+
+func TestWebhookWorkerRetriesAndDisables(t *testing.T) {
+	pool := testPool(t)
+	entID, _ := seedEnterprise(t, pool, "webhook")
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	rc := &receiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	repo := postgres.NewWebhookPostgres(pool, log)
+	sub := &domain.WebhookSubscription{
+		EnterpriseID: entID, URL: srv.URL, Secret: testSecret, IsActive: true,
+		EventTypes: []string{domain.RealtimeIncidentCreated},
+	}
+	if err := repo.Create(ctx, sub); err != nil {
+		t.Fatalf("creating subscription: %v", err)
+	}
+
+	newDelivery := func(eventID string) *domain.WebhookDelivery {
+		t.Helper()
+		d := &domain.WebhookDelivery{
+			SubscriptionID: sub.ID, EventID: eventID, EventType: domain.RealtimeIncidentCreated,
+			Payload: []byte(`{"type":"incident.created"}`), Status: domain.WebhookDeliveryPending,
+		}
+		if err := repo.CreateDelivery(ctx, d); err != nil {
+			t.Fatalf("creating delivery: %v", err)
+		}
+		return d
+	}
+
+	w := worker.NewWebhookWorker(repo, postgres.NewAuditPostgres(pool, log), jobs.NewClient(pool), database.NewTxManager(pool),
+		webhook.NewSender(2*time.Second, true), domain.WebhookDisablePolicy{Failures: 3}, log)
+	deliver := func(d *domain.WebhookDelivery, attempt int) error {
+		job := &jobs.Job{Attempts: attempt, MaxAttempts: 3}
+		return w.Deliver(ctx, job, jobs.WebhookDeliverArgs{DeliveryID: d.ID})
+	}
+
+	// A success between failures resets the streak.
+	first := newDelivery("5f0c3b36-4a8e-4c55-8f57-1f7f7d0c0001")
+	if err := deliver(first, 1); err == nil {
+		t.Fatal("delivery to a failing receiver succeeded")
+	}
+	rc.status = http.StatusOK
+	if err := deliver(first, 2); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if rc.verifyErr != nil {
+		t.Errorf("receiver could not verify signature: %v", rc.verifyErr)
+	}
+
+	rc.status = http.StatusBadGateway
+	second := newDelivery("5f0c3b36-4a8e-4c55-8f57-1f7f7d0c0002")
+	for attempt := 1; attempt <= 3; attempt++ {
+		if err := deliver(second, attempt); err == nil {
+			t.Fatalf("attempt %d succeeded", attempt)
+		}
+	}
+
+	deliveries, _, err := repo.ListDeliveries(ctx, sub.ID, domain.WebhookDeliveryFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("listing deliveries: %v", err)
+	}
+	byID := make(map[int64]domain.WebhookDelivery)
+	for _, d := range deliveries {
+		byID[d.ID] = d
+	}
+	if d := byID[first.ID]; d.Status != domain.WebhookDeliverySucceeded || d.ResponseCode == nil || *d.ResponseCode != 200 {
+		t.Errorf("first delivery = %s/%v, want succeeded/200", d.Status, d.ResponseCode)
+	}
+	if d := byID[second.ID]; d.Status != domain.WebhookDeliveryFailed || d.Attempts != 3 || d.ResponseCode == nil || *d.ResponseCode != 502 {
+		t.Errorf("second delivery = %s after %d attempts, code %v; want failed/3/502", d.Status, d.Attempts, d.ResponseCode)
+	}
+
+	got, err := repo.Get(ctx, entID, sub.ID)
+	if err != nil {
+		t.Fatalf("loading subscription: %v", err)
+	}
+	if got.IsActive || got.DisabledAt == nil || got.ConsecutiveFailures != 3 {
+		t.Errorf("subscription active=%v disabled_at=%v failures=%d; want disabled after 3",
+			got.IsActive, got.DisabledAt, got.ConsecutiveFailures)
+	}
+
+	// Pending deliveries of a disabled subscription are given up without sending.
+	rc.body = nil
+	third := newDelivery("5f0c3b36-4a8e-4c55-8f57-1f7f7d0c0003")
+	if err := deliver(third, 1); err != nil {
+		t.Fatalf("delivery for disabled subscription: %v", err)
+	}
+	if rc.body != nil {
+		t.Error("disabled subscription was sent a delivery")
+	}
+}
+
+// memWebhooks has two subscriptions to incident.created in enterprise 3 and
+// stores deliveries in memory.
+type memWebhooks struct {
+	repository.WebhookRepository
+	deliveries []domain.WebhookDelivery
+}
+
+func (r *memWebhooks) ListSubscribed(_ context.Context, enterpriseID int64, eventType string) ([]domain.WebhookSubscription, error) {
+	if enterpriseID != 3 || eventType != domain.EventIncidentCreated {
+		return nil, nil
+	}
+	return []domain.WebhookSubscription{{ID: 10, EnterpriseID: 3}, {ID: 11, EnterpriseID: 3}}, nil
+}
+
+func (r *memWebhooks) CreateDelivery(_ context.Context, d *domain.WebhookDelivery) error {
+	d.ID = int64(len(r.deliveries) + 1)
+	r.deliveries = append(r.deliveries, *d)
+	return nil
+}
+
+func TestWebhookFanoutFromDomainEvents(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo, queue := &memWebhooks{}, &importRepos{}
+	w := worker.NewWebhookWorker(repo, queue.audit, queue, queue, webhook.NewSender(time.Second, false),
+		domain.WebhookDisablePolicy{}, log)
+	ctx := context.Background()
+	three := int64(3)
+
+	event, err := domain.NewIncidentEvent(domain.EventIncidentCreated, &three, domain.IncidentEventPayload{
+		IncidentID: 5, ResidentID: 6, Severity: "high",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Fanout(ctx, event); err != nil {
+		t.Fatalf("Fanout: %v", err)
+	}
+
+	if len(repo.deliveries) != 2 || len(queue.jobs) != 2 {
+		t.Fatalf("got %d deliveries and %d jobs, want 2 of each", len(repo.deliveries), len(queue.jobs))
+	}
+	for i, d := range repo.deliveries {
+		if d.SubscriptionID != int64(10+i) || d.Status != domain.WebhookDeliveryPending {
+			t.Errorf("delivery %d = subscription %d, %s; want %d, pending", i, d.SubscriptionID, d.Status, 10+i)
+		}
+		if args, ok := queue.jobs[i].(jobs.WebhookDeliverArgs); !ok || args.DeliveryID != d.ID {
+			t.Errorf("job %d = %#v, want delivery %d", i, queue.jobs[i], d.ID)
+		}
+	}
+	if repo.deliveries[0].EventID != repo.deliveries[1].EventID {
+		t.Error("subscriptions got different event IDs for one event")
+	}
+
+	var body domain.WebhookPayload
+	if err := json.Unmarshal(repo.deliveries[0].Payload, &body); err != nil {
+		t.Fatal(err)
+	}
+	var data domain.IncidentEventPayload
+	if err := json.Unmarshal(body.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if body.ID != repo.deliveries[0].EventID || body.Type != domain.EventIncidentCreated || body.EnterpriseID != 3 ||
+		data.IncidentID != 5 || data.ResidentID != 6 {
+		t.Errorf("payload = %+v with data %+v", body, data)
+	}
+
+	// Events without an enterprise, or nobody subscribed to, create nothing.
+	orphan, _ := domain.NewIncidentEvent(domain.EventIncidentCreated, nil, domain.IncidentEventPayload{IncidentID: 7})
+	resolved, _ := domain.NewIncidentEvent(domain.EventIncidentResolved, &three, domain.IncidentEventPayload{IncidentID: 5})
+	for _, e := range []*domain.DomainEvent{orphan, resolved} {
+		if err := w.Fanout(ctx, e); err != nil {
+			t.Fatalf("Fanout %s: %v", e.Type, err)
+		}
+	}
+	if len(repo.deliveries) != 2 || len(queue.jobs) != 2 {
+		t.Errorf("unsubscribed events created deliveries: %d deliveries, %d jobs", len(repo.deliveries), len(queue.jobs))
+	}
+}